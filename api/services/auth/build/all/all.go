@@ -25,8 +25,10 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	})
 
 	authapp.Routes(app, authapp.Config{
-		AuthBus: cfg.BusConfig.AuthBus,
-		UserBus: cfg.BusConfig.UserBus,
-		Auth:    cfg.AuthConfig.Auth,
+		AuthBus:         cfg.BusConfig.AuthBus,
+		UserBus:         cfg.BusConfig.UserBus,
+		Auth:            cfg.AuthConfig.Auth,
+		RequireAdminMFA: cfg.AuthConfig.RequireAdminMFA,
+		OAuthProviders:  cfg.AuthConfig.OAuthProviders,
 	})
 }
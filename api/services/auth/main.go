@@ -9,17 +9,25 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
+	"github.com/markbates/goth"
 
 	"github.com/rmsj/service/api/services/auth/build/all"
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/debug"
+	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/app/sdk/mux"
+	"github.com/rmsj/service/app/sdk/oauth"
+	"github.com/rmsj/service/business/domain/auditbus"
+	"github.com/rmsj/service/business/domain/auditbus/stores/auditdb"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/authbus/stores/authdb"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/rolebus/stores/roledb"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/domain/userbus/stores/userdb"
 	"github.com/rmsj/service/business/sdk/delegate"
@@ -76,6 +84,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 			APIHost            string        `conf:"default:0.0.0.0:6000"`
 			DebugHost          string        `conf:"default:0.0.0.0:6010"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			TrustedProxies     []string
 		}
 		Auth struct {
 			KeysEnvVar string
@@ -83,6 +92,19 @@ func run(ctx context.Context, log *logger.Logger) error {
 			ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
 			Issuer     string `conf:"default:service project"`
 			APIKey     string `conf:"default:api_key"`
+			PolicyDir  string
+			PolicyPoll time.Duration `conf:"default:10s"`
+
+			RequireAdminMFA bool `conf:"default:false"`
+		}
+		OAuth struct {
+			CallbackURL        string `conf:"default:http://localhost:6000/v1/auth/oauth"`
+			GoogleClientID     string
+			GoogleClientSecret string `conf:"mask"`
+			OIDCName           string `conf:"default:oidc"`
+			OIDCIssuer         string
+			OIDCClientID       string
+			OIDCClientSecret   string `conf:"mask"`
 		}
 		DB struct {
 			User         string `conf:"default:db_user"`
@@ -155,8 +177,14 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// Create Business Packages
 
 	dlg := delegate.New(log)
+
+	// The audit business only listens for delegate events raised by the
+	// other domains, such as a user account being locked.
+	auditbus.NewBusiness(log, dlg, auditdb.NewStore(log, db))
+
 	userBus := userbus.NewBusiness(log, dlg, userdb.NewStore(log, db, time.Second*30))
-	authBus := authbus.NewBusiness(log, authdb.NewStore(log, db))
+	authBus := authbus.NewBusiness(log, dlg, authdb.NewStore(log, db, 10*time.Second))
+	roleBus := rolebus.NewBusiness(log, roledb.NewStore(log, db, 10*time.Second))
 
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
@@ -187,10 +215,13 @@ func run(ctx context.Context, log *logger.Logger) error {
 	authCfg := auth.Config{
 		Log:       log,
 		UserBus:   userBus,
+		AuthBus:   authBus,
+		RoleBus:   roleBus,
 		KeyLookup: ks,
 		Issuer:    cfg.Auth.Issuer,
 		APIKey:    cfg.Auth.APIKey,
 		ActiveKID: cfg.Auth.ActiveKID,
+		PolicyDir: cfg.Auth.PolicyDir,
 	}
 
 	ath, err := auth.New(authCfg)
@@ -198,6 +229,74 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	// Reload the keys when asked, so keys rotated on disk are picked up
+	// without a restart.
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+
+	go func() {
+		for range reload {
+			n, err := ks.Reload()
+			if err != nil {
+				log.Error(ctx, "keystore", "status", "reload failed", "msg", err)
+				continue
+			}
+			log.Info(ctx, "keystore", "status", "keys reloaded", "keys", n, "activeKID", ath.ActiveKID())
+		}
+	}()
+
+	// Watch the policy directory, so policy bundles changed on disk are
+	// validated and swapped in without a restart. The watch stops when the
+	// service shuts down.
+
+	log.Info(ctx, "startup", "status", "policy loaded", "source", ath.Policy().Source, "revision", ath.Policy().Revision)
+
+	policyCtx, stopPolicy := context.WithCancel(ctx)
+	defer stopPolicy()
+
+	go ath.WatchPolicy(policyCtx, cfg.Auth.PolicyPoll)
+
+	// -------------------------------------------------------------------------
+	// Identity Provider Support
+
+	// A provider is enabled when its client id is set. The endpoints and keys
+	// are discovered from the issuer.
+
+	providers := make(goth.Providers)
+
+	oauthCfgs := []oauth.Config{
+		{
+			Name:         "google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     cfg.OAuth.GoogleClientID,
+			ClientSecret: cfg.OAuth.GoogleClientSecret,
+		},
+		{
+			Name:         cfg.OAuth.OIDCName,
+			Issuer:       cfg.OAuth.OIDCIssuer,
+			ClientID:     cfg.OAuth.OIDCClientID,
+			ClientSecret: cfg.OAuth.OIDCClientSecret,
+		},
+	}
+
+	for _, oc := range oauthCfgs {
+		if oc.ClientID == "" {
+			continue
+		}
+
+		oc.CallbackURL = fmt.Sprintf("%s/%s/callback", strings.TrimSuffix(cfg.OAuth.CallbackURL, "/"), oc.Name)
+
+		provider, err := oauth.NewProvider(ctx, oc)
+		if err != nil {
+			return fmt.Errorf("constructing oauth provider[%s]: %w", oc.Name, err)
+		}
+		providers[oc.Name] = provider
+
+		log.Info(ctx, "startup", "status", "oauth provider enabled", "provider", oc.Name, "issuer", oc.Issuer)
+	}
+
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
 
@@ -239,6 +338,11 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	trustedProxies, err := mid.ParseTrustedProxies(cfg.Web.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
 	cfgMux := mux.Config{
 		Build:  build,
 		Log:    log,
@@ -249,13 +353,15 @@ func run(ctx context.Context, log *logger.Logger) error {
 			UserBus: userBus,
 		},
 		AuthConfig: mux.AuthConfig{
-			Auth: ath,
+			Auth:            ath,
+			RequireAdminMFA: cfg.Auth.RequireAdminMFA,
+			OAuthProviders:  providers,
 		},
 	}
 
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, all.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins)),
+		Handler:      mux.WebAPI(cfgMux, all.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins), mux.WithTrustedProxies(trustedProxies)),
 		ReadTimeout:  cfg.Web.ReadTimeout,
 		WriteTimeout: cfg.Web.WriteTimeout,
 		IdleTimeout:  cfg.Web.IdleTimeout,
@@ -0,0 +1,28 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func Test_Auth(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Auth")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.RunAuth(t, token200(sd), "token-200")
+	test.RunAuth(t, token403(sd), "token-403")
+
+	test.RunAuth(t, impersonate200(sd), "impersonate-200")
+	test.RunAuth(t, impersonate403(sd), "impersonate-403")
+}
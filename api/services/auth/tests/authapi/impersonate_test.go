@@ -0,0 +1,49 @@
+package auth_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func impersonate200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        fmt.Sprintf("/v1/auth/impersonate/%s", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			GotResp:    &tokenResp{},
+			ExpResp:    true,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got.(*tokenResp).Token != "", exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func impersonate403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "admin",
+			URL:        fmt.Sprintf("/v1/auth/impersonate/%s", sd.Admins[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusForbidden,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PermissionDenied, "user[%s] is an admin and can't be impersonated", sd.Admins[1].ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,88 @@
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/foundation/totp"
+)
+
+// password is the password of every seeded user so Basic auth can be used.
+const password = "gophers"
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	var usrs []apitest.User
+	for _, nme := range []string{"Plain User", "MFA User"} {
+		nu := userbus.NewUser{
+			Name:     name.MustParse(nme),
+			Email:    mail.Address{Address: fmt.Sprintf("%d@example.com", time.Now().UnixNano())},
+			Roles:    []role.Role{role.User},
+			Password: password,
+		}
+
+		usr, err := busDomain.User.Create(ctx, nu)
+		if err != nil {
+			return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+		}
+
+		usrs = append(usrs, apitest.User{
+			User:  usr,
+			Token: apitest.Token(busDomain.User, ath, usr.Email.Address),
+		})
+	}
+
+	// -------------------------------------------------------------------------
+
+	mfaUsr := usrs[1].User
+
+	enr, err := busDomain.Auth.EnrollMFA(ctx, mfaUsr.ID, ath.Issuer(), mfaUsr.Email.Address)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("enrolling mfa : %w", err)
+	}
+
+	code, err := totp.Code(enr.Secret, totp.Step(time.Now()))
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("generating code : %w", err)
+	}
+
+	if _, err := busDomain.Auth.VerifyMFA(ctx, mfaUsr.ID, code); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("verifying mfa : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	admins, err := userbus.TestSeedUsers(ctx, 2, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding admins : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  admins[0],
+		Token: apitest.Token(busDomain.User, ath, admins[0].Email.Address),
+	}
+
+	tu2 := apitest.User{
+		User:  admins[1],
+		Token: apitest.Token(busDomain.User, ath, admins[1].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Users:  usrs,
+		Admins: []apitest.User{tu1, tu2},
+	}
+
+	return sd, nil
+}
@@ -0,0 +1,60 @@
+package auth_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+const kid = "s4sKIjD9kIRjxs2tulPqGLdxSfgPErRN1Mu3Hd9k9NQ"
+
+type tokenResp struct {
+	Token string `json:"token"`
+}
+
+func basicAuth(usr apitest.User) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(usr.Email.Address+":"+password))
+}
+
+func token200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:          "basic",
+			URL:           fmt.Sprintf("/v1/auth/token/%s", kid),
+			Authorization: basicAuth(sd.Users[0]),
+			Method:        http.MethodGet,
+			StatusCode:    http.StatusOK,
+			GotResp:       &tokenResp{},
+			ExpResp:       true,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got.(*tokenResp).Token != "", exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func token403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:          "mfa",
+			URL:           fmt.Sprintf("/v1/auth/token/%s", kid),
+			Authorization: basicAuth(sd.Users[1]),
+			Method:        http.MethodGet,
+			StatusCode:    http.StatusForbidden,
+			GotResp:       &errs.Error{},
+			ExpResp:       errs.Newf(errs.PermissionDenied, "multi-factor authentication required, log in with /v1/auth/login"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
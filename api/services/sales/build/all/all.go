@@ -3,7 +3,12 @@ package all
 
 import (
 	"github.com/rmsj/service/app/domain/checkapp"
+	"github.com/rmsj/service/app/domain/imageapp"
+	"github.com/rmsj/service/app/domain/locationapp"
+	"github.com/rmsj/service/app/domain/pricelistapp"
 	"github.com/rmsj/service/app/domain/productapp"
+	"github.com/rmsj/service/app/domain/rateapp"
+	"github.com/rmsj/service/app/domain/roleapp"
 	"github.com/rmsj/service/app/domain/saleapp"
 	"github.com/rmsj/service/app/domain/userapp"
 	"github.com/rmsj/service/app/sdk/mux"
@@ -27,18 +32,58 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	})
 
 	productapp.Routes(app, productapp.Config{
+		Log:         cfg.Log,
+		DB:          cfg.DB,
+		UserBus:     cfg.BusConfig.UserBus,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		LocationBus: cfg.BusConfig.LocationBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+	})
+
+	imageapp.Routes(app, imageapp.Config{
 		Log:        cfg.Log,
-		UserBus:    cfg.BusConfig.UserBus,
+		DB:         cfg.DB,
 		ProductBus: cfg.BusConfig.ProductBus,
+		ImageBus:   cfg.BusConfig.ImageBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
 
 	saleapp.Routes(app, saleapp.Config{
+		Log:         cfg.Log,
+		DB:          cfg.DB,
+		UserBus:     cfg.BusConfig.UserBus,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		SaleBus:     cfg.BusConfig.SaleBus,
+		PriceBus:    cfg.BusConfig.PriceBus,
+		LocationBus: cfg.BusConfig.LocationBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+	})
+
+	locationapp.Routes(app, locationapp.Config{
+		Log:         cfg.Log,
+		DB:          cfg.DB,
+		LocationBus: cfg.BusConfig.LocationBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+	})
+
+	pricelistapp.Routes(app, pricelistapp.Config{
+		Log:        cfg.Log,
+		DB:         cfg.DB,
+		PriceBus:   cfg.BusConfig.PriceBus,
+		AuthClient: cfg.SalesConfig.AuthClient,
+	})
+
+	rateapp.Routes(app, rateapp.Config{
+		Log:        cfg.Log,
+		RateBus:    cfg.BusConfig.RateBus,
+		AuthClient: cfg.SalesConfig.AuthClient,
+	})
+
+	roleapp.Routes(app, roleapp.Config{
 		Log:        cfg.Log,
 		DB:         cfg.DB,
+		RoleBus:    cfg.BusConfig.RoleBus,
 		UserBus:    cfg.BusConfig.UserBus,
-		ProductBus: cfg.BusConfig.ProductBus,
-		SaleBus:    cfg.BusConfig.SaleBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
 
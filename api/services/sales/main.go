@@ -16,19 +16,31 @@ import (
 
 	"github.com/rmsj/service/api/services/sales/build/all"
 	"github.com/rmsj/service/api/services/sales/build/crud"
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/debug"
 	"github.com/rmsj/service/app/sdk/mux"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/authbus/stores/authdb"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/imagebus/stores/imagedb"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/locationbus/stores/locationdb"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/domain/pricebus/stores/pricedb"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/productbus/stores/productdb"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/ratebus/stores/ratedb"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/rolebus/stores/roledb"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/salebus/stores/saledb"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/domain/userbus/stores/userdb"
 	"github.com/rmsj/service/business/sdk/delegate"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/blob"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
 )
@@ -87,7 +99,12 @@ func run(ctx context.Context, log *logger.Logger) error {
 			CORSAllowedOrigins []string      `conf:"default:*"`
 		}
 		Auth struct {
-			Host string `conf:"default:http://auth:6000"`
+			Host        string        `conf:"default:http://auth:6000"`
+			LocalVerify bool          `conf:"default:false"`
+			Issuer      string        `conf:"default:service project"`
+			KeysRefresh time.Duration `conf:"default:5m"`
+			PolicyDir   string
+			PolicyPoll  time.Duration `conf:"default:10s"`
 		}
 		DB struct {
 			User         string `conf:"default:db_user"`
@@ -98,6 +115,9 @@ func run(ctx context.Context, log *logger.Logger) error {
 			MaxOpenConns int    `conf:"default:0"`
 			DisableTLS   bool   `conf:"default:true"`
 		}
+		Blob struct {
+			Root string `conf:"default:zarf/blobs"`
+		}
 		Tempo struct {
 			Host        string  `conf:"default:tempo:4317"`
 			ServiceName string  `conf:"default:sales"`
@@ -163,19 +183,68 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// Create Business Packages
 
 	userStorage := userdb.NewStore(log, db, time.Minute)
+	productStorage := productdb.NewStore(log, db)
 
 	dlg := delegate.New(log)
-	authBus := authbus.NewBusiness(log, authdb.NewStore(log, db))
+	// Tokens are revoked by the auth service, so revocations are not cached
+	// here where they would go stale when verifying tokens locally.
+	authBus := authbus.NewBusiness(log, dlg, authdb.NewStore(log, db, 0))
 	userBus := userbus.NewBusiness(log, dlg, userStorage)
-	productBus := productbus.NewBusiness(log, dlg, productdb.NewStore(log, db))
-	saleBus := salebus.NewBusiness(log, saledb.NewStore(log, db))
+	productBus := productbus.NewBusiness(log, dlg, productStorage, productStorage)
+	rateBus := ratebus.NewBusiness(log, ratedb.NewStore(log, db))
+	saleBus := salebus.NewBusiness(log, productBus, rateBus, saledb.NewStore(log, db))
+	priceBus := pricebus.NewBusiness(log, pricedb.NewStore(log, db))
+	locationBus := locationbus.NewBusiness(log, locationdb.NewStore(log, db))
+	roleBus := rolebus.NewBusiness(log, roledb.NewStore(log, db, 10*time.Second))
+
+	blobs, err := blob.NewLocal(cfg.Blob.Root)
+	if err != nil {
+		return fmt.Errorf("opening blob store: %w", err)
+	}
+
+	imageBus := imagebus.NewBusiness(log, imagedb.NewStore(log, db), blobs)
 
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
 	log.Info(ctx, "startup", "status", "initializing authentication support")
 
-	authClient := authclient.New(log, cfg.Auth.Host)
+	var authOptions []func(cln *authclient.Client)
+
+	// In local verify mode tokens are verified with the keys published by the
+	// auth service and the rules are evaluated here. Tokens signed by a key
+	// that isn't known yet are still sent to the auth service.
+	if cfg.Auth.LocalVerify {
+		keys := authclient.NewKeySet(log, authclient.New(log, cfg.Auth.Host), cfg.Auth.KeysRefresh)
+		defer keys.Shutdown()
+
+		ath, err := auth.New(auth.Config{
+			Log:       log,
+			UserBus:   userBus,
+			AuthBus:   authBus,
+			RoleBus:   roleBus,
+			KeyLookup: keys,
+			Issuer:    cfg.Auth.Issuer,
+			PolicyDir: cfg.Auth.PolicyDir,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing auth: %w", err)
+		}
+
+		// Watch the policy directory the same way the auth service does, so
+		// both services evaluate the same bundle.
+
+		log.Info(ctx, "startup", "status", "policy loaded", "source", ath.Policy().Source, "revision", ath.Policy().Revision)
+
+		policyCtx, stopPolicy := context.WithCancel(ctx)
+		defer stopPolicy()
+
+		go ath.WatchPolicy(policyCtx, cfg.Auth.PolicyPoll)
+
+		authOptions = append(authOptions, authclient.WithLocalVerify(ath, keys))
+	}
+
+	authClient := authclient.New(log, cfg.Auth.Host, authOptions...)
 
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
@@ -224,10 +293,15 @@ func run(ctx context.Context, log *logger.Logger) error {
 		DB:     db,
 		Tracer: tracer,
 		BusConfig: mux.BusConfig{
-			AuthBus:    authBus,
-			UserBus:    userBus,
-			ProductBus: productBus,
-			SaleBus:    saleBus,
+			AuthBus:     authBus,
+			UserBus:     userBus,
+			ProductBus:  productBus,
+			SaleBus:     saleBus,
+			PriceBus:    priceBus,
+			ImageBus:    imageBus,
+			RateBus:     rateBus,
+			LocationBus: locationBus,
+			RoleBus:     roleBus,
 		},
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
@@ -0,0 +1,165 @@
+package image_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/imageapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	body, contentType := multipartBody(imagebus.TestGeneratePNG(64, 32), imagebus.TestGeneratePNG(16, 48))
+
+	table := []apitest.Table{
+		{
+			Name:        "basic",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:       sd.Users[0].Token,
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusOK,
+			Body:        body,
+			ContentType: contentType,
+			GotResp:     &imageapp.ProductImages{},
+			ExpResp: &imageapp.ProductImages{
+				ProductID: sd.Products[1].ID.String(),
+				Images: []imageapp.Image{
+					{ProductID: sd.Products[1].ID.String(), Position: 1, ContentType: "image/png", Width: 64, Height: 32},
+					{ProductID: sd.Products[1].ID.String(), Position: 2, ContentType: "image/png", Width: 16, Height: 48},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*imageapp.ProductImages)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*imageapp.ProductImages)
+
+				if len(gotResp.Images) != len(expResp.Images) {
+					return cmp.Diff(gotResp, expResp)
+				}
+
+				for i, img := range gotResp.Images {
+					expResp.Images[i].ID = img.ID
+					expResp.Images[i].Size = img.Size
+					expResp.Images[i].Hash = img.Hash
+					expResp.Images[i].URL = img.URL
+					expResp.Images[i].ThumbnailURL = img.ThumbnailURL
+					expResp.Images[i].DateCreated = img.DateCreated
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	unsupported, unsupportedType := multipartBody([]byte("not an image"))
+
+	data := imagebus.TestGeneratePNG(10, 10)
+	duplicate, duplicateType := multipartBody(data, data)
+
+	none, noneType := multipartBody()
+
+	table := []apitest.Table{
+		{
+			Name:        "unsupported",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:       sd.Users[0].Token,
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusBadRequest,
+			Body:        unsupported,
+			ContentType: unsupportedType,
+			GotResp:     &errs.Error{},
+			ExpResp:     errs.New(errs.InvalidArgument, imagebus.ErrUnsupportedType),
+			CmpFunc:     cmpErrContains,
+		},
+		{
+			Name:        "duplicate",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:       sd.Users[0].Token,
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusBadRequest,
+			Body:        duplicate,
+			ContentType: duplicateType,
+			GotResp:     &errs.Error{},
+			ExpResp:     errs.Newf(errs.FailedPrecondition, "image1.png: %s", imagebus.ErrDuplicate),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:        "none",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:       sd.Users[0].Token,
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusBadRequest,
+			Body:        none,
+			ContentType: noneType,
+			GotResp:     &errs.Error{},
+			ExpResp:     errs.Newf(errs.InvalidArgument, "no image uploaded"),
+			CmpFunc:     cmpErrContains,
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	body, contentType := multipartBody(imagebus.TestGeneratePNG(10, 10))
+
+	table := []apitest.Table{
+		{
+			Name:        "emptytoken",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:       "&nbsp;",
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusUnauthorized,
+			Body:        body,
+			ContentType: contentType,
+			GotResp:     &errs.Error{},
+			ExpResp:     errs.Newf(errs.Unauthenticated, "authentication failed: token contains an invalid number of segments"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:        "wronguser",
+			URL:         fmt.Sprintf("/v1/products/%s/images", sd.Products[2].ID),
+			Token:       sd.Users[0].Token,
+			Method:      http.MethodPost,
+			StatusCode:  http.StatusUnauthorized,
+			Body:        body,
+			ContentType: contentType,
+			GotResp:     &errs.Error{},
+			ExpResp:     errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+// cmpErrContains compares the error code and checks the message contains the
+// expected message, since the rest of it carries details of the upload.
+func cmpErrContains(got any, exp any) string {
+	gotResp := got.(*errs.Error)
+	expResp := exp.(*errs.Error)
+
+	if !strings.Contains(gotResp.Message, expResp.Message) {
+		return fmt.Sprintf("message %q does not contain %q", gotResp.Message, expResp.Message)
+	}
+
+	return cmp.Diff(gotResp.Code, expResp.Code)
+}
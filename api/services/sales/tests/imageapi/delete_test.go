@@ -0,0 +1,29 @@
+package image_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func delete200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "asuser",
+			URL:        fmt.Sprintf("/v1/products/%s/images/%s", sd.Products[0].ID, sd.Images[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+		{
+			Name:       "asadmin",
+			URL:        fmt.Sprintf("/v1/products/%s/images/%s", sd.Products[0].ID, sd.Images[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,35 @@
+package image_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func Test_Image(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Image")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, query200(sd), "query-200")
+	test.Run(t, content200(sd), "content-200")
+	test.Run(t, content404(sd), "content-404")
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create401(sd), "create-401")
+
+	test.Run(t, reorder200(sd), "reorder-200")
+	test.Run(t, reorder400(sd), "reorder-400")
+
+	test.Run(t, delete200(sd), "delete-200")
+}
@@ -0,0 +1,40 @@
+package image_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/domain/imageapp"
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+func toAppImage(img imagebus.Image) imageapp.Image {
+	return imageapp.Image{
+		ID:           img.ID.String(),
+		ProductID:    img.ProductID.String(),
+		Position:     img.Position,
+		ContentType:  img.ContentType,
+		Size:         img.Size,
+		Width:        img.Width,
+		Height:       img.Height,
+		Hash:         img.Hash,
+		URL:          fmt.Sprintf("/v1/images/%s", img.ID),
+		ThumbnailURL: fmt.Sprintf("/v1/images/%s/thumbnail", img.ID),
+		DateCreated:  img.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppProductImages(productID uuid.UUID, imgs []imagebus.Image) *imageapp.ProductImages {
+	app := imageapp.ProductImages{
+		ProductID: productID.String(),
+		Images:    make([]imageapp.Image, len(imgs)),
+	}
+
+	for i, img := range imgs {
+		app.Images[i] = toAppImage(img)
+	}
+
+	return &app
+}
@@ -0,0 +1,102 @@
+package image_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/domain/imageapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/images", sd.Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &imageapp.ProductImages{},
+			ExpResp:    toAppProductImages(sd.Products[0].ID, sd.Images),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "empty",
+			URL:        fmt.Sprintf("/v1/products/%s/images", sd.Products[1].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &imageapp.ProductImages{},
+			ExpResp:    toAppProductImages(sd.Products[1].ID, nil),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func content200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "original",
+			URL:        fmt.Sprintf("/v1/images/%s", sd.Images[0].ID),
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &[]byte{},
+			ExpResp:    sd.Images[0].Hash,
+			CmpFunc: func(got any, exp any) string {
+				sum := sha256.Sum256(*got.(*[]byte))
+				return cmp.Diff(hex.EncodeToString(sum[:]), exp)
+			},
+		},
+		{
+			Name:       "thumbnail",
+			URL:        fmt.Sprintf("/v1/images/%s/thumbnail", sd.Images[0].ID),
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &[]byte{},
+			ExpResp:    "200x100",
+			CmpFunc: func(got any, exp any) string {
+				cfg, err := png.DecodeConfig(bytes.NewReader(*got.(*[]byte)))
+				if err != nil {
+					return err.Error()
+				}
+
+				return cmp.Diff(fmt.Sprintf("%dx%d", cfg.Width, cfg.Height), exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func content404(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing",
+			URL:        fmt.Sprintf("/v1/images/%s", uuid.New()),
+			Method:     http.MethodGet,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.New(errs.NotFound, imagebus.ErrNotFound),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,93 @@
+package image_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds1, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	imgs, err := imagebus.TestGenerateSeedImages(ctx, 3, busDomain.Image, prds1[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding images : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds2, err := productbus.TestGenerateSeedProducts(ctx, 1, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Admins:   []apitest.User{tu2},
+		Users:    []apitest.User{tu1},
+		Products: append(prds1, prds2...),
+		Images:   imgs,
+	}
+
+	return sd, nil
+}
+
+// multipartBody builds a multipart form with one file in the image field for
+// each of the specified contents.
+func multipartBody(files ...[]byte) ([]byte, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for i, data := range files {
+		fw, err := mw.CreateFormFile("image", fmt.Sprintf("image%d.png", i))
+		if err != nil {
+			panic(err)
+		}
+
+		if _, err := fw.Write(data); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes(), mw.FormDataContentType()
+}
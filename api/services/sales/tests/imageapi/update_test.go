@@ -0,0 +1,75 @@
+package image_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/imageapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+func reorder200(sd apitest.SeedData) []apitest.Table {
+	imgs := []imagebus.Image{sd.Images[2], sd.Images[0], sd.Images[1]}
+	for i := range imgs {
+		imgs[i].Position = i + 1
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/images/order", sd.Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &imageapp.ImageOrder{
+				ImageIDs: []string{imgs[0].ID.String(), imgs[1].ID.String(), imgs[2].ID.String()},
+			},
+			GotResp: &imageapp.ProductImages{},
+			ExpResp: toAppProductImages(sd.Products[0].ID, imgs),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func reorder400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "incomplete",
+			URL:        fmt.Sprintf("/v1/products/%s/images/order", sd.Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input: &imageapp.ImageOrder{
+				ImageIDs: []string{sd.Images[0].ID.String()},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.InvalidArgument, imagebus.ErrInvalidOrder),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "empty",
+			URL:        fmt.Sprintf("/v1/products/%s/images/order", sd.Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input:      &imageapp.ImageOrder{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"image_ids\",\"error\":\"image_ids is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,41 @@
+package locationapi_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func Test_Location(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Location")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, query200(sd), "query-200")
+	test.Run(t, queryByID200(sd), "querybyid-200")
+	test.Run(t, queryByID404(sd), "querybyid-404")
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create401(sd), "create-401")
+	test.Run(t, create409(sd), "create-409")
+
+	test.Run(t, update200(sd), "update-200")
+
+	test.Run(t, users200(sd), "users-200")
+
+	test.Run(t, stock200(sd), "stock-200")
+	test.Run(t, stock400(sd), "stock-400")
+	test.Run(t, stock401(sd), "stock-401")
+
+	test.Run(t, delete200(sd), "delete-200")
+}
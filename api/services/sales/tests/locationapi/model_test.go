@@ -0,0 +1,41 @@
+package locationapi_test
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rmsj/service/app/domain/locationapp"
+	"github.com/rmsj/service/business/domain/locationbus"
+)
+
+func toAppLocation(loc locationbus.Location) locationapp.Location {
+	return locationapp.Location{
+		ID:          loc.ID.String(),
+		Name:        loc.Name.String(),
+		Address:     loc.Address,
+		Timezone:    loc.Timezone.String(),
+		DateCreated: loc.DateCreated.Format(time.RFC3339),
+		DateUpdated: loc.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppLocationPtr(loc locationbus.Location) *locationapp.Location {
+	app := toAppLocation(loc)
+	return &app
+}
+
+func toAppLocations(locs []locationbus.Location) []locationapp.Location {
+	sorted := make([]locationbus.Location, len(locs))
+	copy(sorted, locs)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name.String() < sorted[j].Name.String()
+	})
+
+	items := make([]locationapp.Location, len(sorted))
+	for i, loc := range sorted {
+		items[i] = toAppLocation(loc)
+	}
+
+	return items
+}
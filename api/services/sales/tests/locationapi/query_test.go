@@ -0,0 +1,95 @@
+package locationapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/locationapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/locationbus"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/locations?page=1&rows=10&order_by=name,ASC",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[locationapp.Location]{},
+			ExpResp: &query.Result[locationapp.Location]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(sd.Locations),
+				Items:       toAppLocations(sd.Locations),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "by-user",
+			URL:        fmt.Sprintf("/v1/locations?page=1&rows=10&user_id=%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[locationapp.Location]{},
+			ExpResp: &query.Result[locationapp.Location]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items:       toAppLocations([]locationbus.Location{sd.Locations[0]}),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/locations/%s", sd.Locations[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &locationapp.Location{},
+			ExpResp:    toAppLocationPtr(sd.Locations[0]),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID404(sd apitest.SeedData) []apitest.Table {
+	const missingID = "5cf37266-3473-4006-984f-9325122678b7"
+
+	table := []apitest.Table{
+		{
+			Name:       "missing",
+			URL:        "/v1/locations/" + missingID,
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "location not found: %s", missingID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
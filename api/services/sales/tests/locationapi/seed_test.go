@@ -0,0 +1,68 @@
+package locationapi_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	locs, err := locationbus.TestGenerateSeedLocations(ctx, 3, busDomain.Location)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding locations : %w", err)
+	}
+
+	if err := busDomain.Location.SetUsers(ctx, locs[0], []uuid.UUID{tu1.ID}); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding location users : %w", err)
+	}
+
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 1, busDomain.Product, tu1.ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	sd := apitest.SeedData{
+		Admins:    []apitest.User{tu2},
+		Users:     []apitest.User{tu1},
+		Products:  prds,
+		Locations: locs,
+	}
+
+	return sd, nil
+}
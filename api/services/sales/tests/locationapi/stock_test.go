@@ -0,0 +1,115 @@
+package locationapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/productapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func stock200(sd apitest.SeedData) []apitest.Table {
+	ls := productapp.LocationStock{
+		LocationID: sd.Locations[0].ID.String(),
+		Stock:      12,
+		Unit:       "piece",
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "set",
+			URL:        fmt.Sprintf("/v1/products/%s/stock/%s", sd.Products[0].ID, sd.Locations[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input:      &productapp.SetLocationStock{Stock: 12},
+			GotResp:    &productapp.LocationStock{},
+			ExpResp:    &ls,
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.LocationStock)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.LocationStock)
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "query",
+			URL:        fmt.Sprintf("/v1/products/%s/stock", sd.Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Stock{},
+			ExpResp: &productapp.Stock{
+				ProductID: sd.Products[0].ID.String(),
+				Stock:     sd.Products[0].Stock.Value(),
+				Unit:      sd.Products[0].Unit.String(),
+				Locations: []productapp.LocationStock{ls},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Stock)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Stock)
+				for i := range gotResp.Locations {
+					if i < len(expResp.Locations) {
+						expResp.Locations[i].DateUpdated = gotResp.Locations[i].DateUpdated
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func stock400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "negative",
+			URL:        fmt.Sprintf("/v1/products/%s/stock/%s", sd.Products[0].ID, sd.Locations[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input:      &productapp.SetLocationStock{Stock: -1},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"stock\",\"error\":\"stock must be 0 or greater\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func stock401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        fmt.Sprintf("/v1/products/%s/stock/%s", sd.Products[0].ID, sd.Locations[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusUnauthorized,
+			Input:      &productapp.SetLocationStock{Stock: 1},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,50 @@
+package locationapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/locationapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/business/sdk/dbtest"
+)
+
+func update200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/locations/%s", sd.Locations[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &locationapp.UpdateLocation{
+				Name:     dbtest.StringPointer("Harbour"),
+				Timezone: dbtest.StringPointer("Pacific/Auckland"),
+			},
+			GotResp: &locationapp.Location{},
+			ExpResp: &locationapp.Location{
+				ID:          sd.Locations[1].ID.String(),
+				Name:        "Harbour",
+				Address:     sd.Locations[1].Address,
+				Timezone:    "Pacific/Auckland",
+				DateCreated: toAppLocation(sd.Locations[1]).DateCreated,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*locationapp.Location)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*locationapp.Location)
+
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
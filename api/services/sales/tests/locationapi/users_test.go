@@ -0,0 +1,47 @@
+package locationapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/locationapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func users200(sd apitest.SeedData) []apitest.Table {
+	users := locationapp.Users{
+		UserIDs: []string{sd.Users[0].ID.String()},
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "set",
+			URL:        fmt.Sprintf("/v1/locations/%s/users", sd.Locations[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input:      &users,
+			GotResp:    &locationapp.Users{},
+			ExpResp:    &users,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "query",
+			URL:        fmt.Sprintf("/v1/locations/%s/users", sd.Locations[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &locationapp.Users{},
+			ExpResp:    &users,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,111 @@
+package pricelistapi_test
+
+import (
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/pricelistapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/pricelists",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &pricelistapp.NewPriceList{
+				Name:      "Distributors",
+				Currency:  "eur",
+				Priority:  3,
+				ValidFrom: "2026-01-01T00:00:00Z",
+			},
+			GotResp: &pricelistapp.PriceList{},
+			ExpResp: &pricelistapp.PriceList{
+				Name:      "Distributors",
+				Currency:  "EUR",
+				Priority:  3,
+				ValidFrom: "2026-01-01T00:00:00Z",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*pricelistapp.PriceList)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*pricelistapp.PriceList)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/pricelists",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &pricelistapp.NewPriceList{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "invalid-validity",
+			URL:        "/v1/pricelists",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &pricelistapp.NewPriceList{
+				Name:      "Backwards",
+				ValidFrom: "2026-02-01T00:00:00Z",
+				ValidTo:   "2026-01-01T00:00:00Z",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "price list validity ends before it starts"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        "/v1/pricelists",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &pricelistapp.NewPriceList{
+				Name: "Sneaky",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,22 @@
+package pricelistapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func delete200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/pricelists/%s", sd.PriceLists[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,76 @@
+package pricelistapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/pricelistapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func items200(sd apitest.SeedData) []apitest.Table {
+	items := pricelistapp.Items{
+		Items: []pricelistapp.Item{
+			{ProductID: sd.Products[0].ID.String(), MinQuantity: 1, Price: 9.5},
+			{ProductID: sd.Products[0].ID.String(), MinQuantity: 10, Price: 8},
+		},
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "set",
+			URL:        fmt.Sprintf("/v1/pricelists/%s/items", sd.PriceLists[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input:      &items,
+			GotResp:    &pricelistapp.Items{},
+			ExpResp:    &items,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "get",
+			URL:        fmt.Sprintf("/v1/pricelists/%s/items", sd.PriceLists[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &pricelistapp.Items{},
+			ExpResp:    &items,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func items400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "duplicate-tier",
+			URL:        fmt.Sprintf("/v1/pricelists/%s/items", sd.PriceLists[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input: &pricelistapp.Items{
+				Items: []pricelistapp.Item{
+					{ProductID: sd.Products[0].ID.String(), MinQuantity: 1, Price: 9.5},
+					{ProductID: sd.Products[0].ID.String(), MinQuantity: 1, Price: 8},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "product[%s]: duplicate min quantity[1]: price list item not valid", sd.Products[0].ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
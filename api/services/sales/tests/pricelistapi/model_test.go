@@ -0,0 +1,19 @@
+package pricelistapi_test
+
+import (
+	"time"
+
+	"github.com/rmsj/service/app/domain/pricelistapp"
+	"github.com/rmsj/service/business/domain/pricebus"
+)
+
+func toAppPriceList(pl pricebus.PriceList) pricelistapp.PriceList {
+	return pricelistapp.PriceList{
+		ID:          pl.ID.String(),
+		Name:        pl.Name.String(),
+		Currency:    pl.Currency.String(),
+		Priority:    pl.Priority,
+		DateCreated: pl.DateCreated.Format(time.RFC3339),
+		DateUpdated: pl.DateUpdated.Format(time.RFC3339),
+	}
+}
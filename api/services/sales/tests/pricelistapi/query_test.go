@@ -0,0 +1,55 @@
+package pricelistapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/domain/pricelistapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func queryByID200(sd apitest.SeedData) []apitest.Table {
+	exp := toAppPriceList(sd.PriceLists[0])
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/pricelists/%s", sd.PriceLists[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &pricelistapp.PriceList{},
+			ExpResp:    &exp,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID404(sd apitest.SeedData) []apitest.Table {
+	priceListID := uuid.New()
+
+	table := []apitest.Table{
+		{
+			Name:       "unknown",
+			URL:        fmt.Sprintf("/v1/pricelists/%s", priceListID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "price list not found: %s", priceListID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
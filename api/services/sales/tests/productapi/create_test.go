@@ -24,8 +24,63 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			},
 			GotResp: &productapp.Product{},
 			ExpResp: &productapp.Product{
-				Name:  "Guitar",
-				Price: 10.34,
+				Name:      "Guitar",
+				Kind:      "simple",
+				Currency:  "USD",
+				Unit:      "piece",
+				Price:     10.34,
+				CreatedBy: sd.Users[0].ID.String(),
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "bundle",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				Name:  "Guitar Kit",
+				Price: 25,
+				Bundle: &productapp.NewBundle{
+					Pricing: "fixed",
+					Components: []productapp.NewComponent{
+						{ProductID: sd.Products[0].ID.String(), Quantity: 2},
+					},
+				},
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				Name:     "Guitar Kit",
+				Kind:     "bundle",
+				Currency: "USD",
+				Unit:     "piece",
+				Price:    25,
+				Bundle: &productapp.Bundle{
+					Pricing: "fixed",
+					Components: []productapp.Component{
+						{
+							ProductID: sd.Products[0].ID.String(),
+							Name:      sd.Products[0].Name.String(),
+							Price:     sd.Products[0].Price.Value(),
+							Quantity:  2,
+						},
+					},
+				},
+				CreatedBy: sd.Users[0].ID.String(),
 			},
 			CmpFunc: func(got any, exp any) string {
 				gotResp, exists := got.(*productapp.Product)
@@ -62,6 +117,28 @@ func create400(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:       "bundle-bad-pricing",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewProduct{
+				Name:  "Guitar Kit",
+				Price: 25,
+				Bundle: &productapp.NewBundle{
+					Pricing: "other",
+					Components: []productapp.NewComponent{
+						{ProductID: sd.Products[0].ID.String(), Quantity: 1},
+					},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"pricing\",\"error\":\"pricing must be one of [fixed sum]\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -106,13 +183,17 @@ func create401(sd apitest.SeedData) []apitest.Table {
 			},
 		},
 		{
-			Name:       "wronguser",
-			URL:        "/v1/products",
-			Token:      sd.Admins[0].Token,
-			Method:     http.MethodPost,
-			StatusCode: http.StatusUnauthorized,
-			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[admin]] rule[rule_user_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			Name:          "nopermission",
+			URL:           "/v1/products",
+			Authorization: "ApiKey " + sd.Users[0].APIKey,
+			Method:        http.MethodPost,
+			StatusCode:    http.StatusUnauthorized,
+			Input: &productapp.NewProduct{
+				Name:  "Guitar",
+				Price: 10.34,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
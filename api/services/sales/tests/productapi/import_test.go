@@ -0,0 +1,173 @@
+package product_test
+
+import (
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/productapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func import200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "dryrun-create",
+			URL:        "/v1/products/import?format=jsonl&dry_run=true",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: map[string]any{
+				"sku":      "IMP-001",
+				"name":     "Imported",
+				"category": "Imports",
+				"price":    12.5,
+				"stock":    3,
+			},
+			GotResp: &productapp.ImportSummary{},
+			ExpResp: &productapp.ImportSummary{
+				DryRun:  true,
+				Created: 1,
+				Results: []productapp.ImportResult{
+					{
+						Action: "create",
+						SKU:    "IMP-001",
+						Name:   "Imported",
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "update",
+			URL:        "/v1/products/import?format=jsonl",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: map[string]any{
+				"id":    sd.Products[3].ID.String(),
+				"name":  sd.Products[3].Name.String(),
+				"price": sd.Products[3].Price.Value(),
+				"stock": 7,
+			},
+			GotResp: &productapp.ImportSummary{},
+			ExpResp: &productapp.ImportSummary{
+				Updated: 1,
+				Results: []productapp.ImportResult{
+					{
+						Action:  "update",
+						ID:      sd.Products[3].ID.String(),
+						Name:    sd.Products[3].Name.String(),
+						Changes: []string{"stock: 0 -> 7"},
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unchanged",
+			URL:        "/v1/products/import?format=jsonl",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: map[string]any{
+				"id":    sd.Products[2].ID.String(),
+				"name":  sd.Products[2].Name.String(),
+				"price": sd.Products[2].Price.Value(),
+			},
+			GotResp: &productapp.ImportSummary{},
+			ExpResp: &productapp.ImportSummary{
+				Unchanged: 1,
+				Results: []productapp.ImportResult{
+					{
+						Action: "unchanged",
+						ID:     sd.Products[2].ID.String(),
+						Name:   sd.Products[2].Name.String(),
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func import400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-format",
+			URL:        "/v1/products/import?format=xml",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"format\",\"error\":\"invalid format \\\"xml\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-name",
+			URL:        "/v1/products/import?format=jsonl",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: map[string]any{
+				"sku":   "IMP-002",
+				"name":  "$#!",
+				"price": 10,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "read: line 1: parse name: invalid name \"$#!\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unknown-id",
+			URL:        "/v1/products/import?format=jsonl",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: map[string]any{
+				"id":    "00000000-0000-0000-0000-000000000001",
+				"name":  "Unknown",
+				"price": 10,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "import: import: product[1]: id[00000000-0000-0000-0000-000000000001]: product not in catalog"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func import401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "wronguser",
+			URL:        "/v1/products/import?format=jsonl",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -11,7 +11,13 @@ func toAppProduct(prd productbus.Product) productapp.Product {
 	return productapp.Product{
 		ID:          prd.ID.String(),
 		Name:        prd.Name.String(),
+		Kind:        string(prd.Kind),
 		Price:       prd.Price.Value(),
+		Currency:    prd.Currency.String(),
+		Unit:        prd.Unit.String(),
+		Stock:       prd.Stock.Value(),
+		MaxQuantity: prd.MaxQuantity.Value(),
+		CreatedBy:   prd.CreatedBy.String(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
 	}
@@ -30,3 +36,15 @@ func toAppProducts(prds []productbus.Product) []productapp.Product {
 
 	return items
 }
+
+// toAppProductsAdmin converts the products as an administrator sees them,
+// which includes their cost.
+func toAppProductsAdmin(prds []productbus.Product) []productapp.Product {
+	items := toAppProducts(prds)
+	for i, prd := range prds {
+		cost := prd.Cost.Value()
+		items[i].Cost = &cost
+	}
+
+	return items
+}
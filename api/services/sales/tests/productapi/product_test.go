@@ -23,11 +23,19 @@ func Test_Product(t *testing.T) {
 	test.Run(t, query200(sd), "query-200")
 	test.Run(t, query400(sd), "query-400")
 	test.Run(t, queryByID200(sd), "querybyid-200")
+	test.Run(t, queryCosts401(sd), "querycosts-401")
+
+	test.Run(t, search200(sd), "search-200")
+	test.Run(t, search400(sd), "search-400")
 
 	test.Run(t, create200(sd), "create-200")
 	test.Run(t, create401(sd), "create-401")
 	test.Run(t, create400(sd), "create-400")
 
+	test.Run(t, import200(sd), "import-200")
+	test.Run(t, import400(sd), "import-400")
+	test.Run(t, import401(sd), "import-401")
+
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
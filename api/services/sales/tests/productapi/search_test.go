@@ -0,0 +1,74 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/productapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/query"
+)
+
+func search200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/search?q=%s&page=1&rows=10", sd.Products[0].Name),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.SearchResult]{},
+			ExpResp: &query.Result[productapp.SearchResult]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items: []productapp.SearchResult{
+					{
+						Product: toAppProduct(sd.Products[0]),
+						Snippet: fmt.Sprintf("<em>%s</em>", sd.Products[0].Name),
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.SearchResult])
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*query.Result[productapp.SearchResult])
+
+				for i := range gotResp.Items {
+					if i < len(expResp.Items) {
+						expResp.Items[i].Score = gotResp.Items[i].Score
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func search400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-query",
+			URL:        "/v1/products/search?q=a",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"q\",\"error\":\"search query not valid\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
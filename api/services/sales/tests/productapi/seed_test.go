@@ -6,9 +6,11 @@ import (
 
 	"github.com/rmsj/service/app/sdk/apitest"
 	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/business/types/role"
 )
 
@@ -21,14 +23,28 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
 	}
 
-	prds1, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product)
+	prds1, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
 
+	// The key is restricted to reading products, so the user can't use it to
+	// write them despite the role granting it.
+	nk := authbus.NewAPIKey{
+		UserID: usrs[0].ID,
+		Name:   "Read Only",
+		Scopes: []permission.Permission{permission.ProductsRead},
+	}
+
+	_, key, err := busDomain.Auth.CreateAPIKey(ctx, nk)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding api key : %w", err)
+	}
+
 	tu1 := apitest.User{
-		User:  usrs[0],
-		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+		User:   usrs[0],
+		Token:  apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+		APIKey: key,
 	}
 
 	// -------------------------------------------------------------------------
@@ -38,7 +54,7 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
 	}
 
-	prds2, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product)
+	prds2, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
@@ -29,7 +29,11 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			ExpResp: &productapp.Product{
 				ID:          sd.Products[0].ID.String(),
 				Name:        "Guitar",
+				Kind:        "simple",
+				Currency:    "USD",
+				Unit:        "piece",
 				Price:       10.34,
+				CreatedBy:   sd.Users[0].ID.String(),
 				DateCreated: sd.Products[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Products[0].DateCreated.Format(time.RFC3339),
 			},
@@ -98,6 +102,18 @@ func update401(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:       "wronguser",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Products[2].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -0,0 +1,111 @@
+package rateapi_test
+
+import (
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/rateapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/rates",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &rateapp.NewRate{
+				From:          "eur",
+				To:            "GBP",
+				Rate:          0.86,
+				EffectiveFrom: "2026-01-01T00:00:00Z",
+			},
+			GotResp: &rateapp.Rate{},
+			ExpResp: &rateapp.Rate{
+				From:          "EUR",
+				To:            "GBP",
+				Rate:          0.86,
+				EffectiveFrom: "2026-01-01T00:00:00Z",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*rateapp.Rate)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*rateapp.Rate)
+
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/rates",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &rateapp.NewRate{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"from\",\"error\":\"from is a required field\"},{\"field\":\"to\",\"error\":\"to is a required field\"},{\"field\":\"rate\",\"error\":\"rate must be greater than 0\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "same-currency",
+			URL:        "/v1/rates",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &rateapp.NewRate{
+				From: "USD",
+				To:   "USD",
+				Rate: 1,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "USD to itself: exchange rate not valid"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        "/v1/rates",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &rateapp.NewRate{
+				From: "USD",
+				To:   "EUR",
+				Rate: 0.9,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,27 @@
+package rateapi_test
+
+import (
+	"time"
+
+	"github.com/rmsj/service/app/domain/rateapp"
+	"github.com/rmsj/service/business/domain/ratebus"
+)
+
+func toAppRate(rate ratebus.Rate) rateapp.Rate {
+	return rateapp.Rate{
+		From:          rate.From.String(),
+		To:            rate.To.String(),
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom.Format(time.RFC3339),
+		DateCreated:   rate.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppRates(rates []ratebus.Rate) []rateapp.Rate {
+	items := make([]rateapp.Rate, len(rates))
+	for i, rate := range rates {
+		items[i] = toAppRate(rate)
+	}
+
+	return items
+}
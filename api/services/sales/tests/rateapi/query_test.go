@@ -0,0 +1,58 @@
+package rateapi_test
+
+import (
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/rateapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/ratebus"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	usdEUR := []ratebus.Rate{sd.Rates[1], sd.Rates[0]}
+
+	table := []apitest.Table{
+		{
+			Name:       "by-currency",
+			URL:        "/v1/rates?page=1&rows=10&from=usd&to=EUR",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[rateapp.Rate]{},
+			ExpResp: &query.Result[rateapp.Rate]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(usdEUR),
+				Items:       toAppRates(usdEUR),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-query-filter",
+			URL:        "/v1/rates?page=1&rows=10&from=dollars",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"from\",\"error\":\"invalid currency \\\"DOLLARS\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,65 @@
+package rateapi_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	now := time.Now().Truncate(time.Second)
+
+	nrs := []ratebus.NewRate{
+		{From: currency.MustParse("USD"), To: currency.MustParse("EUR"), Rate: 0.9, EffectiveFrom: now.AddDate(0, 0, -2)},
+		{From: currency.MustParse("USD"), To: currency.MustParse("EUR"), Rate: 0.92, EffectiveFrom: now.AddDate(0, 0, -1)},
+		{From: currency.MustParse("GBP"), To: currency.MustParse("USD"), Rate: 1.27, EffectiveFrom: now.AddDate(0, 0, -1)},
+	}
+
+	rates, err := ratebus.TestSeedRates(ctx, nrs, busDomain.Rate)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding rates : %w", err)
+	}
+
+	sd := apitest.SeedData{
+		Admins: []apitest.User{tu2},
+		Users:  []apitest.User{tu1},
+		Rates:  rates,
+	}
+
+	return sd, nil
+}
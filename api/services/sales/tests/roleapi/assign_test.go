@@ -0,0 +1,88 @@
+package roleapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/roleapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func assign200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "assign",
+			URL:        fmt.Sprintf("/v1/users/%s/roles/%s", sd.Users[0].ID, sd.Roles[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			GotResp:    &roleapp.Role{},
+			ExpResp:    toAppRolePtr(sd.Roles[0]),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "user-roles",
+			URL:        fmt.Sprintf("/v1/users/%s/roles", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &roleapp.Roles{},
+			ExpResp:    &roleapp.Roles{toAppRole(sd.Roles[0])},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unassign",
+			URL:        fmt.Sprintf("/v1/users/%s/roles/%s", sd.Users[0].ID, sd.Roles[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
+
+func assign400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "system",
+			URL:        fmt.Sprintf("/v1/users/%s/roles/%s", sd.Users[0].ID, sd.Roles[3].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.FailedPrecondition, "system roles can't be renamed, deleted or assigned"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func assign403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-held",
+			URL:        fmt.Sprintf("/v1/users/%s/roles/%s", sd.Users[1].ID, sd.Roles[1].ID),
+			Token:      sd.Users[1].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusForbidden,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PermissionDenied, "permission[sales:report] is not held by the caller and can't be granted"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,185 @@
+package roleapi_test
+
+import (
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/roleapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &roleapp.NewRole{
+				Name:        "cashier",
+				Description: "Takes payments",
+				Permissions: []string{"sales:create", "products:*", "sales:create"},
+			},
+			GotResp: &roleapp.Role{},
+			ExpResp: &roleapp.Role{
+				Name:        "cashier",
+				Description: "Takes payments",
+				Permissions: []string{"products:*", "sales:create"},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*roleapp.Role)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "manager",
+			URL:        "/v1/roles",
+			Token:      sd.Users[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &roleapp.NewRole{
+				Name:        "clerk",
+				Description: "Sells products",
+				Permissions: []string{"sales:create", "roles:read"},
+			},
+			GotResp: &roleapp.Role{},
+			ExpResp: &roleapp.Role{
+				Name:        "clerk",
+				Description: "Sells products",
+				Permissions: []string{"roles:read", "sales:create"},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*roleapp.Role)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &roleapp.NewRole{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-permission",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &roleapp.NewRole{
+				Name:        "auditor",
+				Permissions: []string{"Sales"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "parse permissions: invalid permission \"Sales\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        "/v1/roles",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &roleapp.NewRole{
+				Name: "accountant",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-held",
+			URL:        "/v1/roles",
+			Token:      sd.Users[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusForbidden,
+			Input: &roleapp.NewRole{
+				Name:        "superuser",
+				Permissions: []string{"sales:create", "*"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PermissionDenied, "permission[*] is not held by the caller and can't be granted"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "system-name",
+			URL:        "/v1/roles",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &roleapp.NewRole{
+				Name: "admin",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Aborted, "role name already exists"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
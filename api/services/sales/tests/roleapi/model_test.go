@@ -0,0 +1,26 @@
+package roleapi_test
+
+import (
+	"time"
+
+	"github.com/rmsj/service/app/domain/roleapp"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/types/permission"
+)
+
+func toAppRole(rle rolebus.Role) roleapp.Role {
+	return roleapp.Role{
+		ID:          rle.ID.String(),
+		Name:        rle.Name,
+		Description: rle.Description,
+		Permissions: permission.ParseToString(rle.Permissions),
+		System:      rle.System,
+		DateCreated: rle.DateCreated.Format(time.RFC3339),
+		DateUpdated: rle.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppRolePtr(rle rolebus.Role) *roleapp.Role {
+	app := toAppRole(rle)
+	return &app
+}
@@ -0,0 +1,41 @@
+package roleapi_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+)
+
+func Test_Role(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Role")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, queryByID200(sd), "querybyid-200")
+	test.Run(t, queryByID404(sd), "querybyid-404")
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create401(sd), "create-401")
+	test.Run(t, create403(sd), "create-403")
+	test.Run(t, create409(sd), "create-409")
+
+	test.Run(t, update200(sd), "update-200")
+	test.Run(t, update400(sd), "update-400")
+
+	test.Run(t, assign200(sd), "assign-200")
+	test.Run(t, assign400(sd), "assign-400")
+	test.Run(t, assign403(sd), "assign-403")
+
+	test.Run(t, delete200(sd), "delete-200")
+	test.Run(t, delete400(sd), "delete-400")
+}
@@ -0,0 +1,100 @@
+package roleapi_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	rles, err := rolebus.TestGenerateSeedRoles(ctx, 3, busDomain.Role, permission.SalesReport)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding roles : %w", err)
+	}
+
+	// The system user role is kept last so the tests can check it can't be
+	// changed like a custom role.
+	nme := role.User.String()
+	sys, err := busDomain.Role.Query(ctx, rolebus.QueryFilter{Name: &nme}, rolebus.DefaultOrderBy, page.MustParse("1", "10"))
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("querying system roles : %w", err)
+	}
+
+	for _, rle := range sys {
+		if rle.System && rle.Name == nme {
+			rles = append(rles, rle)
+		}
+	}
+
+	if len(rles) != 4 {
+		return apitest.SeedData{}, fmt.Errorf("system role %q not found", nme)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The role manager can manage roles but only holds the permissions of the
+	// user role besides them.
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	mgr, err := rolebus.TestGenerateSeedRoles(ctx, 1, busDomain.Role, permission.RolesRead, permission.RolesWrite, permission.RolesAssign)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding roles : %w", err)
+	}
+
+	if err := busDomain.Role.Assign(ctx, usrs[0].ID, mgr[0]); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("assigning role : %w", err)
+	}
+
+	tu3 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Admins: []apitest.User{tu2},
+		Users:  []apitest.User{tu1, tu3},
+		Roles:  rles,
+	}
+
+	return sd, nil
+}
@@ -0,0 +1,72 @@
+package roleapi_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/roleapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/sdk/dbtest"
+)
+
+func update200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/roles/%s", sd.Roles[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &roleapp.UpdateRole{
+				Name:        dbtest.StringPointer("store_manager"),
+				Permissions: []string{"sales:*"},
+			},
+			GotResp: &roleapp.Role{},
+			ExpResp: &roleapp.Role{
+				ID:          sd.Roles[1].ID.String(),
+				Name:        "store_manager",
+				Description: sd.Roles[1].Description,
+				Permissions: []string{"sales:*"},
+				DateCreated: toAppRole(sd.Roles[1]).DateCreated,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*roleapp.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*roleapp.Role)
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func update400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "rename-system",
+			URL:        fmt.Sprintf("/v1/roles/%s", sd.Roles[3].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input: &roleapp.UpdateRole{
+				Name: dbtest.StringPointer("member"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.FailedPrecondition, "system roles can't be renamed, deleted or assigned"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
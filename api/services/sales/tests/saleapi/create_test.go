@@ -1,7 +1,9 @@
 package saleapi_test
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -32,7 +34,8 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			},
 			GotResp: &saleapp.Sale{},
 			ExpResp: &saleapp.Sale{
-				Amount: sd.Products[0].Price.Value() + sd.Products[1].Price.Value()*2,
+				Currency: "USD",
+				Amount:   sd.Products[0].Price.Value() + sd.Products[1].Price.Value()*2,
 				Customer: saleapp.Customer{
 					ID:    sd.Users[0].ID.String(),
 					Name:  sd.Users[0].Name.String(),
@@ -40,20 +43,236 @@ func create200(sd apitest.SeedData) []apitest.Table {
 				},
 				Items: []saleapp.Item{
 					{
-						ID:         sd.Products[0].ID.String(),
-						Name:       sd.Products[0].Name.String(),
-						UnityPrice: sd.Products[0].Price.Value(),
-						Quantity:   1,
-						Amount:     sd.Products[0].Price.Value(),
-						Discount:   0,
+						ID:           sd.Products[0].ID.String(),
+						Name:         sd.Products[0].Name.String(),
+						UnityPrice:   sd.Products[0].Price.Value(),
+						ExchangeRate: 1,
+						Quantity:     1,
+						Unit:         "piece",
+						Amount:       sd.Products[0].Price.Value(),
+						Discount:     0,
 					},
 					{
-						ID:         sd.Products[1].ID.String(),
-						Name:       sd.Products[1].Name.String(),
-						UnityPrice: sd.Products[1].Price.Value(),
-						Quantity:   2,
-						Amount:     sd.Products[1].Price.Value() * 2,
-						Discount:   0,
+						ID:           sd.Products[1].ID.String(),
+						Name:         sd.Products[1].Name.String(),
+						UnityPrice:   sd.Products[1].Price.Value(),
+						ExchangeRate: 1,
+						Quantity:     2,
+						Unit:         "piece",
+						Amount:       sd.Products[1].Price.Value() * 2,
+						Discount:     0,
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*saleapp.Sale)
+				expResp := exp.(*saleapp.Sale)
+
+				expResp.ID = gotResp.ID
+				expResp.UpdatedAt = gotResp.UpdatedAt
+				expResp.CreatedAt = gotResp.CreatedAt
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "pricelist",
+			URL:        "/v1/sales",
+			Token:      sd.Users[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[0].ID.String(),
+						Quantity:  2,
+					},
+					{
+						ProductID: sd.Products[1].ID.String(),
+						Quantity:  1,
+					},
+				},
+			},
+			GotResp: &saleapp.Sale{},
+			ExpResp: &saleapp.Sale{
+				Currency: "USD",
+				Amount:   2 + sd.Products[1].Price.Value(),
+				Customer: saleapp.Customer{
+					ID:    sd.Users[1].ID.String(),
+					Name:  sd.Users[1].Name.String(),
+					Email: sd.Users[1].Email.Address,
+				},
+				Items: []saleapp.Item{
+					{
+						ID:           sd.Products[0].ID.String(),
+						Name:         sd.Products[0].Name.String(),
+						UnityPrice:   1,
+						ExchangeRate: 1,
+						PriceListID:  sd.PriceLists[0].ID.String(),
+						Quantity:     2,
+						Unit:         "piece",
+						Amount:       2,
+						Discount:     0,
+					},
+					{
+						ID:           sd.Products[1].ID.String(),
+						Name:         sd.Products[1].Name.String(),
+						UnityPrice:   sd.Products[1].Price.Value(),
+						ExchangeRate: 1,
+						Quantity:     1,
+						Unit:         "piece",
+						Amount:       sd.Products[1].Price.Value(),
+						Discount:     0,
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*saleapp.Sale)
+				expResp := exp.(*saleapp.Sale)
+
+				expResp.ID = gotResp.ID
+				expResp.UpdatedAt = gotResp.UpdatedAt
+				expResp.CreatedAt = gotResp.CreatedAt
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "bundle",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[3].ID.String(),
+						Quantity:  2,
+					},
+				},
+			},
+			GotResp: &saleapp.Sale{},
+			ExpResp: &saleapp.Sale{
+				Currency: "USD",
+				Amount:   40,
+				Customer: saleapp.Customer{
+					ID:    sd.Users[0].ID.String(),
+					Name:  sd.Users[0].Name.String(),
+					Email: sd.Users[0].Email.Address,
+				},
+				Items: []saleapp.Item{
+					{
+						ID:           sd.Products[3].ID.String(),
+						Name:         sd.Products[3].Name.String(),
+						UnityPrice:   20,
+						ExchangeRate: 1,
+						Quantity:     2,
+						Unit:         "piece",
+						Amount:       40,
+						Discount:     0,
+						Components: []saleapp.ItemComponent{
+							{
+								ProductID: sd.Products[2].ID.String(),
+								Quantity:  4,
+								Amount:    40,
+							},
+						},
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*saleapp.Sale)
+				expResp := exp.(*saleapp.Sale)
+
+				expResp.ID = gotResp.ID
+				expResp.UpdatedAt = gotResp.UpdatedAt
+				expResp.CreatedAt = gotResp.CreatedAt
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "by-weight",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[4].ID.String(),
+						Quantity:  750,
+						Unit:      "g",
+					},
+				},
+			},
+			GotResp: &saleapp.Sale{},
+			ExpResp: &saleapp.Sale{
+				Currency: "USD",
+				Amount:   22.5,
+				Customer: saleapp.Customer{
+					ID:    sd.Users[0].ID.String(),
+					Name:  sd.Users[0].Name.String(),
+					Email: sd.Users[0].Email.Address,
+				},
+				Items: []saleapp.Item{
+					{
+						ID:           sd.Products[4].ID.String(),
+						Name:         sd.Products[4].Name.String(),
+						UnityPrice:   30,
+						ExchangeRate: 1,
+						Quantity:     0.75,
+						Unit:         "kg",
+						Amount:       22.5,
+						Discount:     0,
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*saleapp.Sale)
+				expResp := exp.(*saleapp.Sale)
+
+				expResp.ID = gotResp.ID
+				expResp.UpdatedAt = gotResp.UpdatedAt
+				expResp.CreatedAt = gotResp.CreatedAt
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "currency",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &saleapp.NewSale{
+				Currency: "eur",
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[4].ID.String(),
+						Quantity:  1,
+					},
+				},
+			},
+			GotResp: &saleapp.Sale{},
+			ExpResp: &saleapp.Sale{
+				Currency: "EUR",
+				Amount:   15,
+				Customer: saleapp.Customer{
+					ID:    sd.Users[0].ID.String(),
+					Name:  sd.Users[0].Name.String(),
+					Email: sd.Users[0].Email.Address,
+				},
+				Items: []saleapp.Item{
+					{
+						ID:           sd.Products[4].ID.String(),
+						Name:         sd.Products[4].Name.String(),
+						UnityPrice:   15,
+						ExchangeRate: 0.5,
+						Quantity:     1,
+						Unit:         "kg",
+						Amount:       15,
+						Discount:     0,
 					},
 				},
 			},
@@ -138,6 +357,115 @@ func create400(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:       "insufficient-stock",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[3].ID.String(),
+						Quantity:  6,
+					},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.FailedPrecondition, "insufficient stock"),
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				expResp := exp.(*errs.Error)
+
+				if !strings.HasSuffix(gotResp.Message, expResp.Message) {
+					return fmt.Sprintf("message %q does not end with %q", gotResp.Message, expResp.Message)
+				}
+
+				return cmp.Diff(gotResp.Code, expResp.Code)
+			},
+		},
+		{
+			Name:       "missing-rate",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &saleapp.NewSale{
+				Currency: "JPY",
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[0].ID.String(),
+						Quantity:  1,
+					},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.FailedPrecondition, "exchange rate not found"),
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				expResp := exp.(*errs.Error)
+
+				if !strings.HasSuffix(gotResp.Message, expResp.Message) {
+					return fmt.Sprintf("message %q does not end with %q", gotResp.Message, expResp.Message)
+				}
+
+				return cmp.Diff(gotResp.Code, expResp.Code)
+			},
+		},
+		{
+			Name:       "fractional-pieces",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[0].ID.String(),
+						Quantity:  1.5,
+					},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "piece must be whole"),
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				expResp := exp.(*errs.Error)
+
+				if !strings.HasSuffix(gotResp.Message, expResp.Message) {
+					return fmt.Sprintf("message %q does not end with %q", gotResp.Message, expResp.Message)
+				}
+
+				return cmp.Diff(gotResp.Code, expResp.Code)
+			},
+		},
+		{
+			Name:       "max-quantity",
+			URL:        "/v1/sales",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &saleapp.NewSale{
+				Items: []saleapp.NewSaleItem{
+					{
+						ProductID: sd.Products[4].ID.String(),
+						Quantity:  5.5,
+					},
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "quantity over the product maximum"),
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				expResp := exp.(*errs.Error)
+
+				if !strings.HasSuffix(gotResp.Message, expResp.Message) {
+					return fmt.Sprintf("message %q does not end with %q", gotResp.Message, expResp.Message)
+				}
+
+				return cmp.Diff(gotResp.Code, expResp.Code)
+			},
+		},
 	}
 
 	return table
@@ -0,0 +1,100 @@
+package saleapi_test
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/app/domain/saleapp"
+	"github.com/rmsj/service/app/sdk/apitest"
+	"github.com/rmsj/service/app/sdk/errs"
+)
+
+func report200(sd apitest.SeedData) []apitest.Table {
+	expReport := func(cur string, rate float64) *saleapp.Report {
+		round := func(value float64) float64 {
+			return math.Round(value*100) / 100
+		}
+
+		var amount, discount float64
+		for _, sl := range sd.Sales {
+			amount += sl.Amount.Value()
+			discount += sl.Discount.Value()
+		}
+
+		rpt := saleapp.Report{
+			Currency: cur,
+			Sales:    len(sd.Sales),
+			Amount:   round(amount * rate),
+			Discount: round(discount * rate),
+		}
+
+		rpt.Days = []saleapp.ReportDay{
+			{
+				Sales:    rpt.Sales,
+				Amount:   rpt.Amount,
+				Discount: rpt.Discount,
+			},
+		}
+
+		return &rpt
+	}
+
+	cmpFunc := func(got any, exp any) string {
+		gotResp := got.(*saleapp.Report)
+		expResp := exp.(*saleapp.Report)
+
+		for i := range gotResp.Days {
+			if i < len(expResp.Days) {
+				expResp.Days[i].Date = gotResp.Days[i].Date
+			}
+		}
+
+		return cmp.Diff(gotResp, expResp)
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "default-currency",
+			URL:        "/v1/sales/report",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &saleapp.Report{},
+			ExpResp:    expReport("USD", 1),
+			CmpFunc:    cmpFunc,
+		},
+		{
+			Name:       "converted",
+			URL:        "/v1/sales/report?currency=eur",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &saleapp.Report{},
+			ExpResp:    expReport("EUR", sd.Rates[0].Rate),
+			CmpFunc:    cmpFunc,
+		},
+	}
+
+	return table
+}
+
+func report401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "user",
+			URL:        "/v1/sales/report",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
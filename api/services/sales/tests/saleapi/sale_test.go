@@ -26,6 +26,9 @@ func Test_Sale(t *testing.T) {
 	test.Run(t, query400(sd), "query-400")
 	test.Run(t, queryByID200(sd), "querybyid-200")
 
+	test.Run(t, report200(sd), "report-200")
+	test.Run(t, report401(sd), "report-401")
+
 	test.Run(t, create200(sd), "create-200")
 	test.Run(t, create401(sd), "create-401")
 	test.Run(t, create400(sd), "create-400")
@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/rmsj/service/app/sdk/apitest"
 	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
 	"github.com/rmsj/service/business/types/role"
 )
 
@@ -23,7 +32,7 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
 	}
 
-	prds, err := productbus.TestGenerateSeedProducts(ctx, 3, busDomain.Product)
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 3, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
@@ -34,7 +43,7 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 	for _, prd := range prds {
 		items = append(items, salebus.NewSaleItem{
 			ProductID: prd.ID,
-			Quantity:  1,
+			Quantity:  quantity.MustParse(1, quantity.Piece),
 			Price:     prd.Price,
 		})
 	}
@@ -66,10 +75,97 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
 	}
 
+	// -------------------------------------------------------------------------
+
+	pls, err := pricebus.TestGenerateSeedPriceLists(ctx, 1, busDomain.Price)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding price lists : %w", err)
+	}
+
+	plItems := []pricebus.Item{
+		{ProductID: prds[0].ID, MinQuantity: 1, Price: money.MustParse(1.5)},
+		{ProductID: prds[0].ID, MinQuantity: 2, Price: money.MustParse(1)},
+	}
+	if err := busDomain.Price.SetItems(ctx, pls[0], plItems); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding price list items : %w", err)
+	}
+
+	if err := busDomain.Price.SetAssignments(ctx, pls[0], pricebus.Assignments{UserIDs: []uuid.UUID{td2.ID}}); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding price list assignments : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	stock := quantity.MustParse(10, quantity.Piece)
+	prds[2], err = busDomain.Product.Update(ctx, prds[2], productbus.UpdateProduct{Stock: &stock})
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding component stock : %w", err)
+	}
+
+	kit := productbus.NewProduct{
+		Name:  name.MustParse("Starter Kit"),
+		Price: money.MustParse(20),
+		Bundle: &productbus.Bundle{
+			Pricing: productbus.PricingFixed,
+			Components: []productbus.Component{
+				{ProductID: prds[2].ID, Quantity: 2},
+			},
+		},
+		CreatedBy: td1.ID,
+	}
+
+	bdl, err := busDomain.Product.Create(ctx, kit)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding bundle : %w", err)
+	}
+
+	coffee := productbus.NewProduct{
+		Name:        name.MustParse("Coffee Beans"),
+		Price:       money.MustParse(30),
+		Unit:        quantity.Kilogram,
+		Stock:       quantity.MustParse(10, quantity.Kilogram),
+		MaxQuantity: quantity.MustParse(5, quantity.Kilogram),
+		CreatedBy:   td1.ID,
+	}
+
+	byWeight, err := busDomain.Product.Create(ctx, coffee)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding product by weight : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu3 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	nrs := []ratebus.NewRate{
+		{
+			From:          currency.MustParse("USD"),
+			To:            currency.MustParse("EUR"),
+			Rate:          0.5,
+			EffectiveFrom: time.Now().AddDate(0, 0, -7),
+		},
+	}
+
+	rates, err := ratebus.TestSeedRates(ctx, nrs, busDomain.Rate)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding rates : %w", err)
+	}
+
 	sd := apitest.SeedData{
-		Users:    []apitest.User{td1, td2},
-		Products: prds,
-		Sales:    append(sales1, sales2...),
+		Admins:     []apitest.User{tu3},
+		Users:      []apitest.User{td1, td2},
+		Products:   append(prds, bdl, byWeight),
+		Sales:      append(sales1, sales2...),
+		PriceLists: pls,
+		Rates:      rates,
 	}
 
 	return sd, nil
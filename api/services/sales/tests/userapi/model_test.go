@@ -9,6 +9,11 @@ import (
 )
 
 func toAppUser(bus userbus.User) userapp.User {
+	var lockedUntil string
+	if !bus.LockedUntil.IsZero() {
+		lockedUntil = bus.LockedUntil.Format(time.RFC3339)
+	}
+
 	return userapp.User{
 		ID:          bus.ID.String(),
 		Name:        bus.Name.String(),
@@ -16,6 +21,7 @@ func toAppUser(bus userbus.User) userapp.User {
 		Roles:       role.ParseToString(bus.Roles),
 		Department:  bus.Department.String(),
 		Enabled:     bus.Enabled,
+		LockedUntil: lockedUntil,
 		DateCreated: bus.DateCreated.Format(time.RFC3339),
 		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
 	}
@@ -2,12 +2,15 @@ package user_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/rmsj/service/app/sdk/apitest"
 	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/rolebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/business/types/role"
 )
 
@@ -54,9 +57,60 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 
 	// -------------------------------------------------------------------------
 
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	for range userbus.LockoutPolicy.Threshold {
+		if _, err := busDomain.User.Authenticate(ctx, usrs[0].Email, "wrong"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			return apitest.SeedData{}, fmt.Errorf("locking user : %w", err)
+		}
+	}
+
+	locked, err := busDomain.User.QueryByID(ctx, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("querying locked user : %w", err)
+	}
+
+	tu6 := apitest.User{
+		User: locked,
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	rles, err := rolebus.TestGenerateSeedRoles(ctx, 1, busDomain.Role, permission.UsersWrite)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding roles : %w", err)
+	}
+
+	if err := busDomain.Role.Assign(ctx, usrs[0].ID, rles[0]); err != nil {
+		return apitest.SeedData{}, fmt.Errorf("assigning role : %w", err)
+	}
+
+	tu8 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	tu7 := apitest.User{
+		User:  tu4.User,
+		Token: apitest.ImpersonationToken(db.BusDomain.User, ath, tu4.Email.Address, tu1.ID),
+	}
+
+	// -------------------------------------------------------------------------
+
 	sd := apitest.SeedData{
-		Users:  []apitest.User{tu3, tu4, tu5},
-		Admins: []apitest.User{tu1, tu2},
+		Users:        []apitest.User{tu3, tu4, tu5, tu6, tu8},
+		Admins:       []apitest.User{tu1, tu2},
+		Impersonated: []apitest.User{tu7},
 	}
 
 	return sd, nil
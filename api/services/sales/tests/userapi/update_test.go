@@ -9,6 +9,7 @@ import (
 	"github.com/rmsj/service/app/domain/userapp"
 	"github.com/rmsj/service/app/sdk/apitest"
 	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/business/sdk/dbtest"
 )
 
@@ -165,7 +166,7 @@ func update401(sd apitest.SeedData) []apitest.Table {
 				PasswordConfirm: dbtest.StringPointer("123"),
 			},
 			GotResp: &errs.Error{},
-			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_permission_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -185,6 +186,60 @@ func update401(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:       "rolemanager",
+			URL:        fmt.Sprintf("/v1/users/role/%s", sd.Users[4].ID),
+			Token:      sd.Users[4].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusUnauthorized,
+			Input: &userapp.UpdateUserRole{
+				Roles: []string{"ADMIN"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[user]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func update403(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "impersonated",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Impersonated[0].ID),
+			Token:      sd.Impersonated[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusForbidden,
+			Input: &userapp.UpdateUser{
+				Password:        dbtest.StringPointer("123"),
+				PasswordConfirm: dbtest.StringPointer("123"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.PermissionDenied, mid.ErrImpersonated),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "manager-admin",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Admins[0].ID),
+			Token:      sd.Users[4].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusForbidden,
+			Input: &userapp.UpdateUser{
+				Password:        dbtest.StringPointer("123"),
+				PasswordConfirm: dbtest.StringPointer("123"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PermissionDenied, "user[%s] is an admin and can only be changed by an admin", sd.Admins[0].ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -31,6 +31,10 @@ func Test_User(t *testing.T) {
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update403(sd), "update-403")
+
+	test.Run(t, unlock200(sd), "unlock-200")
+	test.Run(t, unlock401(sd), "unlock-401")
 
 	test.Run(t, delete200(sd), "delete-200")
 	test.Run(t, delete401(sd), "delete-401")
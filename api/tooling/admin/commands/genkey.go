@@ -1,65 +1,38 @@
 package commands
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"os"
+
+	"github.com/rmsj/service/foundation/keystore"
 )
 
-// GenKey creates an x509 private/public key for auth tokens.
-func GenKey() error {
+// GenKey creates a private/public key pair for auth tokens signed with the
+// specified algorithm: RS256, ES256 or EdDSA.
+func GenKey(alg string) error {
 
-	// Generate a new private key.
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	// Generate a new private key in PEM form.
+	privatePEM, err := keystore.GeneratePrivatePEM(alg)
 	if err != nil {
 		return fmt.Errorf("generating key: %w", err)
 	}
 
-	// Create a file for the private key information in PEM form.
-	privateFile, err := os.Create("private.pem")
-	if err != nil {
-		return fmt.Errorf("creating private file: %w", err)
-	}
-	defer privateFile.Close()
-
-	// Construct a PEM block for the private key.
-	privateBlock := pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	}
-
 	// Write the private key to the private key file.
-	if err := pem.Encode(privateFile, &privateBlock); err != nil {
-		return fmt.Errorf("encoding to private file: %w", err)
+	if err := os.WriteFile("private.pem", privatePEM, 0o600); err != nil {
+		return fmt.Errorf("writing private file: %w", err)
 	}
 
-	// Create a file for the public key information in PEM form.
-	publicFile, err := os.Create("public.pem")
+	// Derive the public key from the private key.
+	publicPEM, err := keystore.PublicPEM(string(privatePEM))
 	if err != nil {
-		return fmt.Errorf("creating public file: %w", err)
-	}
-	defer publicFile.Close()
-
-	// Marshal the public key from the private key to PKIX.
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return fmt.Errorf("marshaling public key: %w", err)
-	}
-
-	// Construct a PEM block for the public key.
-	publicBlock := pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: asn1Bytes,
+		return fmt.Errorf("deriving public key: %w", err)
 	}
 
 	// Write the public key to the public key file.
-	if err := pem.Encode(publicFile, &publicBlock); err != nil {
-		return fmt.Errorf("encoding to public file: %w", err)
+	if err := os.WriteFile("public.pem", []byte(publicPEM), 0o644); err != nil {
+		return fmt.Errorf("writing public file: %w", err)
 	}
 
-	fmt.Println("private and public key files generated")
+	fmt.Printf("%s private and public key files generated\n", alg)
 	return nil
 }
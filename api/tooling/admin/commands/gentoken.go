@@ -85,7 +85,7 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   usr.ID.String(),
 			Issuer:    ath.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(auth.MaxTokenLifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
 		Roles: role.ParseToString(usr.Roles),
@@ -95,7 +95,7 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 	// with need to be configured with the information found in the public key
 	// file to validate these claims. Dgraph does not support key rotate at
 	// this time.
-	token, _, err := ath.GenerateToken(kid, claims)
+	token, err := ath.GenerateToken(kid, claims)
 	if err != nil {
 		return fmt.Errorf("generating token: %w", err)
 	}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/productbus/productio"
+	"github.com/rmsj/service/business/domain/productbus/stores/productdb"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// ProductsImport upserts the products found in the file into the database.
+// When dryRun is set the changes are printed but not applied.
+func ProductsImport(log *logger.Logger, cfg sqldb.Config, path string, format string, dryRun bool) error {
+	if path == "" {
+		fmt.Println("help: products import <file> [csv|jsonl] [dry-run]")
+		return ErrHelp
+	}
+
+	frmt, err := fileFormat(path, format)
+	if err != nil {
+		return fmt.Errorf("file format: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	ips, err := productio.Read(f, frmt)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tx, err := sqldb.NewBeginner(db).Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	productStore := productdb.NewStore(log, db)
+
+	productBus, err := productbus.NewBusiness(log, nil, productStore, productStore).NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("product business: %w", err)
+	}
+
+	results, err := productBus.Import(ctx, ips, uuid.Nil, dryRun)
+	if err != nil {
+		return fmt.Errorf("import products: %w", err)
+	}
+
+	printImport(os.Stdout, results)
+
+	if dryRun {
+		fmt.Println("dry run, no changes applied")
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ProductsExport writes every product in the database to the file, or to
+// stdout when no file is provided, in a format ProductsImport can read back.
+func ProductsExport(log *logger.Logger, cfg sqldb.Config, path string, format string) error {
+	var out io.Writer = os.Stdout
+	if path != "" && path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create file: %w", err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	frmt, err := fileFormat(path, format)
+	if err != nil {
+		return fmt.Errorf("file format: %w", err)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	productStore := productdb.NewStore(log, db)
+	productBus := productbus.NewBusiness(log, nil, productStore, productStore)
+
+	const rowsPerPage = 100
+
+	var prds []productbus.Product
+	for n := 1; ; n++ {
+		pg, err := page.Parse(strconv.Itoa(n), strconv.Itoa(rowsPerPage))
+		if err != nil {
+			return fmt.Errorf("parsing page information: %w", err)
+		}
+
+		batch, err := productBus.Query(ctx, productbus.QueryFilter{}, productbus.DefaultOrderBy, pg)
+		if err != nil {
+			return fmt.Errorf("retrieve products: %w", err)
+		}
+
+		prds = append(prds, batch...)
+
+		if len(batch) < rowsPerPage {
+			break
+		}
+	}
+
+	if err := productio.Write(out, frmt, prds); err != nil {
+		return fmt.Errorf("write products: %w", err)
+	}
+
+	return nil
+}
+
+// fileFormat uses the format when provided, otherwise it is derived from the
+// file extension. CSV is the default when neither is available.
+func fileFormat(path string, format string) (productio.Format, error) {
+	switch {
+	case format != "":
+		return productio.ParseFormat(format)
+	case path != "" && path != "-":
+		return productio.FormatFromPath(path)
+	}
+
+	return productio.CSV, nil
+}
+
+func printImport(w io.Writer, results []productbus.ImportResult) {
+	counts := make(map[productbus.ImportAction]int)
+
+	for _, res := range results {
+		counts[res.Action]++
+
+		key := res.Product.SKU
+		if res.Product.ID != uuid.Nil {
+			key = res.Product.ID.String()
+		}
+
+		fmt.Fprintf(w, "%-10s %-36s %s", res.Action, key, res.Product.Name)
+		if len(res.Changes) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(res.Changes, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "created: %d, updated: %d, unchanged: %d\n", counts[productbus.ImportCreate], counts[productbus.ImportUpdate], counts[productbus.ImportUnchanged])
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/ratebus/rateio"
+	"github.com/rmsj/service/business/domain/ratebus/stores/ratedb"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// RatesImport adds the exchange rates found in the CSV file to the database.
+// Either all the rates in the file are stored or none is.
+func RatesImport(log *logger.Logger, cfg sqldb.Config, path string) error {
+	if path == "" {
+		fmt.Println("help: rates import <file>")
+		return ErrHelp
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	nrs, err := rateio.Read(f)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tx, err := sqldb.NewBeginner(db).Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rateBus, err := ratebus.NewBusiness(log, ratedb.NewStore(log, db)).NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("rate business: %w", err)
+	}
+
+	rates, err := rateBus.Import(ctx, nrs)
+	if err != nil {
+		return fmt.Errorf("import rates: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	for _, rate := range rates {
+		fmt.Printf("%s %s %-14v %s\n", rate.From, rate.To, rate.Rate, rate.EffectiveFrom.Format(time.RFC3339))
+	}
+	fmt.Printf("imported: %d\n", len(rates))
+
+	return nil
+}
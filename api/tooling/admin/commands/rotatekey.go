@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/foundation/keystore"
+)
+
+// RotateKey generates a new signing key for the algorithm in the keys
+// folder. The new key is promoted once the grace period has passed and the
+// keys it replaces are retired retireAfter later. Keys past their retirement
+// are removed. Retiring keys sooner than the longest token lifetime is
+// allowed, to stop trusting a compromised key, but tokens signed by the
+// retired keys stop verifying before they expire, so a warning is printed.
+func RotateKey(keysFolder string, alg string, grace time.Duration, retireAfter time.Duration) error {
+	if retireAfter < auth.MaxTokenLifetime {
+		fmt.Printf("warning: keys retire after %s but tokens can live for %s, tokens signed by the retired keys stop verifying early\n", retireAfter, auth.MaxTokenLifetime)
+	}
+
+	rot, err := keystore.Rotate(keysFolder, alg, grace, retireAfter)
+	if err != nil {
+		return fmt.Errorf("rotating key: %w", err)
+	}
+
+	fmt.Printf("new key: %s, signing from %s\n", rot.KID, rot.ActivatesAt.Format(time.RFC3339))
+
+	for kid, retiresAt := range rot.Retiring {
+		fmt.Printf("key %s retires at %s\n", kid, retiresAt.Format(time.RFC3339))
+	}
+
+	for _, kid := range rot.Retired {
+		fmt.Printf("key %s retired and removed\n", kid)
+	}
+
+	fmt.Println("send SIGHUP to the auth service to load the keys")
+
+	return nil
+}
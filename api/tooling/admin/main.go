@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/google/uuid"
 	"github.com/rmsj/service/api/tooling/admin/commands"
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/business/sdk/sqldb"
 	"github.com/rmsj/service/foundation/logger"
 )
@@ -32,6 +34,7 @@ type config struct {
 	Auth struct {
 		KeysFolder string `conf:"default:zarf/keys/"`
 		DefaultKID string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+		KeyAlg     string `conf:"default:RS256,flag:alg,help:signing algorithm for new keys: RS256, ES256 or EdDSA"`
 	}
 }
 
@@ -121,11 +124,62 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 			return fmt.Errorf("getting users: %w", err)
 		}
 
+	case "products":
+		switch args.Num(1) {
+		case "import":
+			path := args.Num(2)
+			format := args.Num(3)
+			dryRun := args.Num(4) == "dry-run"
+			if format == "dry-run" {
+				format, dryRun = "", true
+			}
+			if err := commands.ProductsImport(log, dbConfig, path, format, dryRun); err != nil {
+				return fmt.Errorf("importing products: %w", err)
+			}
+
+		case "export":
+			path := args.Num(2)
+			format := args.Num(3)
+			if err := commands.ProductsExport(log, dbConfig, path, format); err != nil {
+				return fmt.Errorf("exporting products: %w", err)
+			}
+
+		default:
+			fmt.Println("help: products import <file> [csv|jsonl] [dry-run]")
+			fmt.Println("help: products export [file|-] [csv|jsonl]")
+			return commands.ErrHelp
+		}
+
+	case "rates":
+		switch args.Num(1) {
+		case "import":
+			if err := commands.RatesImport(log, dbConfig, args.Num(2)); err != nil {
+				return fmt.Errorf("importing rates: %w", err)
+			}
+
+		default:
+			fmt.Println("help: rates import <file>")
+			return commands.ErrHelp
+		}
+
 	case "genkey":
-		if err := commands.GenKey(); err != nil {
+		if err := commands.GenKey(cfg.Auth.KeyAlg); err != nil {
 			return fmt.Errorf("key generation: %w", err)
 		}
 
+	case "rotate-key":
+		grace, err := parseDuration(args.Num(1), time.Hour)
+		if err != nil {
+			return fmt.Errorf("parsing grace period: %w", err)
+		}
+		retireAfter, err := parseDuration(args.Num(2), auth.MaxTokenLifetime)
+		if err != nil {
+			return fmt.Errorf("parsing retire after: %w", err)
+		}
+		if err := commands.RotateKey(cfg.Auth.KeysFolder, cfg.Auth.KeyAlg, grace, retireAfter); err != nil {
+			return fmt.Errorf("rotating key: %w", err)
+		}
+
 	case "gentoken":
 		userID, err := uuid.Parse(args.Num(1))
 		if err != nil {
@@ -144,7 +198,11 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 		fmt.Println("seed:       add data to the database")
 		fmt.Println("useradd:    add a new user to the database")
 		fmt.Println("users:      get a list of users from the database")
+		fmt.Println("products:   import or export the product catalog")
+		fmt.Println("rates:      import exchange rates")
 		fmt.Println("genkey:     generate a set of private/public key files")
+		fmt.Printf("rotate-key: rotate the signing key [grace] [retire-after, default %s]\n", auth.MaxTokenLifetime)
+		fmt.Println("use --alg before the command to pick RS256, ES256 or EdDSA keys")
 		fmt.Println("gentoken:   generate a JWT for a user with claims")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
@@ -152,3 +210,13 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 
 	return nil
 }
+
+// parseDuration parses an optional duration argument, returning the default
+// when it is not provided.
+func parseDuration(arg string, def time.Duration) (time.Duration, error) {
+	if arg == "" {
+		return def, nil
+	}
+
+	return time.ParseDuration(arg)
+}
@@ -4,11 +4,14 @@ package authapp
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"net/mail"
+	"slices"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/markbates/goth"
 
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
@@ -16,20 +19,26 @@ import (
 	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/role"
 	"github.com/rmsj/service/foundation/web"
 )
 
 type app struct {
-	auth    *auth.Auth
-	authBus *authbus.Business
-	userBus *userbus.Business
+	auth            *auth.Auth
+	authBus         *authbus.Business
+	userBus         *userbus.Business
+	requireAdminMFA bool
+	providers       goth.Providers
 }
 
-func newApp(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business) *app {
+func newApp(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business, requireAdminMFA bool, providers goth.Providers) *app {
 	return &app{
-		auth:    ath,
-		authBus: authBus,
-		userBus: userBus,
+		auth:            ath,
+		authBus:         authBus,
+		userBus:         userBus,
+		requireAdminMFA: requireAdminMFA,
+		providers:       providers,
 	}
 }
 
@@ -42,12 +51,38 @@ func (a *app) token(ctx context.Context, r *http.Request) web.Encoder {
 	// The BearerBasic middleware function generates the claims.
 	claims := mid.GetClaims(ctx)
 
-	tkn, refreshToken, err := a.auth.GenerateToken(kid, claims)
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.DataLoss, err)
+	}
+
+	// Basic auth can't carry a second factor, so users who have or need
+	// multi-factor authentication must log in with login instead.
+	_, required, err := a.mfaRequired(ctx, userID, claims)
+	if err != nil {
+		return errs.Newf(errs.Internal, "mfa required: userID[%s]: %s", userID, err)
+	}
+
+	if required {
+		return errs.Newf(errs.PermissionDenied, "multi-factor authentication required, log in with /v1/auth/login")
+	}
+
+	tkn, err := a.auth.GenerateToken(kid, claims)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
-	return token{Token: tkn, RefreshToken: refreshToken}
+	return token{Token: tkn}
+}
+
+// jwks publishes the public keys that verify tokens issued by this service.
+func (a *app) jwks(_ context.Context, _ *http.Request) web.Encoder {
+	set, err := a.auth.JWKS()
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return jwks(set)
 }
 
 func (a *app) authenticate(ctx context.Context, r *http.Request) web.Encoder {
@@ -68,20 +103,109 @@ func (a *app) authenticate(ctx context.Context, r *http.Request) web.Encoder {
 }
 
 func (a *app) authorize(ctx context.Context, r *http.Request) web.Encoder {
-	var auth authclient.Authorize
-	if err := web.Decode(r, &auth); err != nil {
+	var req authclient.Authorize
+	if err := web.Decode(r, &req); err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	if err := a.auth.Authorize(ctx, auth.Claims, auth.UserID, auth.Rule); err != nil {
-		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", auth.Claims.Roles, auth.Rule, err)
+	var err error
+	switch req.Rule {
+	case auth.RuleAdminOrLocation:
+		err = a.auth.AuthorizeLocation(ctx, req.Claims, req.LocationID, req.Locations, req.Rule)
+	case auth.RulePermission:
+		err = a.auth.AuthorizePermission(ctx, req.Claims, req.Permission)
+	case auth.RulePermissionOrSubject:
+		err = a.auth.AuthorizePermissionOrSubject(ctx, req.Claims, req.UserID, req.Permission)
+	case auth.RulePermissionOrLocation:
+		err = a.auth.AuthorizePermissionOrLocation(ctx, req.Claims, req.LocationID, req.Locations, req.Permission)
+	default:
+		err = a.auth.Authorize(ctx, req.Claims, req.UserID, req.Rule)
+	}
+
+	if err != nil {
+		return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", req.Claims.Roles, req.Rule, err)
 	}
 
 	return nil
 }
 
-// login handles user login with username and password
+// login handles user login with username and password. When multi-factor
+// authentication applies to the user a challenge is returned instead of the
+// token, to be completed with loginMFA.
 func (a *app) login(ctx context.Context, r *http.Request) web.Encoder {
+	// if we get to this point, we already have claims as the login itself happens in the middleware
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.DataLoss, err)
+	}
+
+	enrolled, required, err := a.mfaRequired(ctx, userID, mid.GetClaims(ctx))
+	if err != nil {
+		return errs.Newf(errs.Internal, "mfa required: userID[%s]: %s", userID, err)
+	}
+
+	if required {
+		ch, err := a.authBus.CreateMFAChallenge(ctx, userID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "create mfa challenge: userID[%s]: %s", userID, err)
+		}
+
+		return toAppMFAChallenge(ch, !enrolled)
+	}
+
+	return a.issueToken(ctx, r)
+}
+
+// oauthBegin starts a login with the identity provider and redirects the user
+// to it. The login is completed by the callback, which the OAuth middleware
+// handles before login issues the token.
+func (a *app) oauthBegin(ctx context.Context, r *http.Request) web.Encoder {
+	provider, exists := a.providers[web.Param(r, "provider")]
+	if !exists {
+		return errs.Newf(errs.NotFound, "unknown provider %q", web.Param(r, "provider"))
+	}
+
+	state, err := id.NewRandomString(43)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	sess, err := provider.BeginAuth(state)
+	if err != nil {
+		return errs.Newf(errs.Internal, "begin auth: provider[%s]: %s", provider.Name(), err)
+	}
+
+	authURL, err := sess.GetAuthURL()
+	if err != nil {
+		return errs.Newf(errs.Internal, "auth url: provider[%s]: %s", provider.Name(), err)
+	}
+
+	ns := authbus.NewOAuthState{
+		State:    state,
+		Provider: provider.Name(),
+		Session:  sess.Marshal(),
+	}
+
+	if _, err := a.authBus.CreateOAuthState(ctx, ns); err != nil {
+		return errs.Newf(errs.Internal, "create oauth state: provider[%s]: %s", provider.Name(), err)
+	}
+
+	mid.SetOAuthState(ctx, r, state)
+
+	http.Redirect(web.GetWriter(ctx), r, authURL, http.StatusFound)
+
+	return web.NewNoResponse()
+}
+
+// loginMFA completes a login that required a second factor. The LoginMFA
+// middleware checks the challenge and code and generates the claims.
+func (a *app) loginMFA(ctx context.Context, r *http.Request) web.Encoder {
+	return a.issueToken(ctx, r)
+}
+
+// issueToken generates a token for the claims in the context and starts a
+// new session for the device, returning its refresh token.
+func (a *app) issueToken(ctx context.Context, r *http.Request) web.Encoder {
 	kid := web.Param(r, "kid")
 	if kid == "" {
 		kid = a.auth.ActiveKID()
@@ -90,38 +214,35 @@ func (a *app) login(ctx context.Context, r *http.Request) web.Encoder {
 		}
 	}
 
-	// if we get to this point, we already have claims as the login itself happens in the middleware
-
-	// The BearerBasic middleware function generates the claims.
+	// The Login middleware functions generate the claims.
 	claims := mid.GetClaims(ctx)
 
-	tkn, refreshToken, err := a.auth.GenerateToken(kid, claims)
+	tkn, err := a.auth.GenerateToken(kid, claims)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
-	// update user with new refresh token
 	userID, err := mid.GetUserID(ctx)
 	if err != nil {
 		return errs.New(errs.DataLoss, err)
 	}
 
-	usr, err := a.userBus.QueryByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, userbus.ErrNotFound) {
-			return errs.Newf(errs.NotFound, "invalid user id: %s", userID)
-		}
-		return errs.Newf(errs.Internal, "error getting user to login")
+	ns := authbus.NewSession{
+		UserID:    userID,
+		UserAgent: r.UserAgent(),
+		IP:        mid.ClientIP(r),
 	}
-	_, err = a.userBus.Update(ctx, usr, userbus.UpdateUser{RefreshToken: &refreshToken})
+
+	_, refreshToken, err := a.authBus.CreateSession(ctx, ns)
 	if err != nil {
-		return errs.Newf(errs.Internal, "error updating user refresh token: email[%s]", usr.Email)
+		return errs.Newf(errs.Internal, "create session: userID[%s]: %s", userID, err)
 	}
 
 	return token{Token: tkn, RefreshToken: refreshToken}
 }
 
-// refresh creates a new token for the logged in user, using the provided refresh token
+// refresh creates a new token for the user. The RefreshToken middleware
+// rotates the session and generates the claims.
 func (a *app) refresh(ctx context.Context, r *http.Request) web.Encoder {
 	kid := web.Param(r, "kid")
 	if kid == "" {
@@ -131,31 +252,59 @@ func (a *app) refresh(ctx context.Context, r *http.Request) web.Encoder {
 		}
 	}
 
-	var app auth.RefreshToken
+	refreshToken, err := mid.GetRefreshToken(ctx)
+	if err != nil {
+		return errs.New(errs.DataLoss, err)
+	}
+
+	tkn, err := a.auth.GenerateToken(kid, mid.GetClaims(ctx))
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return token{
+		Token:        tkn,
+		RefreshToken: refreshToken,
+	}
+}
+
+// logout revokes the access token used to make the request and, when a
+// refresh token is provided, the session it belongs to.
+func (a *app) logout(ctx context.Context, r *http.Request) web.Encoder {
+	var app Logout
 	if err := web.Decode(r, &app); err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	// if we get to this point, we already have claims as the login itself happens in the middleware
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
 
-	// The BearerBasic middleware function generates the claims.
 	claims := mid.GetClaims(ctx)
+	if claims.ID == "" {
+		return errs.Newf(errs.FailedPrecondition, "token has no id and can't be revoked on its own")
+	}
 
-	fmt.Printf("claims: %+v \n", claims)
-
-	tkn, refreshToken, err := a.auth.GenerateToken(kid, claims)
-	if err != nil {
-		return errs.New(errs.Internal, err)
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
 	}
 
-	if err := a.updateRefreshToken(ctx, app.Token, refreshToken); err != nil {
-		return errs.Newf(errs.FailedPrecondition, "error updating token")
+	if err := a.authBus.RevokeToken(ctx, claims.ID, userID, expiresAt); err != nil {
+		return errs.Newf(errs.Internal, "revoke token: userID[%s]: %s", userID, err)
 	}
 
-	return token{
-		Token:        tkn,
-		RefreshToken: refreshToken,
+	if app.RefreshToken != "" {
+		if err := a.authBus.RevokeSessionByToken(ctx, userID, app.RefreshToken); err != nil {
+			if errors.Is(err, authbus.ErrInvalidSession) {
+				return errs.New(errs.InvalidArgument, err)
+			}
+			return errs.Newf(errs.Internal, "revoke session: userID[%s]: %s", userID, err)
+		}
 	}
+
+	return nil
 }
 
 // forgotPassword creates a forgot password token and sends via email to the user, if a valid email is provided, otherwise, do nothing
@@ -197,6 +346,11 @@ func (a *app) resetPassword(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.InvalidArgument, "error updating user password")
 	}
 
+	// A password reset signs the user out everywhere.
+	if err := a.authBus.RevokeUserSessions(ctx, userID); err != nil {
+		return errs.Newf(errs.Internal, "revoke sessions: userID[%s]: %s", userID, err)
+	}
+
 	return nil
 }
 
@@ -313,37 +467,476 @@ func (a *app) queryPasswordResetByToken(ctx context.Context, token string) (Pass
 	return toAppPasswordResetToken(pr), nil
 }
 
-// updateRefreshToken updates user refresh token after a new token is generated
-func (a *app) updateRefreshToken(ctx context.Context, currentToken, newToken string) error {
+// updateUserPassword updates user password using the reset password flow
+func (a *app) updateUserPassword(ctx context.Context, userID uuid.UUID, up userbus.UpdateUser) error {
 
-	usr, err := a.userBus.QueryByRefreshToken(ctx, currentToken)
+	usr, err := a.userBus.QueryByID(ctx, userID)
 	if err != nil {
-		return errs.Newf(errs.Internal, "error updating user token: %s", err)
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "invalid contact id: %s", userID)
+		}
+		return errs.Newf(errs.Internal, "error getting user to update refresh token: email[%s]", usr.Email)
 	}
 
-	_, err = a.userBus.Update(ctx, usr, userbus.UpdateUser{RefreshToken: &newToken})
+	_, err = a.userBus.Update(ctx, usr, up)
 	if err != nil {
-		return errs.Newf(errs.Internal, "updating refresh token: email[%s]: %s", usr.Email, err)
+		return errs.Newf(errs.Internal, "error updating refresh token: email[%s]", usr.Email)
 	}
 
 	return nil
 }
 
-// updateUserPassword updates user password using the reset password flow
-func (a *app) updateUserPassword(ctx context.Context, userID uuid.UUID, up userbus.UpdateUser) error {
+// =============================================================================
+// Multi-factor authentication
+
+// mfaEnroll starts multi-factor enrollment for the authenticated user.
+func (a *app) mfaEnroll(ctx context.Context, _ *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return a.enrollMFA(ctx, userID)
+}
+
+// loginMFAEnroll starts multi-factor enrollment for a user whose login was
+// challenged because MFA is required for their role.
+func (a *app) loginMFAEnroll(ctx context.Context, r *http.Request) web.Encoder {
+	var app MFAChallengeEnroll
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ch, err := a.authBus.QueryMFAChallenge(ctx, app.Challenge)
+	if err != nil {
+		if errors.Is(err, authbus.ErrNotFound) || errors.Is(err, authbus.ErrMFAChallengeExpired) {
+			return errs.Newf(errs.Unauthenticated, "invalid or expired mfa challenge")
+		}
+		return errs.Newf(errs.Internal, "query mfa challenge: %s", err)
+	}
+
+	return a.enrollMFA(ctx, ch.UserID)
+}
+
+// mfaVerify confirms a pending enrollment for the authenticated user.
+func (a *app) mfaVerify(ctx context.Context, r *http.Request) web.Encoder {
+	var app MFACode
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
 
+	if _, err := a.authBus.VerifyMFA(ctx, userID, app.Code); err != nil {
+		switch {
+		case errors.Is(err, authbus.ErrMFANotEnrolled), errors.Is(err, authbus.ErrMFAEnabled):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, authbus.ErrInvalidMFACode):
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "verify mfa: userID[%s]: %s", userID, err)
+	}
+
+	return nil
+}
+
+// mfaDisable turns off multi-factor authentication for the authenticated user.
+func (a *app) mfaDisable(ctx context.Context, r *http.Request) web.Encoder {
+	var app MFACode
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if a.requireAdminMFA && isAdmin(mid.GetClaims(ctx)) {
+		return errs.Newf(errs.FailedPrecondition, "mfa is required for admins")
+	}
+
+	if err := a.authBus.DisableMFA(ctx, userID, app.Code); err != nil {
+		switch {
+		case errors.Is(err, authbus.ErrMFANotEnrolled):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, authbus.ErrInvalidMFACode):
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "disable mfa: userID[%s]: %s", userID, err)
+	}
+
+	return nil
+}
+
+func (a *app) enrollMFA(ctx context.Context, userID uuid.UUID) web.Encoder {
 	usr, err := a.userBus.QueryByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, userbus.ErrNotFound) {
-			return errs.Newf(errs.NotFound, "invalid contact id: %s", userID)
+			return errs.Newf(errs.NotFound, "invalid user id: %s", userID)
 		}
-		return errs.Newf(errs.Internal, "error getting user to update refresh token: email[%s]", usr.Email)
+		return errs.Newf(errs.Internal, "query user: userID[%s]: %s", userID, err)
 	}
 
-	_, err = a.userBus.Update(ctx, usr, up)
+	enr, err := a.authBus.EnrollMFA(ctx, usr.ID, a.auth.Issuer(), usr.Email.Address)
 	if err != nil {
-		return errs.Newf(errs.Internal, "error updating refresh token: email[%s]", usr.Email)
+		if errors.Is(err, authbus.ErrMFAEnabled) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "enroll mfa: userID[%s]: %s", userID, err)
+	}
+
+	return toAppMFAEnrollment(enr)
+}
+
+// mfaRequired reports whether the user has MFA enabled and whether the login
+// needs a second factor, either because MFA is enabled or because it is
+// required for the admin role.
+func (a *app) mfaRequired(ctx context.Context, userID uuid.UUID, claims auth.Claims) (bool, bool, error) {
+	mfa, err := a.authBus.QueryMFA(ctx, userID)
+	if err != nil && !errors.Is(err, authbus.ErrMFANotEnrolled) {
+		return false, false, err
+	}
+
+	if mfa.Enabled {
+		return true, true, nil
+	}
+
+	return false, a.requireAdminMFA && isAdmin(claims), nil
+}
+
+func isAdmin(claims auth.Claims) bool {
+	return slices.Contains(claims.Roles, role.Admin.String())
+}
+
+// =============================================================================
+// Sessions
+
+// querySessions lists the active sessions for the authenticated user.
+func (a *app) querySessions(ctx context.Context, _ *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sessions, err := a.authBus.QuerySessions(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query sessions: userID[%s]: %s", userID, err)
+	}
+
+	return toAppSessions(sessions)
+}
+
+// revokeSession signs the authenticated user out of a single session.
+func (a *app) revokeSession(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sessionID, err := uuid.Parse(web.Param(r, "session_id"))
+	if err != nil {
+		return errs.NewFieldErrors("session_id", err)
+	}
+
+	if err := a.authBus.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, authbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "session[%s] not found", sessionID)
+		}
+		return errs.Newf(errs.Internal, "revoke session: sessionID[%s]: %s", sessionID, err)
+	}
+
+	return nil
+}
+
+// revokeSessions signs the authenticated user out of every session.
+func (a *app) revokeSessions(ctx context.Context, _ *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.authBus.RevokeUserSessions(ctx, userID); err != nil {
+		return errs.Newf(errs.Internal, "revoke sessions: userID[%s]: %s", userID, err)
+	}
+
+	return nil
+}
+
+// queryPolicy shows the active authorization policy bundle.
+func (a *app) queryPolicy(ctx context.Context, _ *http.Request) web.Encoder {
+	return toAppPolicy(a.auth.Policy())
+}
+
+// reloadPolicy loads the policy bundle from the policy directory. A bundle
+// that fails validation is rejected and the active bundle is kept.
+func (a *app) reloadPolicy(ctx context.Context, _ *http.Request) web.Encoder {
+	info, err := a.auth.ReloadPolicy(ctx)
+	if err != nil {
+		if errors.Is(err, auth.ErrPolicyUnchanged) {
+			return toAppPolicy(info)
+		}
+		return errs.Newf(errs.FailedPrecondition, "reload policy: %s", err)
+	}
+
+	return toAppPolicy(info)
+}
+
+// clientToken issues an access token for the client credentials grant. The
+// ClientCredentials middleware authenticates the client and grants the scopes.
+func (a *app) clientToken(ctx context.Context, _ *http.Request) web.Encoder {
+	claims := mid.GetClaims(ctx)
+
+	tkn, err := a.auth.GenerateToken(a.auth.ActiveKID(), claims)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	// Tokens must not be cached along the way.
+	web.GetWriter(ctx).Header().Set("Cache-Control", "no-store")
+
+	ct := ClientToken{
+		AccessToken: tkn,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(claims.ExpiresAt.Time).Seconds()),
+		Scope:       claims.Scope,
+	}
+
+	return ct
+}
+
+// createClient registers a client. The secret is only returned here.
+func (a *app) createClient(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewClient
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	nc, err := toBusNewClient(app, userID)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if _, err := a.userBus.QueryByID(ctx, nc.OwnerID); err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.Newf(errs.FailedPrecondition, "owner[%s] not found", nc.OwnerID)
+		}
+		return errs.Newf(errs.Internal, "query owner: ownerID[%s]: %s", nc.OwnerID, err)
+	}
+
+	clt, secret, err := a.authBus.CreateClient(ctx, nc)
+	if err != nil {
+		return errs.Newf(errs.Internal, "create client: ownerID[%s]: %s", nc.OwnerID, err)
+	}
+
+	resp := toAppClient(clt)
+	resp.Secret = secret
+
+	return resp
+}
+
+// queryClients returns the registered clients.
+func (a *app) queryClients(ctx context.Context, _ *http.Request) web.Encoder {
+	clts, err := a.authBus.QueryClients(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query clients: %s", err)
+	}
+
+	return toAppClients(clts)
+}
+
+// deleteClient removes a client, which stops its tokens being accepted.
+func (a *app) deleteClient(ctx context.Context, r *http.Request) web.Encoder {
+	clientID, err := uuid.Parse(web.Param(r, "client_id"))
+	if err != nil {
+		return errs.NewFieldErrors("client_id", err)
+	}
+
+	clt, err := a.authBus.QueryClientByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, authbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "client[%s] not found", clientID)
+		}
+		return errs.Newf(errs.Internal, "query client: clientID[%s]: %s", clientID, err)
+	}
+
+	if err := a.authBus.DeleteClient(ctx, clt); err != nil {
+		return errs.Newf(errs.Internal, "delete client: clientID[%s]: %s", clientID, err)
+	}
+
+	return nil
+}
+
+// createAPIKey creates a personal API key for the authenticated user. The key
+// is only returned here. A key can't be used to create more keys, so a
+// leaked key can't outlive its own expiry or revocation.
+func (a *app) createAPIKey(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewAPIKey
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	claims := mid.GetClaims(ctx)
+	if claims.IsClient() || claims.IsAPIKey() {
+		return errs.Newf(errs.PermissionDenied, "api keys can only be created by a user signed in with a token")
+	}
+
+	nk, err := toBusNewAPIKey(app, userID, mid.GetTime(ctx))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ak, key, err := a.authBus.CreateAPIKey(ctx, nk)
+	if err != nil {
+		return errs.Newf(errs.Internal, "create api key: userID[%s]: %s", userID, err)
+	}
+
+	resp := toAppAPIKey(ak)
+	resp.Key = key
+
+	return resp
+}
+
+// queryAPIKeys returns the personal API keys of the authenticated user.
+func (a *app) queryAPIKeys(ctx context.Context, _ *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	keys, err := a.authBus.QueryAPIKeys(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query api keys: userID[%s]: %s", userID, err)
+	}
+
+	return toAppAPIKeys(keys)
+}
+
+// revokeAPIKey revokes one of the personal API keys of the authenticated
+// user.
+func (a *app) revokeAPIKey(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	keyID, err := uuid.Parse(web.Param(r, "key_id"))
+	if err != nil {
+		return errs.NewFieldErrors("key_id", err)
+	}
+
+	if err := a.authBus.RevokeAPIKey(ctx, userID, keyID); err != nil {
+		if errors.Is(err, authbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "api key[%s] not found", keyID)
+		}
+		return errs.Newf(errs.Internal, "revoke api key: keyID[%s]: %s", keyID, err)
 	}
 
 	return nil
 }
+
+// impersonate issues a short-lived token for an admin to act as the user.
+// The token names the admin in its act claim, has no refresh token and the
+// start is kept in the audit trail. Admins can't be impersonated.
+func (a *app) impersonate(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return errs.NewFieldErrors("user_id", err)
+	}
+
+	actorID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	claims := mid.GetClaims(ctx)
+	if claims.IsClient() || claims.IsAPIKey() {
+		return errs.Newf(errs.PermissionDenied, "impersonation can only be started by an admin signed in with a token")
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "user[%s] not found", userID)
+		}
+		return errs.Newf(errs.Internal, "query user: userID[%s]: %s", userID, err)
+	}
+
+	if !usr.Enabled {
+		return errs.Newf(errs.FailedPrecondition, "user[%s] is disabled", userID)
+	}
+
+	// Impersonating an admin would hand the admin role to whoever starts it,
+	// so only users without it can be impersonated.
+	if slices.Contains(usr.Roles, role.Admin) {
+		return errs.Newf(errs.PermissionDenied, "user[%s] is an admin and can't be impersonated", userID)
+	}
+
+	imp, err := a.authBus.Impersonate(ctx, actorID, usr.ID)
+	if err != nil {
+		if errors.Is(err, authbus.ErrSelfImpersonation) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "impersonate: userID[%s]: %s", userID, err)
+	}
+
+	kid := a.auth.ActiveKID()
+	if kid == "" {
+		return errs.New(errs.FailedPrecondition, errs.NewFieldErrors("kid", errors.New("missing kid")))
+	}
+
+	tkn, err := a.auth.GenerateToken(kid, impersonationClaims(a.auth, usr, imp, mid.GetTime(ctx)))
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return token{Token: tkn}
+}
+
+// impersonationClaims constructs the claims of a token for the admin acting
+// as the user. The user is the subject so the token is authorized as them.
+func impersonationClaims(ath *auth.Auth, usr userbus.User, imp authbus.Impersonation, now time.Time) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        imp.TokenID,
+			Subject:   usr.ID.String(),
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(imp.ExpiresAt.UTC()),
+			IssuedAt:  jwt.NewNumericDate(now.UTC()),
+		},
+		Roles: role.ParseToString(usr.Roles),
+		Actor: &auth.Actor{Subject: imp.ActorID.String()},
+	}
+}
+
+// introspect reports whether the token in the form is active and what it
+// stands for. The Client middleware authenticates the caller.
+func (a *app) introspect(ctx context.Context, r *http.Request) web.Encoder {
+	token := r.PostForm.Get("token")
+	if token == "" {
+		return errs.NewFieldErrors("token", errors.New("missing token"))
+	}
+
+	in, err := a.auth.Introspect(ctx, token)
+	if err != nil {
+		return errs.Newf(errs.Internal, "introspect: %s", err)
+	}
+
+	// The answer must not be cached along the way.
+	web.GetWriter(ctx).Header().Set("Cache-Control", "no-store")
+
+	return toAppIntrospection(in)
+}
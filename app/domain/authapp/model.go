@@ -2,15 +2,20 @@ package authapp
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/types/permission"
 )
 
 type token struct {
 	Token        string `json:"token"`
-	RefreshToken string `json:"refreshToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
 // Encode implements the encoder interface.
@@ -19,6 +24,29 @@ func (t token) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
+// Logout optionally carries the refresh token of the session to end along
+// with the access token.
+type Logout struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Decode implements the decoder interface. An empty body is allowed.
+func (app *Logout) Decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, app)
+}
+
+// jwks is the set of public keys published for token verification.
+type jwks auth.JWKS
+
+// Encode implements the encoder interface.
+func (j jwks) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(j)
+	return data, "application/json", err
+}
+
 // PasswordResetToken represents a password reset in the system
 type PasswordResetToken struct {
 	Email  string
@@ -82,3 +110,410 @@ func (app ResetPassword) Validate() error {
 	}
 	return nil
 }
+
+// MFAChallenge is returned by login when a second factor is required.
+type MFAChallenge struct {
+	MFARequired    bool   `json:"mfaRequired"`
+	EnrollRequired bool   `json:"enrollRequired"`
+	Challenge      string `json:"challenge"`
+	ExpiresAt      string `json:"expiresAt"`
+}
+
+// Encode implements the encoder interface.
+func (app MFAChallenge) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppMFAChallenge(bus authbus.MFAChallenge, enrollRequired bool) MFAChallenge {
+	return MFAChallenge{
+		MFARequired:    true,
+		EnrollRequired: enrollRequired,
+		Challenge:      bus.Token,
+		ExpiresAt:      bus.ExpiryAt.Format(time.RFC3339),
+	}
+}
+
+// MFAEnrollment contains what a user needs to set up an authenticator app.
+type MFAEnrollment struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Encode implements the encoder interface.
+func (app MFAEnrollment) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppMFAEnrollment(bus authbus.MFAEnrollment) MFAEnrollment {
+	return MFAEnrollment{
+		Secret:        bus.Secret,
+		URI:           bus.URI,
+		RecoveryCodes: bus.RecoveryCodes,
+	}
+}
+
+// MFACode contains a code from an authenticator app or a recovery code.
+type MFACode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *MFACode) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app MFACode) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+	}
+	return nil
+}
+
+// MFAChallengeEnroll is used to enroll in MFA during a challenged login.
+type MFAChallengeEnroll struct {
+	Challenge string `json:"challenge" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *MFAChallengeEnroll) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app MFAChallengeEnroll) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+	}
+	return nil
+}
+
+// Session represents a device signed in as the user. The id identifies the
+// session across refresh token rotations.
+type Session struct {
+	ID           string `json:"id"`
+	UserAgent    string `json:"userAgent"`
+	IP           string `json:"ip"`
+	DateCreated  string `json:"dateCreated"`
+	DateLastUsed string `json:"dateLastUsed"`
+	ExpiresAt    string `json:"expiresAt"`
+}
+
+func toAppSession(bus authbus.Session) Session {
+	return Session{
+		ID:           bus.FamilyID.String(),
+		UserAgent:    bus.UserAgent,
+		IP:           bus.IP,
+		DateCreated:  bus.DateCreated.Format(time.RFC3339),
+		DateLastUsed: bus.DateLastUsed.Format(time.RFC3339),
+		ExpiresAt:    bus.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// Sessions is a collection of sessions.
+type Sessions []Session
+
+// Encode implements the encoder interface.
+func (app Sessions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSessions(sessions []authbus.Session) Sessions {
+	app := make(Sessions, len(sessions))
+	for i, sess := range sessions {
+		app[i] = toAppSession(sess)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// Policy describes the active authorization policy bundle.
+type Policy struct {
+	Revision string `json:"revision"`
+	Digest   string `json:"digest"`
+	Source   string `json:"source"`
+	LoadedAt string `json:"loadedAt"`
+}
+
+// Encode implements the encoder interface.
+func (app Policy) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPolicy(info auth.PolicyInfo) Policy {
+	return Policy{
+		Revision: info.Revision,
+		Digest:   info.Digest,
+		Source:   info.Source,
+		LoadedAt: info.LoadedAt.Format(time.RFC3339),
+	}
+}
+
+// =============================================================================
+
+// ClientToken is the access token issued by the client credentials grant,
+// in the shape OAuth2 clients expect.
+type ClientToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app ClientToken) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Client represents a registered OAuth2 client. The secret is only set when
+// the client is created.
+type Client struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Secret      string   `json:"secret,omitempty"`
+	Scopes      []string `json:"scopes"`
+	OwnerID     string   `json:"ownerID"`
+	DateCreated string   `json:"dateCreated"`
+}
+
+// Encode implements the encoder interface.
+func (app Client) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppClient(bus authbus.Client) Client {
+	return Client{
+		ID:          bus.ID.String(),
+		Name:        bus.Name,
+		Scopes:      permission.ParseToString(bus.Scopes),
+		OwnerID:     bus.OwnerID.String(),
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// Clients is a collection of clients.
+type Clients []Client
+
+// Encode implements the encoder interface.
+func (app Clients) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppClients(clts []authbus.Client) Clients {
+	app := make(Clients, len(clts))
+	for i, clt := range clts {
+		app[i] = toAppClient(clt)
+	}
+
+	return app
+}
+
+// NewClient contains information needed to register a client. The client is
+// owned by the caller unless an owner is specified.
+type NewClient struct {
+	Name    string   `json:"name" validate:"required,max=100"`
+	Scopes  []string `json:"scopes" validate:"required,min=1"`
+	OwnerID string   `json:"ownerID" validate:"omitempty,uuid"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewClient) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewClient) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+	}
+	return nil
+}
+
+func toBusNewClient(app NewClient, ownerID uuid.UUID) (authbus.NewClient, error) {
+	scopes, err := permission.ParseMany(app.Scopes)
+	if err != nil {
+		return authbus.NewClient{}, errs.NewFieldErrors("scopes", err)
+	}
+
+	if app.OwnerID != "" {
+		ownerID, err = uuid.Parse(app.OwnerID)
+		if err != nil {
+			return authbus.NewClient{}, errs.NewFieldErrors("ownerID", err)
+		}
+	}
+
+	bus := authbus.NewClient{
+		Name:    app.Name,
+		Scopes:  scopes,
+		OwnerID: ownerID,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// APIKey represents a personal API key. The key is only set when the key is
+// created and the prefix identifies the key otherwise.
+type APIKey struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Key          string   `json:"key,omitempty"`
+	Prefix       string   `json:"prefix"`
+	Scopes       []string `json:"scopes"`
+	ExpiresAt    string   `json:"expiresAt,omitempty"`
+	DateLastUsed string   `json:"dateLastUsed,omitempty"`
+	DateCreated  string   `json:"dateCreated"`
+}
+
+// Encode implements the encoder interface.
+func (app APIKey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppAPIKey(bus authbus.APIKey) APIKey {
+	app := APIKey{
+		ID:          bus.ID.String(),
+		Name:        bus.Name,
+		Prefix:      authbus.APIKeyPrefix + bus.Prefix,
+		Scopes:      permission.ParseToString(bus.Scopes),
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+	}
+
+	if !bus.ExpiresAt.IsZero() {
+		app.ExpiresAt = bus.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if !bus.DateLastUsed.IsZero() {
+		app.DateLastUsed = bus.DateLastUsed.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+// APIKeys is a collection of personal API keys.
+type APIKeys []APIKey
+
+// Encode implements the encoder interface.
+func (app APIKeys) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppAPIKeys(keys []authbus.APIKey) APIKeys {
+	app := make(APIKeys, len(keys))
+	for i, key := range keys {
+		app[i] = toAppAPIKey(key)
+	}
+
+	return app
+}
+
+// NewAPIKey contains information needed to create a personal API key. No
+// scopes leaves the key unrestricted and no expiry keeps it valid until it's
+// revoked.
+type NewAPIKey struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expiresAt"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewAPIKey) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewAPIKey) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+	}
+	return nil
+}
+
+func toBusNewAPIKey(app NewAPIKey, userID uuid.UUID, now time.Time) (authbus.NewAPIKey, error) {
+	scopes, err := permission.ParseMany(app.Scopes)
+	if err != nil {
+		return authbus.NewAPIKey{}, errs.NewFieldErrors("scopes", err)
+	}
+
+	var expiresAt time.Time
+	if app.ExpiresAt != "" {
+		expiresAt, err = time.Parse(time.RFC3339, app.ExpiresAt)
+		if err != nil {
+			return authbus.NewAPIKey{}, errs.NewFieldErrors("expiresAt", err)
+		}
+
+		if !expiresAt.After(now) {
+			return authbus.NewAPIKey{}, errs.NewFieldErrors("expiresAt", errors.New("must be in the future"))
+		}
+	}
+
+	bus := authbus.NewAPIKey{
+		UserID:    userID,
+		Name:      app.Name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Introspection is the answer to a token introspection request, in the shape
+// RFC 7662 describes. Only active is set for a token that isn't active.
+type Introspection struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"token_type,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app Introspection) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppIntrospection(in auth.Introspection) Introspection {
+	if !in.Active {
+		return Introspection{}
+	}
+
+	app := Introspection{
+		Active:    true,
+		TokenType: in.TokenType,
+		Subject:   in.Claims.Subject,
+		Issuer:    in.Claims.Issuer,
+		ClientID:  in.Claims.ClientID,
+		Scope:     in.Claims.Scope,
+		Roles:     in.Claims.Roles,
+	}
+
+	if in.Claims.ExpiresAt != nil {
+		app.ExpiresAt = in.Claims.ExpiresAt.Unix()
+	}
+
+	if in.Claims.IssuedAt != nil {
+		app.IssuedAt = in.Claims.IssuedAt.Unix()
+	}
+
+	return app
+}
@@ -3,10 +3,13 @@ package authapp
 import (
 	"net/http"
 
+	"github.com/markbates/goth"
+
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/foundation/web"
 )
 
@@ -15,6 +18,13 @@ type Config struct {
 	AuthBus *authbus.Business
 	UserBus *userbus.Business
 	Auth    *auth.Auth
+
+	// RequireAdminMFA forces users with the admin role to complete a
+	// second factor, enrolling during login if they have not yet.
+	RequireAdminMFA bool
+
+	// OAuthProviders are the identity providers users can log in with.
+	OAuthProviders goth.Providers
 }
 
 // Routes adds specific routes for this group.
@@ -23,19 +33,48 @@ func Routes(app *web.App, cfg Config) {
 
 	bearer := mid.Bearer(cfg.Auth)
 	apiKey := mid.APIKey(cfg.Auth)
-	basic := mid.Basic(cfg.Auth, cfg.UserBus)
-	login := mid.Login(cfg.Auth, cfg.UserBus)
-	refresh := mid.RefreshToken(cfg.Auth, cfg.UserBus)
+	basic := mid.Basic(cfg.Auth, cfg.AuthBus, cfg.UserBus)
+	login := mid.Login(cfg.Auth, cfg.AuthBus, cfg.UserBus)
+	loginMFA := mid.LoginMFA(cfg.Auth, cfg.AuthBus, cfg.UserBus)
+	refresh := mid.RefreshToken(cfg.Auth, cfg.AuthBus, cfg.UserBus)
+	oauth := mid.OAuth(cfg.Auth, cfg.AuthBus, cfg.UserBus, cfg.OAuthProviders)
+	clientCredentials := mid.ClientCredentials(cfg.Auth, cfg.AuthBus)
+	introspector := mid.Client(cfg.AuthBus, permission.TokensIntrospect)
 	resetPass := mid.ResetToken(cfg.AuthBus, cfg.UserBus)
+	ruleAdminOnly := mid.AuthorizeLocal(cfg.Auth, auth.RuleAdminOnly)
+	denyImpersonation := mid.DenyImpersonation()
 
-	api := newApp(cfg.Auth, cfg.AuthBus, cfg.UserBus)
+	api := newApp(cfg.Auth, cfg.AuthBus, cfg.UserBus, cfg.RequireAdminMFA, cfg.OAuthProviders)
 
+	app.HandlerFunc(http.MethodGet, "", "/.well-known/jwks.json", api.jwks)
 	app.HandlerFunc(http.MethodGet, version, "/auth/token/{kid}", api.token, basic)
 	app.HandlerFunc(http.MethodPost, version, "/auth/login", api.login, login)
+	app.HandlerFunc(http.MethodPost, version, "/auth/login/mfa", api.loginMFA, loginMFA)
+	app.HandlerFunc(http.MethodPost, version, "/auth/login/mfa/enroll", api.loginMFAEnroll)
+	app.HandlerFunc(http.MethodPost, version, "/auth/oauth/token", api.clientToken, clientCredentials)
+	app.HandlerFunc(http.MethodGet, version, "/auth/oauth/{provider}", api.oauthBegin)
+	app.HandlerFunc(http.MethodGet, version, "/auth/oauth/{provider}/callback", api.login, oauth)
+	app.HandlerFunc(http.MethodPost, version, "/auth/mfa/enroll", api.mfaEnroll, bearer, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/auth/mfa/verify", api.mfaVerify, bearer, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/auth/mfa/disable", api.mfaDisable, bearer, denyImpersonation)
 	app.HandlerFunc(http.MethodPost, version, "/auth/refresh", api.refresh, refresh)
+	app.HandlerFunc(http.MethodPost, version, "/auth/logout", api.logout, bearer)
+	app.HandlerFunc(http.MethodGet, version, "/auth/sessions", api.querySessions, bearer)
+	app.HandlerFunc(http.MethodDelete, version, "/auth/sessions", api.revokeSessions, bearer, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/auth/sessions/{session_id}", api.revokeSession, bearer, denyImpersonation)
 	app.HandlerFunc(http.MethodPost, version, "/auth/forgot", api.forgotPassword)
 	app.HandlerFunc(http.MethodPost, version, "/auth/reset-password/{reset_token}", api.resetPassword, resetPass)
 	app.HandlerFunc(http.MethodGet, version, "/auth/authenticate", api.authenticate, bearer)
+	app.HandlerFunc(http.MethodPost, version, "/auth/introspect", api.introspect, introspector)
 	app.HandlerFunc(http.MethodGet, version, "/auth/authenticate-api", api.authenticateAPI, apiKey)
 	app.HandlerFunc(http.MethodPost, version, "/auth/authorize", api.authorize)
+	app.HandlerFunc(http.MethodGet, version, "/auth/apikeys", api.queryAPIKeys, bearer)
+	app.HandlerFunc(http.MethodPost, version, "/auth/apikeys", api.createAPIKey, bearer, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/auth/apikeys/{key_id}", api.revokeAPIKey, bearer, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/auth/clients", api.queryClients, bearer, ruleAdminOnly)
+	app.HandlerFunc(http.MethodPost, version, "/auth/clients", api.createClient, bearer, ruleAdminOnly, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/auth/clients/{client_id}", api.deleteClient, bearer, ruleAdminOnly, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/auth/impersonate/{user_id}", api.impersonate, bearer, ruleAdminOnly, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/auth/policies", api.queryPolicy, bearer, ruleAdminOnly)
+	app.HandlerFunc(http.MethodPost, version, "/auth/policies/reload", api.reloadPolicy, bearer, ruleAdminOnly)
 }
@@ -0,0 +1,253 @@
+// Package imageapp maintains the app layer api for the product image domain.
+package imageapp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// maxUploadImages is the largest number of images accepted in one upload.
+const maxUploadImages = 10
+
+// uploadField is the multipart form field the images are uploaded in.
+const uploadField = "image"
+
+type app struct {
+	imageBus *imagebus.Business
+}
+
+func newApp(imageBus *imagebus.Business) *app {
+	return &app{
+		imageBus: imageBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	imageBus, err := a.imageBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		imageBus: imageBus,
+	}, nil
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadImages*(imagebus.MaxSize+1<<10))
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "multipart: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	var imgs []imagebus.Image
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "multipart: %s", err)
+		}
+
+		if part.FormName() != uploadField || part.FileName() == "" {
+			continue
+		}
+
+		if len(imgs) == maxUploadImages {
+			return errs.NewFieldErrors(uploadField, fmt.Errorf("at most %d images can be uploaded at once", maxUploadImages))
+		}
+
+		// Read one byte over the limit so the business layer can tell the
+		// image is too large without reading the rest of it.
+		data, err := io.ReadAll(io.LimitReader(part, imagebus.MaxSize+1))
+		if err != nil {
+			return errs.Newf(errs.InvalidArgument, "read: %s", err)
+		}
+
+		img, err := a.imageBus.Create(ctx, imagebus.NewImage{ProductID: prd.ID, Data: data})
+		if err != nil {
+			switch {
+			case errors.Is(err, imagebus.ErrTooLarge), errors.Is(err, imagebus.ErrUnsupportedType):
+				return errs.NewFieldErrors(uploadField, fmt.Errorf("%s: %w", part.FileName(), err))
+			case errors.Is(err, imagebus.ErrDuplicate):
+				return errs.New(errs.FailedPrecondition, fmt.Errorf("%s: %w", part.FileName(), imagebus.ErrDuplicate))
+			}
+			return errs.Newf(errs.Internal, "create: productID[%s]: %s", prd.ID, err)
+		}
+
+		imgs = append(imgs, img)
+	}
+
+	if len(imgs) == 0 {
+		return errs.NewFieldErrors(uploadField, errors.New("no image uploaded"))
+	}
+
+	return toAppProductImages(prd.ID, imgs)
+}
+
+func (a *app) reorder(ctx context.Context, r *http.Request) web.Encoder {
+	var app ImageOrder
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ids, err := toBusImageOrder(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	imgs, err := a.imageBus.Reorder(ctx, prd.ID, ids)
+	if err != nil {
+		if errors.Is(err, imagebus.ErrInvalidOrder) {
+			return errs.New(errs.InvalidArgument, imagebus.ErrInvalidOrder)
+		}
+		return errs.Newf(errs.Internal, "reorder: productID[%s]: %s", prd.ID, err)
+	}
+
+	return toAppProductImages(prd.ID, imgs)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	img, appErr := a.queryImage(ctx, r)
+	if appErr != nil {
+		return appErr
+	}
+
+	if img.ProductID != prd.ID {
+		return errs.New(errs.NotFound, imagebus.ErrNotFound)
+	}
+
+	if err := a.imageBus.Delete(ctx, img); err != nil {
+		return errs.Newf(errs.Internal, "delete: imageID[%s]: %s", img.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) queryByProduct(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	imgs, err := a.imageBus.QueryByProduct(ctx, prd.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyproduct: productID[%s]: %s", prd.ID, err)
+	}
+
+	return toAppProductImages(prd.ID, imgs)
+}
+
+func (a *app) content(ctx context.Context, r *http.Request) web.Encoder {
+	return a.serve(ctx, r, false)
+}
+
+func (a *app) thumbnail(ctx context.Context, r *http.Request) web.Encoder {
+	return a.serve(ctx, r, true)
+}
+
+// serve writes the image, or its thumbnail, to the client. The content of an
+// image never changes since it is stored under its hash, so clients can cache
+// it indefinitely and revalidate with the hash based ETag.
+func (a *app) serve(ctx context.Context, r *http.Request, thumb bool) web.Encoder {
+	img, appErr := a.queryImage(ctx, r)
+	if appErr != nil {
+		return appErr
+	}
+
+	rc, info, err := a.imageBus.Open(ctx, img, thumb)
+	if err != nil {
+		if errors.Is(err, imagebus.ErrNotFound) {
+			return errs.New(errs.NotFound, imagebus.ErrNotFound)
+		}
+		return errs.Newf(errs.Internal, "open: imageID[%s]: %s", img.ID, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return errs.Newf(errs.Internal, "read: imageID[%s]: %s", img.ID, err)
+	}
+
+	etag := img.Hash
+	if thumb {
+		etag += "-thumb"
+	}
+
+	w := web.GetWriter(ctx)
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+etag+`"`)
+
+	http.ServeContent(w, r, "", info.ModTime, bytes.NewReader(data))
+
+	return web.NewNoResponse()
+}
+
+// queryImage retrieves the image identified in the request path.
+func (a *app) queryImage(ctx context.Context, r *http.Request) (imagebus.Image, *errs.Error) {
+	imageID, err := uuid.Parse(web.Param(r, "image_id"))
+	if err != nil {
+		return imagebus.Image{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	img, err := a.imageBus.QueryByID(ctx, imageID)
+	if err != nil {
+		if errors.Is(err, imagebus.ErrNotFound) {
+			return imagebus.Image{}, errs.New(errs.NotFound, imagebus.ErrNotFound)
+		}
+		return imagebus.Image{}, errs.Newf(errs.Internal, "querybyid: imageID[%s]: %s", imageID, err)
+	}
+
+	return img, nil
+}
@@ -0,0 +1,104 @@
+package imageapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+// Image represents information about an image of a product.
+type Image struct {
+	ID           string `json:"id"`
+	ProductID    string `json:"product_id"`
+	Position     int    `json:"position"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Hash         string `json:"hash"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	DateCreated  string `json:"dateCreated"`
+}
+
+func toAppImage(img imagebus.Image) Image {
+	return Image{
+		ID:           img.ID.String(),
+		ProductID:    img.ProductID.String(),
+		Position:     img.Position,
+		ContentType:  img.ContentType,
+		Size:         img.Size,
+		Width:        img.Width,
+		Height:       img.Height,
+		Hash:         img.Hash,
+		URL:          fmt.Sprintf("/v1/images/%s", img.ID),
+		ThumbnailURL: fmt.Sprintf("/v1/images/%s/thumbnail", img.ID),
+		DateCreated:  img.DateCreated.Format(time.RFC3339),
+	}
+}
+
+// ProductImages represents the ordered images of a product.
+type ProductImages struct {
+	ProductID string  `json:"product_id"`
+	Images    []Image `json:"images"`
+}
+
+// Encode implements the encoder interface.
+func (app ProductImages) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppProductImages(productID uuid.UUID, imgs []imagebus.Image) ProductImages {
+	app := ProductImages{
+		ProductID: productID.String(),
+		Images:    make([]Image, len(imgs)),
+	}
+
+	for i, img := range imgs {
+		app.Images[i] = toAppImage(img)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// ImageOrder contains the ids of all the images of a product in the order
+// they should be shown.
+type ImageOrder struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1,dive,required"`
+}
+
+// Decode implements the decoder interface.
+func (app *ImageOrder) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ImageOrder) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusImageOrder(app ImageOrder) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(app.ImageIDs))
+
+	for i, s := range app.ImageIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse image id[%s]: %w", s, err)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,48 @@
+package imageapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	DB         *sqlx.DB
+	ProductBus *productbus.Business
+	ImageBus   *imagebus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group. The image content is served
+// without authentication so it can be referenced directly from pages.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	permProductsWrite := mid.AuthorizePermission(cfg.AuthClient, permission.ProductsWrite)
+	permAuthorizeProduct := mid.AuthorizeProductPermission(cfg.AuthClient, cfg.ProductBus, auth.RulePermission, permission.ProductsRead)
+	permAuthorizeOwner := mid.AuthorizeProductPermission(cfg.AuthClient, cfg.ProductBus, auth.RulePermissionOrSubject, permission.ProductsManage)
+
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.ImageBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/images", api.queryByProduct, authen, permAuthorizeProduct)
+	app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/images", api.create, authen, permProductsWrite, permAuthorizeOwner, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}/images/order", api.reorder, authen, permProductsWrite, permAuthorizeOwner, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}/images/{image_id}", api.delete, authen, permProductsWrite, permAuthorizeOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/images/{image_id}", api.content)
+	app.HandlerFunc(http.MethodGet, version, "/images/{image_id}/thumbnail", api.thumbnail)
+}
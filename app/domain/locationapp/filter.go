@@ -0,0 +1,65 @@
+package locationapp
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/types/name"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	ID      string
+	Name    string
+	UserID  string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("order_by"),
+		ID:      values.Get("location_id"),
+		Name:    values.Get("name"),
+		UserID:  values.Get("user_id"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (locationbus.QueryFilter, error) {
+	var filter locationbus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		if err != nil {
+			return locationbus.QueryFilter{}, errs.NewFieldErrors("location_id", err)
+		}
+		filter.ID = &id
+	}
+
+	if qp.Name != "" {
+		nme, err := name.Parse(qp.Name)
+		if err != nil {
+			return locationbus.QueryFilter{}, errs.NewFieldErrors("name", err)
+		}
+		filter.Name = &nme
+	}
+
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		if err != nil {
+			return locationbus.QueryFilter{}, errs.NewFieldErrors("user_id", err)
+		}
+		filter.UserID = &id
+	}
+
+	return filter, nil
+}
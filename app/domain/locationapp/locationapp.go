@@ -0,0 +1,208 @@
+// Package locationapp maintains the app layer api for the location domain.
+package locationapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/foundation/web"
+)
+
+type app struct {
+	locationBus *locationbus.Business
+}
+
+func newApp(locationBus *locationbus.Business) *app {
+	return &app{
+		locationBus: locationBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locationBus, err := a.locationBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		locationBus: locationBus,
+	}, nil
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewLocation
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nl, err := toBusNewLocation(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	loc, err := a.locationBus.Create(ctx, nl)
+	if err != nil {
+		if errors.Is(err, locationbus.ErrUniqueName) {
+			return errs.New(errs.Aborted, locationbus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "create: loc[%+v]: %s", app, err)
+	}
+
+	return toAppLocation(loc)
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateLocation
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ul, err := toBusUpdateLocation(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	loc, errEnc := a.location(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	updLoc, err := a.locationBus.Update(ctx, loc, ul)
+	if err != nil {
+		if errors.Is(err, locationbus.ErrUniqueName) {
+			return errs.New(errs.Aborted, locationbus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "update: locationID[%s] ul[%+v]: %s", loc.ID, app, err)
+	}
+
+	return toAppLocation(updLoc)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	loc, errEnc := a.location(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.locationBus.Delete(ctx, loc); err != nil {
+		return errs.Newf(errs.Internal, "delete: locationID[%s]: %s", loc.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, locationbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	locs, err := a.locationBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.locationBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppLocations(locs), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	loc, errEnc := a.location(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppLocation(loc)
+}
+
+func (a *app) queryUsers(ctx context.Context, r *http.Request) web.Encoder {
+	loc, errEnc := a.location(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	userIDs, err := a.locationBus.QueryUsers(ctx, loc)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryusers: locationID[%s]: %s", loc.ID, err)
+	}
+
+	return toAppUsers(userIDs)
+}
+
+func (a *app) setUsers(ctx context.Context, r *http.Request) web.Encoder {
+	var app Users
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userIDs, err := toBusUsers(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	loc, errEnc := a.location(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.locationBus.SetUsers(ctx, loc, userIDs); err != nil {
+		return errs.Newf(errs.Internal, "setusers: locationID[%s]: %s", loc.ID, err)
+	}
+
+	return toAppUsers(userIDs)
+}
+
+// location retrieves the location identified in the request path.
+func (a *app) location(ctx context.Context, r *http.Request) (locationbus.Location, *errs.Error) {
+	locationID, err := uuid.Parse(web.Param(r, "location_id"))
+	if err != nil {
+		return locationbus.Location{}, errs.NewFieldErrors("location_id", err)
+	}
+
+	loc, err := a.locationBus.QueryByID(ctx, locationID)
+	if err != nil {
+		if errors.Is(err, locationbus.ErrNotFound) {
+			return locationbus.Location{}, errs.Newf(errs.NotFound, "location not found: %s", locationID)
+		}
+		return locationbus.Location{}, errs.Newf(errs.Internal, "querybyid: locationID[%s]: %s", locationID, err)
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,197 @@
+package locationapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/timezone"
+)
+
+// Location represents information about an individual location.
+type Location struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	Timezone    string `json:"timezone"`
+	DateCreated string `json:"dateCreated"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Location) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppLocation(loc locationbus.Location) Location {
+	return Location{
+		ID:          loc.ID.String(),
+		Name:        loc.Name.String(),
+		Address:     loc.Address,
+		Timezone:    loc.Timezone.String(),
+		DateCreated: loc.DateCreated.Format(time.RFC3339),
+		DateUpdated: loc.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppLocations(locs []locationbus.Location) []Location {
+	app := make([]Location, len(locs))
+	for i, loc := range locs {
+		app[i] = toAppLocation(loc)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// NewLocation defines the data needed to add a new location. The timezone is
+// an IANA name, such as America/New_York, and defaults to UTC.
+type NewLocation struct {
+	Name     string `json:"name" validate:"required"`
+	Address  string `json:"address" validate:"max=255"`
+	Timezone string `json:"timezone"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewLocation) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewLocation) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewLocation(app NewLocation) (locationbus.NewLocation, error) {
+	nme, err := name.Parse(app.Name)
+	if err != nil {
+		return locationbus.NewLocation{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	tz := timezone.Default
+	if app.Timezone != "" {
+		tz, err = timezone.Parse(app.Timezone)
+		if err != nil {
+			return locationbus.NewLocation{}, fmt.Errorf("parse timezone: %w", err)
+		}
+	}
+
+	bus := locationbus.NewLocation{
+		Name:     nme,
+		Address:  app.Address,
+		Timezone: tz,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// UpdateLocation defines the data needed to update a location.
+type UpdateLocation struct {
+	Name     *string `json:"name"`
+	Address  *string `json:"address" validate:"omitempty,max=255"`
+	Timezone *string `json:"timezone"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateLocation) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateLocation) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateLocation(app UpdateLocation) (locationbus.UpdateLocation, error) {
+	var bus locationbus.UpdateLocation
+
+	if app.Name != nil {
+		nme, err := name.Parse(*app.Name)
+		if err != nil {
+			return locationbus.UpdateLocation{}, fmt.Errorf("parse name: %w", err)
+		}
+		bus.Name = &nme
+	}
+
+	bus.Address = app.Address
+
+	if app.Timezone != nil {
+		tz, err := timezone.Parse(*app.Timezone)
+		if err != nil {
+			return locationbus.UpdateLocation{}, fmt.Errorf("parse timezone: %w", err)
+		}
+		bus.Timezone = &tz
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Users represents the users assigned to a location.
+type Users struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// Encode implements the encoder interface.
+func (app Users) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Decode implements the decoder interface.
+func (app *Users) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Users) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toAppUsers(userIDs []uuid.UUID) Users {
+	app := Users{
+		UserIDs: make([]string, len(userIDs)),
+	}
+
+	for i, userID := range userIDs {
+		app.UserIDs[i] = userID.String()
+	}
+
+	return app
+}
+
+func toBusUsers(app Users) ([]uuid.UUID, error) {
+	userIDs := make([]uuid.UUID, len(app.UserIDs))
+
+	for i, value := range app.UserIDs {
+		var err error
+		userIDs[i], err = uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse user id: %w", err)
+		}
+	}
+
+	return userIDs, nil
+}
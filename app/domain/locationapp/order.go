@@ -0,0 +1,10 @@
+package locationapp
+
+import (
+	"github.com/rmsj/service/business/domain/locationbus"
+)
+
+var orderByFields = map[string]string{
+	"location_id": locationbus.OrderByID,
+	"name":        locationbus.OrderByName,
+}
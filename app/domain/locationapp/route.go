@@ -0,0 +1,42 @@
+package locationapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	LocationBus *locationbus.Business
+	AuthClient  *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	permLocationsWrite := mid.AuthorizePermission(cfg.AuthClient, permission.LocationsWrite)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.LocationBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/locations", api.query, authen)
+	app.HandlerFunc(http.MethodGet, version, "/locations/{location_id}", api.queryByID, authen)
+	app.HandlerFunc(http.MethodPost, version, "/locations", api.create, authen, permLocationsWrite)
+	app.HandlerFunc(http.MethodPut, version, "/locations/{location_id}", api.update, authen, permLocationsWrite)
+	app.HandlerFunc(http.MethodDelete, version, "/locations/{location_id}", api.delete, authen, permLocationsWrite)
+	app.HandlerFunc(http.MethodGet, version, "/locations/{location_id}/users", api.queryUsers, authen, permLocationsWrite)
+	app.HandlerFunc(http.MethodPut, version, "/locations/{location_id}/users", api.setUsers, authen, permLocationsWrite, transaction)
+}
@@ -0,0 +1,66 @@
+package pricelistapp
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/name"
+)
+
+type queryParams struct {
+	Page     string
+	Rows     string
+	OrderBy  string
+	ID       string
+	Name     string
+	Currency string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:     values.Get("page"),
+		Rows:     values.Get("rows"),
+		OrderBy:  values.Get("order_by"),
+		ID:       values.Get("price_list_id"),
+		Name:     values.Get("name"),
+		Currency: values.Get("currency"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (pricebus.QueryFilter, error) {
+	var filter pricebus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		if err != nil {
+			return pricebus.QueryFilter{}, errs.NewFieldErrors("price_list_id", err)
+		}
+		filter.ID = &id
+	}
+
+	if qp.Name != "" {
+		nme, err := name.Parse(qp.Name)
+		if err != nil {
+			return pricebus.QueryFilter{}, errs.NewFieldErrors("name", err)
+		}
+		filter.Name = &nme
+	}
+
+	if qp.Currency != "" {
+		cur, err := currency.Parse(qp.Currency)
+		if err != nil {
+			return pricebus.QueryFilter{}, errs.NewFieldErrors("currency", err)
+		}
+		filter.Currency = &cur
+	}
+
+	return filter, nil
+}
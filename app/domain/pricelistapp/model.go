@@ -0,0 +1,381 @@
+package pricelistapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+)
+
+// PriceList represents information about an individual price list.
+type PriceList struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Currency    string `json:"currency"`
+	Priority    int    `json:"priority"`
+	ValidFrom   string `json:"validFrom,omitempty"`
+	ValidTo     string `json:"validTo,omitempty"`
+	DateCreated string `json:"dateCreated"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app PriceList) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPriceList(pl pricebus.PriceList) PriceList {
+	return PriceList{
+		ID:          pl.ID.String(),
+		Name:        pl.Name.String(),
+		Currency:    pl.Currency.String(),
+		Priority:    pl.Priority,
+		ValidFrom:   formatTime(pl.ValidFrom),
+		ValidTo:     formatTime(pl.ValidTo),
+		DateCreated: pl.DateCreated.Format(time.RFC3339),
+		DateUpdated: pl.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppPriceLists(pls []pricebus.PriceList) []PriceList {
+	app := make([]PriceList, len(pls))
+	for i, pl := range pls {
+		app[i] = toAppPriceList(pl)
+	}
+
+	return app
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// =============================================================================
+
+// NewPriceList defines the data needed to add a new price list. The currency
+// defaults to USD and the validity dates are optional RFC3339 timestamps.
+type NewPriceList struct {
+	Name      string `json:"name" validate:"required"`
+	Currency  string `json:"currency"`
+	Priority  int    `json:"priority" validate:"gte=0"`
+	ValidFrom string `json:"validFrom"`
+	ValidTo   string `json:"validTo"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewPriceList) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewPriceList) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewPriceList(app NewPriceList) (pricebus.NewPriceList, error) {
+	nme, err := name.Parse(app.Name)
+	if err != nil {
+		return pricebus.NewPriceList{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	cur := currency.Default
+	if app.Currency != "" {
+		cur, err = currency.Parse(app.Currency)
+		if err != nil {
+			return pricebus.NewPriceList{}, fmt.Errorf("parse currency: %w", err)
+		}
+	}
+
+	validFrom, err := parseTime(app.ValidFrom)
+	if err != nil {
+		return pricebus.NewPriceList{}, fmt.Errorf("parse validFrom: %w", err)
+	}
+
+	validTo, err := parseTime(app.ValidTo)
+	if err != nil {
+		return pricebus.NewPriceList{}, fmt.Errorf("parse validTo: %w", err)
+	}
+
+	bus := pricebus.NewPriceList{
+		Name:      nme,
+		Currency:  cur,
+		Priority:  app.Priority,
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// UpdatePriceList defines the data needed to update a price list. An empty
+// validity date removes that bound.
+type UpdatePriceList struct {
+	Name      *string `json:"name"`
+	Currency  *string `json:"currency"`
+	Priority  *int    `json:"priority" validate:"omitempty,gte=0"`
+	ValidFrom *string `json:"validFrom"`
+	ValidTo   *string `json:"validTo"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdatePriceList) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdatePriceList) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdatePriceList(app UpdatePriceList) (pricebus.UpdatePriceList, error) {
+	var bus pricebus.UpdatePriceList
+
+	if app.Name != nil {
+		nme, err := name.Parse(*app.Name)
+		if err != nil {
+			return pricebus.UpdatePriceList{}, fmt.Errorf("parse name: %w", err)
+		}
+		bus.Name = &nme
+	}
+
+	if app.Currency != nil {
+		cur, err := currency.Parse(*app.Currency)
+		if err != nil {
+			return pricebus.UpdatePriceList{}, fmt.Errorf("parse currency: %w", err)
+		}
+		bus.Currency = &cur
+	}
+
+	bus.Priority = app.Priority
+
+	if app.ValidFrom != nil {
+		validFrom, err := parseTime(*app.ValidFrom)
+		if err != nil {
+			return pricebus.UpdatePriceList{}, fmt.Errorf("parse validFrom: %w", err)
+		}
+		bus.ValidFrom = &validFrom
+	}
+
+	if app.ValidTo != nil {
+		validTo, err := parseTime(*app.ValidTo)
+		if err != nil {
+			return pricebus.UpdatePriceList{}, fmt.Errorf("parse validTo: %w", err)
+		}
+		bus.ValidTo = &validTo
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Item represents the price of a product from a minimum quantity on.
+type Item struct {
+	ProductID   string  `json:"product_id" validate:"required"`
+	MinQuantity int     `json:"min_quantity" validate:"gte=1"`
+	Price       float64 `json:"price" validate:"gte=0"`
+}
+
+// Items represents the full set of prices of a price list.
+type Items struct {
+	Items []Item `json:"items" validate:"dive"`
+}
+
+// Encode implements the encoder interface.
+func (app Items) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Decode implements the decoder interface.
+func (app *Items) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Items) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toAppItems(items []pricebus.Item) Items {
+	app := Items{
+		Items: make([]Item, len(items)),
+	}
+
+	for i, itm := range items {
+		app.Items[i] = Item{
+			ProductID:   itm.ProductID.String(),
+			MinQuantity: itm.MinQuantity,
+			Price:       itm.Price.Value(),
+		}
+	}
+
+	return app
+}
+
+func toBusItems(app Items) ([]pricebus.Item, error) {
+	items := make([]pricebus.Item, len(app.Items))
+
+	for i, itm := range app.Items {
+		productID, err := uuid.Parse(itm.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("parse product id: %w", err)
+		}
+
+		price, err := money.Parse(itm.Price)
+		if err != nil {
+			return nil, fmt.Errorf("parse price: %w", err)
+		}
+
+		items[i] = pricebus.Item{
+			ProductID:   productID,
+			MinQuantity: itm.MinQuantity,
+			Price:       price,
+		}
+	}
+
+	return items, nil
+}
+
+// =============================================================================
+
+// Assignments represents the users and customer groups a price list applies
+// to.
+type Assignments struct {
+	UserIDs []string `json:"user_ids"`
+	Groups  []string `json:"groups"`
+}
+
+// Encode implements the encoder interface.
+func (app Assignments) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Decode implements the decoder interface.
+func (app *Assignments) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Assignments) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toAppAssignments(asg pricebus.Assignments) Assignments {
+	app := Assignments{
+		UserIDs: make([]string, len(asg.UserIDs)),
+		Groups:  make([]string, len(asg.Groups)),
+	}
+
+	for i, userID := range asg.UserIDs {
+		app.UserIDs[i] = userID.String()
+	}
+
+	for i, group := range asg.Groups {
+		app.Groups[i] = group.String()
+	}
+
+	return app
+}
+
+func toBusAssignments(app Assignments) (pricebus.Assignments, error) {
+	userIDs, err := parseUserIDs(app.UserIDs)
+	if err != nil {
+		return pricebus.Assignments{}, err
+	}
+
+	groups := make([]name.Name, len(app.Groups))
+	for i, group := range app.Groups {
+		groups[i], err = name.Parse(group)
+		if err != nil {
+			return pricebus.Assignments{}, fmt.Errorf("parse group: %w", err)
+		}
+	}
+
+	bus := pricebus.Assignments{
+		UserIDs: userIDs,
+		Groups:  groups,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// GroupMembers represents the users that belong to a customer group.
+type GroupMembers struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// Encode implements the encoder interface.
+func (app GroupMembers) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Decode implements the decoder interface.
+func (app *GroupMembers) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app GroupMembers) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func parseUserIDs(values []string) ([]uuid.UUID, error) {
+	userIDs := make([]uuid.UUID, len(values))
+
+	for i, value := range values {
+		var err error
+		userIDs[i], err = uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse user id: %w", err)
+		}
+	}
+
+	return userIDs, nil
+}
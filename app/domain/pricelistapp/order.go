@@ -0,0 +1,11 @@
+package pricelistapp
+
+import (
+	"github.com/rmsj/service/business/domain/pricebus"
+)
+
+var orderByFields = map[string]string{
+	"price_list_id": pricebus.OrderByID,
+	"name":          pricebus.OrderByName,
+	"priority":      pricebus.OrderByPriority,
+}
@@ -0,0 +1,282 @@
+// Package pricelistapp maintains the app layer api for the price list domain.
+package pricelistapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/foundation/web"
+)
+
+type app struct {
+	priceBus *pricebus.Business
+}
+
+func newApp(priceBus *pricebus.Business) *app {
+	return &app{
+		priceBus: priceBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	priceBus, err := a.priceBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		priceBus: priceBus,
+	}, nil
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewPriceList
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	npl, err := toBusNewPriceList(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	pl, err := a.priceBus.Create(ctx, npl)
+	if err != nil {
+		if errors.Is(err, pricebus.ErrInvalidValidity) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "create: pl[%+v]: %s", app, err)
+	}
+
+	return toAppPriceList(pl)
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdatePriceList
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	upl, err := toBusUpdatePriceList(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	updPl, err := a.priceBus.Update(ctx, pl, upl)
+	if err != nil {
+		if errors.Is(err, pricebus.ErrInvalidValidity) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "update: priceListID[%s] upl[%+v]: %s", pl.ID, app, err)
+	}
+
+	return toAppPriceList(updPl)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.priceBus.Delete(ctx, pl); err != nil {
+		return errs.Newf(errs.Internal, "delete: priceListID[%s]: %s", pl.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, pricebus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	pls, err := a.priceBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.priceBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppPriceLists(pls), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppPriceList(pl)
+}
+
+func (a *app) queryItems(ctx context.Context, r *http.Request) web.Encoder {
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	items, err := a.priceBus.QueryItems(ctx, pl)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryitems: priceListID[%s]: %s", pl.ID, err)
+	}
+
+	return toAppItems(items)
+}
+
+func (a *app) setItems(ctx context.Context, r *http.Request) web.Encoder {
+	var app Items
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	items, err := toBusItems(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.priceBus.SetItems(ctx, pl, items); err != nil {
+		if errors.Is(err, pricebus.ErrInvalidItem) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "setitems: priceListID[%s]: %s", pl.ID, err)
+	}
+
+	return toAppItems(items)
+}
+
+func (a *app) queryAssignments(ctx context.Context, r *http.Request) web.Encoder {
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	asg, err := a.priceBus.QueryAssignments(ctx, pl)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryassignments: priceListID[%s]: %s", pl.ID, err)
+	}
+
+	return toAppAssignments(asg)
+}
+
+func (a *app) setAssignments(ctx context.Context, r *http.Request) web.Encoder {
+	var app Assignments
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	asg, err := toBusAssignments(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	pl, errEnc := a.priceList(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.priceBus.SetAssignments(ctx, pl, asg); err != nil {
+		return errs.Newf(errs.Internal, "setassignments: priceListID[%s]: %s", pl.ID, err)
+	}
+
+	return toAppAssignments(asg)
+}
+
+func (a *app) setGroupMembers(ctx context.Context, r *http.Request) web.Encoder {
+	var app GroupMembers
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	group, err := name.Parse(web.Param(r, "group"))
+	if err != nil {
+		return errs.NewFieldErrors("group", err)
+	}
+
+	userIDs, err := parseUserIDs(app.UserIDs)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	if err := a.priceBus.SetGroupMembers(ctx, group, userIDs); err != nil {
+		return errs.Newf(errs.Internal, "setgroupmembers: group[%s]: %s", group, err)
+	}
+
+	return app
+}
+
+// priceList retrieves the price list identified in the request path.
+func (a *app) priceList(ctx context.Context, r *http.Request) (pricebus.PriceList, *errs.Error) {
+	priceListID, err := uuid.Parse(web.Param(r, "price_list_id"))
+	if err != nil {
+		return pricebus.PriceList{}, errs.NewFieldErrors("price_list_id", err)
+	}
+
+	pl, err := a.priceBus.QueryByID(ctx, priceListID)
+	if err != nil {
+		if errors.Is(err, pricebus.ErrNotFound) {
+			return pricebus.PriceList{}, errs.Newf(errs.NotFound, "price list not found: %s", priceListID)
+		}
+		return pricebus.PriceList{}, errs.Newf(errs.Internal, "querybyid: priceListID[%s]: %s", priceListID, err)
+	}
+
+	return pl, nil
+}
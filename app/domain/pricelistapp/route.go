@@ -0,0 +1,46 @@
+package pricelistapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	DB         *sqlx.DB
+	PriceBus   *pricebus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	permPriceListsRead := mid.AuthorizePermission(cfg.AuthClient, permission.PriceListsRead)
+	permPriceListsWrite := mid.AuthorizePermission(cfg.AuthClient, permission.PriceListsWrite)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.PriceBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/pricelists", api.query, authen, permPriceListsRead)
+	app.HandlerFunc(http.MethodGet, version, "/pricelists/{price_list_id}", api.queryByID, authen, permPriceListsRead)
+	app.HandlerFunc(http.MethodPost, version, "/pricelists", api.create, authen, permPriceListsWrite)
+	app.HandlerFunc(http.MethodPut, version, "/pricelists/{price_list_id}", api.update, authen, permPriceListsWrite)
+	app.HandlerFunc(http.MethodDelete, version, "/pricelists/{price_list_id}", api.delete, authen, permPriceListsWrite)
+	app.HandlerFunc(http.MethodGet, version, "/pricelists/{price_list_id}/items", api.queryItems, authen, permPriceListsRead)
+	app.HandlerFunc(http.MethodPut, version, "/pricelists/{price_list_id}/items", api.setItems, authen, permPriceListsWrite, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/pricelists/{price_list_id}/assignments", api.queryAssignments, authen, permPriceListsRead)
+	app.HandlerFunc(http.MethodPut, version, "/pricelists/{price_list_id}/assignments", api.setAssignments, authen, permPriceListsWrite, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/customergroups/{group}/members", api.setGroupMembers, authen, permPriceListsWrite, transaction)
+}
@@ -1,6 +1,8 @@
 package productapp
 
 import (
+	"errors"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,17 +11,21 @@ import (
 
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/productbus/productio"
 	"github.com/rmsj/service/business/types/name"
 )
 
 type queryParams struct {
-	Page    string
-	Rows    string
-	OrderBy string
-	ID      string
-	IDs     []string
-	Name    string
-	Price   string
+	Page     string
+	Rows     string
+	OrderBy  string
+	ID       string
+	IDs      []string
+	Name     string
+	Price    string
+	UserID   string
+	Search   string
+	Category string
 }
 
 func parseQueryParams(r *http.Request) queryParams {
@@ -32,13 +38,16 @@ func parseQueryParams(r *http.Request) queryParams {
 	}
 
 	filter := queryParams{
-		Page:    values.Get("page"),
-		Rows:    values.Get("rows"),
-		OrderBy: values.Get("order_by"),
-		ID:      values.Get("product_id"),
-		IDs:     ids,
-		Name:    values.Get("name"),
-		Price:   values.Get("price"),
+		Page:     values.Get("page"),
+		Rows:     values.Get("rows"),
+		OrderBy:  values.Get("order_by"),
+		ID:       values.Get("product_id"),
+		IDs:      ids,
+		Name:     values.Get("name"),
+		Price:    values.Get("price"),
+		UserID:   values.Get("user_id"),
+		Search:   values.Get("q"),
+		Category: values.Get("category"),
 	}
 
 	return filter
@@ -71,6 +80,18 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 		filter.Price = &price
 	}
 
+	if qp.Category != "" {
+		filter.Category = &qp.Category
+	}
+
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		if err != nil {
+			return productbus.QueryFilter{}, errs.NewFieldErrors("user_id", err)
+		}
+		filter.CreatedBy = &id
+	}
+
 	if len(qp.IDs) > 0 {
 		for _, id := range qp.IDs {
 			parsedID, err := uuid.Parse(id)
@@ -83,3 +104,56 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 
 	return filter, nil
 }
+
+// =============================================================================
+
+// maxImportSize is the largest import file accepted.
+const maxImportSize = 10 << 20
+
+type importParams struct {
+	Format      string
+	ContentType string
+	DryRun      string
+}
+
+func parseImportParams(r *http.Request) importParams {
+	values := r.URL.Query()
+
+	qp := importParams{
+		Format:      values.Get("format"),
+		ContentType: r.Header.Get("Content-Type"),
+		DryRun:      values.Get("dry_run"),
+	}
+
+	return qp
+}
+
+// parseImportFormat uses the format parameter when provided, otherwise the
+// format is derived from the content type of the request.
+func parseImportFormat(qp importParams) (productio.Format, error) {
+	if qp.Format != "" {
+		return productio.ParseFormat(qp.Format)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(qp.ContentType)
+	if err != nil {
+		return "", errors.New("format or content type required")
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return productio.CSV, nil
+	case "application/jsonl", "application/x-ndjson":
+		return productio.JSONL, nil
+	}
+
+	return "", errors.New("unsupported content type " + mediaType)
+}
+
+func parseDryRun(qp importParams) (bool, error) {
+	if qp.DryRun == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(qp.DryRun)
+}
@@ -5,19 +5,34 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
 	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
 // Product represents information about an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Price       float64 `json:"price"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string   `json:"id"`
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Kind        string   `json:"kind"`
+	Bundle      *Bundle  `json:"bundle,omitempty"`
+	Price       float64  `json:"price"`
+	Cost        *float64 `json:"cost,omitempty"`
+	Currency    string   `json:"currency"`
+	Unit        string   `json:"unit"`
+	Stock       float64  `json:"stock"`
+	MaxQuantity float64  `json:"max_quantity,omitempty"`
+	CreatedBy   string   `json:"createdBy"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
 }
 
 // Encode implements the encoder interface.
@@ -27,13 +42,29 @@ func (app Product) Encode() ([]byte, string, error) {
 }
 
 func toAppProduct(prd productbus.Product) Product {
-	return Product{
+	app := Product{
 		ID:          prd.ID.String(),
+		SKU:         prd.SKU,
 		Name:        prd.Name.String(),
+		Description: prd.Description,
+		Category:    prd.Category,
+		Kind:        string(prd.Kind),
 		Price:       prd.Price.Value(),
+		Currency:    prd.Currency.String(),
+		Unit:        prd.Unit.String(),
+		Stock:       prd.Stock.Value(),
+		MaxQuantity: prd.MaxQuantity.Value(),
+		CreatedBy:   prd.CreatedBy.String(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
 	}
+
+	if prd.IsBundle() {
+		bdl := toAppBundle(prd.Bundle)
+		app.Bundle = &bdl
+	}
+
+	return app
 }
 
 func toAppProducts(prds []productbus.Product) []Product {
@@ -49,8 +80,16 @@ func toAppProducts(prds []productbus.Product) []Product {
 
 // NewProduct defines the data needed to add a new product.
 type NewProduct struct {
-	Name  string  `json:"name" validate:"required"`
-	Price float64 `json:"price" validate:"required,gte=0"`
+	SKU         string     `json:"sku" validate:"max=64"`
+	Name        string     `json:"name" validate:"required"`
+	Description string     `json:"description" validate:"max=1000"`
+	Category    string     `json:"category" validate:"max=100"`
+	Bundle      *NewBundle `json:"bundle"`
+	Price       float64    `json:"price" validate:"required,gte=0"`
+	Currency    string     `json:"currency"`
+	Unit        string     `json:"unit" validate:"omitempty,oneof=piece kg g l m"`
+	Stock       float64    `json:"stock" validate:"gte=0"`
+	MaxQuantity float64    `json:"max_quantity" validate:"gte=0"`
 }
 
 // Decode implements the decoder interface.
@@ -67,7 +106,7 @@ func (app NewProduct) Validate() error {
 	return nil
 }
 
-func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
+func toBusNewProduct(userID uuid.UUID, app NewProduct) (productbus.NewProduct, error) {
 
 	name, err := name.Parse(app.Name)
 	if err != nil {
@@ -79,9 +118,51 @@ func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
 		return productbus.NewProduct{}, fmt.Errorf("parse price: %w", err)
 	}
 
+	cur := currency.Default
+	if app.Currency != "" {
+		cur, err = currency.Parse(app.Currency)
+		if err != nil {
+			return productbus.NewProduct{}, fmt.Errorf("parse currency: %w", err)
+		}
+	}
+
+	unit := quantity.Piece
+	if app.Unit != "" {
+		unit, err = quantity.ParseUnit(app.Unit)
+		if err != nil {
+			return productbus.NewProduct{}, fmt.Errorf("parse unit: %w", err)
+		}
+	}
+
+	stock, err := quantity.Parse(app.Stock, unit)
+	if err != nil {
+		return productbus.NewProduct{}, fmt.Errorf("parse stock: %w", err)
+	}
+
+	maxQty, err := quantity.Parse(app.MaxQuantity, unit)
+	if err != nil {
+		return productbus.NewProduct{}, fmt.Errorf("parse max quantity: %w", err)
+	}
+
 	bus := productbus.NewProduct{
-		Name:  name,
-		Price: price,
+		SKU:         app.SKU,
+		Name:        name,
+		Description: app.Description,
+		Category:    app.Category,
+		Price:       price,
+		Currency:    cur,
+		Unit:        unit,
+		Stock:       stock,
+		MaxQuantity: maxQty,
+		CreatedBy:   userID,
+	}
+
+	if app.Bundle != nil {
+		bdl, err := toBusBundle(*app.Bundle)
+		if err != nil {
+			return productbus.NewProduct{}, err
+		}
+		bus.Bundle = &bdl
 	}
 
 	return bus, nil
@@ -91,8 +172,16 @@ func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
 
 // UpdateProduct defines the data needed to update a product.
 type UpdateProduct struct {
-	Name  *string  `json:"name"`
-	Price *float64 `json:"price" validate:"omitempty,gte=0"`
+	SKU         *string    `json:"sku" validate:"omitempty,max=64"`
+	Name        *string    `json:"name"`
+	Description *string    `json:"description" validate:"omitempty,max=1000"`
+	Category    *string    `json:"category" validate:"omitempty,max=100"`
+	Bundle      *NewBundle `json:"bundle"`
+	Price       *float64   `json:"price" validate:"omitempty,gte=0"`
+	Cost        *float64   `json:"cost" validate:"omitempty,gte=0"`
+	Currency    *string    `json:"currency"`
+	Stock       *float64   `json:"stock" validate:"omitempty,gte=0"`
+	MaxQuantity *float64   `json:"max_quantity" validate:"omitempty,gte=0"`
 }
 
 // Decode implements the decoder interface.
@@ -109,7 +198,9 @@ func (app UpdateProduct) Validate() error {
 	return nil
 }
 
-func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
+// toBusUpdateProduct converts the update, where stock and maximum quantity are
+// in the unit of the product being updated.
+func toBusUpdateProduct(app UpdateProduct, unit quantity.Unit) (productbus.UpdateProduct, error) {
 	var nme *name.Name
 	if app.Name != nil {
 		nm, err := name.Parse(*app.Name)
@@ -128,10 +219,349 @@ func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
 		price = &prc
 	}
 
+	var cost *money.Money
+	if app.Cost != nil {
+		cst, err := money.Parse(*app.Cost)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		cost = &cst
+	}
+
+	var cur *currency.Currency
+	if app.Currency != nil {
+		c, err := currency.Parse(*app.Currency)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		cur = &c
+	}
+
+	var stock *quantity.Quantity
+	if app.Stock != nil {
+		stk, err := quantity.Parse(*app.Stock, unit)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		stock = &stk
+	}
+
+	var maxQty *quantity.Quantity
+	if app.MaxQuantity != nil {
+		mq, err := quantity.Parse(*app.MaxQuantity, unit)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		maxQty = &mq
+	}
+
 	bus := productbus.UpdateProduct{
-		Name:  nme,
-		Price: price,
+		SKU:         app.SKU,
+		Name:        nme,
+		Description: app.Description,
+		Category:    app.Category,
+		Price:       price,
+		Cost:        cost,
+		Currency:    cur,
+		Stock:       stock,
+		MaxQuantity: maxQty,
+	}
+
+	if app.Bundle != nil {
+		bdl, err := toBusBundle(*app.Bundle)
+		if err != nil {
+			return productbus.UpdateProduct{}, err
+		}
+		bus.Bundle = &bdl
 	}
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// Bundle represents the products a bundle is made of and how it is priced.
+type Bundle struct {
+	Pricing    string      `json:"pricing"`
+	Discount   float64     `json:"discount"`
+	Components []Component `json:"components"`
+}
+
+// Component represents a product contained in a bundle.
+type Component struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Quantity  int     `json:"quantity"`
+}
+
+func toAppBundle(bdl productbus.Bundle) Bundle {
+	app := Bundle{
+		Pricing:    string(bdl.Pricing),
+		Discount:   bdl.Discount,
+		Components: make([]Component, len(bdl.Components)),
+	}
+
+	for i, c := range bdl.Components {
+		app.Components[i] = Component{
+			ProductID: c.ProductID.String(),
+			Quantity:  c.Quantity,
+		}
+	}
+
+	return app
+}
+
+// NewBundle defines the data needed to make a product a bundle. The price of
+// the product is ignored when the pricing is sum, since it is calculated
+// from the components.
+type NewBundle struct {
+	Pricing    string         `json:"pricing" validate:"required,oneof=fixed sum"`
+	Discount   float64        `json:"discount" validate:"gte=0,lte=100"`
+	Components []NewComponent `json:"components" validate:"required,min=1,dive"`
+}
+
+// NewComponent defines a product and how many units of it go in a bundle.
+type NewComponent struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gte=1"`
+}
+
+func toBusBundle(app NewBundle) (productbus.Bundle, error) {
+	pricing, err := productbus.ParseBundlePricing(app.Pricing)
+	if err != nil {
+		return productbus.Bundle{}, fmt.Errorf("parse pricing: %w", err)
+	}
+
+	bus := productbus.Bundle{
+		Pricing:    pricing,
+		Discount:   app.Discount,
+		Components: make([]productbus.Component, len(app.Components)),
+	}
+
+	for i, c := range app.Components {
+		productID, err := uuid.Parse(c.ProductID)
+		if err != nil {
+			return productbus.Bundle{}, fmt.Errorf("parse component product id: %w", err)
+		}
+
+		bus.Components[i] = productbus.Component{
+			ProductID: productID,
+			Quantity:  c.Quantity,
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// SearchResult represents a product matched by a search.
+type SearchResult struct {
+	Product Product `json:"product"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+func toAppSearchResults(res []productbus.SearchResult) []SearchResult {
+	app := make([]SearchResult, len(res))
+	for i, r := range res {
+		app[i] = SearchResult{
+			Product: toAppProduct(r.Product),
+			Score:   r.Score,
+			Snippet: r.Snippet,
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// ImportResult represents the outcome of importing a single product.
+type ImportResult struct {
+	Action  string   `json:"action"`
+	ID      string   `json:"id,omitempty"`
+	SKU     string   `json:"sku,omitempty"`
+	Name    string   `json:"name"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// ImportSummary represents the outcome of a product import.
+type ImportSummary struct {
+	DryRun    bool           `json:"dryRun"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Results   []ImportResult `json:"results"`
+}
+
+// Encode implements the encoder interface.
+func (app ImportSummary) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppImportSummary(results []productbus.ImportResult, dryRun bool) ImportSummary {
+	app := ImportSummary{
+		DryRun:  dryRun,
+		Results: make([]ImportResult, len(results)),
+	}
+
+	for i, res := range results {
+		switch res.Action {
+		case productbus.ImportCreate:
+			app.Created++
+		case productbus.ImportUpdate:
+			app.Updated++
+		case productbus.ImportUnchanged:
+			app.Unchanged++
+		}
+
+		var id string
+		if res.Product.ID != uuid.Nil {
+			id = res.Product.ID.String()
+		}
+
+		app.Results[i] = ImportResult{
+			Action:  string(res.Action),
+			ID:      id,
+			SKU:     res.Product.SKU,
+			Name:    res.Product.Name.String(),
+			Changes: res.Changes,
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// CostChange represents the cost of a product from the moment it took effect.
+type CostChange struct {
+	Cost          float64 `json:"cost"`
+	EffectiveFrom string  `json:"effective_from"`
+}
+
+// CostHistory represents the changes to the cost of a product, most recent
+// first.
+type CostHistory struct {
+	ProductID string       `json:"product_id"`
+	Changes   []CostChange `json:"changes"`
+}
+
+// Encode implements the encoder interface.
+func (app CostHistory) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppCostHistory(productID uuid.UUID, ccs []productbus.CostChange) CostHistory {
+	app := CostHistory{
+		ProductID: productID.String(),
+		Changes:   make([]CostChange, len(ccs)),
+	}
+
+	for i, cc := range ccs {
+		app.Changes[i] = CostChange{
+			Cost:          cc.Cost.Value(),
+			EffectiveFrom: cc.EffectiveFrom.Format(time.RFC3339),
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// LocationStock represents the stock of a product kept at a location.
+type LocationStock struct {
+	LocationID  string  `json:"location_id"`
+	Stock       float64 `json:"stock"`
+	Unit        string  `json:"unit"`
+	DateUpdated string  `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app LocationStock) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppLocationStock(ls productbus.LocationStock) LocationStock {
+	return LocationStock{
+		LocationID:  ls.LocationID.String(),
+		Stock:       ls.Stock.Value(),
+		Unit:        ls.Stock.Unit().String(),
+		DateUpdated: ls.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// Stock represents the total stock of a product and the part of it kept at
+// each location the stock is tracked at.
+type Stock struct {
+	ProductID string          `json:"product_id"`
+	Stock     float64         `json:"stock"`
+	Unit      string          `json:"unit"`
+	Locations []LocationStock `json:"locations"`
+}
+
+// Encode implements the encoder interface.
+func (app Stock) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppStock(prd productbus.Product, lss []productbus.LocationStock) Stock {
+	app := Stock{
+		ProductID: prd.ID.String(),
+		Stock:     prd.Stock.Value(),
+		Unit:      prd.Unit.String(),
+		Locations: make([]LocationStock, len(lss)),
+	}
+
+	for i, ls := range lss {
+		app.Locations[i] = toAppLocationStock(ls)
+	}
+
+	return app
+}
+
+// SetLocationStock defines the data needed to set the stock of a product kept
+// at a location. The stock is in the unit of the product when no unit is
+// provided.
+type SetLocationStock struct {
+	Stock float64 `json:"stock" validate:"gte=0"`
+	Unit  string  `json:"unit" validate:"omitempty,oneof=piece kg g l m"`
+}
+
+// Decode implements the decoder interface.
+func (app *SetLocationStock) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app SetLocationStock) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusLocationStock(app SetLocationStock, unit quantity.Unit) (quantity.Quantity, error) {
+	if app.Unit != "" {
+		var err error
+		unit, err = quantity.ParseUnit(app.Unit)
+		if err != nil {
+			return quantity.Quantity{}, fmt.Errorf("parse unit: %w", err)
+		}
+	}
+
+	qty, err := quantity.Parse(app.Stock, unit)
+	if err != nil {
+		return quantity.Quantity{}, fmt.Errorf("parse stock: %w", err)
+	}
+
+	return qty, nil
+}
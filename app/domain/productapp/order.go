@@ -8,5 +8,6 @@ var orderByFields = map[string]string{
 	"product_id": productbus.OrderByProductID,
 	"name":       productbus.OrderByName,
 	"price":      productbus.OrderByPrice,
+	"margin":     productbus.OrderByMargin,
 	"user_id":    productbus.OrderByUserID,
 }
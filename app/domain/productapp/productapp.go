@@ -4,26 +4,50 @@ package productapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/locationbus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/productbus/productio"
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/foundation/web"
 )
 
 type app struct {
-	productBus *productbus.Business
+	productBus  *productbus.Business
+	locationBus *locationbus.Business
 }
 
-func newApp(productBus *productbus.Business) *app {
+func newApp(productBus *productbus.Business, locationBus *locationbus.Business) *app {
 	return &app{
-		productBus: productBus,
+		productBus:  productBus,
+		locationBus: locationBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	productBus, err := a.productBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
 	}
+
+	return &app{
+		productBus: productBus,
+	}, nil
 }
 
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
@@ -32,17 +56,30 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	np, err := toBusNewProduct(app)
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	np, err := toBusNewProduct(userID, app)
 	if err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	prd, err := a.productBus.Create(ctx, np)
 	if err != nil {
+		if errors.Is(err, productbus.ErrInvalidBundle) || errors.Is(err, productbus.ErrInvalidQuantity) {
+			return errs.New(errs.InvalidArgument, err)
+		}
 		return errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
 	}
 
-	return toAppProduct(prd)
+	return a.withContents(ctx, prd)
 }
 
 func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
@@ -51,47 +88,46 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	up, err := toBusUpdateProduct(app)
+	prd, err := mid.GetProduct(ctx)
 	if err != nil {
-		return errs.New(errs.InvalidArgument, err)
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
-	pID, err := a.productID(r)
+	up, err := toBusUpdateProduct(app, prd.Unit)
 	if err != nil {
-		return errs.New(errs.Internal, err)
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if app.Cost != nil && !mid.IsAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "only administrators can set the cost of a product")
 	}
 
-	prd, err := a.productBus.QueryByID(ctx, pID)
+	a, err = a.newWithTx(ctx)
 	if err != nil {
-		if errors.Is(err, productbus.ErrNotFound) {
-			return errs.Newf(errs.NotFound, "invalid product id: %s", pID)
-		}
-		return errs.Newf(errs.Internal, "error getting product to update - please try again or contact support")
+		return errs.New(errs.Internal, err)
 	}
 
 	updPrd, err := a.productBus.Update(ctx, prd, up)
 	if err != nil {
+		if errors.Is(err, productbus.ErrInvalidBundle) || errors.Is(err, productbus.ErrInvalidQuantity) {
+			return errs.New(errs.InvalidArgument, err)
+		}
 		return errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, app, err)
 	}
 
-	return toAppProduct(updPrd)
+	return a.withContents(ctx, updPrd)
 }
 
-func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
-	pID, err := a.productID(r)
-	if err != nil {
-		return errs.New(errs.Internal, err)
-	}
-
-	prd, err := a.productBus.QueryByID(ctx, pID)
+func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
 	if err != nil {
-		if errors.Is(err, productbus.ErrNotFound) {
-			return errs.Newf(errs.NotFound, "invalid product id: %s", pID)
-		}
-		return errs.Newf(errs.Internal, "error getting product to delete - please try again or contact support")
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
 	if err := a.productBus.Delete(ctx, prd); err != nil {
+		if errors.Is(err, productbus.ErrComponentInUse) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
 		return errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
 	}
 
@@ -116,6 +152,10 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.NewFieldErrors("order", err)
 	}
 
+	if orderBy.Field == productbus.OrderByMargin && !mid.IsAdmin(ctx) {
+		return errs.NewFieldErrors("order", errors.New("ordering by margin requires the admin role"))
+	}
+
 	prds, err := a.productBus.Query(ctx, filter, orderBy, page)
 	if err != nil {
 		return errs.Newf(errs.Internal, "query: %s", err)
@@ -126,27 +166,197 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "count: %s", err)
 	}
 
-	return query.NewResult(toAppProducts(prds), total, page)
+	app := toAppProducts(prds)
+	if err := a.bundleContents(ctx, app); err != nil {
+		return errs.Newf(errs.Internal, "bundle contents: %s", err)
+	}
+
+	if mid.IsAdmin(ctx) {
+		for i, prd := range prds {
+			cost := prd.Cost.Value()
+			app[i].Cost = &cost
+		}
+	}
+
+	return query.NewResult(app, total, page)
 }
 
-func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
-	productID, err := a.productID(r)
+func (a *app) search(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	res, total, err := a.productBus.Search(ctx, qp.Search, page)
+	if err != nil {
+		if errors.Is(err, productbus.ErrInvalidQuery) {
+			return errs.NewFieldErrors("q", err)
+		}
+		return errs.Newf(errs.Internal, "search: %s", err)
+	}
+
+	return query.NewResult(toAppSearchResults(res), total, page)
+}
+
+func (a *app) importProducts(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseImportParams(r)
+
+	format, err := parseImportFormat(qp)
+	if err != nil {
+		return errs.NewFieldErrors("format", err)
+	}
+
+	dryRun, err := parseDryRun(qp)
+	if err != nil {
+		return errs.NewFieldErrors("dry_run", err)
+	}
+
+	ips, err := productio.Read(http.MaxBytesReader(nil, r.Body, maxImportSize), format)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "read: %s", err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
-	prd, err := a.productBus.QueryByID(ctx, productID)
+	results, err := a.productBus.Import(ctx, ips, userID, dryRun)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "import: %s", err)
+	}
+
+	return toAppImportSummary(results, dryRun)
+}
+
+func (a *app) queryByID(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "querybyid: %s", err)
 	}
 
-	return toAppProduct(prd)
+	return a.withContents(ctx, prd)
+}
+
+func (a *app) queryCostHistory(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	ccs, err := a.productBus.QueryCostHistory(ctx, prd.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querycosthistory: productID[%s]: %s", prd.ID, err)
+	}
+
+	return toAppCostHistory(prd.ID, ccs)
+}
+
+func (a *app) queryLocationStock(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	lss, err := a.productBus.QueryLocationStock(ctx, prd)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querylocationstock: productID[%s]: %s", prd.ID, err)
+	}
+
+	return toAppStock(prd, lss)
+}
+
+func (a *app) setLocationStock(ctx context.Context, r *http.Request) web.Encoder {
+	var app SetLocationStock
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	locationID, err := uuid.Parse(web.Param(r, "location_id"))
+	if err != nil {
+		return errs.NewFieldErrors("location_id", err)
+	}
+
+	if _, err := a.locationBus.QueryByID(ctx, locationID); err != nil {
+		if errors.Is(err, locationbus.ErrNotFound) {
+			return errs.Newf(errs.NotFound, "location not found: %s", locationID)
+		}
+		return errs.Newf(errs.Internal, "querybyid: locationID[%s]: %s", locationID, err)
+	}
+
+	qty, err := toBusLocationStock(app, prd.Unit)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ls, err := a.productBus.SetLocationStock(ctx, prd, locationID, qty)
+	if err != nil {
+		if errors.Is(err, productbus.ErrInvalidBundle) || errors.Is(err, productbus.ErrInvalidQuantity) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "setlocationstock: productID[%s] locationID[%s]: %s", prd.ID, locationID, err)
+	}
+
+	return toAppLocationStock(ls)
 }
 
-func (a *app) productID(r *http.Request) (uuid.UUID, error) {
-	id := web.Param(r, "product_id")
-	if id == "" {
-		return uuid.Nil, errs.Newf(errs.Internal, "product id not in request")
+// withContents converts the product and, for a bundle, fills in the details
+// of the products it contains. The cost is only included for administrators.
+func (a *app) withContents(ctx context.Context, prd productbus.Product) web.Encoder {
+	app := []Product{toAppProduct(prd)}
+	if err := a.bundleContents(ctx, app); err != nil {
+		return errs.Newf(errs.Internal, "bundle contents: productID[%s]: %s", prd.ID, err)
+	}
+
+	if mid.IsAdmin(ctx) {
+		cost := prd.Cost.Value()
+		app[0].Cost = &cost
 	}
-	return uuid.Parse(id)
+
+	return app[0]
+}
+
+// bundleContents fills in the name and price of the components of any bundle
+// in the set of products.
+func (a *app) bundleContents(ctx context.Context, prds []Product) error {
+	components := make(map[string]productbus.Product)
+
+	for _, prd := range prds {
+		if prd.Bundle == nil {
+			continue
+		}
+
+		for i, c := range prd.Bundle.Components {
+			cp, exists := components[c.ProductID]
+			if !exists {
+				productID, err := uuid.Parse(c.ProductID)
+				if err != nil {
+					return fmt.Errorf("parse: componentID[%s]: %w", c.ProductID, err)
+				}
+
+				cp, err = a.productBus.QueryByID(ctx, productID)
+				if err != nil {
+					return err
+				}
+				components[c.ProductID] = cp
+			}
+
+			prd.Bundle.Components[i].Name = cp.Name.String()
+			prd.Bundle.Components[i].Price = cp.Price.Value()
+		}
+	}
+
+	return nil
 }
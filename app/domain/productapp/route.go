@@ -3,21 +3,28 @@ package productapp
 import (
 	"net/http"
 
+	"github.com/jmoiron/sqlx"
+
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/locationbus"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log        *logger.Logger
-	UserBus    *userbus.Business
-	ProductBus *productbus.Business
-	AuthClient *authclient.Client
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	UserBus     *userbus.Business
+	ProductBus  *productbus.Business
+	LocationBus *locationbus.Business
+	AuthClient  *authclient.Client
 }
 
 // Routes adds specific routes for this group.
@@ -25,14 +32,26 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
-	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
-	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
+	permProductsRead := mid.AuthorizePermission(cfg.AuthClient, permission.ProductsRead)
+	permProductsWrite := mid.AuthorizePermission(cfg.AuthClient, permission.ProductsWrite)
+	permProductsImport := mid.AuthorizePermission(cfg.AuthClient, permission.ProductsImport)
+	permAuthorizeProduct := mid.AuthorizeProductPermission(cfg.AuthClient, cfg.ProductBus, auth.RulePermission, permission.ProductsRead)
+	permAuthorizeOwner := mid.AuthorizeProductPermission(cfg.AuthClient, cfg.ProductBus, auth.RulePermissionOrSubject, permission.ProductsManage)
+	permAuthorizeManage := mid.AuthorizeProductPermission(cfg.AuthClient, cfg.ProductBus, auth.RulePermission, permission.ProductsManage)
+	ruleAuthorizeAdmin := mid.AuthorizeProduct(cfg.AuthClient, cfg.ProductBus, auth.RuleAdminOnly)
+
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 
-	api := newApp(cfg.ProductBus)
+	api := newApp(cfg.ProductBus, cfg.LocationBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen)
-	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen)
-	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen)
+	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, permProductsRead)
+	app.HandlerFunc(http.MethodGet, version, "/products/search", api.search, authen, permProductsRead)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, permAuthorizeProduct)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/costs", api.queryCostHistory, authen, ruleAuthorizeAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/stock", api.queryLocationStock, authen, permAuthorizeProduct)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}/stock/{location_id}", api.setLocationStock, authen, permAuthorizeManage)
+	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, permProductsWrite, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/products/import", api.importProducts, authen, permProductsImport, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, permProductsWrite, permAuthorizeOwner, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, permProductsWrite, permAuthorizeOwner)
 }
@@ -0,0 +1,53 @@
+package rateapp
+
+import (
+	"net/http"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/types/currency"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	From    string
+	To      string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("order_by"),
+		From:    values.Get("from"),
+		To:      values.Get("to"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (ratebus.QueryFilter, error) {
+	var filter ratebus.QueryFilter
+
+	if qp.From != "" {
+		from, err := currency.Parse(qp.From)
+		if err != nil {
+			return ratebus.QueryFilter{}, errs.NewFieldErrors("from", err)
+		}
+		filter.From = &from
+	}
+
+	if qp.To != "" {
+		to, err := currency.Parse(qp.To)
+		if err != nil {
+			return ratebus.QueryFilter{}, errs.NewFieldErrors("to", err)
+		}
+		filter.To = &to
+	}
+
+	return filter, nil
+}
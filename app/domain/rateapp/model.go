@@ -0,0 +1,99 @@
+package rateapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/types/currency"
+)
+
+// Rate represents information about an individual exchange rate.
+type Rate struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Rate          float64 `json:"rate"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+	DateCreated   string  `json:"dateCreated"`
+}
+
+// Encode implements the encoder interface.
+func (app Rate) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppRate(rate ratebus.Rate) Rate {
+	return Rate{
+		From:          rate.From.String(),
+		To:            rate.To.String(),
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom.Format(time.RFC3339),
+		DateCreated:   rate.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppRates(rates []ratebus.Rate) []Rate {
+	app := make([]Rate, len(rates))
+	for i, rate := range rates {
+		app[i] = toAppRate(rate)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// NewRate defines the data needed to add an exchange rate. The rate takes
+// effect immediately when no RFC3339 effectiveFrom is provided.
+type NewRate struct {
+	From          string  `json:"from" validate:"required"`
+	To            string  `json:"to" validate:"required"`
+	Rate          float64 `json:"rate" validate:"gt=0"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewRate) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewRate) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewRate(app NewRate) (ratebus.NewRate, error) {
+	from, err := currency.Parse(app.From)
+	if err != nil {
+		return ratebus.NewRate{}, fmt.Errorf("parse from: %w", err)
+	}
+
+	to, err := currency.Parse(app.To)
+	if err != nil {
+		return ratebus.NewRate{}, fmt.Errorf("parse to: %w", err)
+	}
+
+	var effectiveFrom time.Time
+	if app.EffectiveFrom != "" {
+		effectiveFrom, err = time.Parse(time.RFC3339, app.EffectiveFrom)
+		if err != nil {
+			return ratebus.NewRate{}, fmt.Errorf("parse effectiveFrom: %w", err)
+		}
+	}
+
+	bus := ratebus.NewRate{
+		From:          from,
+		To:            to,
+		Rate:          app.Rate,
+		EffectiveFrom: effectiveFrom,
+	}
+
+	return bus, nil
+}
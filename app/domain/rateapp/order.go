@@ -0,0 +1,11 @@
+package rateapp
+
+import (
+	"github.com/rmsj/service/business/domain/ratebus"
+)
+
+var orderByFields = map[string]string{
+	"effective_from": ratebus.OrderByEffectiveFrom,
+	"from":           ratebus.OrderByFrom,
+	"to":             ratebus.OrderByTo,
+}
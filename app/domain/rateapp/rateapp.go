@@ -0,0 +1,78 @@
+// Package rateapp maintains the app layer api for the exchange rate domain.
+package rateapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/foundation/web"
+)
+
+type app struct {
+	rateBus *ratebus.Business
+}
+
+func newApp(rateBus *ratebus.Business) *app {
+	return &app{
+		rateBus: rateBus,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewRate
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nr, err := toBusNewRate(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	rate, err := a.rateBus.Create(ctx, nr)
+	if err != nil {
+		if errors.Is(err, ratebus.ErrInvalidRate) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "create: rate[%+v]: %s", app, err)
+	}
+
+	return toAppRate(rate)
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, ratebus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	rates, err := a.rateBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.rateBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppRates(rates), total, page)
+}
@@ -0,0 +1,32 @@
+package rateapp
+
+import (
+	"net/http"
+
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	RateBus    *ratebus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	permRatesWrite := mid.AuthorizePermission(cfg.AuthClient, permission.RatesWrite)
+
+	api := newApp(cfg.RateBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/rates", api.query, authen)
+	app.HandlerFunc(http.MethodPost, version, "/rates", api.create, authen, permRatesWrite)
+}
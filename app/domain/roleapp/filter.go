@@ -0,0 +1,60 @@
+package roleapp
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/rolebus"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	ID      string
+	Name    string
+	UserID  string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("order_by"),
+		ID:      values.Get("role_id"),
+		Name:    values.Get("name"),
+		UserID:  values.Get("user_id"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (rolebus.QueryFilter, error) {
+	var filter rolebus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		if err != nil {
+			return rolebus.QueryFilter{}, errs.NewFieldErrors("role_id", err)
+		}
+		filter.ID = &id
+	}
+
+	if qp.Name != "" {
+		filter.Name = &qp.Name
+	}
+
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		if err != nil {
+			return rolebus.QueryFilter{}, errs.NewFieldErrors("user_id", err)
+		}
+		filter.UserID = &id
+	}
+
+	return filter, nil
+}
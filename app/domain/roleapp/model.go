@@ -0,0 +1,139 @@
+package roleapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/types/permission"
+)
+
+// Role represents information about an individual role.
+type Role struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	System      bool     `json:"system"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Role) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppRole(rle rolebus.Role) Role {
+	return Role{
+		ID:          rle.ID.String(),
+		Name:        rle.Name,
+		Description: rle.Description,
+		Permissions: permission.ParseToString(rle.Permissions),
+		System:      rle.System,
+		DateCreated: rle.DateCreated.Format(time.RFC3339),
+		DateUpdated: rle.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppRoles(rles []rolebus.Role) []Role {
+	app := make([]Role, len(rles))
+	for i, rle := range rles {
+		app[i] = toAppRole(rle)
+	}
+
+	return app
+}
+
+// Roles represents the roles assigned to a user.
+type Roles []Role
+
+// Encode implements the encoder interface.
+func (app Roles) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// NewRole defines the data needed to add a new role. Permissions take the
+// form resource:action, such as sales:report, where either part may be a
+// wildcard.
+type NewRole struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewRole) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewRole) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewRole(app NewRole) (rolebus.NewRole, error) {
+	perms, err := permission.ParseMany(app.Permissions)
+	if err != nil {
+		return rolebus.NewRole{}, fmt.Errorf("parse permissions: %w", err)
+	}
+
+	bus := rolebus.NewRole{
+		Name:        app.Name,
+		Description: app.Description,
+		Permissions: perms,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// UpdateRole defines the data needed to update a role. Providing permissions
+// replaces the permissions of the role.
+type UpdateRole struct {
+	Name        *string  `json:"name" validate:"omitempty,min=1,max=50"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateRole) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateRole) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateRole(app UpdateRole) (rolebus.UpdateRole, error) {
+	bus := rolebus.UpdateRole{
+		Name:        app.Name,
+		Description: app.Description,
+	}
+
+	if app.Permissions != nil {
+		perms, err := permission.ParseMany(app.Permissions)
+		if err != nil {
+			return rolebus.UpdateRole{}, fmt.Errorf("parse permissions: %w", err)
+		}
+		bus.Permissions = perms
+	}
+
+	return bus, nil
+}
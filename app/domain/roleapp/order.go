@@ -0,0 +1,10 @@
+package roleapp
+
+import (
+	"github.com/rmsj/service/business/domain/rolebus"
+)
+
+var orderByFields = map[string]string{
+	"role_id": rolebus.OrderByID,
+	"name":    rolebus.OrderByName,
+}
@@ -0,0 +1,334 @@
+// Package roleapp maintains the app layer api for the role domain.
+package roleapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/web"
+)
+
+type app struct {
+	roleBus *rolebus.Business
+	userBus *userbus.Business
+}
+
+func newApp(roleBus *rolebus.Business, userBus *userbus.Business) *app {
+	return &app{
+		roleBus: roleBus,
+		userBus: userBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleBus, err := a.roleBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		roleBus: roleBus,
+		userBus: a.userBus,
+	}, nil
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewRole
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nr, err := toBusNewRole(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if errEnc := a.checkGrant(ctx, nr.Permissions); errEnc != nil {
+		return errEnc
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	rle, err := a.roleBus.Create(ctx, nr)
+	if err != nil {
+		if errors.Is(err, rolebus.ErrUniqueName) {
+			return errs.New(errs.Aborted, rolebus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "create: rle[%+v]: %s", app, err)
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateRole
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ur, err := toBusUpdateRole(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	rle, errEnc := a.role(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if errEnc := a.checkGrant(ctx, slices.Concat(rle.Permissions, ur.Permissions)); errEnc != nil {
+		return errEnc
+	}
+
+	updRle, err := a.roleBus.Update(ctx, rle, ur)
+	if err != nil {
+		switch {
+		case errors.Is(err, rolebus.ErrUniqueName):
+			return errs.New(errs.Aborted, rolebus.ErrUniqueName)
+		case errors.Is(err, rolebus.ErrSystemRole):
+			return errs.New(errs.FailedPrecondition, rolebus.ErrSystemRole)
+		}
+		return errs.Newf(errs.Internal, "update: roleID[%s] ur[%+v]: %s", rle.ID, app, err)
+	}
+
+	return toAppRole(updRle)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	rle, errEnc := a.role(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if errEnc := a.checkGrant(ctx, rle.Permissions); errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.roleBus.Delete(ctx, rle); err != nil {
+		if errors.Is(err, rolebus.ErrSystemRole) {
+			return errs.New(errs.FailedPrecondition, rolebus.ErrSystemRole)
+		}
+		return errs.Newf(errs.Internal, "delete: roleID[%s]: %s", rle.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, rolebus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	rles, err := a.roleBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.roleBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppRoles(rles), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	rle, errEnc := a.role(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) queryUserRoles(ctx context.Context, r *http.Request) web.Encoder {
+	usr, errEnc := a.user(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	filter := rolebus.QueryFilter{
+		UserID: &usr.ID,
+	}
+
+	rles, err := a.roleBus.Query(ctx, filter, rolebus.DefaultOrderBy, page.MustParse("1", "100"))
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: userID[%s]: %s", usr.ID, err)
+	}
+
+	return Roles(toAppRoles(rles))
+}
+
+func (a *app) assign(ctx context.Context, r *http.Request) web.Encoder {
+	usr, errEnc := a.user(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	rle, errEnc := a.role(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if errEnc := a.checkGrant(ctx, rle.Permissions); errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.roleBus.Assign(ctx, usr.ID, rle); err != nil {
+		if errors.Is(err, rolebus.ErrSystemRole) {
+			return errs.New(errs.FailedPrecondition, rolebus.ErrSystemRole)
+		}
+		return errs.Newf(errs.Internal, "assign: userID[%s] roleID[%s]: %s", usr.ID, rle.ID, err)
+	}
+
+	return toAppRole(rle)
+}
+
+func (a *app) unassign(ctx context.Context, r *http.Request) web.Encoder {
+	usr, errEnc := a.user(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	rle, errEnc := a.role(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if errEnc := a.checkGrant(ctx, rle.Permissions); errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.roleBus.Unassign(ctx, usr.ID, rle); err != nil {
+		if errors.Is(err, rolebus.ErrSystemRole) {
+			return errs.New(errs.FailedPrecondition, rolebus.ErrSystemRole)
+		}
+		return errs.Newf(errs.Internal, "unassign: userID[%s] roleID[%s]: %s", usr.ID, rle.ID, err)
+	}
+
+	return nil
+}
+
+// checkGrant refuses a caller who isn't an admin when the permissions of the
+// role being changed or handed out aren't all held by the caller, so a user
+// who can manage roles can't use them to grant themselves more.
+func (a *app) checkGrant(ctx context.Context, perms []permission.Permission) *errs.Error {
+	if mid.IsAdmin(ctx) {
+		return nil
+	}
+
+	claims := mid.GetClaims(ctx)
+
+	scopes, err := permission.ParseMany(claims.Scopes())
+	if err != nil {
+		return errs.Newf(errs.Internal, "parse scopes: %s", err)
+	}
+
+	// A client holds the scopes it was granted. A user holds the permissions
+	// of their roles and claims, limited by the scopes of a restricted key.
+	held := scopes
+	if !claims.IsClient() {
+		granted, err := a.roleBus.QueryPermissions(ctx, mid.GetSubjectID(ctx), claims.Roles)
+		if err != nil {
+			return errs.Newf(errs.Internal, "querypermissions: %s", err)
+		}
+
+		extra, err := permission.ParseMany(claims.Permissions)
+		if err != nil {
+			return errs.Newf(errs.Internal, "parse permissions: %s", err)
+		}
+
+		held = append(granted, extra...)
+	}
+
+	for _, perm := range perms {
+		if !grants(held, perm) || (claims.IsScopeRestricted() && !grants(scopes, perm)) {
+			return errs.Newf(errs.PermissionDenied, "permission[%s] is not held by the caller and can't be granted", perm)
+		}
+	}
+
+	return nil
+}
+
+// grants reports whether any of the permissions grants the wanted one.
+func grants(perms []permission.Permission, want permission.Permission) bool {
+	return slices.ContainsFunc(perms, func(p permission.Permission) bool {
+		return p.Grants(want)
+	})
+}
+
+// role retrieves the role identified in the request path.
+func (a *app) role(ctx context.Context, r *http.Request) (rolebus.Role, *errs.Error) {
+	roleID, err := uuid.Parse(web.Param(r, "role_id"))
+	if err != nil {
+		return rolebus.Role{}, errs.NewFieldErrors("role_id", err)
+	}
+
+	rle, err := a.roleBus.QueryByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, rolebus.ErrNotFound) {
+			return rolebus.Role{}, errs.Newf(errs.NotFound, "role not found: %s", roleID)
+		}
+		return rolebus.Role{}, errs.Newf(errs.Internal, "querybyid: roleID[%s]: %s", roleID, err)
+	}
+
+	return rle, nil
+}
+
+// user retrieves the user identified in the request path.
+func (a *app) user(ctx context.Context, r *http.Request) (userbus.User, *errs.Error) {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return userbus.User{}, errs.NewFieldErrors("user_id", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return userbus.User{}, errs.Newf(errs.NotFound, "user not found: %s", userID)
+		}
+		return userbus.User{}, errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	return usr, nil
+}
@@ -0,0 +1,48 @@
+package roleapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	DB         *sqlx.DB
+	RoleBus    *rolebus.Business
+	UserBus    *userbus.Business
+	AuthClient *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	permRolesRead := mid.AuthorizePermission(cfg.AuthClient, permission.RolesRead)
+	permRolesWrite := mid.AuthorizePermission(cfg.AuthClient, permission.RolesWrite)
+	permRolesAssign := mid.AuthorizePermission(cfg.AuthClient, permission.RolesAssign)
+	denyImpersonation := mid.DenyImpersonation()
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.RoleBus, cfg.UserBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/roles", api.query, authen, permRolesRead)
+	app.HandlerFunc(http.MethodGet, version, "/roles/{role_id}", api.queryByID, authen, permRolesRead)
+	app.HandlerFunc(http.MethodPost, version, "/roles", api.create, authen, permRolesWrite, denyImpersonation, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/roles/{role_id}", api.update, authen, permRolesWrite, denyImpersonation, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/roles/{role_id}", api.delete, authen, permRolesWrite, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}/roles", api.queryUserRoles, authen, permRolesRead)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}/roles/{role_id}", api.assign, authen, permRolesAssign, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}/roles/{role_id}", api.unassign, authen, permRolesAssign, denyImpersonation)
+}
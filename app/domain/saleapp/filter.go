@@ -2,6 +2,7 @@ package saleapp
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,20 +11,28 @@ import (
 )
 
 type queryParams struct {
-	Page    string
-	Rows    string
-	OrderBy string
-	ID      string
+	Page             string
+	Rows             string
+	OrderBy          string
+	ID               string
+	LocationID       string
+	StartCreatedDate string
+	EndCreatedDate   string
+	Currency         string
 }
 
 func parseQueryParams(r *http.Request) queryParams {
 	values := r.URL.Query()
 
 	filter := queryParams{
-		Page:    values.Get("page"),
-		Rows:    values.Get("rows"),
-		OrderBy: values.Get("order_by"),
-		ID:      values.Get("sale_id"),
+		Page:             values.Get("page"),
+		Rows:             values.Get("rows"),
+		OrderBy:          values.Get("order_by"),
+		ID:               values.Get("sale_id"),
+		LocationID:       values.Get("location_id"),
+		StartCreatedDate: values.Get("start_created_date"),
+		EndCreatedDate:   values.Get("end_created_date"),
+		Currency:         values.Get("currency"),
 	}
 
 	return filter
@@ -41,5 +50,29 @@ func parseFilter(qp queryParams) (salebus.QueryFilter, error) {
 		filter.ID = &id
 	}
 
+	if qp.LocationID != "" {
+		id, err := uuid.Parse(qp.LocationID)
+		if err != nil {
+			return salebus.QueryFilter{}, errs.NewFieldErrors("location_id", err)
+		}
+		filter.LocationID = &id
+	}
+
+	if qp.StartCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
+		if err != nil {
+			return salebus.QueryFilter{}, errs.NewFieldErrors("start_created_date", err)
+		}
+		filter.StartCreatedDate = &t
+	}
+
+	if qp.EndCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
+		if err != nil {
+			return salebus.QueryFilter{}, errs.NewFieldErrors("end_created_date", err)
+		}
+		filter.EndCreatedDate = &t
+	}
+
 	return filter, nil
 }
@@ -8,10 +8,13 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
 type Customer struct {
@@ -21,23 +24,40 @@ type Customer struct {
 }
 
 type Item struct {
-	ID         string  `json:"id"`
-	Name       string  `json:"name"`
-	UnityPrice float64 `json:"unity_price"`
-	Quantity   int     `json:"quantity"`
-	Amount     float64 `json:"amount"`
-	Discount   float64 `json:"discount"`
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	UnityPrice   float64         `json:"unity_price"`
+	PriceListID  string          `json:"price_list_id,omitempty"`
+	ExchangeRate float64         `json:"exchange_rate"`
+	Quantity     float64         `json:"quantity"`
+	Unit         string          `json:"unit"`
+	Amount       float64         `json:"amount"`
+	Discount     float64         `json:"discount"`
+	UnitCost     *float64        `json:"unit_cost,omitempty"`
+	Margin       *float64        `json:"margin,omitempty"`
+	Components   []ItemComponent `json:"components,omitempty"`
+}
+
+// ItemComponent represents a product sold as part of a bundle and the share
+// of the bundle amount allocated to it.
+type ItemComponent struct {
+	ProductID string  `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Amount    float64 `json:"amount"`
 }
 
 // Sale represents information about an individual sale.
 type Sale struct {
-	ID        string   `json:"id"`
-	Discount  float64  `json:"discount"`
-	Amount    float64  `json:"amount"`
-	Customer  Customer `json:"customer"`
-	Items     []Item   `json:"items"`
-	UpdatedAt string   `json:"updatedAt"`
-	CreatedAt string   `json:"createdAt"`
+	ID         string   `json:"id"`
+	Currency   string   `json:"currency"`
+	LocationID string   `json:"location_id,omitempty"`
+	Discount   float64  `json:"discount"`
+	Amount     float64  `json:"amount"`
+	Margin     *float64 `json:"margin,omitempty"`
+	Customer   Customer `json:"customer"`
+	Items      []Item   `json:"items"`
+	UpdatedAt  string   `json:"updatedAt"`
+	CreatedAt  string   `json:"createdAt"`
 }
 
 // Encode implements the encoder interface.
@@ -49,6 +69,7 @@ func (app Sale) Encode() ([]byte, string, error) {
 func ToAppSale(bus salebus.Sale, user userbus.User, productsInSale []productbus.Product) (Sale, error) {
 	saleApp := Sale{
 		ID:       bus.ID.String(),
+		Currency: bus.Currency.String(),
 		Discount: bus.Discount.Value(),
 		Amount:   bus.Amount.Value(),
 		Customer: Customer{
@@ -60,6 +81,10 @@ func ToAppSale(bus salebus.Sale, user userbus.User, productsInSale []productbus.
 		CreatedAt: bus.CreatedAt.Format(time.RFC3339),
 	}
 
+	if bus.LocationID != uuid.Nil {
+		saleApp.LocationID = bus.LocationID.String()
+	}
+
 	for _, item := range bus.Items {
 		var product productbus.Product
 		for _, prd := range productsInSale {
@@ -67,52 +92,108 @@ func ToAppSale(bus salebus.Sale, user userbus.User, productsInSale []productbus.
 				product = prd
 			}
 		}
+
+		var priceListID string
+		if item.PriceListID != uuid.Nil {
+			priceListID = item.PriceListID.String()
+		}
+
+		var components []ItemComponent
+		for _, sic := range item.Components {
+			components = append(components, ItemComponent{
+				ProductID: sic.ProductID.String(),
+				Quantity:  sic.Quantity,
+				Amount:    sic.Amount.Value(),
+			})
+		}
+
 		saleApp.Items = append(saleApp.Items, Item{
-			ID:         item.ProductID.String(),
-			Name:       product.Name.String(),
-			UnityPrice: item.UnityPrice.Value(),
-			Quantity:   item.Quantity,
-			Amount:     item.Amount.Value(),
-			Discount:   item.Discount.Value(),
+			ID:           item.ProductID.String(),
+			Name:         product.Name.String(),
+			UnityPrice:   item.UnityPrice.Value(),
+			PriceListID:  priceListID,
+			ExchangeRate: item.ExchangeRate,
+			Quantity:     item.Quantity.Value(),
+			Unit:         item.Quantity.Unit().String(),
+			Amount:       item.Amount.Value(),
+			Discount:     item.Discount.Value(),
+			Components:   components,
 		})
 	}
 
 	return saleApp, nil
 }
 
-// NewSale defines the data needed to add a new sale.
+// withMargins adds the unit cost and gross margin of each item, and the gross
+// margin of the whole sale. Cost is only meant for administrators so callers
+// must check the role before using it.
+func withMargins(app Sale, bus salebus.Sale) Sale {
+	margin := bus.Margin()
+	app.Margin = &margin
+
+	for i, item := range bus.Items {
+		unitCost := item.UnitCost.Value()
+		itemMargin := item.Margin()
+
+		app.Items[i].UnitCost = &unitCost
+		app.Items[i].Margin = &itemMargin
+	}
+
+	return app
+}
+
+// NewSale defines the data needed to add a new sale. The sale is in USD when
+// no currency is provided.
 type NewSale struct {
-	Discount float64       `json:"discount" validate:"omitempty,gte=0,lte=1000000"`
-	Items    []NewSaleItem `json:"items" validate:"required"`
+	Currency   string        `json:"currency"`
+	LocationID string        `json:"location_id" validate:"omitempty,uuid"`
+	Discount   float64       `json:"discount" validate:"omitempty,gte=0,lte=1000000"`
+	Items      []NewSaleItem `json:"items" validate:"required"`
 }
 
+// NewSaleItem defines the data needed to add an item to a sale. The quantity
+// is in the unit of the product when no unit is provided.
 type NewSaleItem struct {
-	ProductID string `json:"product_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,gte=1,lte=100"`
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	Unit      string  `json:"unit" validate:"omitempty,oneof=piece kg g l m"`
 }
 
-func toBusNewSale(userID uuid.UUID, app NewSale, productsInSale []productbus.Product) (salebus.NewSale, error) {
+// toBusNewSale converts the sale using the quantities and quotes resolved for
+// its items, which are expected in the same order as the items.
+func toBusNewSale(userID uuid.UUID, app NewSale, quantities []quantity.Quantity, quotes []pricebus.Quote) (salebus.NewSale, error) {
 
 	discount, err := money.Parse(app.Discount)
 	if err != nil {
 		return salebus.NewSale{}, fmt.Errorf("parse discount: %w", err)
 	}
 
+	cur := currency.Default
+	if app.Currency != "" {
+		cur, err = currency.Parse(app.Currency)
+		if err != nil {
+			return salebus.NewSale{}, fmt.Errorf("parse currency: %w", err)
+		}
+	}
+
+	var locationID uuid.UUID
+	if app.LocationID != "" {
+		locationID, err = uuid.Parse(app.LocationID)
+		if err != nil {
+			return salebus.NewSale{}, fmt.Errorf("parse location id: %w", err)
+		}
+	}
+
 	bus := salebus.NewSale{
-		UserID:   userID,
-		Discount: discount,
+		UserID:     userID,
+		Currency:   cur,
+		LocationID: locationID,
+		Discount:   discount,
 	}
 
-	// far from ideal - we can use a join instead
 	var saleItems []salebus.NewSaleItem
-	for _, item := range app.Items {
-		var product productbus.Product
-		for _, prd := range productsInSale {
-			if prd.ID.String() == item.ProductID {
-				product = prd
-			}
-		}
-		newItem, err := toBusNewSaleItem(item, product.Price)
+	for i, item := range app.Items {
+		newItem, err := toBusNewSaleItem(item, quantities[i], quotes[i])
 		if err != nil {
 			return salebus.NewSale{}, fmt.Errorf("parse items: %w", err)
 		}
@@ -123,7 +204,7 @@ func toBusNewSale(userID uuid.UUID, app NewSale, productsInSale []productbus.Pro
 	return bus, nil
 }
 
-func toBusNewSaleItem(app NewSaleItem, itemPrice money.Money) (salebus.NewSaleItem, error) {
+func toBusNewSaleItem(app NewSaleItem, qty quantity.Quantity, quote pricebus.Quote) (salebus.NewSaleItem, error) {
 
 	productID, err := uuid.Parse(app.ProductID)
 	if err != nil {
@@ -131,9 +212,11 @@ func toBusNewSaleItem(app NewSaleItem, itemPrice money.Money) (salebus.NewSaleIt
 	}
 
 	slItem := salebus.NewSaleItem{
-		ProductID: productID,
-		Quantity:  app.Quantity,
-		Price:     itemPrice,
+		ProductID:   productID,
+		Quantity:    qty,
+		Price:       quote.UnitPrice,
+		Currency:    quote.Currency,
+		PriceListID: quote.PriceListID,
 	}
 
 	return slItem, nil
@@ -151,3 +234,50 @@ func (app NewSale) Validate() error {
 	}
 	return nil
 }
+
+// =============================================================================
+
+// Report represents the sales over a period with the amounts in a single
+// currency.
+type Report struct {
+	Currency string      `json:"currency"`
+	Sales    int         `json:"sales"`
+	Amount   float64     `json:"amount"`
+	Discount float64     `json:"discount"`
+	Days     []ReportDay `json:"days"`
+}
+
+// ReportDay represents the sales of a single day in a report.
+type ReportDay struct {
+	Date     string  `json:"date"`
+	Sales    int     `json:"sales"`
+	Amount   float64 `json:"amount"`
+	Discount float64 `json:"discount"`
+}
+
+// Encode implements the encoder interface.
+func (app Report) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppReport(bus salebus.Report) Report {
+	app := Report{
+		Currency: bus.Currency.String(),
+		Sales:    bus.Sales,
+		Amount:   bus.Amount,
+		Discount: bus.Discount,
+		Days:     make([]ReportDay, len(bus.Days)),
+	}
+
+	for i, day := range bus.Days {
+		app.Days[i] = ReportDay{
+			Date:     day.Date.Format(time.DateOnly),
+			Sales:    day.Sales,
+			Amount:   day.Amount,
+			Discount: day.Discount,
+		}
+	}
+
+	return app
+}
@@ -5,24 +5,30 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log        *logger.Logger
-	DB         *sqlx.DB
-	UserBus    *userbus.Business
-	ProductBus *productbus.Business
-	SaleBus    *salebus.Business
-	AuthClient *authclient.Client
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	UserBus     *userbus.Business
+	ProductBus  *productbus.Business
+	SaleBus     *salebus.Business
+	PriceBus    *pricebus.Business
+	LocationBus *locationbus.Business
+	AuthClient  *authclient.Client
 }
 
 // Routes adds specific routes for this group.
@@ -30,11 +36,16 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authenticate := mid.Authenticate(cfg.AuthClient)
+	permSalesCreate := mid.AuthorizePermission(cfg.AuthClient, permission.SalesCreate)
+	permSalesReport := mid.AuthorizePermission(cfg.AuthClient, permission.SalesReport)
+	permAuthorizeRead := mid.AuthorizeSalePermission(cfg.AuthClient, cfg.SaleBus, cfg.LocationBus, auth.RulePermissionOrLocation, permission.SalesRead)
+	permAuthorizeManage := mid.AuthorizeSalePermission(cfg.AuthClient, cfg.SaleBus, cfg.LocationBus, auth.RulePermissionOrLocation, permission.SalesManage)
 	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 
-	api := newApp(cfg.UserBus, cfg.ProductBus, cfg.SaleBus)
+	api := newApp(cfg.UserBus, cfg.ProductBus, cfg.SaleBus, cfg.PriceBus, cfg.LocationBus, cfg.AuthClient)
 	app.HandlerFunc(http.MethodGet, version, "/sales", api.query, authenticate)
-	app.HandlerFunc(http.MethodGet, version, "/sales/{sale_id}", api.queryByID, authenticate)
-	app.HandlerFunc(http.MethodPost, version, "/sales", api.create, authenticate, transaction)
-	app.HandlerFunc(http.MethodDelete, version, "/sales/{sale_id}", api.delete, authenticate, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/sales/report", api.report, authenticate, permSalesReport)
+	app.HandlerFunc(http.MethodGet, version, "/sales/{sale_id}", api.queryByID, authenticate, permAuthorizeRead)
+	app.HandlerFunc(http.MethodPost, version, "/sales", api.create, authenticate, permSalesCreate, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/sales/{sale_id}", api.delete, authenticate, permAuthorizeManage, transaction)
 }
@@ -4,33 +4,48 @@ package saleapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/google/uuid"
 
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/app/sdk/query"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/business/types/quantity"
 	"github.com/rmsj/service/foundation/web"
 )
 
 type app struct {
-	userBus    *userbus.Business
-	productBus *productbus.Business
-	saleBus    *salebus.Business
+	userBus     *userbus.Business
+	productBus  *productbus.Business
+	saleBus     *salebus.Business
+	priceBus    *pricebus.Business
+	locationBus *locationbus.Business
+	authClient  *authclient.Client
 }
 
-func newApp(user *userbus.Business, product *productbus.Business, sale *salebus.Business) *app {
+func newApp(user *userbus.Business, product *productbus.Business, sale *salebus.Business, price *pricebus.Business, location *locationbus.Business, authClient *authclient.Client) *app {
 	return &app{
-		userBus:    user,
-		productBus: product,
-		saleBus:    sale,
+		userBus:     user,
+		productBus:  product,
+		saleBus:     sale,
+		priceBus:    price,
+		locationBus: location,
+		authClient:  authClient,
 	}
 }
 
@@ -58,10 +73,23 @@ func (a *app) newWithTx(ctx context.Context) (*app, error) {
 		return nil, err
 	}
 
+	priceBus, err := a.priceBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	locationBus, err := a.locationBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
 	return &app{
-		userBus:    userBus,
-		productBus: productBus,
-		saleBus:    saleBus,
+		userBus:     userBus,
+		productBus:  productBus,
+		saleBus:     saleBus,
+		priceBus:    priceBus,
+		locationBus: locationBus,
+		authClient:  a.authClient,
 	}, nil
 
 }
@@ -87,19 +115,39 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "invalid user for sale: %s", err)
 	}
 
+	if err := a.authorizeLocation(ctx, user.ID, app.LocationID); err != nil {
+		return err.(*errs.Error)
+	}
+
 	// validate products and get them if all good
 	products, err := a.validateProductsInSale(ctx, app.Items)
 	if err != nil {
 		return errs.New(err.(*errs.Error).Code, err)
 	}
 
-	newSaleBus, err := toBusNewSale(user.ID, app, products)
+	quantities, err := saleQuantities(app.Items, products)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	quotes, err := a.resolvePrices(ctx, user.ID, app.Items, quantities, products)
+	if err != nil {
+		return errs.Newf(errs.Internal, "error resolving prices for sale: %s", err)
+	}
+
+	newSaleBus, err := toBusNewSale(user.ID, app, quantities, quotes)
 	if err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
 	sl, err := a.saleBus.Create(ctx, newSaleBus)
 	if err != nil {
+		switch {
+		case errors.Is(err, productbus.ErrInsufficientStock), errors.Is(err, ratebus.ErrNotFound):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, productbus.ErrInvalidQuantity), errors.Is(err, productbus.ErrMaxQuantity):
+			return errs.New(errs.InvalidArgument, err)
+		}
 		return errs.Newf(errs.Internal, "error creating sale: %s", err)
 	}
 
@@ -108,22 +156,18 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "error parsing sale after creation - sale id[%s]: %s", sl.ID, err)
 	}
 
+	if mid.IsAdmin(ctx) {
+		result = withMargins(result, sl)
+	}
+
 	return result
 }
 
 // Delete removes a sale from the system.
 func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
-	sID, err := a.saleID(r)
-	if err != nil {
-		return errs.New(errs.Internal, err)
-	}
-
-	sl, err := a.saleBus.QueryByID(ctx, sID)
+	sl, err := mid.GetSale(ctx)
 	if err != nil {
-		if errors.Is(err, salebus.ErrNotFound) {
-			return errs.Newf(errs.NotFound, "invalid sale id: %s", sID)
-		}
-		return errs.Newf(errs.Internal, "error getting sale to delete: %s", err)
+		return errs.Newf(errs.Internal, "sale missing in context: %s", err)
 	}
 
 	if err := a.saleBus.Delete(ctx, sl); err != nil {
@@ -151,6 +195,20 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.NewFieldErrors("order", err)
 	}
 
+	// Users only see the sales made at their own locations, or not made at a
+	// location at all.
+	if !mid.IsAdmin(ctx) {
+		userID, err := mid.GetUserID(ctx)
+		if err != nil {
+			return errs.Newf(errs.Internal, "user missing in context: %s", err)
+		}
+
+		filter.LocationIDs, err = a.locationBus.QueryIDsByUser(ctx, userID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "queryidsbyuser: %s", err)
+		}
+	}
+
 	sls, err := a.saleBus.Query(ctx, filter, orderBy, pg)
 	if err != nil {
 		return errs.Newf(errs.Internal, "query: %s", err)
@@ -183,6 +241,10 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 		if err != nil {
 			return errs.Newf(errs.Internal, "error parsing sale sale - sale id[%s]: %s", sl.ID, err)
 		}
+
+		if mid.IsAdmin(ctx) {
+			sale = withMargins(sale, sl)
+		}
 		result = append(result, sale)
 	}
 
@@ -190,14 +252,9 @@ func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
 }
 
 func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
-	saleID, err := a.saleID(r)
-	if err != nil {
-		return errs.New(errs.Internal, err)
-	}
-
-	sl, err := a.saleBus.QueryByID(ctx, saleID)
+	sl, err := mid.GetSale(ctx)
 	if err != nil {
-		return errs.Newf(errs.Internal, "querybyid: %s", err)
+		return errs.Newf(errs.Internal, "sale missing in context: %s", err)
 	}
 
 	user, err := a.userBus.QueryByID(ctx, sl.UserID)
@@ -220,9 +277,83 @@ func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
 	if err != nil {
 		return errs.Newf(errs.Internal, "error sale - sale id[%s]: %s", sl.ID, err)
 	}
+
+	if mid.IsAdmin(ctx) {
+		sale = withMargins(sale, sl)
+	}
 	return sale
 }
 
+// report adds up the sales per day in the requested currency, which defaults
+// to USD.
+func (a *app) report(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	cur := currency.Default
+	if qp.Currency != "" {
+		cur, err = currency.Parse(qp.Currency)
+		if err != nil {
+			return errs.NewFieldErrors("currency", err)
+		}
+	}
+
+	rpt, err := a.saleBus.Report(ctx, filter, cur)
+	if err != nil {
+		if errors.Is(err, ratebus.ErrNotFound) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "report: %s", err)
+	}
+
+	return toAppReport(rpt)
+}
+
+// authorizeLocation checks the location the sale is made at exists and that
+// the user is allowed to sell there, by being assigned to it or holding the
+// permission to manage sales anywhere.
+func (a *app) authorizeLocation(ctx context.Context, userID uuid.UUID, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	locationID, err := uuid.Parse(id)
+	if err != nil {
+		return errs.NewFieldErrors("location_id", err)
+	}
+
+	if _, err := a.locationBus.QueryByID(ctx, locationID); err != nil {
+		if errors.Is(err, locationbus.ErrNotFound) {
+			return errs.Newf(errs.InvalidArgument, "invalid location id: %s", id)
+		}
+		return errs.Newf(errs.Internal, "querybyid: locationID[%s]: %s", locationID, err)
+	}
+
+	locations, err := a.locationBus.QueryIDsByUser(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryidsbyuser: %s", err)
+	}
+
+	authz := authclient.Authorize{
+		Claims:     mid.GetClaims(ctx),
+		UserID:     userID,
+		Rule:       auth.RulePermissionOrLocation,
+		LocationID: locationID,
+		Locations:  locations,
+		Permission: permission.SalesManage.String(),
+	}
+
+	if err := a.authClient.Authorize(ctx, authz); err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return nil
+}
+
 func (a *app) validateProductsInSale(ctx context.Context, items []NewSaleItem) ([]productbus.Product, error) {
 	// loop through items to get ids
 	var pIDs []uuid.UUID
@@ -258,6 +389,62 @@ func (a *app) validateProductsInSale(ctx context.Context, items []NewSaleItem) (
 	return products, nil
 }
 
+// saleQuantities parses the quantity of each item and converts it to the unit
+// of its product, in the same order as the items. Items without a unit are in
+// the unit of the product.
+func saleQuantities(items []NewSaleItem, products []productbus.Product) ([]quantity.Quantity, error) {
+	quantities := make([]quantity.Quantity, len(items))
+	for i, item := range items {
+		for _, prd := range products {
+			if prd.ID.String() != item.ProductID {
+				continue
+			}
+
+			unit := prd.Unit
+			if item.Unit != "" {
+				var err error
+				unit, err = quantity.ParseUnit(item.Unit)
+				if err != nil {
+					return nil, fmt.Errorf("productID[%s]: %w", prd.ID, err)
+				}
+			}
+
+			qty, err := quantity.Parse(item.Quantity, unit)
+			if err != nil {
+				return nil, fmt.Errorf("productID[%s]: %w", prd.ID, err)
+			}
+
+			quantities[i], err = prd.SaleQuantity(qty)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return quantities, nil
+}
+
+// resolvePrices works out the unit price the buyer pays for each item, in the
+// same order as the items, falling back to the product price when no price
+// list applies. Quantities are those of the items in the unit of the product.
+func (a *app) resolvePrices(ctx context.Context, buyerID uuid.UUID, items []NewSaleItem, quantities []quantity.Quantity, products []productbus.Product) ([]pricebus.Quote, error) {
+	reqs := make([]pricebus.PriceRequest, len(items))
+	for i, item := range items {
+		for _, prd := range products {
+			if prd.ID.String() == item.ProductID {
+				reqs[i] = pricebus.PriceRequest{
+					ProductID: prd.ID,
+					Quantity:  quantities[i].Value(),
+					BasePrice: prd.Price,
+					Currency:  prd.Currency,
+				}
+			}
+		}
+	}
+
+	return a.priceBus.Resolve(ctx, buyerID, reqs)
+}
+
 func (a *app) productsForSale(ctx context.Context, pIDs []uuid.UUID) ([]productbus.Product, error) {
 
 	// get all products for this order
@@ -274,11 +461,3 @@ func (a *app) productsForSale(ctx context.Context, pIDs []uuid.UUID) ([]productb
 
 	return products, nil
 }
-
-func (a *app) saleID(r *http.Request) (uuid.UUID, error) {
-	id := web.Param(r, "sale_id")
-	if id == "" {
-		return uuid.Nil, errs.Newf(errs.Internal, "sale id not in request")
-	}
-	return uuid.Parse(id)
-}
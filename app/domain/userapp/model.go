@@ -20,6 +20,7 @@ type User struct {
 	Roles       []string `json:"roles"`
 	Department  string   `json:"department"`
 	Enabled     bool     `json:"enabled"`
+	LockedUntil string   `json:"lockedUntil,omitempty"`
 	DateCreated string   `json:"dateCreated"`
 	DateUpdated string   `json:"dateUpdated"`
 }
@@ -31,6 +32,11 @@ func (app User) Encode() ([]byte, string, error) {
 }
 
 func toAppUser(bus userbus.User) User {
+	var lockedUntil string
+	if !bus.LockedUntil.IsZero() {
+		lockedUntil = bus.LockedUntil.Format(time.RFC3339)
+	}
+
 	return User{
 		ID:          bus.ID.String(),
 		Name:        bus.Name.String(),
@@ -38,6 +44,7 @@ func toAppUser(bus userbus.User) User {
 		Roles:       role.ParseToString(bus.Roles),
 		Department:  bus.Department.String(),
 		Enabled:     bus.Enabled,
+		LockedUntil: lockedUntil,
 		DateCreated: bus.DateCreated.Format(time.RFC3339),
 		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/web"
 )
@@ -23,16 +24,20 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	permUsersRead := mid.AuthorizePermission(cfg.AuthClient, permission.UsersRead)
+	permAuthorizeRead := mid.AuthorizeUserPermission(cfg.AuthClient, cfg.UserBus, auth.RulePermissionOrSubject, permission.UsersRead)
+	permAuthorizeWrite := mid.AuthorizeUserPermission(cfg.AuthClient, cfg.UserBus, auth.RulePermissionOrSubject, permission.UsersWrite)
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
-	ruleAuthorizeUser := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOrSubject)
 	ruleAuthorizeAdmin := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOnly)
+	denyImpersonation := mid.DenyImpersonation()
 
 	api := newApp(cfg.UserBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, permUsersRead)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, permAuthorizeRead)
+	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin, denyImpersonation)
+	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin, denyImpersonation)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, permAuthorizeWrite, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/users/{user_id}/unlock", api.unlock, authen, ruleAuthorizeAdmin, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, permAuthorizeWrite, denyImpersonation)
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/app/sdk/mid"
@@ -12,6 +13,7 @@ import (
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/role"
 	"github.com/rmsj/service/foundation/web"
 )
 
@@ -63,6 +65,10 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "user missing in context: %s", err)
 	}
 
+	if err := checkAdminTarget(ctx, usr); err != nil {
+		return err
+	}
+
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
 		return errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
@@ -95,12 +101,30 @@ func (a *app) updateRole(ctx context.Context, r *http.Request) web.Encoder {
 	return toAppUser(updUsr)
 }
 
+func (a *app) unlock(ctx context.Context, _ *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	updUsr, err := a.userBus.Unlock(ctx, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "unlock: userID[%s]: %s", usr.ID, err)
+	}
+
+	return toAppUser(updUsr)
+}
+
 func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
 	usr, err := mid.GetUser(ctx)
 	if err != nil {
 		return errs.Newf(errs.Internal, "userID missing in context: %s", err)
 	}
 
+	if err := checkAdminTarget(ctx, usr); err != nil {
+		return err
+	}
+
 	if err := a.userBus.Delete(ctx, usr); err != nil {
 		return errs.Newf(errs.Internal, "delete: userID[%s]: %s", usr.ID, err)
 	}
@@ -150,3 +174,14 @@ func (a *app) queryByID(ctx context.Context, _ *http.Request) web.Encoder {
 
 	return toAppUser(usr)
 }
+
+// checkAdminTarget refuses changes to an admin account by anyone but an admin
+// or the admin themselves. Otherwise users:write could be used to take over
+// an admin account by changing its email or password.
+func checkAdminTarget(ctx context.Context, usr userbus.User) *errs.Error {
+	if !slices.Contains(usr.Roles, role.Admin) || mid.IsAdmin(ctx) || usr.ID == mid.GetSubjectID(ctx) {
+		return nil
+	}
+
+	return errs.Newf(errs.PermissionDenied, "user[%s] is an admin and can only be changed by an admin", usr.ID)
+}
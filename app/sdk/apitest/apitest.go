@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/business/domain/userbus"
@@ -21,13 +22,23 @@ import (
 
 // Test contains functions for executing an api test.
 type Test struct {
-	DB   *dbtest.Database
-	Auth *auth.Auth
-	mux  http.Handler
+	DB      *dbtest.Database
+	Auth    *auth.Auth
+	mux     http.Handler
+	authMux http.Handler
 }
 
 // Run performs the actual test logic based on the table data.
 func (at *Test) Run(t *testing.T, table []Table, testName string) {
+	run(t, at.mux, table, testName)
+}
+
+// RunAuth performs the test logic against the routes of the auth service.
+func (at *Test) RunAuth(t *testing.T, table []Table, testName string) {
+	run(t, at.authMux, table, testName)
+}
+
+func run(t *testing.T, mux http.Handler, table []Table, testName string) {
 	for _, tt := range table {
 		f := func(t *testing.T) {
 			r := httptest.NewRequest(tt.Method, tt.URL, nil)
@@ -42,8 +53,17 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 				r = httptest.NewRequest(tt.Method, tt.URL, bytes.NewBuffer(d))
 			}
 
+			if tt.Body != nil {
+				r = httptest.NewRequest(tt.Method, tt.URL, bytes.NewReader(tt.Body))
+				r.Header.Set("Content-Type", tt.ContentType)
+			}
+
 			r.Header.Set("Authorization", "Bearer "+tt.Token)
-			at.mux.ServeHTTP(w, r)
+			if tt.Authorization != "" {
+				r.Header.Set("Authorization", tt.Authorization)
+			}
+
+			mux.ServeHTTP(w, r)
 
 			if w.Code != tt.StatusCode {
 				t.Fatalf("%s: Should receive a status code of %d for the response : %d", tt.Name, tt.StatusCode, w.Code)
@@ -53,8 +73,14 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 				return
 			}
 
-			if err := json.Unmarshal(w.Body.Bytes(), tt.GotResp); err != nil {
-				t.Fatalf("Should be able to unmarshal the response : %s", err)
+			switch raw := tt.GotResp.(type) {
+			case *[]byte:
+				*raw = w.Body.Bytes()
+
+			default:
+				if err := json.Unmarshal(w.Body.Bytes(), tt.GotResp); err != nil {
+					t.Fatalf("Should be able to unmarshal the response : %s", err)
+				}
 			}
 
 			diff := tt.CmpFunc(tt.GotResp, tt.ExpResp)
@@ -94,7 +120,35 @@ func Token(userBus *userbus.Business, ath *auth.Auth, email string) string {
 		Roles: role.ParseToString(dbUsr.Roles),
 	}
 
-	token, _, err := ath.GenerateToken(kid, claims)
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		return ""
+	}
+
+	return token
+}
+
+// ImpersonationToken generates a token for an admin impersonating a user.
+func ImpersonationToken(userBus *userbus.Business, ath *auth.Auth, email string, actorID uuid.UUID) string {
+	addr, _ := mail.ParseAddress(email)
+
+	dbUsr, err := userBus.QueryByEmail(context.Background(), *addr)
+	if err != nil {
+		return ""
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   dbUsr.ID.String(),
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(15 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: role.ParseToString(dbUsr.Roles),
+		Actor: &auth.Actor{Subject: actorID.String()},
+	}
+
+	token, err := ath.GenerateToken(kid, claims)
 	if err != nil {
 		return ""
 	}
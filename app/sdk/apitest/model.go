@@ -1,34 +1,54 @@
 package apitest
 
 import (
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/rolebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 )
 
-// User extends the dbtest user for api test support.
+// User extends the dbtest user for api test support. APIKey holds a personal
+// API key when the test seeds one for the user.
 type User struct {
 	userbus.User
-	Token string
+	Token  string
+	APIKey string
 }
 
-// SeedData represents users for api tests.
+// SeedData represents users for api tests. Impersonated users hold a token
+// issued to an admin impersonating them.
 type SeedData struct {
-	Users    []User
-	Admins   []User
-	Products []productbus.Product
-	Sales    []salebus.Sale
+	Users        []User
+	Admins       []User
+	Impersonated []User
+	Products     []productbus.Product
+	Sales        []salebus.Sale
+	PriceLists   []pricebus.PriceList
+	Images       []imagebus.Image
+	Rates        []ratebus.Rate
+	Locations    []locationbus.Location
+	Roles        []rolebus.Role
 }
 
-// Table represent fields needed for running an api test.
+// Table represent fields needed for running an api test. Body and
+// ContentType send a raw request body instead of the JSON encoded Input, and
+// a GotResp of type *[]byte receives the raw response body. Authorization
+// replaces the bearer Token header, for routes using other schemes.
 type Table struct {
-	Name       string
-	URL        string
-	Token      string
-	Method     string
-	StatusCode int
-	Input      any
-	GotResp    any
-	ExpResp    any
-	CmpFunc    func(got any, exp any) string
+	Name          string
+	URL           string
+	Token         string
+	Authorization string
+	Method        string
+	StatusCode    int
+	Input         any
+	Body          []byte
+	ContentType   string
+	GotResp       any
+	ExpResp       any
+	CmpFunc       func(got any, exp any) string
 }
@@ -21,6 +21,8 @@ func New(t *testing.T, testName string) *Test {
 	ath, err := auth.New(auth.Config{
 		Log:       db.Log,
 		UserBus:   db.BusDomain.User,
+		AuthBus:   db.BusDomain.Auth,
+		RoleBus:   db.BusDomain.Role,
 		KeyLookup: &KeyStore{},
 		APIKey:    "api_key",
 		ActiveKID: "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1",
@@ -31,7 +33,7 @@ func New(t *testing.T, testName string) *Test {
 
 	// -------------------------------------------------------------------------
 
-	server := httptest.NewServer(mux.WebAPI(mux.Config{
+	authMux := mux.WebAPI(mux.Config{
 		Log: db.Log,
 		DB:  db.DB,
 		BusConfig: mux.BusConfig{
@@ -41,7 +43,9 @@ func New(t *testing.T, testName string) *Test {
 		AuthConfig: mux.AuthConfig{
 			Auth: ath,
 		},
-	}, authbuild.Routes()))
+	}, authbuild.Routes())
+
+	server := httptest.NewServer(authMux)
 
 	authClient := authclient.New(db.Log, server.URL)
 
@@ -51,10 +55,15 @@ func New(t *testing.T, testName string) *Test {
 		Log: db.Log,
 		DB:  db.DB,
 		BusConfig: mux.BusConfig{
-			AuthBus:    db.BusDomain.Auth,
-			UserBus:    db.BusDomain.User,
-			ProductBus: db.BusDomain.Product,
-			SaleBus:    db.BusDomain.Sale,
+			AuthBus:     db.BusDomain.Auth,
+			UserBus:     db.BusDomain.User,
+			ProductBus:  db.BusDomain.Product,
+			SaleBus:     db.BusDomain.Sale,
+			PriceBus:    db.BusDomain.Price,
+			ImageBus:    db.BusDomain.Image,
+			RateBus:     db.BusDomain.Rate,
+			LocationBus: db.BusDomain.Location,
+			RoleBus:     db.BusDomain.Role,
 		},
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
@@ -62,8 +71,9 @@ func New(t *testing.T, testName string) *Test {
 	}, salesbuild.Routes())
 
 	return &Test{
-		DB:   db,
-		Auth: ath,
-		mux:  tMux,
+		DB:      db,
+		Auth:    ath,
+		mux:     tMux,
+		authMux: authMux,
 	}
 }
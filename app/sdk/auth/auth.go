@@ -5,44 +5,125 @@ package auth
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/rolebus"
 	"github.com/rmsj/service/business/domain/userbus"
-	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/business/types/role"
 	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
 )
 
+func init() {
+	// Tokens carry their issue time to the millisecond, so revoking the tokens
+	// of a user can be told apart from the tokens issued right after it, in
+	// the same second. Tokens are parsed with the same precision.
+	jwt.TimePrecision = time.Millisecond
+}
+
+// MaxTokenLifetime is the longest lifetime of a token signed by the service,
+// given to the tokens issued with basic authentication and by the admin
+// tooling. A retired signing key must stay published at least this long or
+// the tokens it signed stop verifying before they expire.
+const MaxTokenLifetime = 8760 * time.Hour
+
 // ErrForbidden is returned when a auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
-// Claims represents the authorization claims transmitted via a JWT.
+// ErrScopeRestricted is returned when an API key restricted to scopes is used
+// on a route authorized by role. Only permission checks can be matched to the
+// scopes, so the key is refused everywhere else.
+var ErrScopeRestricted = errors.New("api key restricted to scopes can only be used on routes that check a permission")
+
+// Claims represents the authorization claims transmitted via a JWT. The
+// registered ID is the jti claim, used to revoke a single token. Permissions
+// are granted on top of the ones the roles of the subject grant. Tokens
+// issued to a client carry the client id, which is also the subject, and the
+// granted scopes space separated instead of roles. Claims resolved from a
+// personal API key carry the key id, and the scopes of the key when it's
+// restricted, which limit the permissions of the user.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"perms,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	APIKeyID    string   `json:"api_key_id,omitempty"`
+	Actor       *Actor   `json:"act,omitempty"`
+}
+
+// Actor identifies the admin acting as the subject of an impersonation
+// token, following the act claim of RFC 8693.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonated reports whether the claims were issued to an admin acting as
+// the subject.
+func (c Claims) IsImpersonated() bool {
+	return c.Actor != nil
+}
+
+// IsClient reports whether the claims were issued to a client rather than a
+// user.
+func (c Claims) IsClient() bool {
+	return c.ClientID != ""
+}
+
+// IsAPIKey reports whether the claims were resolved from a personal API key.
+func (c Claims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// IsScopeRestricted reports whether the claims were resolved from an API key
+// that is restricted to scopes.
+func (c Claims) IsScopeRestricted() bool {
+	return c.IsAPIKey() && c.Scope != ""
+}
+
+// Scopes returns the scopes granted to a client or a restricted API key.
+func (c Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
 }
 
 // KeyLookup declares a method set of behavior for looking up
 // private and public keys for JWT use. The return could be a
-// PEM encoded string or a JWS based key.
+// PEM encoded string or a JWS based key. PublicKeys lists the keys
+// that can verify tokens, and ActiveKID names the key that signs new
+// tokens, or returns an empty string to leave it to configuration.
 type KeyLookup interface {
 	PrivateKey(kid string) (key string, err error)
 	PublicKey(kid string) (key string, err error)
+	PublicKeys() map[string]string
+	ActiveKID() string
 }
 
 // Config represents information required to initialize auth.
 type Config struct {
 	Log       *logger.Logger
 	UserBus   *userbus.Business
+	AuthBus   *authbus.Business
+	RoleBus   *rolebus.Business
 	KeyLookup KeyLookup
 	Issuer    string
 	APIKey    string
 	ActiveKID string
+	PolicyDir string
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
@@ -51,26 +132,50 @@ type Auth struct {
 	log       *logger.Logger
 	keyLookup KeyLookup
 	userBus   *userbus.Business
-	method    jwt.SigningMethod
+	authBus   *authbus.Business
+	roleBus   *rolebus.Business
 	parser    *jwt.Parser
+	policy    atomic.Pointer[policy]
+	policyDir string
+	reload    sync.Mutex
 	issuer    string
 	apiKey    string
 	activeKID string
 }
 
-// New creates an Auth to support authentication/authorization.
+// New creates an Auth to support authentication/authorization. The policies
+// are read from the policy directory when one is configured, otherwise the
+// embedded policies are used.
 func New(cfg Config) (*Auth, error) {
+	var p *policy
+	var err error
+
+	switch cfg.PolicyDir {
+	case "":
+		p, err = embeddedPolicy(context.Background())
+	default:
+		p, err = directoryPolicy(context.Background(), cfg.PolicyDir)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
 	a := Auth{
 		log:       cfg.Log,
 		keyLookup: cfg.KeyLookup,
 		userBus:   cfg.UserBus,
-		method:    jwt.GetSigningMethod(jwt.SigningMethodRS256.Name),
-		parser:    jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
+		authBus:   cfg.AuthBus,
+		roleBus:   cfg.RoleBus,
+		parser:    jwt.NewParser(jwt.WithValidMethods(signingMethods)),
+		policyDir: cfg.PolicyDir,
 		issuer:    cfg.Issuer,
 		apiKey:    cfg.APIKey,
 		activeKID: cfg.ActiveKID,
 	}
 
+	a.policy.Store(p)
+
 	return &a, nil
 }
 
@@ -79,54 +184,142 @@ func (a *Auth) Issuer() string {
 	return a.issuer
 }
 
-// APIKey provides the configured API Key used to authenticate certain endpoints.
+// APIKey provides the configured API Key used to authenticate certain
+// endpoints. The shared key is deprecated in favor of registered clients.
 func (a *Auth) APIKey() string {
 	return a.apiKey
 }
 
-// ActiveKID provides the configured active key ID, if not present in the path.
+// ActiveKID provides the active key ID, if not present in the path. The key
+// store decides when it rotates keys, otherwise the configured key is used.
 func (a *Auth) ActiveKID() string {
+	if kid := a.keyLookup.ActiveKID(); kid != "" {
+		return kid
+	}
+
 	return a.activeKID
 }
 
-// GenerateToken generates a signed JWT token string representing the user Claims.
-func (a *Auth) GenerateToken(kid string, claims Claims) (string, string, error) {
-	token := jwt.NewWithClaims(a.method, claims)
-	token.Header["kid"] = kid
+// Policy describes the active policy bundle.
+func (a *Auth) Policy() PolicyInfo {
+	return a.policy.Load().info
+}
 
-	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
+// ReloadPolicy reads the policy directory and swaps in the bundle when it
+// changed. The bundle is validated first and a bundle that fails validation
+// never replaces the active one. ErrPolicyUnchanged is returned when the
+// directory holds the active bundle.
+func (a *Auth) ReloadPolicy(ctx context.Context) (PolicyInfo, error) {
+	if a.policyDir == "" {
+		return PolicyInfo{}, errors.New("no policy directory configured")
+	}
+
+	a.reload.Lock()
+	defer a.reload.Unlock()
+
+	b, err := readBundle(os.DirFS(a.policyDir))
 	if err != nil {
-		return "", "", fmt.Errorf("private key: %w", err)
+		return PolicyInfo{}, fmt.Errorf("reading bundle: %w", err)
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	if b.digest == a.policy.Load().info.Digest {
+		return a.Policy(), ErrPolicyUnchanged
+	}
+
+	p, err := newPolicy(ctx, b, a.policyDir)
 	if err != nil {
-		return "", "", fmt.Errorf("parsing private pem: %w", err)
+		return PolicyInfo{}, fmt.Errorf("validating bundle: %w", err)
 	}
 
-	str, err := token.SignedString(privateKey)
+	a.policy.Store(p)
+
+	return p.info, nil
+}
+
+// WatchPolicy reloads the policy directory at the specified interval until
+// the context is canceled, so bundles changed on disk are validated and
+// swapped in without a restart. A rejected bundle is logged once and the
+// active bundle is kept. Without a policy directory it returns right away.
+func (a *Auth) WatchPolicy(ctx context.Context, every time.Duration) {
+	if a.policyDir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := a.ReloadPolicy(ctx)
+		switch {
+		case errors.Is(err, ErrPolicyUnchanged):
+			lastErr = ""
+
+		case err != nil:
+			if err.Error() != lastErr {
+				a.log.Error(ctx, "policy", "status", "reload rejected", "revision", a.Policy().Revision, "msg", err)
+			}
+			lastErr = err.Error()
+
+		default:
+			lastErr = ""
+			a.log.Info(ctx, "policy", "status", "policy reloaded", "revision", info.Revision)
+		}
+	}
+}
+
+// GenerateToken generates a signed JWT token string representing the user
+// Claims. The signing algorithm follows the type of the key behind the kid.
+// A unique jti is assigned when the claims don't carry one so the token can
+// be revoked.
+func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
+	if claims.ID == "" {
+		claims.ID = uuid.NewString()
+	}
+
+	privateKeyPEM, err := a.keyLookup.PrivateKey(kid)
 	if err != nil {
-		return "", "", fmt.Errorf("signing token: %w", err)
+		return "", fmt.Errorf("private key: %w", err)
 	}
 
-	refreshToken, err := id.NewRandomString(64)
+	method, privateKey, err := parsePrivateKey(privateKeyPEM)
 	if err != nil {
-		return "", "", fmt.Errorf("refresh token: %w", err)
+		return "", fmt.Errorf("parsing private pem: %w", err)
 	}
 
-	return str, refreshToken, nil
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	str, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("signing token: %w", err)
+	}
+
+	return str, nil
 }
 
 // Authenticate processes the token to validate the sender's token is valid.
+// A personal API key sent as ApiKey <key> is resolved to the claims of the
+// user who owns it.
 func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, error) {
+	if key, found := strings.CutPrefix(bearerToken, "ApiKey "); found {
+		return a.authenticateAPIKey(ctx, key)
+	}
+
 	if !strings.HasPrefix(bearerToken, "Bearer ") {
-		return Claims{}, errors.New("expected authorization header format: Bearer <token>")
+		return Claims{}, errors.New("expected authorization header format: Bearer <token> or ApiKey <key>")
 	}
 
-	jwt := bearerToken[7:]
+	tokenStr := bearerToken[7:]
 
 	var claims Claims
-	token, _, err := a.parser.ParseUnverified(jwt, &claims)
+	token, _, err := a.parser.ParseUnverified(tokenStr, &claims)
 	if err != nil {
 		return Claims{}, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -146,21 +339,62 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, fmt.Errorf("failed to fetch public key: %w", err)
 	}
 
+	method, publicKey, err := parsePublicKey(pem)
+	if err != nil {
+		return Claims{}, fmt.Errorf("parsing public key: %w", err)
+	}
+
 	input := map[string]any{
 		"Key":   pem,
-		"Token": jwt,
+		"Token": tokenStr,
 		"ISS":   a.issuer,
+		"Alg":   method.Alg(),
+	}
+
+	// OPA can't verify EdDSA signatures so they are verified here, leaving
+	// the policy to check the algorithm and claims.
+	if method == jwt.SigningMethodEdDSA {
+		if err := verifySignature(tokenStr, method, publicKey); err != nil {
+			return Claims{}, fmt.Errorf("authentication failed : %w", err)
+		}
+		input["Verified"] = true
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, regoAuthentication, RuleAuthenticate, input); err != nil {
-		a.log.Info(ctx, "**Authenticate-FAILED**", "token", jwt)
+	if err := a.opaPolicyEvaluation(ctx, RuleAuthenticate, input); err != nil {
+		a.log.Info(ctx, "**Authenticate-FAILED**", "token", tokenStr)
 		return Claims{}, fmt.Errorf("authentication failed : %w", err)
 	}
 
-	// Check the database for this user to verify they are still enabled.
+	// Check the database for this user or client to verify they are still
+	// enabled.
+
+	switch {
+	case claims.IsClient():
+		if err := a.isClientEnabled(ctx, claims); err != nil {
+			return Claims{}, fmt.Errorf("client not enabled : %w", err)
+		}
+
+	case claims.IsImpersonated():
+		if err := a.isUserEnabled(ctx, claims); err != nil {
+			return Claims{}, fmt.Errorf("user not enabled : %w", err)
+		}
+
+		if err := a.isActorAdmin(ctx, claims); err != nil {
+			return Claims{}, fmt.Errorf("actor not allowed : %w", err)
+		}
 
-	if err := a.isUserEnabled(ctx, claims); err != nil {
-		return Claims{}, fmt.Errorf("user not enabled : %w", err)
+		if slices.Contains(claims.Roles, role.Admin.String()) {
+			return Claims{}, errors.New("impersonated subject can't be an admin")
+		}
+
+	default:
+		if err := a.isUserEnabled(ctx, claims); err != nil {
+			return Claims{}, fmt.Errorf("user not enabled : %w", err)
+		}
+	}
+
+	if err := a.isTokenRevoked(ctx, claims); err != nil {
+		return Claims{}, fmt.Errorf("token revoked : %w", err)
 	}
 
 	return claims, nil
@@ -168,32 +402,158 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 
 // Authorize attempts to authorize the user with the provided input roles, if
 // none of the input roles are within the user's claims, we return an error
-// otherwise the user is authorized.
+// otherwise the user is authorized. API keys restricted to scopes are
+// refused since roles can't be matched to the scopes.
 func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
+	if claims.IsScopeRestricted() {
+		return ErrScopeRestricted
+	}
+
 	input := map[string]any{
 		"Roles":   claims.Roles,
 		"Subject": claims.Subject,
 		"UserID":  userID,
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, regoAuthorization, rule, input); err != nil {
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
+	}
+
+	return nil
+}
+
+// AuthorizeLocation attempts to authorize the user for a resource kept at the
+// specified location, given the locations the user is assigned to. A nil
+// location means the resource is not at any location. API keys restricted to
+// scopes are refused as they are by Authorize.
+func (a *Auth) AuthorizeLocation(ctx context.Context, claims Claims, locationID uuid.UUID, locations []uuid.UUID, rule string) error {
+	if claims.IsScopeRestricted() {
+		return ErrScopeRestricted
+	}
+
+	input := map[string]any{
+		"Roles":     claims.Roles,
+		"Subject":   claims.Subject,
+		"Locations": locations,
+	}
+
+	if locationID != uuid.Nil {
+		input["LocationID"] = locationID
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
 		return fmt.Errorf("rego evaluation failed : %w", err)
 	}
 
 	return nil
 }
 
-// opaPolicyEvaluation asks opa to evaluate the token against the specified token
-// policy and public key.
-func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule string, input any) error {
-	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
+// AuthorizePermission attempts to authorize the user for an action that needs
+// the specified permission, granted by the claims or the roles of the user.
+// A client is authorized by the scopes it was granted instead, and an API key
+// restricted to scopes must also have the permission in scope.
+func (a *Auth) AuthorizePermission(ctx context.Context, claims Claims, permission string) error {
+	return a.authorizePermission(ctx, claims, RulePermission, permission, map[string]any{"UserID": uuid.Nil})
+}
+
+// AuthorizePermissionOrSubject attempts to authorize the user like
+// AuthorizePermission, also authorizing the user the action is on, or who
+// owns the resource, without the permission.
+func (a *Auth) AuthorizePermissionOrSubject(ctx context.Context, claims Claims, userID uuid.UUID, permission string) error {
+	return a.authorizePermission(ctx, claims, RulePermissionOrSubject, permission, map[string]any{"UserID": userID})
+}
+
+// AuthorizePermissionOrLocation attempts to authorize the user like
+// AuthorizePermission, also authorizing a user assigned to the location the
+// resource is kept at without the permission. A nil location means the
+// resource is not at any location.
+func (a *Auth) AuthorizePermissionOrLocation(ctx context.Context, claims Claims, locationID uuid.UUID, locations []uuid.UUID, permission string) error {
+	input := map[string]any{
+		"Locations": locations,
+	}
+
+	if locationID != uuid.Nil {
+		input["LocationID"] = locationID
+	}
 
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", regoScript),
-	).PrepareForEval(ctx)
+	return a.authorizePermission(ctx, claims, RulePermissionOrLocation, permission, input)
+}
+
+// authorizePermission evaluates the permission rule with the permissions of
+// the user added to the input the rule needs.
+func (a *Auth) authorizePermission(ctx context.Context, claims Claims, rule string, permission string, input map[string]any) error {
+	if claims.IsClient() {
+		return a.AuthorizeScope(ctx, claims, permission)
+	}
+
+	perms, err := a.permissions(ctx, claims)
 	if err != nil {
-		return err
+		return fmt.Errorf("permissions: %w", err)
+	}
+
+	input["Subject"] = claims.Subject
+	input["Permissions"] = perms
+	input["Permission"] = permission
+
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
+	}
+
+	if claims.IsScopeRestricted() {
+		return a.AuthorizeScope(ctx, claims, permission)
+	}
+
+	return nil
+}
+
+// AuthorizeScope attempts to authorize the client for an action that needs
+// the specified scope.
+func (a *Auth) AuthorizeScope(ctx context.Context, claims Claims, scope string) error {
+	input := map[string]any{
+		"Subject": claims.Subject,
+		"Scopes":  claims.Scopes(),
+		"Scope":   scope,
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, RuleScope, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
+	}
+
+	return nil
+}
+
+// permissions returns the permissions carried by the claims along with the
+// ones granted by the roles of the subject. If no role business was provided
+// only the claims are used.
+func (a *Auth) permissions(ctx context.Context, claims Claims) ([]string, error) {
+	perms := slices.Clone(claims.Permissions)
+
+	if a.roleBus == nil {
+		return perms, nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject: %w", err)
+	}
+
+	granted, err := a.roleBus.QueryPermissions(ctx, userID, claims.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("query permissions: %w", err)
+	}
+
+	return append(perms, permission.ParseToString(granted)...), nil
+}
+
+// opaPolicyEvaluation asks opa to evaluate the input against the prepared
+// query for the specified rule.
+func (a *Auth) opaPolicyEvaluation(ctx context.Context, rule string, input any) error {
+	ctx, span := otel.AddSpan(ctx, "app.sdk.auth.opa", attribute.String("rule", rule))
+	defer span.End()
+
+	q, exists := a.policy.Load().queries[rule]
+	if !exists {
+		return fmt.Errorf("unknown rule %q", rule)
 	}
 
 	results, err := q.Eval(ctx, rego.EvalInput(input))
@@ -213,6 +573,22 @@ func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule
 	return nil
 }
 
+// verifySignature checks the token signature with the public key, requiring
+// the token to be signed with the expected method.
+func verifySignature(token string, method jwt.SigningMethod, publicKey crypto.PublicKey) error {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{method.Alg()}), jwt.WithoutClaimsValidation())
+
+	keyFunc := func(*jwt.Token) (any, error) {
+		return publicKey, nil
+	}
+
+	if _, err := parser.ParseWithClaims(token, &Claims{}, keyFunc); err != nil {
+		return fmt.Errorf("verify signature: %w", err)
+	}
+
+	return nil
+}
+
 // isUserEnabled hits the database and checks the user is not disabled. If the
 // no database connection was provided, this check is skipped.
 func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
@@ -236,3 +612,135 @@ func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
 
 	return nil
 }
+
+// isActorAdmin hits the database and checks the admin impersonating the
+// subject is still enabled and still an admin. If no database connection was
+// provided, this check is skipped.
+func (a *Auth) isActorAdmin(ctx context.Context, claims Claims) error {
+	if a.userBus == nil {
+		return nil
+	}
+
+	actorID, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		return fmt.Errorf("parse actor: %w", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, actorID)
+	if err != nil {
+		return fmt.Errorf("query actor: %w", err)
+	}
+
+	if !usr.Enabled {
+		return errors.New("actor disabled")
+	}
+
+	if !slices.Contains(usr.Roles, role.Admin) {
+		return errors.New("actor is not an admin")
+	}
+
+	return nil
+}
+
+// authenticateAPIKey resolves a personal API key to the claims of the user
+// who owns it. The key stands in for a token, so it expires with the key.
+func (a *Auth) authenticateAPIKey(ctx context.Context, key string) (Claims, error) {
+	if a.authBus == nil || a.userBus == nil {
+		return Claims{}, errors.New("api keys are not supported")
+	}
+
+	ak, err := a.authBus.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		return Claims{}, fmt.Errorf("authentication failed : %w", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, ak.UserID)
+	if err != nil {
+		return Claims{}, fmt.Errorf("query user: %w", err)
+	}
+
+	if !usr.Enabled {
+		return Claims{}, errors.New("user not enabled : user disabled")
+	}
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  usr.ID.String(),
+			Issuer:   a.issuer,
+			IssuedAt: jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles:    role.ParseToString(usr.Roles),
+		Scope:    strings.Join(permission.ParseToString(ak.Scopes), " "),
+		APIKeyID: ak.ID.String(),
+	}
+
+	if !ak.ExpiresAt.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(ak.ExpiresAt.UTC())
+	}
+
+	return claims, nil
+}
+
+// isClientEnabled hits the database and checks the client is still
+// registered and its owner is not disabled. If no database connection was
+// provided, this check is skipped.
+func (a *Auth) isClientEnabled(ctx context.Context, claims Claims) error {
+	if a.authBus == nil || a.userBus == nil {
+		return nil
+	}
+
+	clientID, err := uuid.Parse(claims.ClientID)
+	if err != nil {
+		return fmt.Errorf("parse client: %w", err)
+	}
+
+	if claims.Subject != claims.ClientID {
+		return errors.New("subject is not the client")
+	}
+
+	clt, err := a.authBus.QueryClientByID(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("query client: %w", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, clt.OwnerID)
+	if err != nil {
+		return fmt.Errorf("query owner: %w", err)
+	}
+
+	if !usr.Enabled {
+		return fmt.Errorf("owner disabled")
+	}
+
+	return nil
+}
+
+// isTokenRevoked checks the token has not been revoked, on its own or along
+// with every token for the user. If no auth business was provided, this
+// check is skipped.
+func (a *Auth) isTokenRevoked(ctx context.Context, claims Claims) error {
+	if a.authBus == nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parse user: %w", err)
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := a.authBus.IsTokenRevoked(ctx, claims.ID, userID, issuedAt)
+	if err != nil {
+		return fmt.Errorf("query revocation: %w", err)
+	}
+
+	if revoked {
+		return errors.New("token has been revoked")
+	}
+
+	return nil
+}
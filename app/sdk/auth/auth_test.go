@@ -3,7 +3,15 @@ package auth_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +20,7 @@ import (
 
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/foundation/keystore"
 	"github.com/rmsj/service/foundation/logger"
 )
 
@@ -34,6 +43,15 @@ func Test_Auth(t *testing.T) {
 	t.Run("test4", test4(ath))
 	t.Run("test5", test5(ath))
 	t.Run("test6", test6(ath))
+	t.Run("test7", test7(ath))
+	t.Run("jwks", jwks(ath))
+	t.Run("permissions", permissions(ath))
+	t.Run("permission-or-subject", permissionOrSubject(ath))
+	t.Run("permission-or-location", permissionOrLocation(ath))
+	t.Run("scopes", scopes(ath))
+	t.Run("scoped-apikey", scopedAPIKey(ath))
+	t.Run("introspect", introspect(ath))
+	t.Run("impersonation", impersonation(ath))
 }
 
 func test1(ath *auth.Auth) func(t *testing.T) {
@@ -43,24 +61,29 @@ func test1(ath *auth.Auth) func(t *testing.T) {
 				Issuer:    ath.Issuer(),
 				Subject:   "5cf37266-3473-4006-984f-9325122678b7",
 				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC().Truncate(time.Second).Add(125 * time.Millisecond)),
 			},
 			Roles: []string{role.Admin.String()},
 		}
 
-		token, refresh, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %s", err)
 		}
-		if refresh == "" {
-			t.Fatal("Should be able to generate a refresh token")
-		}
 
 		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
 		if err != nil {
 			t.Fatalf("Should be able to authenticate the claims : %s", err)
 		}
 
+		if parsedClaims.ID == "" {
+			t.Fatal("Should be able to get a token id from the claims")
+		}
+
+		if parsedClaims.IssuedAt.Sub(parsedClaims.IssuedAt.Truncate(time.Second)) != 125*time.Millisecond {
+			t.Fatalf("Should keep the issue time to the millisecond : got %s", parsedClaims.IssuedAt.Time)
+		}
+
 		userID := uuid.MustParse(claims.Subject)
 
 		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAdminOnly)
@@ -94,7 +117,7 @@ func test2(ath *auth.Auth) func(t *testing.T) {
 			Roles: []string{role.User.String()},
 		}
 
-		token, _, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %v", err)
 		}
@@ -121,6 +144,11 @@ func test2(ath *auth.Auth) func(t *testing.T) {
 			t.Errorf("Should be able to authorize the RuleAdminOrSubject claim with Roles.User only : %s", err)
 		}
 
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAdminOrOwner)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleAdminOrOwner claim with Roles.User only : %s", err)
+		}
+
 		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAny)
 		if err != nil {
 			t.Errorf("Should be able to authorize the RuleAny any claim with Roles.User only : %s", err)
@@ -142,7 +170,7 @@ func test3(ath *auth.Auth) func(t *testing.T) {
 			Roles: []string{role.User.String()},
 		}
 
-		token, _, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %s", err)
 		}
@@ -158,6 +186,11 @@ func test3(ath *auth.Auth) func(t *testing.T) {
 		if err == nil {
 			t.Error("Should NOT be able to authorize the RuleAdminOrSubject claim with Roles.User only and different userID")
 		}
+
+		err = ath.Authorize(context.Background(), parsedClaims, userID, auth.RuleAdminOrOwner)
+		if err == nil {
+			t.Error("Should NOT be able to authorize the RuleAdminOrOwner claim with Roles.User only and different owner")
+		}
 	}
 
 	return f
@@ -176,7 +209,7 @@ func test4(ath *auth.Auth) func(t *testing.T) {
 		}
 		userID := uuid.MustParse("9e979baa-61c9-4b50-81f2-f216d53f5c15")
 
-		token, _, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %s", err)
 		}
@@ -208,7 +241,7 @@ func test5(ath *auth.Auth) func(t *testing.T) {
 		}
 		userID := uuid.MustParse("9e979baa-61c9-4b50-81f2-f216d53f5c15")
 
-		token, _, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %s", err)
 		}
@@ -240,7 +273,7 @@ func test6(ath *auth.Auth) func(t *testing.T) {
 		}
 		userID := uuid.MustParse("9e979baa-61c9-4b50-81f2-f216d53f5c15")
 
-		token, _, err := ath.GenerateToken(kid, claims)
+		token, err := ath.GenerateToken(kid, claims)
 		if err != nil {
 			t.Fatalf("Should be able to generate a JWT : %s", err)
 		}
@@ -259,6 +292,773 @@ func test6(ath *auth.Auth) func(t *testing.T) {
 	return f
 }
 
+func test7(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles: []string{role.User.String()},
+		}
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		locationID := uuid.MustParse("0a3d0c8e-2f8b-4e43-a1b6-5c4f0f7f6a21")
+		otherID := uuid.MustParse("7f1f9a0e-3c2d-4b8a-9e6f-1d2c3b4a5e6f")
+
+		err = ath.AuthorizeLocation(context.Background(), parsedClaims, locationID, []uuid.UUID{otherID, locationID}, auth.RuleAdminOrLocation)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleAdminOrLocation claim with Roles.User at an assigned location : %s", err)
+		}
+
+		err = ath.AuthorizeLocation(context.Background(), parsedClaims, locationID, []uuid.UUID{otherID}, auth.RuleAdminOrLocation)
+		if err == nil {
+			t.Error("Should NOT be able to authorize the RuleAdminOrLocation claim with Roles.User at a location not assigned")
+		}
+
+		err = ath.AuthorizeLocation(context.Background(), parsedClaims, uuid.Nil, nil, auth.RuleAdminOrLocation)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleAdminOrLocation claim with Roles.User without a location : %s", err)
+		}
+
+		claims.Roles = []string{role.Admin.String()}
+
+		token, err = ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err = ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		err = ath.AuthorizeLocation(context.Background(), parsedClaims, locationID, nil, auth.RuleAdminOrLocation)
+		if err != nil {
+			t.Errorf("Should be able to authorize the RuleAdminOrLocation claim with Roles.Admin at any location : %s", err)
+		}
+	}
+
+	return f
+}
+
+func permissions(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject: "5cf37266-3473-4006-984f-9325122678b7",
+			},
+			Roles:       []string{role.User.String()},
+			Permissions: []string{"sales:*", "products:import"},
+		}
+
+		for _, perm := range []string{"sales:create", "sales:report", "products:import"} {
+			if err := ath.AuthorizePermission(context.Background(), claims, perm); err != nil {
+				t.Errorf("Should be able to authorize %s : %s", perm, err)
+			}
+		}
+
+		if err := ath.AuthorizePermission(context.Background(), claims, "products:write"); err == nil {
+			t.Error("Should NOT be able to authorize a permission that isn't granted")
+		}
+	}
+
+	return f
+}
+
+func permissionOrSubject(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		subject := uuid.MustParse("5cf37266-3473-4006-984f-9325122678b7")
+		other := uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject: subject.String(),
+			},
+			Roles: []string{role.User.String()},
+		}
+
+		if err := ath.AuthorizePermissionOrSubject(context.Background(), claims, subject, "users:write"); err != nil {
+			t.Errorf("Should be able to authorize the subject without the permission : %s", err)
+		}
+
+		if err := ath.AuthorizePermissionOrSubject(context.Background(), claims, other, "users:write"); err == nil {
+			t.Error("Should NOT be able to authorize another user without the permission")
+		}
+
+		claims.Permissions = []string{"users:write"}
+
+		if err := ath.AuthorizePermissionOrSubject(context.Background(), claims, other, "users:write"); err != nil {
+			t.Errorf("Should be able to authorize another user with the permission : %s", err)
+		}
+	}
+
+	return f
+}
+
+func permissionOrLocation(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		location := uuid.MustParse("8a0d2b1e-6c84-4a55-9f0e-1b7f0a3c2d45")
+		other := uuid.MustParse("c3e1f5a2-0b9d-4e6f-8a7c-2d4b6e8f0a13")
+
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject: "5cf37266-3473-4006-984f-9325122678b7",
+			},
+			Roles: []string{role.User.String()},
+		}
+
+		locations := []uuid.UUID{location}
+
+		if err := ath.AuthorizePermissionOrLocation(context.Background(), claims, location, locations, "sales:read"); err != nil {
+			t.Errorf("Should be able to authorize a resource at the user's location without the permission : %s", err)
+		}
+
+		if err := ath.AuthorizePermissionOrLocation(context.Background(), claims, uuid.Nil, locations, "sales:read"); err != nil {
+			t.Errorf("Should be able to authorize a resource not at any location without the permission : %s", err)
+		}
+
+		if err := ath.AuthorizePermissionOrLocation(context.Background(), claims, other, locations, "sales:read"); err == nil {
+			t.Error("Should NOT be able to authorize a resource at another location without the permission")
+		}
+
+		claims.Permissions = []string{"sales:read"}
+
+		if err := ath.AuthorizePermissionOrLocation(context.Background(), claims, other, locations, "sales:read"); err != nil {
+			t.Errorf("Should be able to authorize a resource at another location with the permission : %s", err)
+		}
+	}
+
+	return f
+}
+
+func scopes(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		const clientID = "b5d5e1a6-2d0c-4a8a-9f0e-6f1f4c3f7a21"
+
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   clientID,
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			ClientID: clientID,
+			Scope:    "products:import sales:*",
+		}
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		if !parsedClaims.IsClient() {
+			t.Fatal("Should be able to tell the claims belong to a client")
+		}
+
+		for _, perm := range []string{"products:import", "sales:report"} {
+			if err := ath.AuthorizePermission(context.Background(), parsedClaims, perm); err != nil {
+				t.Errorf("Should be able to authorize %s with the scopes : %s", perm, err)
+			}
+		}
+
+		if err := ath.AuthorizePermission(context.Background(), parsedClaims, "users:read"); err == nil {
+			t.Error("Should NOT be able to authorize a scope that isn't granted")
+		}
+
+		if err := ath.Authorize(context.Background(), parsedClaims, uuid.MustParse(clientID), auth.RuleAny); err == nil {
+			t.Error("Should NOT be able to authorize a role rule for a client")
+		}
+	}
+
+	return f
+}
+
+func scopedAPIKey(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		const userID = "5cf37266-3473-4006-984f-9325122678b7"
+
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:   ath.Issuer(),
+				Subject:  userID,
+				IssuedAt: jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles:    []string{role.Admin.String()},
+			APIKeyID: "0b3c5f4e-9d0a-4b57-8a1e-2a4c6d8e0f13",
+			Scope:    "sales:create",
+		}
+
+		if err := ath.Authorize(context.Background(), claims, uuid.MustParse(userID), auth.RuleAdminOnly); !errors.Is(err, auth.ErrScopeRestricted) {
+			t.Errorf("Should NOT be able to pass an admin only rule with a scoped key : %v", err)
+		}
+
+		if err := ath.Authorize(context.Background(), claims, uuid.MustParse(userID), auth.RuleAny); !errors.Is(err, auth.ErrScopeRestricted) {
+			t.Errorf("Should NOT be able to pass a role rule with a scoped key : %v", err)
+		}
+
+		if err := ath.AuthorizeLocation(context.Background(), claims, uuid.Nil, nil, auth.RuleAdminOrLocation); !errors.Is(err, auth.ErrScopeRestricted) {
+			t.Errorf("Should NOT be able to pass a location rule with a scoped key : %v", err)
+		}
+
+		claims.Scope = ""
+
+		if err := ath.Authorize(context.Background(), claims, uuid.MustParse(userID), auth.RuleAdminOnly); err != nil {
+			t.Errorf("Should be able to pass an admin only rule with an unrestricted key : %s", err)
+		}
+	}
+
+	return f
+}
+
+func introspect(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles: []string{role.User.String()},
+		}
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		in, err := ath.Introspect(context.Background(), token)
+		if err != nil {
+			t.Fatalf("Should be able to introspect the token : %s", err)
+		}
+
+		if !in.Active || in.TokenType != auth.TokenTypeAccess || in.Claims.Subject != claims.Subject {
+			t.Errorf("Should report the token as an active access token for the subject, got %+v", in)
+		}
+
+		expired := claims
+		expired.ExpiresAt = jwt.NewNumericDate(time.Now().UTC().Add(-time.Hour))
+
+		token, err = ath.GenerateToken(kid, expired)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		for _, tkn := range []string{token, "not-a-token", "sk_abc.def", ""} {
+			in, err := ath.Introspect(context.Background(), tkn)
+			if err != nil {
+				t.Fatalf("Should be able to introspect %q : %s", tkn, err)
+			}
+
+			if in.Active {
+				t.Errorf("Should report %q as inactive", tkn)
+			}
+		}
+	}
+
+	return f
+}
+
+func impersonation(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		const userID = "5cf37266-3473-4006-984f-9325122678b7"
+		const actorID = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ath.Issuer(),
+				Subject:   userID,
+				ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(15 * time.Minute)),
+				IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			},
+			Roles: []string{role.User.String()},
+			Actor: &auth.Actor{Subject: actorID},
+		}
+
+		token, err := ath.GenerateToken(kid, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		parsedClaims, err := ath.Authenticate(context.Background(), "Bearer "+token)
+		if err != nil {
+			t.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+
+		if !parsedClaims.IsImpersonated() || parsedClaims.Actor.Subject != actorID {
+			t.Fatalf("Should keep the actor in the claims : %+v", parsedClaims.Actor)
+		}
+
+		if err := ath.Authorize(context.Background(), parsedClaims, uuid.MustParse(userID), auth.RuleAdminOrSubject); err != nil {
+			t.Errorf("Should be able to authorize as the impersonated user : %s", err)
+		}
+
+		if err := ath.Authorize(context.Background(), parsedClaims, uuid.MustParse(actorID), auth.RuleAdminOrSubject); err == nil {
+			t.Error("Should NOT be able to authorize as the actor")
+		}
+	}
+
+	return f
+}
+
+func jwks(ath *auth.Auth) func(t *testing.T) {
+	f := func(t *testing.T) {
+		set, err := ath.JWKS()
+		if err != nil {
+			t.Fatalf("Should be able to build the key set : %s", err)
+		}
+
+		if len(set.Keys) != 1 {
+			t.Fatalf("Should publish one key, got %d", len(set.Keys))
+		}
+
+		jwk := set.Keys[0]
+		if jwk.KID != kid || jwk.KTY != "RSA" || jwk.Alg != "RS256" || jwk.E != "AQAB" {
+			t.Errorf("Should publish the RSA key for kid %s, got %+v", kid, jwk)
+		}
+	}
+
+	return f
+}
+
+func Test_Algorithms(t *testing.T) {
+	log := newUnit(t)
+
+	ks := keystore.New()
+
+	for _, alg := range []string{keystore.AlgRS256, keystore.AlgES256, keystore.AlgEdDSA} {
+		privatePEM, err := keystore.GeneratePrivatePEM(alg)
+		if err != nil {
+			t.Fatalf("Should be able to generate a %s key: %s", alg, err)
+		}
+
+		doc, err := json.Marshal(map[string]string{"key": alg, "pem": string(privatePEM)})
+		if err != nil {
+			t.Fatalf("Should be able to encode the %s key: %s", alg, err)
+		}
+
+		if _, err := ks.LoadByJSON(string(doc)); err != nil {
+			t.Fatalf("Should be able to load the %s key: %s", alg, err)
+		}
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ath.Issuer(),
+			Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{role.User.String()},
+	}
+
+	for _, alg := range []string{keystore.AlgRS256, keystore.AlgES256, keystore.AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			token, err := ath.GenerateToken(alg, claims)
+			if err != nil {
+				t.Fatalf("Should be able to generate a JWT : %s", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.Claims{})
+			if err != nil {
+				t.Fatalf("Should be able to parse the JWT : %s", err)
+			}
+
+			if got := parsed.Method.Alg(); got != alg {
+				t.Fatalf("Should sign with the key algorithm, got %s, exp %s", got, alg)
+			}
+
+			if _, err := ath.Authenticate(context.Background(), "Bearer "+token); err != nil {
+				t.Fatalf("Should be able to authenticate the claims : %s", err)
+			}
+
+			parts := strings.Split(token, ".")
+			other := map[string]string{
+				keystore.AlgRS256: keystore.AlgES256,
+				keystore.AlgES256: keystore.AlgEdDSA,
+				keystore.AlgEdDSA: keystore.AlgRS256,
+			}[alg]
+
+			header := fmt.Sprintf(`{"alg":%q,"kid":%q,"typ":"JWT"}`, alg, other)
+			forged := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + parts[1] + "." + parts[2]
+
+			if _, err := ath.Authenticate(context.Background(), "Bearer "+forged); err == nil {
+				t.Fatal("Should NOT be able to authenticate a token signed for another key")
+			}
+		})
+	}
+
+	set, err := ath.JWKS()
+	if err != nil {
+		t.Fatalf("Should be able to build the key set : %s", err)
+	}
+
+	var types []string
+	for _, jwk := range set.Keys {
+		types = append(types, jwk.KTY+"/"+jwk.Alg)
+	}
+
+	if exp := []string{"EC/ES256", "OKP/EdDSA", "RSA/RS256"}; fmt.Sprint(types) != fmt.Sprint(exp) {
+		t.Errorf("Should publish every key type, got %v, exp %v", types, exp)
+	}
+}
+
+func Test_PolicyDir(t *testing.T) {
+	log := newUnit(t)
+
+	files := make(map[string][]byte)
+	for _, name := range []string{"authentication.rego", "authorization.rego"} {
+		data, err := os.ReadFile(filepath.Join("rego", name))
+		if err != nil {
+			t.Fatalf("Should be able to read %s: %s", name, err)
+		}
+		files[name] = data
+	}
+
+	writeBundle := func(t *testing.T, authorization []byte, extra map[string]string) string {
+		dir := t.TempDir()
+
+		bundle := map[string][]byte{
+			"authentication.rego": files["authentication.rego"],
+			"authorization.rego":  authorization,
+		}
+		for name, data := range extra {
+			bundle[name] = []byte(data)
+		}
+
+		for name, data := range bundle {
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+				t.Fatalf("Should be able to write %s: %s", name, err)
+			}
+		}
+
+		return dir
+	}
+
+	// Copy the embedded policies with a support role that any rule accepts.
+
+	dir := writeBundle(t, bytes.Replace(files["authorization.rego"], []byte("role_all := {role_admin, role_user}"), []byte(`role_all := {role_admin, role_user, "support"}`), 1), nil)
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+		PolicyDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "5cf37266-3473-4006-984f-9325122678b7",
+		},
+		Roles: []string{"support"},
+	}
+
+	userID := uuid.MustParse(claims.Subject)
+
+	if err := ath.Authorize(context.Background(), claims, userID, auth.RuleAny); err != nil {
+		t.Errorf("Should be able to authorize with the rule changed by the loaded policy : %s", err)
+	}
+
+	if err := ath.Authorize(context.Background(), claims, userID, auth.RuleUserOnly); err == nil {
+		t.Error("Should NOT be able to authorize a rule the loaded policy didn't change")
+	}
+
+	if err := ath.Authorize(context.Background(), claims, userID, "rule_unknown"); err == nil {
+		t.Error("Should NOT be able to authorize with an unknown rule")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "authentication.rego")); err != nil {
+		t.Fatalf("Should be able to remove the policy: %s", err)
+	}
+
+	if _, err := auth.New(auth.Config{Log: log, KeyLookup: &keyStore{}, PolicyDir: dir}); err == nil {
+		t.Error("Should NOT be able to create an authenticator with a missing policy")
+	}
+
+	// A permissive bundle is rejected by the embedded tests, whether it ships
+	// no tests, weaker tests or defines the tests in the policy itself.
+
+	permissive := bytes.Replace(files["authorization.rego"], []byte("default rule_admin_only := false"), []byte("default rule_admin_only := true"), 1)
+
+	tt := []struct {
+		name          string
+		authorization []byte
+		extra         map[string]string
+		exp           string
+	}{
+		{
+			name:          "no-tests",
+			authorization: permissive,
+			exp:           "embedded: tests failed: test_rule_admin_only",
+		},
+		{
+			name:          "weak-tests",
+			authorization: permissive,
+			extra:         map[string]string{"authorization_test.rego": "package ardan.rego\n\nimport rego.v1\n\ntest_rule_admin_only := true\n"},
+			exp:           "embedded: tests failed: test_rule_admin_only",
+		},
+		{
+			name:          "policy-tests",
+			authorization: append(slices.Clone(permissive), []byte("\ntest_rule_admin_only := true\n")...),
+			exp:           `rule "test_rule_admin_only" is a test`,
+		},
+	}
+
+	for _, tst := range tt {
+		t.Run(tst.name, func(t *testing.T) {
+			dir := writeBundle(t, tst.authorization, tst.extra)
+
+			_, err := auth.New(auth.Config{Log: log, KeyLookup: &keyStore{}, PolicyDir: dir})
+			if err == nil || !strings.Contains(err.Error(), tst.exp) {
+				t.Fatalf("Should NOT be able to create an authenticator with a permissive policy, got %v, exp %q", err, tst.exp)
+			}
+		})
+	}
+}
+
+func Test_PolicyReload(t *testing.T) {
+	log := newUnit(t)
+
+	dir := t.TempDir()
+
+	files := make(map[string][]byte)
+	for _, name := range []string{"authentication.rego", "authorization.rego", "authorization_test.rego"} {
+		data, err := os.ReadFile(filepath.Join("rego", name))
+		if err != nil {
+			t.Fatalf("Should be able to read %s: %s", name, err)
+		}
+		files[name] = data
+
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("Should be able to write %s: %s", name, err)
+		}
+	}
+
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("Should be able to write %s: %s", name, err)
+		}
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+		PolicyDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	active := ath.Policy()
+	if active.Revision == "" || active.Source != dir {
+		t.Fatalf("Should describe the loaded policy, got %+v", active)
+	}
+
+	if _, err := ath.ReloadPolicy(context.Background()); !errors.Is(err, auth.ErrPolicyUnchanged) {
+		t.Fatalf("Should find the policy unchanged, got %v", err)
+	}
+
+	// A policy that doesn't compile must not replace the active one.
+
+	write("authorization.rego", []byte("package ardan.rego\n\nrule_any if {"))
+
+	if _, err := ath.ReloadPolicy(context.Background()); err == nil {
+		t.Fatal("Should NOT be able to load a policy that doesn't compile")
+	}
+
+	// A policy that fails its tests must not replace the active one.
+
+	write("authorization.rego", bytes.Replace(files["authorization.rego"], []byte("rule_user_only if {"), []byte("rule_user_only if {\n\tfalse"), 1))
+
+	if _, err := ath.ReloadPolicy(context.Background()); err == nil || !strings.Contains(err.Error(), "test_rule_user_only") {
+		t.Fatalf("Should NOT be able to load a policy that fails its tests, got %v", err)
+	}
+
+	if got := ath.Policy(); got != active {
+		t.Fatalf("Should keep the active policy, got %+v, exp %+v", got, active)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "5cf37266-3473-4006-984f-9325122678b7",
+		},
+		Roles: []string{role.User.String()},
+	}
+
+	userID := uuid.MustParse(claims.Subject)
+
+	if err := ath.Authorize(context.Background(), claims, userID, auth.RuleUserOnly); err != nil {
+		t.Fatalf("Should authorize with the active policy : %s", err)
+	}
+
+	// A valid policy with a manifest replaces the active one.
+
+	write("authorization.rego", files["authorization.rego"])
+	write(".manifest", []byte(`{"revision":"v2"}`))
+
+	info, err := ath.ReloadPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to load a valid policy : %s", err)
+	}
+
+	if info.Revision != "v2" || ath.Policy().Revision != "v2" {
+		t.Fatalf("Should activate the manifest revision, got %s", ath.Policy().Revision)
+	}
+}
+
+func Test_WatchPolicy(t *testing.T) {
+	log := newUnit(t)
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"authentication.rego", "authorization.rego", "authorization_test.rego"} {
+		data, err := os.ReadFile(filepath.Join("rego", name))
+		if err != nil {
+			t.Fatalf("Should be able to read %s: %s", name, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("Should be able to write %s: %s", name, err)
+		}
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+		PolicyDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		ath.WatchPolicy(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	if err := os.WriteFile(filepath.Join(dir, ".manifest"), []byte(`{"revision":"v2"}`), 0600); err != nil {
+		t.Fatalf("Should be able to write the manifest: %s", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for ath.Policy().Revision != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Should reload the changed policy, got %s", ath.Policy().Revision)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should stop watching once the context is canceled")
+	}
+}
+
+// =============================================================================
+
+func BenchmarkAuthenticate(b *testing.B) {
+	ath := newBench(b)
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ath.Issuer(),
+			Subject:   "5cf37266-3473-4006-984f-9325122678b7",
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{role.Admin.String()},
+	}
+
+	token, err := ath.GenerateToken(kid, claims)
+	if err != nil {
+		b.Fatalf("Should be able to generate a JWT : %s", err)
+	}
+
+	bearer := "Bearer " + token
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := ath.Authenticate(ctx, bearer); err != nil {
+			b.Fatalf("Should be able to authenticate the claims : %s", err)
+		}
+	}
+}
+
+func BenchmarkAuthorize(b *testing.B) {
+	ath := newBench(b)
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "5cf37266-3473-4006-984f-9325122678b7",
+		},
+		Roles: []string{role.Admin.String()},
+	}
+
+	userID := uuid.MustParse(claims.Subject)
+	ctx := context.Background()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ath.Authorize(ctx, claims, userID, auth.RuleAdminOrSubject); err != nil {
+				b.Errorf("Should be able to authorize the claims : %s", err)
+				return
+			}
+		}
+	})
+}
+
+func newBench(b *testing.B) *auth.Auth {
+	log := logger.New(io.Discard, logger.LevelInfo, "BENCH", func(context.Context) string { return "" })
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: &keyStore{},
+		Issuer:    "service project",
+	})
+	if err != nil {
+		b.Fatalf("Should be able to create an authenticator: %s", err)
+	}
+
+	return ath
+}
+
 // =============================================================================
 
 func newUnit(t *testing.T) *logger.Logger {
@@ -288,6 +1088,14 @@ func (ks *keyStore) PublicKey(kid string) (string, error) {
 	return publicKeyPEM, nil
 }
 
+func (ks *keyStore) PublicKeys() map[string]string {
+	return map[string]string{kid: publicKeyPEM}
+}
+
+func (ks *keyStore) ActiveKID() string {
+	return ""
+}
+
 const (
 	kid = "s4sKIjD9kIRjxs2tulPqGLdxSfgPErRN1Mu3Hd9k9NQ"
 
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/types/role"
+)
+
+// Set of token types reported by introspection.
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+	TokenTypeAPIKey  = "api_key"
+)
+
+// Introspection describes a token presented for introspection. The claims
+// are only set when the token is active.
+type Introspection struct {
+	Active    bool
+	TokenType string
+	Claims    Claims
+}
+
+// Introspect reports whether the token is active and what it stands for.
+// Access tokens, refresh tokens and personal API keys are told apart by their
+// shape and go through the same checks as Authenticate, including
+// revocation and whether the user or client is still enabled. A token that
+// fails a check is reported as inactive rather than as an error.
+func (a *Auth) Introspect(ctx context.Context, token string) (Introspection, error) {
+	switch {
+	case strings.Count(token, ".") == 2:
+		claims, err := a.Authenticate(ctx, "Bearer "+token)
+		if err != nil {
+			return Introspection{}, nil
+		}
+
+		return Introspection{Active: true, TokenType: TokenTypeAccess, Claims: claims}, nil
+
+	case strings.HasPrefix(token, authbus.APIKeyPrefix):
+		claims, err := a.authenticateAPIKey(ctx, token)
+		if err != nil {
+			return Introspection{}, nil
+		}
+
+		return Introspection{Active: true, TokenType: TokenTypeAPIKey, Claims: claims}, nil
+
+	case token != "" && !strings.Contains(token, "."):
+		return a.introspectRefreshToken(ctx, token)
+	}
+
+	return Introspection{}, nil
+}
+
+// introspectRefreshToken resolves a refresh token to the claims of the user
+// holding the session, without rotating it.
+func (a *Auth) introspectRefreshToken(ctx context.Context, token string) (Introspection, error) {
+	if a.authBus == nil || a.userBus == nil {
+		return Introspection{}, nil
+	}
+
+	sess, err := a.authBus.QuerySessionByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, authbus.ErrInvalidSession) {
+			return Introspection{}, nil
+		}
+		return Introspection{}, fmt.Errorf("query session: %w", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, sess.UserID)
+	if err != nil {
+		return Introspection{}, nil
+	}
+
+	if !usr.Enabled {
+		return Introspection{}, nil
+	}
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   usr.ID.String(),
+			Issuer:    a.issuer,
+			ExpiresAt: jwt.NewNumericDate(sess.ExpiresAt.UTC()),
+			IssuedAt:  jwt.NewNumericDate(sess.DateLastUsed.UTC()),
+		},
+		Roles: role.ParseToString(usr.Roles),
+	}
+
+	return Introspection{Active: true, TokenType: TokenTypeRefresh, Claims: claims}, nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// JWK represents a public key in JSON Web Key form, RFC 7517. RSA keys set
+// N and E, ECDSA keys Crv, X and Y, and Ed25519 keys Crv and X.
+type JWK struct {
+	KTY string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	KID string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKey rebuilds the public key described by the JWK. ECDSA keys must
+// use the P-256 curve.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KTY {
+	case "RSA":
+		n, err := decodeSegment(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding n: %w", err)
+		}
+
+		e, err := decodeSegment(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding e: %w", err)
+		}
+
+		pk := rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+
+		return &pk, nil
+
+	case "EC":
+		if k.Crv != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		x, err := decodeSegment(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+
+		y, err := decodeSegment(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y: %w", err)
+		}
+
+		pk := ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+
+		return &pk, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		x, err := decodeSegment(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", k.KTY)
+}
+
+// JWKS represents a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the set of public keys that can verify tokens issued by this
+// service, so other services can verify them locally.
+func (a *Auth) JWKS() (JWKS, error) {
+	keys := a.keyLookup.PublicKeys()
+
+	jwks := JWKS{
+		Keys: make([]JWK, 0, len(keys)),
+	}
+
+	for kid, publicPEM := range keys {
+		method, publicKey, err := parsePublicKey(publicPEM)
+		if err != nil {
+			return JWKS{}, fmt.Errorf("parsing public pem: kid[%s]: %w", kid, err)
+		}
+
+		jwk := JWK{
+			Use: "sig",
+			Alg: method.Alg(),
+			KID: kid,
+		}
+
+		switch pk := publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KTY = "RSA"
+			jwk.N = encodeSegment(pk.N.Bytes())
+			jwk.E = encodeSegment(big.NewInt(int64(pk.E)).Bytes())
+
+		case *ecdsa.PublicKey:
+			size := (pk.Curve.Params().BitSize + 7) / 8
+			jwk.KTY = "EC"
+			jwk.Crv = pk.Curve.Params().Name
+			jwk.X = encodeSegment(pk.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeSegment(pk.Y.FillBytes(make([]byte, size)))
+
+		case ed25519.PublicKey:
+			jwk.KTY = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeSegment(pk)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	slices.SortFunc(jwks.Keys, func(a, b JWK) int {
+		return strings.Compare(a.KID, b.KID)
+	})
+
+	return jwks, nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signingMethods lists the algorithms a token can be signed with. The
+// algorithm is chosen by the type of key behind the kid.
+var signingMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// parsePrivateKey decodes a PEM encoded private key and returns it with the
+// signing method matching its type.
+func parsePrivateKey(privatePEM string) (jwt.SigningMethod, crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid key: key must be PEM encoded")
+	}
+
+	var key any
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parsing private key: %w", err)
+			}
+		}
+	}
+
+	switch pk := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, pk, nil
+	case *ecdsa.PrivateKey:
+		if pk.Curve != elliptic.P256() {
+			return nil, nil, fmt.Errorf("unsupported curve %s", pk.Curve.Params().Name)
+		}
+		return jwt.SigningMethodES256, pk, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, pk, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+// parsePublicKey decodes a PEM encoded public key and returns it with the
+// signing method matching its type.
+func parsePublicKey(publicPEM string) (jwt.SigningMethod, crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, nil, errors.New("invalid key: key must be PEM encoded")
+	}
+
+	var key any
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing public key: %w", err)
+		}
+	}
+
+	switch pk := key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, pk, nil
+	case *ecdsa.PublicKey:
+		if pk.Curve != elliptic.P256() {
+			return nil, nil, fmt.Errorf("unsupported curve %s", pk.Curve.Params().Name)
+		}
+		return jwt.SigningMethodES256, pk, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, pk, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported public key type %T", key)
+}
@@ -49,3 +49,22 @@ func (app RefreshToken) Validate() error {
 	}
 	return nil
 }
+
+// LoginMFA is used to complete a login with a multi-factor challenge.
+type LoginMFA struct {
+	Challenge string `json:"challenge" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *LoginMFA) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app LoginMFA) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// ErrPolicyUnchanged is returned when a reload finds the active policy.
+var ErrPolicyUnchanged = errors.New("policy unchanged")
+
+// manifestFile optionally names the revision of a policy bundle, following
+// the OPA bundle layout.
+const manifestFile = ".manifest"
+
+// embeddedSource names the source of the policy built into the binary.
+const embeddedSource = "embedded"
+
+// PolicyInfo describes the active policy bundle. The revision comes from the
+// bundle manifest, or is the digest when the bundle has none.
+type PolicyInfo struct {
+	Revision string
+	Digest   string
+	Source   string
+	LoadedAt time.Time
+}
+
+// policy holds a prepared query for every rule. The modules are compiled
+// once and the prepared queries are safe for concurrent use, so evaluating
+// a rule doesn't recompile the policy.
+type policy struct {
+	queries map[string]rego.PreparedEvalQuery
+	info    PolicyInfo
+}
+
+// bundle represents the rego files read from a policy source.
+type bundle struct {
+	modules  map[string]string
+	tests    map[string]string
+	revision string
+	digest   string
+}
+
+// readBundle reads every rego file at the root of the file system. Files
+// ending in _test.rego hold policy tests and are only used for validation.
+func readBundle(fsys fs.FS) (bundle, error) {
+	names, err := fs.Glob(fsys, "*.rego")
+	if err != nil {
+		return bundle{}, fmt.Errorf("glob: %w", err)
+	}
+
+	if len(names) == 0 {
+		return bundle{}, errors.New("no rego files found")
+	}
+
+	b := bundle{
+		modules: make(map[string]string),
+		tests:   make(map[string]string),
+	}
+
+	hash := sha256.New()
+
+	slices.Sort(names)
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return bundle{}, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		fmt.Fprintf(hash, "%s\n%d\n", name, len(data))
+		hash.Write(data)
+
+		switch {
+		case strings.HasSuffix(name, "_test.rego"):
+			b.tests[name] = string(data)
+		default:
+			b.modules[name] = string(data)
+		}
+	}
+
+	var m struct {
+		Revision string `json:"revision"`
+	}
+
+	data, err := fs.ReadFile(fsys, manifestFile)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &m); err != nil {
+			return bundle{}, fmt.Errorf("decode %s: %w", manifestFile, err)
+		}
+
+		fmt.Fprintf(hash, "%s\n%d\n", manifestFile, len(data))
+		hash.Write(data)
+
+	case !errors.Is(err, fs.ErrNotExist):
+		return bundle{}, fmt.Errorf("read %s: %w", manifestFile, err)
+	}
+
+	b.digest = hex.EncodeToString(hash.Sum(nil))
+
+	b.revision = m.Revision
+	if b.revision == "" {
+		b.revision = b.digest[:12]
+	}
+
+	return b, nil
+}
+
+// newPolicy validates the bundle and prepares the queries for every rule.
+// The bundle must compile, define every rule and pass its tests. A bundle
+// that isn't the embedded one must also pass the embedded tests, so it
+// can't weaken the rules by shipping weaker tests or none at all.
+func newPolicy(ctx context.Context, b bundle, source string) (*policy, error) {
+	modules, err := parseModules(b.modules)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := definedRules(modules)
+	for _, rule := range policyRules() {
+		if !defined[rule] {
+			return nil, fmt.Errorf("rule %q is not defined", rule)
+		}
+	}
+
+	// Tests are evaluated with the policy modules loaded, so a module could
+	// otherwise make a failing test pass by defining it as well.
+	for rule := range defined {
+		if strings.HasPrefix(rule, "test_") {
+			return nil, fmt.Errorf("rule %q is a test and must be in a _test.rego file", rule)
+		}
+	}
+
+	tests, err := parseModules(b.tests)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runTests(ctx, modules, tests); err != nil {
+		return nil, err
+	}
+
+	if source != embeddedSource {
+		embedded, err := embeddedTests()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := runTests(ctx, modules, embedded); err != nil {
+			return nil, fmt.Errorf("embedded: %w", err)
+		}
+	}
+
+	p := policy{
+		queries: make(map[string]rego.PreparedEvalQuery),
+		info: PolicyInfo{
+			Revision: b.revision,
+			Digest:   b.digest,
+			Source:   source,
+			LoadedAt: time.Now(),
+		},
+	}
+
+	for _, rule := range policyRules() {
+		options := []func(*rego.Rego){
+			rego.Query(fmt.Sprintf("x = data.%s.%s", opaPackage, rule)),
+		}
+		for _, module := range modules {
+			options = append(options, rego.ParsedModule(module))
+		}
+
+		q, err := rego.New(options...).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("prepare rule[%s]: %w", rule, err)
+		}
+
+		p.queries[rule] = q
+	}
+
+	return &p, nil
+}
+
+// embeddedPolicy prepares the policy built into the binary.
+func embeddedPolicy(ctx context.Context) (*policy, error) {
+	fsys, err := fs.Sub(regoFS, "rego")
+	if err != nil {
+		return nil, fmt.Errorf("embedded: %w", err)
+	}
+
+	b, err := readBundle(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("embedded: %w", err)
+	}
+
+	return newPolicy(ctx, b, embeddedSource)
+}
+
+// embeddedTests parses the tests built into the binary. They are run on
+// their own against every bundle read from outside the binary.
+func embeddedTests() ([]*ast.Module, error) {
+	fsys, err := fs.Sub(regoFS, "rego")
+	if err != nil {
+		return nil, fmt.Errorf("embedded: %w", err)
+	}
+
+	b, err := readBundle(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("embedded: %w", err)
+	}
+
+	tests, err := parseModules(b.tests)
+	if err != nil {
+		return nil, fmt.Errorf("embedded: %w", err)
+	}
+
+	return tests, nil
+}
+
+// directoryPolicy prepares the policy read from the directory.
+func directoryPolicy(ctx context.Context, dir string) (*policy, error) {
+	b, err := readBundle(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+
+	return newPolicy(ctx, b, dir)
+}
+
+// runTests evaluates every test_ rule in the test modules against the
+// policy. Each test must evaluate to true.
+func runTests(ctx context.Context, modules []*ast.Module, tests []*ast.Module) error {
+	var failed []string
+
+	for _, test := range tests {
+		for _, rule := range test.Rules {
+			name := rule.Head.Name.String()
+			if !strings.HasPrefix(name, "test_") {
+				continue
+			}
+
+			options := []func(*rego.Rego){
+				rego.Query(fmt.Sprintf("x = %s.%s", test.Package.Path, name)),
+			}
+			for _, module := range append(slices.Clone(modules), tests...) {
+				options = append(options, rego.ParsedModule(module))
+			}
+
+			results, err := rego.New(options...).Eval(ctx)
+			if err != nil {
+				return fmt.Errorf("test[%s]: %w", name, err)
+			}
+
+			if len(results) == 0 {
+				failed = append(failed, name)
+				continue
+			}
+
+			if passed, ok := results[0].Bindings["x"].(bool); !ok || !passed {
+				failed = append(failed, name)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		failed = slices.Compact(failed)
+		return fmt.Errorf("tests failed: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+func parseModules(files map[string]string) ([]*ast.Module, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	modules := make([]*ast.Module, 0, len(names))
+	for _, name := range names {
+		module, err := ast.ParseModule(name, files[name])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// definedRules returns the rules the modules define in the policy package.
+func definedRules(modules []*ast.Module) map[string]bool {
+	defined := make(map[string]bool)
+	for _, module := range modules {
+		if module.Package.Path.String() != "data."+opaPackage {
+			continue
+		}
+
+		for _, rule := range module.Rules {
+			defined[rule.Head.Name.String()] = true
+		}
+	}
+
+	return defined
+}
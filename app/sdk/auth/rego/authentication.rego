@@ -4,10 +4,25 @@ import rego.v1
 
 default auth := false
 
+# The token must be signed with the algorithm of the key behind its kid.
 auth if {
-	[valid, _, _] := io.jwt.decode_verify(input.Token, {
+	input.Alg != "EdDSA"
+	[valid, header, _] := io.jwt.decode_verify(input.Token, {
 		"cert": input.Key,
 		"iss": input.ISS,
+		"alg": input.Alg,
 	})
 	valid == true
+	header.alg == input.Alg
+}
+
+# OPA can't verify EdDSA signatures, so the caller verifies the signature
+# and the claims are checked here.
+auth if {
+	input.Alg == "EdDSA"
+	input.Verified == true
+	[header, payload, _] := io.jwt.decode(input.Token)
+	header.alg == input.Alg
+	payload.iss == input.ISS
+	payload.exp * 1000000000 > time.now_ns()
 }
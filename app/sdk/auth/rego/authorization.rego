@@ -44,3 +44,91 @@ rule_admin_or_subject if {
 	count(input_user) > 0
 	input.UserID == input.Subject
 }
+
+# For ownership checks the caller passes the owner of the resource as UserID,
+# which is then checked the same way as the subject.
+default rule_admin_or_owner := false
+
+rule_admin_or_owner if {
+	rule_admin_or_subject
+}
+
+# For location checks the caller passes the location of the resource as
+# LocationID and the locations the user is assigned to as Locations. Resources
+# that are not at any location are open to every user.
+default rule_admin_or_location := false
+
+rule_admin_or_location if {
+	claim_roles := {role | some role in input.Roles}
+	input_admin := {role_admin} & claim_roles
+	count(input_admin) > 0
+} else if {
+	claim_roles := {role | some role in input.Roles}
+	input_user := {role_user} & claim_roles
+	count(input_user) > 0
+	not input.LocationID
+} else if {
+	claim_roles := {role | some role in input.Roles}
+	input_user := {role_user} & claim_roles
+	count(input_user) > 0
+	input.LocationID in input.Locations
+}
+
+# For permission checks the caller passes the permission the action needs as
+# Permission and the permissions granted to the user as Permissions. A granted
+# resource:* covers every action on the resource and * covers everything.
+default rule_permission := false
+
+rule_permission if {
+	some granted in input.Permissions
+	grants(granted, input.Permission)
+}
+
+grants(granted, _) if {
+	granted == "*"
+}
+
+grants(granted, wanted) if {
+	granted == wanted
+}
+
+grants(granted, wanted) if {
+	parts := split(granted, ":")
+	parts[1] == "*"
+	startswith(wanted, concat("", [parts[0], ":"]))
+}
+
+# For permission or subject checks the caller also passes the user the action
+# is on, or the owner of the resource, as UserID. Users can act on their own
+# account and resources without the permission.
+default rule_permission_or_subject := false
+
+rule_permission_or_subject if {
+	rule_permission
+} else if {
+	input.UserID == input.Subject
+}
+
+# For permission or location checks the caller also passes the location of
+# the resource as LocationID and the locations the user is assigned to as
+# Locations. Users can act on resources at their locations, or not at any
+# location, without the permission.
+default rule_permission_or_location := false
+
+rule_permission_or_location if {
+	rule_permission
+} else if {
+	not input.LocationID
+} else if {
+	input.LocationID in input.Locations
+}
+
+# For scope checks the caller passes the scope the action needs as Scope and
+# the scopes granted to the client or API key as Scopes. Scopes are
+# permissions, so the same wildcards apply.
+default rule_scope := false
+
+rule_scope if {
+	some granted in input.Scopes
+	grants(granted, input.Scope)
+}
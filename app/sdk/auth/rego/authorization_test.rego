@@ -0,0 +1,71 @@
+package ardan.rego
+
+import rego.v1
+
+# These tests run whenever the policy is loaded. A policy that fails them is
+# rejected and the active policy is kept.
+
+test_rule_any if {
+	rule_any with input as {"Roles": ["user"]}
+	rule_any with input as {"Roles": ["admin"]}
+	not rule_any with input as {"Roles": ["guest"]}
+}
+
+test_rule_admin_only if {
+	rule_admin_only with input as {"Roles": ["admin"]}
+	not rule_admin_only with input as {"Roles": ["user"]}
+}
+
+test_rule_user_only if {
+	rule_user_only with input as {"Roles": ["user"]}
+	not rule_user_only with input as {"Roles": ["admin"]}
+}
+
+test_rule_admin_or_subject if {
+	rule_admin_or_subject with input as {"Roles": ["admin"], "UserID": "a", "Subject": "b"}
+	rule_admin_or_subject with input as {"Roles": ["user"], "UserID": "a", "Subject": "a"}
+	not rule_admin_or_subject with input as {"Roles": ["user"], "UserID": "a", "Subject": "b"}
+}
+
+test_rule_admin_or_owner if {
+	rule_admin_or_owner with input as {"Roles": ["admin"], "UserID": "a", "Subject": "b"}
+	rule_admin_or_owner with input as {"Roles": ["user"], "UserID": "a", "Subject": "a"}
+	not rule_admin_or_owner with input as {"Roles": ["user"], "UserID": "a", "Subject": "b"}
+}
+
+test_rule_admin_or_location if {
+	rule_admin_or_location with input as {"Roles": ["admin"], "LocationID": "x", "Locations": []}
+	rule_admin_or_location with input as {"Roles": ["user"], "Locations": []}
+	rule_admin_or_location with input as {"Roles": ["user"], "LocationID": "x", "Locations": ["x"]}
+	not rule_admin_or_location with input as {"Roles": ["user"], "LocationID": "x", "Locations": ["y"]}
+}
+
+test_rule_permission if {
+	rule_permission with input as {"Permissions": ["sales:create"], "Permission": "sales:create"}
+	rule_permission with input as {"Permissions": ["sales:*"], "Permission": "sales:report"}
+	rule_permission with input as {"Permissions": ["*"], "Permission": "products:import"}
+	not rule_permission with input as {"Permissions": ["sales:create"], "Permission": "sales:report"}
+	not rule_permission with input as {"Permissions": ["products:*"], "Permission": "sales:report"}
+	not rule_permission with input as {"Permissions": [], "Permission": "sales:create"}
+}
+
+test_rule_permission_or_subject if {
+	rule_permission_or_subject with input as {"Permissions": ["users:write"], "Permission": "users:write", "UserID": "a", "Subject": "b"}
+	rule_permission_or_subject with input as {"Permissions": [], "Permission": "users:write", "UserID": "a", "Subject": "a"}
+	not rule_permission_or_subject with input as {"Permissions": ["users:read"], "Permission": "users:write", "UserID": "a", "Subject": "b"}
+}
+
+test_rule_permission_or_location if {
+	rule_permission_or_location with input as {"Permissions": ["sales:read"], "Permission": "sales:read", "LocationID": "x", "Locations": []}
+	rule_permission_or_location with input as {"Permissions": [], "Permission": "sales:read", "Locations": []}
+	rule_permission_or_location with input as {"Permissions": [], "Permission": "sales:read", "LocationID": "x", "Locations": ["x"]}
+	not rule_permission_or_location with input as {"Permissions": ["sales:create"], "Permission": "sales:read", "LocationID": "x", "Locations": ["y"]}
+}
+
+test_rule_scope if {
+	rule_scope with input as {"Scopes": ["products:import"], "Scope": "products:import"}
+	rule_scope with input as {"Scopes": ["sales:report", "products:*"], "Scope": "products:import"}
+	not rule_scope with input as {"Scopes": ["sales:report"], "Scope": "products:import"}
+	not rule_scope with input as {"Scopes": [], "Scope": "products:import"}
+	not rule_scope with input as {"Roles": ["admin"], "Scope": "products:import"}
+}
@@ -1,28 +1,49 @@
 package auth
 
 import (
-	_ "embed"
+	"embed"
 )
 
 // These are the current set of rules we have for auth.
 const (
-	RuleAuthenticate   = "auth"
-	RuleAny            = "rule_any"
-	RuleAdminOnly      = "rule_admin_only"
-	RuleUserOnly       = "rule_user_only"
-	RuleAdminOrSubject = "rule_admin_or_subject"
+	RuleAuthenticate         = "auth"
+	RuleAny                  = "rule_any"
+	RuleAdminOnly            = "rule_admin_only"
+	RuleUserOnly             = "rule_user_only"
+	RuleAdminOrSubject       = "rule_admin_or_subject"
+	RuleAdminOrOwner         = "rule_admin_or_owner"
+	RuleAdminOrLocation      = "rule_admin_or_location"
+	RulePermission           = "rule_permission"
+	RulePermissionOrSubject  = "rule_permission_or_subject"
+	RulePermissionOrLocation = "rule_permission_or_location"
+	RuleScope                = "rule_scope"
 )
 
+// authorizationRules lists the rules defined by the authorization policy.
+var authorizationRules = []string{
+	RuleAny,
+	RuleAdminOnly,
+	RuleUserOnly,
+	RuleAdminOrSubject,
+	RuleAdminOrOwner,
+	RuleAdminOrLocation,
+	RulePermission,
+	RulePermissionOrSubject,
+	RulePermissionOrLocation,
+	RuleScope,
+}
+
+// policyRules lists every rule a policy must define.
+func policyRules() []string {
+	return append([]string{RuleAuthenticate}, authorizationRules...)
+}
+
 // Package name of our rego code.
 const (
 	opaPackage string = "ardan.rego"
 )
 
-// Core OPA policies.
-var (
-	//go:embed rego/authentication.rego
-	regoAuthentication string
-
-	//go:embed rego/authorization.rego
-	regoAuthorization string
-)
+// Core OPA policies, used unless a policy directory is configured.
+//
+//go:embed rego/*.rego
+var regoFS embed.FS
@@ -11,8 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
@@ -38,11 +42,15 @@ var defaultClient = http.Client{
 	},
 }
 
-// Client represents a client that can talk to the auth service.
+// Client represents a client that can talk to the auth service. With local
+// verification enabled, tokens signed by a known key are verified and
+// authorized in-process, and the auth service is only called for the rest.
 type Client struct {
 	log  *logger.Logger
 	url  string
 	http *http.Client
+	auth *auth.Auth
+	keys *KeySet
 }
 
 // New constructs an Auth that can be used to talk with the auth service.
@@ -68,8 +76,23 @@ func WithClient(http *http.Client) func(cln *Client) {
 	}
 }
 
-// Authenticate calls the auth service to authenticate the user.
+// WithLocalVerify verifies tokens and evaluates the authorization rules
+// in-process. The auth value must be constructed with the key set as its
+// key lookup so tokens are verified with the auth service's public keys.
+func WithLocalVerify(ath *auth.Auth, keys *KeySet) func(cln *Client) {
+	return func(cln *Client) {
+		cln.auth = ath
+		cln.keys = keys
+	}
+}
+
+// Authenticate authenticates the user. Tokens are verified locally when
+// possible, otherwise the auth service is called.
 func (cln *Client) Authenticate(ctx context.Context, authorization string) (AuthenticateResp, error) {
+	if cln.verifyLocally(authorization) {
+		return cln.authenticateLocal(ctx, authorization)
+	}
+
 	endpoint := fmt.Sprintf("%s/v1/auth/authenticate", cln.url)
 
 	headers := map[string]string{
@@ -84,8 +107,13 @@ func (cln *Client) Authenticate(ctx context.Context, authorization string) (Auth
 	return resp, nil
 }
 
-// Authorize calls the auth service to authorize the user.
+// Authorize authorizes the user. The rules are evaluated locally when local
+// verification is enabled, otherwise the auth service is called.
 func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
+	if cln.auth != nil {
+		return cln.authorizeLocal(ctx, auth)
+	}
+
 	endpoint := fmt.Sprintf("%s/v1/auth/authorize", cln.url)
 
 	if err := cln.do(ctx, http.MethodPost, endpoint, nil, auth, nil); err != nil {
@@ -95,6 +123,90 @@ func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
 	return nil
 }
 
+// JWKS calls the auth service for the public keys that verify tokens.
+func (cln *Client) JWKS(ctx context.Context) (auth.JWKS, error) {
+	endpoint := fmt.Sprintf("%s/.well-known/jwks.json", cln.url)
+
+	var resp auth.JWKS
+	if err := cln.do(ctx, http.MethodGet, endpoint, nil, nil, &resp); err != nil {
+		return auth.JWKS{}, err
+	}
+
+	return resp, nil
+}
+
+// verifyLocally reports whether the token can be verified in-process, which
+// requires local verification and a key set that knows the token's kid.
+func (cln *Client) verifyLocally(authorization string) bool {
+	if cln.auth == nil {
+		return false
+	}
+
+	tokenStr, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found {
+		return false
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenStr, &auth.Claims{})
+	if err != nil {
+		return false
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return false
+	}
+
+	return cln.keys.Known(kid)
+}
+
+func (cln *Client) authenticateLocal(ctx context.Context, authorization string) (AuthenticateResp, error) {
+	ctx, span := otel.AddSpan(ctx, "app.sdk.authclient.authenticatelocal")
+	defer span.End()
+
+	claims, err := cln.auth.Authenticate(ctx, authorization)
+	if err != nil {
+		return AuthenticateResp{}, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return AuthenticateResp{}, fmt.Errorf("parse subject: %w", err)
+	}
+
+	resp := AuthenticateResp{
+		UserID: userID,
+		Claims: claims,
+	}
+
+	return resp, nil
+}
+
+func (cln *Client) authorizeLocal(ctx context.Context, authz Authorize) error {
+	ctx, span := otel.AddSpan(ctx, "app.sdk.authclient.authorizelocal")
+	defer span.End()
+
+	var err error
+	switch authz.Rule {
+	case auth.RuleAdminOrLocation:
+		err = cln.auth.AuthorizeLocation(ctx, authz.Claims, authz.LocationID, authz.Locations, authz.Rule)
+	case auth.RulePermission:
+		err = cln.auth.AuthorizePermission(ctx, authz.Claims, authz.Permission)
+	case auth.RulePermissionOrSubject:
+		err = cln.auth.AuthorizePermissionOrSubject(ctx, authz.Claims, authz.UserID, authz.Permission)
+	case auth.RulePermissionOrLocation:
+		err = cln.auth.AuthorizePermissionOrLocation(ctx, authz.Claims, authz.LocationID, authz.Locations, authz.Permission)
+	default:
+		err = cln.auth.Authorize(ctx, authz.Claims, authz.UserID, authz.Rule)
+	}
+
+	if err != nil {
+		return fmt.Errorf("authorize: you are not authorized for that action, claims[%v] rule[%v]: %w", authz.Claims.Roles, authz.Rule, err)
+	}
+
+	return nil
+}
+
 func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) error {
 	var statusCode int
 
@@ -0,0 +1,233 @@
+package authclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/app/sdk/authclient"
+	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/foundation/keystore"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+var algs = []string{keystore.AlgRS256, keystore.AlgES256, keystore.AlgEdDSA}
+
+func Test_LocalVerify(t *testing.T) {
+	log := newUnit(t)
+
+	ks := keystore.New()
+	for _, alg := range algs {
+		privatePEM, err := keystore.GeneratePrivatePEM(alg)
+		if err != nil {
+			t.Fatalf("Should be able to generate a %s key: %s", alg, err)
+		}
+
+		doc, err := json.Marshal(map[string]string{"key": alg, "pem": string(privatePEM)})
+		if err != nil {
+			t.Fatalf("Should be able to encode the %s key: %s", alg, err)
+		}
+
+		if _, err := ks.LoadByJSON(string(doc)); err != nil {
+			t.Fatalf("Should be able to load the %s key: %s", alg, err)
+		}
+	}
+
+	issuer, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the issuing authenticator: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// The auth service publishes every key but one, which it keeps back to
+	// check unknown keys are sent to the service.
+
+	const unpublished = keystore.AlgEdDSA
+
+	var remoteCalls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		set, err := issuer.JWKS()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var keys []auth.JWK
+		for _, key := range set.Keys {
+			if key.KID != unpublished {
+				keys = append(keys, key)
+			}
+		}
+
+		json.NewEncoder(w).Encode(auth.JWKS{Keys: keys})
+	})
+	mux.HandleFunc("GET /v1/auth/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		remoteCalls.Add(1)
+
+		claims, err := issuer.Authenticate(r.Context(), r.Header.Get("authorization"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(authclient.AuthenticateResp{
+			UserID: uuid.MustParse(claims.Subject),
+			Claims: claims,
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// -------------------------------------------------------------------------
+
+	keys := authclient.NewKeySet(log, authclient.New(log, srv.URL), time.Hour)
+	defer keys.Shutdown()
+
+	if got := len(keys.PublicKeys()); got != len(algs)-1 {
+		t.Fatalf("Should fetch the published keys: got %d, exp %d", got, len(algs)-1)
+	}
+
+	ath, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: keys,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the verifying authenticator: %s", err)
+	}
+
+	cln := authclient.New(log, srv.URL, authclient.WithLocalVerify(ath, keys))
+
+	userID := uuid.New()
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "service project",
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []string{role.User.String()},
+	}
+
+	for _, alg := range algs {
+		t.Run(alg, func(t *testing.T) {
+			token, err := issuer.GenerateToken(alg, claims)
+			if err != nil {
+				t.Fatalf("Should be able to generate a JWT : %s", err)
+			}
+
+			before := remoteCalls.Load()
+
+			resp, err := cln.Authenticate(context.Background(), "Bearer "+token)
+			if err != nil {
+				t.Fatalf("Should be able to authenticate the claims : %s", err)
+			}
+
+			if resp.UserID != userID {
+				t.Errorf("Should get back the user id: got %s, exp %s", resp.UserID, userID)
+			}
+
+			remote := remoteCalls.Load() - before
+			switch alg {
+			case unpublished:
+				if remote != 1 {
+					t.Errorf("Should call the auth service for an unknown key: got %d calls", remote)
+				}
+			default:
+				if remote != 0 {
+					t.Errorf("Should verify a known key locally: got %d calls", remote)
+				}
+			}
+		})
+	}
+
+	t.Run("forged", func(t *testing.T) {
+		forger, err := auth.New(auth.Config{
+			Log:       log,
+			KeyLookup: newForgedKeys(t),
+			Issuer:    "service project",
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create the forging authenticator: %s", err)
+		}
+
+		token, err := forger.GenerateToken(keystore.AlgRS256, claims)
+		if err != nil {
+			t.Fatalf("Should be able to generate a JWT : %s", err)
+		}
+
+		if _, err := cln.Authenticate(context.Background(), "Bearer "+token); err == nil {
+			t.Fatal("Should not authenticate a token signed by another key")
+		}
+	})
+
+	t.Run("authorize", func(t *testing.T) {
+		authz := authclient.Authorize{
+			UserID: userID,
+			Claims: claims,
+			Rule:   auth.RuleUserOnly,
+		}
+
+		if err := cln.Authorize(context.Background(), authz); err != nil {
+			t.Errorf("Should be authorized as a user : %s", err)
+		}
+
+		authz.Rule = auth.RuleAdminOnly
+		if err := cln.Authorize(context.Background(), authz); err == nil {
+			t.Error("Should not be authorized as an admin")
+		}
+	})
+}
+
+// =============================================================================
+
+func newForgedKeys(t *testing.T) *keystore.KeyStore {
+	privatePEM, err := keystore.GeneratePrivatePEM(keystore.AlgRS256)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key: %s", err)
+	}
+
+	doc, err := json.Marshal(map[string]string{"key": keystore.AlgRS256, "pem": string(privatePEM)})
+	if err != nil {
+		t.Fatalf("Should be able to encode the key: %s", err)
+	}
+
+	ks := keystore.New()
+	if _, err := ks.LoadByJSON(string(doc)); err != nil {
+		t.Fatalf("Should be able to load the key: %s", err)
+	}
+
+	return ks
+}
+
+func newUnit(t *testing.T) *logger.Logger {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "00000000-0000-0000-0000-000000000000" })
+
+	t.Cleanup(func() {
+		t.Helper()
+
+		fmt.Println("******************** LOGS ********************")
+		fmt.Print(buf.String())
+		fmt.Println("******************** LOGS ********************")
+	})
+
+	return log
+}
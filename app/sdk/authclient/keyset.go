@@ -0,0 +1,188 @@
+package authclient
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// minRefresh limits how often an unknown kid can trigger a refresh, so a
+// flood of tokens with made up kids can't hammer the auth service.
+const minRefresh = 30 * time.Second
+
+// KeySet implements the auth.KeyLookup interface with the public keys
+// published by the auth service. The keys are refreshed in the background.
+// It can only verify tokens, it never holds private keys.
+type KeySet struct {
+	log         *logger.Logger
+	cln         *Client
+	mu          sync.RWMutex
+	keys        map[string]string
+	lastRefresh time.Time
+	refreshing  bool
+	shutdown    chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewKeySet constructs a key set that fetches the keys from the auth
+// service now and every interval after. A failed fetch is logged and the
+// keys already known are kept.
+func NewKeySet(log *logger.Logger, cln *Client, interval time.Duration) *KeySet {
+	ks := KeySet{
+		log:      log,
+		cln:      cln,
+		keys:     make(map[string]string),
+		shutdown: make(chan struct{}),
+	}
+
+	ks.refresh(context.Background())
+
+	ks.wg.Add(1)
+	go func() {
+		defer ks.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ks.refresh(context.Background())
+			case <-ks.shutdown:
+				return
+			}
+		}
+	}()
+
+	return &ks
+}
+
+// Shutdown stops the background refresh.
+func (ks *KeySet) Shutdown() {
+	close(ks.shutdown)
+	ks.wg.Wait()
+}
+
+// Known reports whether the key set holds the kid. An unknown kid triggers
+// a background refresh in case the auth service rotated its keys.
+func (ks *KeySet) Known(kid string) bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[kid]; exists {
+		return true
+	}
+
+	if !ks.refreshing && time.Since(ks.lastRefresh) > minRefresh {
+		ks.refreshing = true
+
+		ks.wg.Add(1)
+		go func() {
+			defer ks.wg.Done()
+			ks.refresh(context.Background())
+		}()
+	}
+
+	return false
+}
+
+// PrivateKey implements the auth interface. Private keys are never held.
+func (ks *KeySet) PrivateKey(kid string) (string, error) {
+	return "", errors.New("private keys are not available")
+}
+
+// PublicKey implements the auth interface.
+func (ks *KeySet) PublicKey(kid string) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, exists := ks.keys[kid]
+	if !exists {
+		return "", errors.New("kid lookup failed")
+	}
+
+	return key, nil
+}
+
+// PublicKeys implements the auth interface.
+func (ks *KeySet) PublicKeys() map[string]string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	keys := make(map[string]string, len(ks.keys))
+	for kid, key := range ks.keys {
+		keys[kid] = key
+	}
+
+	return keys
+}
+
+// ActiveKID implements the auth interface. Tokens are never signed here.
+func (ks *KeySet) ActiveKID() string {
+	return ""
+}
+
+func (ks *KeySet) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	keys, err := ks.fetch(ctx)
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.lastRefresh = time.Now()
+	ks.refreshing = false
+
+	if err != nil {
+		ks.log.Error(ctx, "authclient: keyset", "status", "refresh failed", "msg", err)
+		return
+	}
+
+	ks.keys = keys
+}
+
+func (ks *KeySet) fetch(ctx context.Context) (map[string]string, error) {
+	jwks, err := ks.cln.JWKS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]string, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		publicPEM, err := toPublicPEM(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("kid[%s]: %w", jwk.KID, err)
+		}
+		keys[jwk.KID] = publicPEM
+	}
+
+	return keys, nil
+}
+
+// toPublicPEM rebuilds the public key described by the JWK in PEM form.
+func toPublicPEM(jwk auth.JWK) (string, error) {
+	publicKey, err := jwk.PublicKey()
+	if err != nil {
+		return "", err
+	}
+
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("marshaling public key: %w", err)
+	}
+
+	publicBlock := pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: asn1Bytes,
+	}
+
+	return string(pem.EncodeToMemory(&publicBlock)), nil
+}
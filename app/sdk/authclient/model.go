@@ -8,10 +8,15 @@ import (
 )
 
 // Authorize defines the information required to perform an authorization.
+// Permission names the permission needed when the rule is the permission
+// rule.
 type Authorize struct {
-	UserID uuid.UUID
-	Claims auth.Claims
-	Rule   string
+	UserID     uuid.UUID
+	Claims     auth.Claims
+	Rule       string
+	LocationID uuid.UUID
+	Locations  []uuid.UUID
+	Permission string
 }
 
 // Decode implements the decoder interface.
@@ -2,10 +2,13 @@ package mid
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
+	"net"
 	"net/http"
 	"net/mail"
+	"net/url"
 	"strings"
 	"time"
 
@@ -17,12 +20,14 @@ import (
 	"github.com/rmsj/service/app/sdk/errs"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/business/types/role"
 	"github.com/rmsj/service/foundation/web"
 )
 
 // Authenticate is a middleware function that integrates with an authentication client
 // to validate user credentials and attach user data to the request context.
+// The authorization header may carry a bearer token or a personal API key.
 func Authenticate(client *authclient.Client) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
@@ -46,7 +51,8 @@ func Authenticate(client *authclient.Client) web.MidFunc {
 	return m
 }
 
-// Bearer processes JWT authentication logic.
+// Bearer processes JWT authentication logic. A personal API key sent as
+// ApiKey <key> is accepted in place of the token.
 func Bearer(ath *auth.Auth) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
@@ -77,7 +83,7 @@ func Bearer(ath *auth.Auth) web.MidFunc {
 }
 
 // Basic processes basic authentication logic.
-func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
+func Basic(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
 			email, pass, ok := parseBasicAuth(r.Header.Get("authorization"))
@@ -85,21 +91,16 @@ func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 				return errs.Newf(errs.Unauthenticated, "invalid Basic auth")
 			}
 
-			addr, err := mail.ParseAddress(email)
-			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
-			}
-
-			usr, err := userBus.Authenticate(ctx, *addr, pass)
-			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
+			usr, errEnc := authenticateUser(ctx, authBus, userBus, r, email, pass)
+			if errEnc != nil {
+				return errEnc
 			}
 
 			claims := auth.Claims{
 				RegisteredClaims: jwt.RegisteredClaims{
 					Subject:   usr.ID.String(),
 					Issuer:    ath.Issuer(),
-					ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
+					ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(auth.MaxTokenLifetime)),
 					IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 				},
 				Roles: role.ParseToString(usr.Roles),
@@ -123,7 +124,7 @@ func Basic(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 }
 
 // Login processes username/password auth logic
-func Login(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
+func Login(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business) web.MidFunc {
 
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
@@ -133,83 +134,107 @@ func Login(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
 				return errs.New(errs.InvalidArgument, err)
 			}
 
-			addr, err := mail.ParseAddress(app.Email)
-			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
+			usr, errEnc := authenticateUser(ctx, authBus, userBus, r, app.Email, app.Password)
+			if errEnc != nil {
+				return errEnc
 			}
 
-			usr, err := userBus.Authenticate(ctx, *addr, app.Password)
-			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
+			ctx = setUserID(ctx, usr.ID)
+			ctx = setClaims(ctx, loginClaims(ath, usr, GetTime(ctx)))
+
+			return next(ctx, r)
+		}
+		return h
+	}
+
+	return m
+}
+
+// LoginMFA processes the second step of a login, exchanging a multi-factor
+// challenge and code for the user's claims.
+func LoginMFA(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			var app auth.LoginMFA
+			if err := web.Decode(r, &app); err != nil {
+				return errs.New(errs.InvalidArgument, err)
 			}
 
-			now := GetTime(ctx)
+			ip := ClientIP(r)
 
-			claims := auth.Claims{
-				RegisteredClaims: jwt.RegisteredClaims{
-					Subject:   usr.ID.String(),
-					Issuer:    ath.Issuer(),
-					ExpiresAt: jwt.NewNumericDate(now.UTC().Add(8 * time.Hour)),
-					IssuedAt:  jwt.NewNumericDate(now.UTC()),
-				},
-				Roles: role.ParseToString(usr.Roles),
+			if err := authBus.CheckLoginClient(ctx, ip); err != nil {
+				if errors.Is(err, authbus.ErrClientLocked) {
+					return errs.New(errs.TooManyRequests, authbus.ErrClientLocked)
+				}
+				return errs.New(errs.Internal, err)
 			}
 
-			subjectID, err := uuid.Parse(claims.Subject)
+			userID, err := authBus.CompleteMFAChallenge(ctx, app.Challenge, app.Code)
 			if err != nil {
-				return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+				switch {
+				case errors.Is(err, authbus.ErrInvalidMFACode):
+					if _, err := authBus.RecordLoginFailure(ctx, ip); err != nil {
+						return errs.New(errs.Internal, err)
+					}
+					return errs.New(errs.Unauthenticated, authbus.ErrInvalidMFACode)
+
+				case errors.Is(err, authbus.ErrNotFound),
+					errors.Is(err, authbus.ErrMFAChallengeExpired),
+					errors.Is(err, authbus.ErrMFANotEnrolled):
+					return errs.New(errs.Unauthenticated, errInvalidChallenge)
+				}
+
+				return errs.New(errs.Internal, err)
 			}
 
-			ctx = setUserID(ctx, subjectID)
-			ctx = setClaims(ctx, claims)
+			usr, err := userBus.QueryByID(ctx, userID)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			ctx = setUserID(ctx, usr.ID)
+			ctx = setClaims(ctx, loginClaims(ath, usr, GetTime(ctx)))
 
 			return next(ctx, r)
 		}
+
 		return h
 	}
 
 	return m
 }
 
-// RefreshToken processes refresh token auth logic
-func RefreshToken(ath *auth.Auth, userBus *userbus.Business) web.MidFunc {
+// RefreshToken exchanges a refresh token for a new one in the same session
+// family. The access token is not required since it has usually expired by
+// the time the client needs to refresh it.
+func RefreshToken(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
-			// to refresh the token, the current one must still be valid
-			claims, err := ath.Authenticate(ctx, r.Header.Get("authorization"))
-			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
-			}
-
-			if claims.Subject == "" {
-				return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, no claims")
-			}
-
-			subjectID, err := uuid.Parse(claims.Subject)
-			if err != nil {
-				return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
-			}
-
 			var app auth.RefreshToken
 			if err := web.Decode(r, &app); err != nil {
 				return errs.New(errs.InvalidArgument, err)
 			}
 
-			usr, err := userBus.QueryByRefreshToken(ctx, app.Token)
+			sess, token, err := authBus.RotateSession(ctx, app.Token, r.UserAgent(), ClientIP(r))
 			if err != nil {
-				return errs.New(errs.Unauthenticated, err)
+				if errors.Is(err, authbus.ErrInvalidSession) || errors.Is(err, authbus.ErrSessionReused) {
+					return errs.New(errs.Unauthenticated, err)
+				}
+				return errs.Newf(errs.Internal, "rotatesession: %s", err)
 			}
 
-			if usr.ID != subjectID {
-				return errs.New(errs.Unauthenticated, errors.New("invalid token"))
+			usr, err := userBus.QueryByID(ctx, sess.UserID)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
 			}
-			ctx = setUserID(ctx, usr.ID)
 
-			now := GetTime(ctx)
-			claims.ExpiresAt = jwt.NewNumericDate(now.UTC().Add(8 * time.Hour))
-			claims.IssuedAt = jwt.NewNumericDate(now.UTC())
+			if !usr.Enabled {
+				return errs.Newf(errs.Unauthenticated, "user disabled")
+			}
 
-			ctx = setClaims(ctx, claims)
+			ctx = setUserID(ctx, usr.ID)
+			ctx = setClaims(ctx, loginClaims(ath, usr, GetTime(ctx)))
+			ctx = setRefreshToken(ctx, token)
 
 			return next(ctx, r)
 		}
@@ -249,12 +274,36 @@ func ResetToken(authBus *authbus.Business, userBus *userbus.Business) web.MidFun
 	return m
 }
 
-// APIKey processes API key authentication logic.
+// APIKey processes API key authentication logic. A bearer token issued to a
+// client is accepted in place of the key. The shared key has no identity or
+// scopes and is only kept as a deprecated fallback for integrations that
+// haven't registered a client yet.
 func APIKey(ath *auth.Auth) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if bearer := r.Header.Get("authorization"); bearer != "" {
+				claims, err := ath.Authenticate(ctx, bearer)
+				if err != nil {
+					return errs.New(errs.Unauthenticated, err)
+				}
+
+				if !claims.IsClient() {
+					return errs.Newf(errs.Unauthenticated, "authorize: token was not issued to a client")
+				}
+
+				clientID, err := uuid.Parse(claims.ClientID)
+				if err != nil {
+					return errs.Newf(errs.Unauthenticated, "parsing client: %s", err)
+				}
+
+				ctx = setUserID(ctx, clientID)
+				ctx = setClaims(ctx, claims)
+
+				return next(ctx, r)
+			}
+
 			authKey := r.Header.Get("hg-api-key")
-			if authKey != ath.APIKey() {
+			if authKey == "" || subtle.ConstantTimeCompare([]byte(authKey), []byte(ath.APIKey())) != 1 {
 				return errs.New(errs.Unauthenticated, errs.Newf(errs.Unauthenticated, "invalid API Key"))
 			}
 
@@ -267,6 +316,224 @@ func APIKey(ath *auth.Auth) web.MidFunc {
 	return m
 }
 
+// ClientCredentials processes the OAuth2 client credentials grant. The client
+// authenticates with HTTP Basic or the client_id and client_secret form
+// values and may ask for a subset of its scopes. Failed attempts count
+// towards the lockout of the client address like failed logins do.
+func ClientCredentials(ath *auth.Auth, authBus *authbus.Business) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if err := r.ParseForm(); err != nil {
+				return errs.Newf(errs.InvalidArgument, "parse form: %s", err)
+			}
+
+			if grant := r.PostForm.Get("grant_type"); grant != "client_credentials" {
+				return errs.Newf(errs.InvalidArgument, "unsupported grant type %q", grant)
+			}
+
+			requested, err := permission.ParseMany(strings.Fields(r.PostForm.Get("scope")))
+			if err != nil {
+				return errs.Newf(errs.InvalidArgument, "scope: %s", err)
+			}
+
+			clientID, secret := parseClientCredentials(r)
+
+			clt, errEnc := authenticateClient(ctx, authBus, r, clientID, secret)
+			if errEnc != nil {
+				return errEnc
+			}
+
+			scopes, err := clt.GrantScopes(requested)
+			if err != nil {
+				if errors.Is(err, authbus.ErrInvalidScope) {
+					return errs.New(errs.InvalidArgument, err)
+				}
+				return errs.New(errs.Internal, err)
+			}
+
+			ctx = setUserID(ctx, clt.ID)
+			ctx = setClaims(ctx, clientClaims(ath, clt, scopes, GetTime(ctx)))
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// Client authenticates the calling client with its credentials, sent the same
+// way as for the client credentials grant, and requires it to hold the scope.
+// The form of the request is parsed.
+func Client(authBus *authbus.Business, scope permission.Permission) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if err := r.ParseForm(); err != nil {
+				return errs.Newf(errs.InvalidArgument, "parse form: %s", err)
+			}
+
+			clientID, secret := parseClientCredentials(r)
+
+			clt, errEnc := authenticateClient(ctx, authBus, r, clientID, secret)
+			if errEnc != nil {
+				return errEnc
+			}
+
+			if _, err := clt.GrantScopes([]permission.Permission{scope}); err != nil {
+				if errors.Is(err, authbus.ErrInvalidScope) {
+					return errs.New(errs.PermissionDenied, err)
+				}
+				return errs.New(errs.Internal, err)
+			}
+
+			ctx = setUserID(ctx, clt.ID)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// errInvalidCredentials is returned for every failed login so the response
+// never reveals whether an email is registered or an account is locked.
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// errInvalidChallenge is returned when an MFA challenge is unknown, expired or
+// was discarded after too many bad codes.
+var errInvalidChallenge = errors.New("invalid or expired mfa challenge")
+
+// loginClaims constructs the claims issued to a user after a login.
+func loginClaims(ath *auth.Auth, usr userbus.User, now time.Time) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   usr.ID.String(),
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(now.UTC().Add(8 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.UTC()),
+		},
+		Roles: role.ParseToString(usr.Roles),
+	}
+}
+
+// clientClaims constructs the claims issued to a client for the granted
+// scopes. The client is the subject of the token.
+func clientClaims(ath *auth.Auth, clt authbus.Client, scopes []permission.Permission, now time.Time) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clt.ID.String(),
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(now.UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.UTC()),
+		},
+		ClientID: clt.ID.String(),
+		Scope:    strings.Join(permission.ParseToString(scopes), " "),
+	}
+}
+
+// authenticateClient verifies the client credentials, tracking failed
+// attempts for the client address the same way authenticateUser does.
+func authenticateClient(ctx context.Context, authBus *authbus.Business, r *http.Request, clientID string, secret string) (authbus.Client, *errs.Error) {
+	ip := ClientIP(r)
+
+	if err := authBus.CheckLoginClient(ctx, ip); err != nil {
+		if errors.Is(err, authbus.ErrClientLocked) {
+			return authbus.Client{}, errs.New(errs.TooManyRequests, authbus.ErrClientLocked)
+		}
+		return authbus.Client{}, errs.New(errs.Internal, err)
+	}
+
+	cltID, err := uuid.Parse(clientID)
+	if err != nil {
+		if _, err := authBus.RecordLoginFailure(ctx, ip); err != nil {
+			return authbus.Client{}, errs.New(errs.Internal, err)
+		}
+		return authbus.Client{}, errs.New(errs.Unauthenticated, authbus.ErrInvalidClient)
+	}
+
+	clt, err := authBus.AuthenticateClient(ctx, cltID, secret)
+	if err != nil {
+		if errors.Is(err, authbus.ErrInvalidClient) {
+			if _, err := authBus.RecordLoginFailure(ctx, ip); err != nil {
+				return authbus.Client{}, errs.New(errs.Internal, err)
+			}
+			return authbus.Client{}, errs.New(errs.Unauthenticated, authbus.ErrInvalidClient)
+		}
+		return authbus.Client{}, errs.New(errs.Internal, err)
+	}
+
+	return clt, nil
+}
+
+// authenticateUser verifies the credentials, tracking failed attempts for the
+// client address. The client is rejected while it is locked out and every
+// credential failure returns the same message.
+func authenticateUser(ctx context.Context, authBus *authbus.Business, userBus *userbus.Business, r *http.Request, email string, password string) (userbus.User, *errs.Error) {
+	ip := ClientIP(r)
+
+	if err := authBus.CheckLoginClient(ctx, ip); err != nil {
+		if errors.Is(err, authbus.ErrClientLocked) {
+			return userbus.User{}, errs.New(errs.TooManyRequests, authbus.ErrClientLocked)
+		}
+		return userbus.User{}, errs.New(errs.Internal, err)
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return userbus.User{}, errs.New(errs.Unauthenticated, errInvalidCredentials)
+	}
+
+	usr, err := userBus.Authenticate(ctx, *addr, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound),
+			errors.Is(err, userbus.ErrAuthenticationFailure),
+			errors.Is(err, userbus.ErrAccountLocked):
+
+			if _, err := authBus.RecordLoginFailure(ctx, ip); err != nil {
+				return userbus.User{}, errs.New(errs.Internal, err)
+			}
+
+			return userbus.User{}, errs.New(errs.Unauthenticated, errInvalidCredentials)
+		}
+
+		return userbus.User{}, errs.New(errs.Internal, err)
+	}
+
+	return usr, nil
+}
+
+// ClientIP returns the address of the client making the request. Behind a
+// proxy this is only the real client when the ClientAddr middleware is
+// configured with the proxy as trusted; otherwise it's the proxy address.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// parseClientCredentials returns the client credentials sent with HTTP Basic,
+// falling back to the client_id and client_secret form values. The form must
+// have been parsed.
+func parseClientCredentials(r *http.Request) (string, string) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	// Basic credentials of OAuth2 clients are form encoded first.
+	clientID, _ = url.QueryUnescape(clientID)
+	secret, _ = url.QueryUnescape(secret)
+
+	return clientID, secret
+}
+
 func parseBasicAuth(auth string) (string, string, bool) {
 	parts := strings.Split(auth, " ")
 	if len(parts) != 2 || parts[0] != "Basic" {
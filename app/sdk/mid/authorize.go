@@ -8,15 +8,43 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/types/permission"
 	"github.com/rmsj/service/foundation/web"
 )
 
 // ErrInvalidID represents a condition where the id is not a uuid.
 var ErrInvalidID = errors.New("ID is not in its proper form")
 
+// ErrImpersonated is returned for requests an admin may not make while
+// impersonating a user.
+var ErrImpersonated = errors.New("not allowed while impersonating a user")
+
+// DenyImpersonation rejects the request when it's made by an admin
+// impersonating the user. It guards changes to accounts, credentials and
+// roles, which must be made by the user or by the admin as themselves.
+func DenyImpersonation() web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if IsImpersonated(ctx) {
+				return errs.New(errs.PermissionDenied, ErrImpersonated)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
 // Authorize validates authorization via the auth service.
 func Authorize(client *authclient.Client, rule string) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
@@ -48,11 +76,78 @@ func Authorize(client *authclient.Client, rule string) web.MidFunc {
 	return m
 }
 
+// AuthorizePermission validates via the auth service that the user holds the
+// specified permission, granted directly or by one of their roles.
+func AuthorizePermission(client *authclient.Client, perm permission.Permission) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			authz := authclient.Authorize{
+				Claims:     GetClaims(ctx),
+				UserID:     userID,
+				Rule:       auth.RulePermission,
+				Permission: perm.String(),
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			if err := client.Authorize(ctx, authz); err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeLocal validates authorization by evaluating the rule in-process,
+// for use by the auth service itself.
+func AuthorizeLocal(ath *auth.Auth, rule string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			if err := ath.Authorize(ctx, GetClaims(ctx), userID, rule); err != nil {
+				return errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", GetClaims(ctx).Roles, rule, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
 // AuthorizeUser executes the specified role and extracts the specified
 // user from the DB if a user id is specified in the call. Depending on the rule
 // specified, the userid from the claims may be compared with the specified
 // user id.
 func AuthorizeUser(client *authclient.Client, userBus *userbus.Business, rule string) web.MidFunc {
+	return authorizeUser(client, userBus, rule, "")
+}
+
+// AuthorizeUserPermission extracts the user like AuthorizeUser and validates
+// the caller holds the permission. With RulePermissionOrSubject the user can
+// also act on their own account without it.
+func AuthorizeUserPermission(client *authclient.Client, userBus *userbus.Business, rule string, perm permission.Permission) web.MidFunc {
+	return authorizeUser(client, userBus, rule, perm.String())
+}
+
+func authorizeUser(client *authclient.Client, userBus *userbus.Business, rule string, perm string) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
 			id := web.Param(r, "user_id")
@@ -83,9 +178,152 @@ func AuthorizeUser(client *authclient.Client, userBus *userbus.Business, rule st
 			defer cancel()
 
 			auth := authclient.Authorize{
-				Claims: GetClaims(ctx),
-				UserID: userID,
-				Rule:   rule,
+				Claims:     GetClaims(ctx),
+				UserID:     userID,
+				Rule:       rule,
+				Permission: perm,
+			}
+
+			if err := client.Authorize(ctx, auth); err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeProduct executes the specified role and extracts the specified
+// product from the DB if a product id is specified in the call. Depending on
+// the rule specified, the userid from the claims may be compared with the
+// user that owns the product.
+func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business, rule string) web.MidFunc {
+	return authorizeProduct(client, productBus, rule, "")
+}
+
+// AuthorizeProductPermission extracts the product like AuthorizeProduct and
+// validates the caller holds the permission. With RulePermissionOrSubject the
+// user who owns the product can also act on it without it.
+func AuthorizeProductPermission(client *authclient.Client, productBus *productbus.Business, rule string, perm permission.Permission) web.MidFunc {
+	return authorizeProduct(client, productBus, rule, perm.String())
+}
+
+func authorizeProduct(client *authclient.Client, productBus *productbus.Business, rule string, perm string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			id := web.Param(r, "product_id")
+
+			var userID uuid.UUID
+
+			if id != "" {
+				productID, err := uuid.Parse(id)
+				if err != nil {
+					return errs.New(errs.Unauthenticated, ErrInvalidID)
+				}
+
+				prd, err := productBus.QueryByID(ctx, productID)
+				if err != nil {
+					switch {
+					case errors.Is(err, productbus.ErrNotFound):
+						return errs.New(errs.Unauthenticated, err)
+					default:
+						return errs.Newf(errs.Unauthenticated, "querybyid: productID[%s]: %s", productID, err)
+					}
+				}
+
+				userID = prd.CreatedBy
+				ctx = setProduct(ctx, prd)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			auth := authclient.Authorize{
+				Claims:     GetClaims(ctx),
+				UserID:     userID,
+				Rule:       rule,
+				Permission: perm,
+			}
+
+			if err := client.Authorize(ctx, auth); err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeSale executes the specified rule and extracts the specified sale
+// from the DB if a sale id is specified in the call. Depending on the rule
+// specified, the location of the sale may be compared with the locations the
+// user from the claims is assigned to.
+func AuthorizeSale(client *authclient.Client, saleBus *salebus.Business, locationBus *locationbus.Business, rule string) web.MidFunc {
+	return authorizeSale(client, saleBus, locationBus, rule, "")
+}
+
+// AuthorizeSalePermission extracts the sale like AuthorizeSale and validates
+// the caller holds the permission. With RulePermissionOrLocation a user
+// assigned to the location of the sale can also act on it without it.
+func AuthorizeSalePermission(client *authclient.Client, saleBus *salebus.Business, locationBus *locationbus.Business, rule string, perm permission.Permission) web.MidFunc {
+	return authorizeSale(client, saleBus, locationBus, rule, perm.String())
+}
+
+func authorizeSale(client *authclient.Client, saleBus *salebus.Business, locationBus *locationbus.Business, rule string, perm string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			id := web.Param(r, "sale_id")
+
+			var locationID uuid.UUID
+
+			if id != "" {
+				saleID, err := uuid.Parse(id)
+				if err != nil {
+					return errs.New(errs.Unauthenticated, ErrInvalidID)
+				}
+
+				sl, err := saleBus.QueryByID(ctx, saleID)
+				if err != nil {
+					switch {
+					case errors.Is(err, salebus.ErrNotFound):
+						return errs.New(errs.Unauthenticated, err)
+					default:
+						return errs.Newf(errs.Unauthenticated, "querybyid: saleID[%s]: %s", saleID, err)
+					}
+				}
+
+				locationID = sl.LocationID
+				ctx = setSale(ctx, sl)
+			}
+
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			locations, err := locationBus.QueryIDsByUser(ctx, userID)
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "queryidsbyuser: userID[%s]: %s", userID, err)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			auth := authclient.Authorize{
+				Claims:     GetClaims(ctx),
+				UserID:     userID,
+				Rule:       rule,
+				LocationID: locationID,
+				Locations:  locations,
+				Permission: perm,
 			}
 
 			if err := client.Authorize(ctx, auth); err != nil {
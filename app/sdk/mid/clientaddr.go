@@ -0,0 +1,111 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/rmsj/service/foundation/web"
+)
+
+// ParseTrustedProxies parses a list of addresses and CIDR ranges into the
+// prefixes accepted by ClientAddr. A single address is trusted on its own.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("parse proxy[%s]: %w", proxy, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy[%s]: %w", proxy, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// ClientAddr replaces the remote address of requests forwarded by a trusted
+// proxy with the client address taken from the X-Forwarded-For header. The
+// header is only honoured when the peer is a trusted proxy, and it is read
+// from the right so a client can't choose its own address by sending the
+// header itself. With no trusted proxies the peer address is always used.
+func ClientAddr(trusted []netip.Prefix) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if len(trusted) > 0 {
+				r.RemoteAddr = forwardedFor(r, trusted)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// forwardedFor walks the X-Forwarded-For hops from the closest to the
+// furthest and returns the first one that isn't a trusted proxy. If every
+// hop is trusted the furthest one is the client.
+func forwardedFor(r *http.Request, trusted []netip.Prefix) string {
+	client, ok := parseAddr(r.RemoteAddr)
+	if !ok || !isTrusted(client, trusted) {
+		return r.RemoteAddr
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseAddr(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+
+		client = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+func parseAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
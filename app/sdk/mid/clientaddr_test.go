@@ -0,0 +1,66 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rmsj/service/app/sdk/mid"
+	"github.com/rmsj/service/foundation/web"
+)
+
+func Test_ClientAddr(t *testing.T) {
+	trusted, err := mid.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the trusted proxies: %s", err)
+	}
+
+	table := []struct {
+		name       string
+		trusted    bool
+		remoteAddr string
+		forwarded  []string
+		exp        string
+	}{
+		{name: "untrusted-peer", trusted: true, remoteAddr: "1.2.3.4:5000", forwarded: []string{"5.6.7.8"}, exp: "1.2.3.4"},
+		{name: "no-proxies", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8"}, exp: "10.0.0.1"},
+		{name: "trusted-peer", trusted: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8"}, exp: "5.6.7.8"},
+		{name: "trusted-single", trusted: true, remoteAddr: "192.168.1.1:5000", forwarded: []string{"5.6.7.8"}, exp: "5.6.7.8"},
+		{name: "spoofed", trusted: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9, 5.6.7.8"}, exp: "5.6.7.8"},
+		{name: "proxy-chain", trusted: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8", "10.0.0.2"}, exp: "5.6.7.8"},
+		{name: "all-trusted", trusted: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"10.0.0.3, 10.0.0.2"}, exp: "10.0.0.3"},
+		{name: "no-header", trusted: true, remoteAddr: "10.0.0.1:5000", exp: "10.0.0.1"},
+		{name: "bad-hop", trusted: true, remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8, junk, 10.0.0.2"}, exp: "10.0.0.2"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var proxies = trusted
+			if !tt.trusted {
+				proxies = nil
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			var got string
+			h := mid.ClientAddr(proxies)(func(ctx context.Context, r *http.Request) web.Encoder {
+				got = mid.ClientIP(r)
+				return nil
+			})
+			h(context.Background(), r)
+
+			if got != tt.exp {
+				t.Errorf("Should get the client address: got %q, exp %q", got, tt.exp)
+			}
+		})
+	}
+
+	if _, err := mid.ParseTrustedProxies([]string{"not-an-address"}); err == nil {
+		t.Errorf("Should not be able to parse an invalid proxy")
+	}
+}
@@ -25,6 +25,8 @@ func Logger(log *logger.Logger) web.MidFunc {
 
 			log.Info(ctx, "request started", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr)
 
+			ctx, id := setIdentity(ctx)
+
 			resp := next(ctx, r)
 
 			var statusCode = errs.OK
@@ -37,6 +39,14 @@ func Logger(log *logger.Logger) web.MidFunc {
 				}
 			}
 
+			// Every request made while an admin impersonates a user is logged
+			// with both identities.
+			if id.actorID != "" {
+				log.Info(ctx, "request completed", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr,
+					"statuscode", statusCode, "since", time.Since(now).String(), "userid", id.userID, "actorid", id.actorID)
+				return resp
+			}
+
 			log.Info(ctx, "request completed", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr,
 				"statuscode", statusCode, "since", time.Since(now).String())
 
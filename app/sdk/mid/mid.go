@@ -4,13 +4,17 @@ package mid
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/role"
 	"github.com/rmsj/service/foundation/web"
 )
 
@@ -32,12 +36,33 @@ const (
 	claimKey ctxKey = iota + 1
 	userIDKey
 	userKey
+	productKey
+	saleKey
 	trKey
+	refreshTokenKey
+	actorIDKey
+	identityKey
 	timeKey ctxStringKey = "time"
 )
 
 func setClaims(ctx context.Context, claims auth.Claims) context.Context {
-	return context.WithValue(ctx, claimKey, claims)
+	ctx = context.WithValue(ctx, claimKey, claims)
+
+	if !claims.IsImpersonated() {
+		return ctx
+	}
+
+	actorID, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		return ctx
+	}
+
+	if id, ok := ctx.Value(identityKey).(*identity); ok {
+		id.userID = claims.Subject
+		id.actorID = actorID.String()
+	}
+
+	return context.WithValue(ctx, actorIDKey, actorID)
 }
 
 // GetClaims returns the claims from the context.
@@ -49,6 +74,11 @@ func GetClaims(ctx context.Context) auth.Claims {
 	return v
 }
 
+// IsAdmin reports whether the claims in the context carry the admin role.
+func IsAdmin(ctx context.Context) bool {
+	return slices.Contains(GetClaims(ctx).Roles, role.Admin.String())
+}
+
 // GetSubjectID returns the subject id from the claims.
 func GetSubjectID(ctx context.Context) uuid.UUID {
 	v := GetClaims(ctx)
@@ -75,6 +105,37 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 	return v, nil
 }
 
+// GetActorID returns the id of the admin impersonating the user from the
+// context. GetUserID still returns the impersonated user, who is the one the
+// request acts as.
+func GetActorID(ctx context.Context) (uuid.UUID, error) {
+	v, ok := ctx.Value(actorIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.UUID{}, errors.New("actor id not found in context")
+	}
+
+	return v, nil
+}
+
+// IsImpersonated reports whether the request is made by an admin
+// impersonating the user.
+func IsImpersonated(ctx context.Context) bool {
+	_, ok := ctx.Value(actorIDKey).(uuid.UUID)
+	return ok
+}
+
+// identity is filled in by setClaims when the request is impersonated so the
+// Logger, which runs before authentication, can log both identities.
+type identity struct {
+	userID  string
+	actorID string
+}
+
+func setIdentity(ctx context.Context) (context.Context, *identity) {
+	var id identity
+	return context.WithValue(ctx, identityKey, &id), &id
+}
+
 func setUser(ctx context.Context, usr userbus.User) context.Context {
 	return context.WithValue(ctx, userKey, usr)
 }
@@ -89,6 +150,48 @@ func GetUser(ctx context.Context) (userbus.User, error) {
 	return v, nil
 }
 
+func setRefreshToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, refreshTokenKey, token)
+}
+
+// GetRefreshToken returns the rotated refresh token from the context.
+func GetRefreshToken(ctx context.Context) (string, error) {
+	v, ok := ctx.Value(refreshTokenKey).(string)
+	if !ok {
+		return "", errors.New("refresh token not found in context")
+	}
+
+	return v, nil
+}
+
+func setProduct(ctx context.Context, prd productbus.Product) context.Context {
+	return context.WithValue(ctx, productKey, prd)
+}
+
+// GetProduct returns the product from the context.
+func GetProduct(ctx context.Context) (productbus.Product, error) {
+	v, ok := ctx.Value(productKey).(productbus.Product)
+	if !ok {
+		return productbus.Product{}, errors.New("product not found in context")
+	}
+
+	return v, nil
+}
+
+func setSale(ctx context.Context, sl salebus.Sale) context.Context {
+	return context.WithValue(ctx, saleKey, sl)
+}
+
+// GetSale returns the sale from the context.
+func GetSale(ctx context.Context) (salebus.Sale, error) {
+	v, ok := ctx.Value(saleKey).(salebus.Sale)
+	if !ok {
+		return salebus.Sale{}, errors.New("sale not found in context")
+	}
+
+	return v, nil
+}
+
 func setTran(ctx context.Context, tx sqldb.CommitRollbacker) context.Context {
 	return context.WithValue(ctx, trKey, tx)
 }
@@ -0,0 +1,225 @@
+package mid
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/markbates/goth"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/app/sdk/errs"
+	"github.com/rmsj/service/app/sdk/oauth"
+	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/foundation/web"
+)
+
+// oauthStateCookie holds the state of a login started with an identity
+// provider, tying the callback to the browser that started it.
+const oauthStateCookie = "oauth_state"
+
+// errInvalidOAuthState is returned for every callback that can't be matched
+// to a login that was started and not yet completed.
+var errInvalidOAuthState = errors.New("invalid or expired oauth state")
+
+// SetOAuthState sets the cookie that ties the callback of the login started
+// by the request to the browser. The callback path must be below the path of
+// the request.
+func SetOAuthState(ctx context.Context, r *http.Request, state string) {
+	http.SetCookie(web.GetWriter(ctx), &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     r.URL.Path,
+		MaxAge:   int(authbus.OAuthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// OAuth completes a login with an identity provider. The state must match the
+// cookie set when the login started and is consumed, the code is exchanged
+// with the PKCE verifier and the account is resolved to a user, linking or
+// creating one by verified email the first time.
+func OAuth(ath *auth.Auth, authBus *authbus.Business, userBus *userbus.Business, providers goth.Providers) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			provider, exists := providers[web.Param(r, "provider")]
+			if !exists {
+				return errs.Newf(errs.NotFound, "unknown provider %q", web.Param(r, "provider"))
+			}
+
+			q := r.URL.Query()
+
+			if e := q.Get("error"); e != "" {
+				return errs.Newf(errs.Unauthenticated, "provider error: %s", e)
+			}
+
+			state := q.Get("state")
+
+			cookie, err := r.Cookie(oauthStateCookie)
+			if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+				return errs.New(errs.Unauthenticated, errInvalidOAuthState)
+			}
+
+			http.SetCookie(web.GetWriter(ctx), &http.Cookie{
+				Name:   oauthStateCookie,
+				Path:   strings.TrimSuffix(r.URL.Path, "/callback"),
+				MaxAge: -1,
+			})
+
+			st, err := authBus.ConsumeOAuthState(ctx, state, provider.Name())
+			if err != nil {
+				if errors.Is(err, authbus.ErrInvalidOAuthState) {
+					return errs.New(errs.Unauthenticated, errInvalidOAuthState)
+				}
+				return errs.New(errs.Internal, err)
+			}
+
+			sess, err := provider.UnmarshalSession(st.Session)
+			if err != nil {
+				return errs.New(errs.Internal, err)
+			}
+
+			if _, err := sess.Authorize(provider, q); err != nil {
+				return errs.Newf(errs.Unauthenticated, "oauth: %s", err)
+			}
+
+			account, err := provider.FetchUser(sess)
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "oauth: %s", err)
+			}
+
+			usr, errEnc := oauthUser(ctx, authBus, userBus, provider.Name(), account)
+			if errEnc != nil {
+				return errEnc
+			}
+
+			if !usr.Enabled {
+				return errs.Newf(errs.Unauthenticated, "user disabled")
+			}
+
+			ctx = setUserID(ctx, usr.ID)
+			ctx = setClaims(ctx, loginClaims(ath, usr, GetTime(ctx)))
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// oauthUser finds the user linked to the provider account. An account that
+// isn't linked yet is linked to the user with the same email, or to a new
+// user, but only when the provider verified the email.
+func oauthUser(ctx context.Context, authBus *authbus.Business, userBus *userbus.Business, provider string, account goth.User) (userbus.User, *errs.Error) {
+	idn, err := authBus.QueryIdentity(ctx, provider, account.UserID)
+	switch {
+	case err == nil:
+		usr, err := userBus.QueryByID(ctx, idn.UserID)
+		if err != nil {
+			return userbus.User{}, errs.New(errs.Unauthenticated, err)
+		}
+
+		if _, err := authBus.UseIdentity(ctx, idn, account.Email); err != nil {
+			return userbus.User{}, errs.New(errs.Internal, err)
+		}
+
+		return usr, nil
+
+	case !errors.Is(err, authbus.ErrNotFound):
+		return userbus.User{}, errs.New(errs.Internal, err)
+	}
+
+	if !oauth.EmailVerified(account) {
+		return userbus.User{}, errs.Newf(errs.Unauthenticated, "oauth: the provider has not verified the email")
+	}
+
+	addr, err := mail.ParseAddress(account.Email)
+	if err != nil {
+		return userbus.User{}, errs.Newf(errs.Unauthenticated, "oauth: invalid email: %s", err)
+	}
+
+	usr, err := userBus.QueryByEmail(ctx, *addr)
+	if err != nil {
+		if !errors.Is(err, userbus.ErrNotFound) {
+			return userbus.User{}, errs.New(errs.Internal, err)
+		}
+
+		usr, err = createOAuthUser(ctx, userBus, *addr, account)
+		if err != nil {
+			return userbus.User{}, errs.New(errs.Internal, err)
+		}
+	}
+
+	ni := authbus.NewIdentity{
+		Provider: provider,
+		Subject:  account.UserID,
+		UserID:   usr.ID,
+		Email:    addr.Address,
+	}
+
+	if _, err := authBus.LinkIdentity(ctx, ni); err != nil {
+		if errors.Is(err, authbus.ErrIdentityLinked) {
+			return userbus.User{}, errs.New(errs.Aborted, err)
+		}
+		return userbus.User{}, errs.New(errs.Internal, err)
+	}
+
+	return usr, nil
+}
+
+// createOAuthUser adds a user for a provider account. The password is random
+// so the account can only be used with the provider until it's reset.
+func createOAuthUser(ctx context.Context, userBus *userbus.Business, addr mail.Address, account goth.User) (userbus.User, error) {
+	password, err := id.NewRandomString(32)
+	if err != nil {
+		return userbus.User{}, err
+	}
+
+	nu := userbus.NewUser{
+		Name:     oauthName(account, addr),
+		Email:    addr,
+		Roles:    []role.Role{role.User},
+		Password: password,
+	}
+
+	return userBus.Create(ctx, nu)
+}
+
+// oauthName picks a valid user name from the provider account, falling back
+// to the email.
+func oauthName(account goth.User, addr mail.Address) name.Name {
+	local, _, _ := strings.Cut(addr.Address, "@")
+
+	for _, value := range []string{account.Name, account.NickName, local} {
+		value = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '\'', r == ' ', r == '-':
+				return r
+			case r == '.' || r == '_':
+				return ' '
+			}
+			return -1
+		}, value)
+
+		if len(value) > 20 {
+			value = value[:20]
+		}
+
+		if nme, err := name.Parse(strings.TrimSpace(value)); err == nil {
+			return nme
+		}
+	}
+
+	return name.MustParse("New User")
+}
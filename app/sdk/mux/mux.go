@@ -5,15 +5,22 @@ package mux
 import (
 	"embed"
 	"net/http"
+	"net/netip"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/markbates/goth"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/rmsj/service/app/sdk/auth"
 	"github.com/rmsj/service/app/sdk/authclient"
 	"github.com/rmsj/service/app/sdk/mid"
 	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/rolebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/foundation/logger"
@@ -30,8 +37,9 @@ type StaticSite struct {
 
 // Options represent optional parameters.
 type Options struct {
-	corsOrigin []string
-	sites      []StaticSite
+	corsOrigin     []string
+	trustedProxies []netip.Prefix
+	sites          []StaticSite
 }
 
 // WithCORS provides configuration options for CORS.
@@ -41,6 +49,14 @@ func WithCORS(origins []string) func(opts *Options) {
 	}
 }
 
+// WithTrustedProxies provides the proxies allowed to report the client
+// address through the X-Forwarded-For header.
+func WithTrustedProxies(proxies []netip.Prefix) func(opts *Options) {
+	return func(opts *Options) {
+		opts.trustedProxies = proxies
+	}
+}
+
 // WithFileServer provides configuration options for file server.
 func WithFileServer(react bool, static embed.FS, dir string, path string) func(opts *Options) {
 	return func(opts *Options) {
@@ -60,14 +76,21 @@ type SalesConfig struct {
 
 // AuthConfig contains auth service specific config.
 type AuthConfig struct {
-	Auth *auth.Auth
+	Auth            *auth.Auth
+	RequireAdminMFA bool
+	OAuthProviders  goth.Providers
 }
 
 type BusConfig struct {
-	UserBus    *userbus.Business
-	AuthBus    *authbus.Business
-	ProductBus *productbus.Business
-	SaleBus    *salebus.Business
+	UserBus     *userbus.Business
+	AuthBus     *authbus.Business
+	ProductBus  *productbus.Business
+	SaleBus     *salebus.Business
+	PriceBus    *pricebus.Business
+	ImageBus    *imagebus.Business
+	RateBus     *ratebus.Business
+	LocationBus *locationbus.Business
+	RoleBus     *rolebus.Business
 }
 
 // Config contains all the mandatory systems required by handlers.
@@ -89,9 +112,15 @@ type RouteAdder interface {
 
 // WebAPI constructs a http.Handler with all application routes bound.
 func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) http.Handler {
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
 	app := web.NewApp(
 		cfg.Log.Info,
 		cfg.Tracer,
+		mid.ClientAddr(opts.trustedProxies),
 		mid.Otel(cfg.Tracer),
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
@@ -99,11 +128,6 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 		mid.Panics(),
 	)
 
-	var opts Options
-	for _, option := range options {
-		option(&opts)
-	}
-
 	if len(opts.corsOrigin) > 0 {
 		app.EnableCORS(opts.corsOrigin)
 	}
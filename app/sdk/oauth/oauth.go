@@ -0,0 +1,319 @@
+// Package oauth provides the identity providers users can log in with using
+// OAuth2 and OpenID Connect. Providers implement the goth interfaces.
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+
+	"github.com/rmsj/service/app/sdk/auth"
+)
+
+// ErrUnknownKey is returned when the ID token is signed with a key the
+// provider doesn't publish.
+var ErrUnknownKey = errors.New("id token signed with unknown key")
+
+// Config describes an OpenID Connect provider. The endpoints are discovered
+// from the issuer.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	CallbackURL  string
+	Scopes       []string
+	Client       *http.Client
+}
+
+// discovery represents the parts of the provider metadata that are used.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider implements goth.Provider for an OpenID Connect provider. Logins
+// are protected with PKCE and a nonce, and the ID token is verified with the
+// keys the provider publishes.
+type Provider struct {
+	name    string
+	issuer  string
+	jwksURI string
+	client  *http.Client
+	config  oauth2.Config
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+// NewProvider constructs a provider, discovering its endpoints.
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	client := goth.HTTPClientWithFallBack(cfg.Client)
+
+	var disc discovery
+	if err := getJSON(ctx, client, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &disc); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	if disc.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("discovery: issuer mismatch: got %q", disc.Issuer)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+
+	p := Provider{
+		name:    cfg.Name,
+		issuer:  disc.Issuer,
+		jwksURI: disc.JWKSURI,
+		client:  client,
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.CallbackURL,
+			Scopes:       append([]string{"openid"}, scopes...),
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  disc.AuthorizationEndpoint,
+				TokenURL: disc.TokenEndpoint,
+			},
+		},
+		keys: make(map[string]crypto.PublicKey),
+	}
+
+	return &p, nil
+}
+
+// Name is the name used to retrieve this provider.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// SetName changes the name of the provider.
+func (p *Provider) SetName(name string) {
+	p.name = name
+}
+
+// Debug is a no-op for this provider.
+func (p *Provider) Debug(bool) {}
+
+// BeginAuth starts a login, generating the PKCE verifier and nonce that are
+// kept in the session until the callback.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	nonce := oauth2.GenerateVerifier()
+
+	sess := Session{
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    nonce,
+	}
+
+	sess.AuthURL = p.config.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(sess.Verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+
+	return &sess, nil
+}
+
+// UnmarshalSession restores a session marshaled when the login started.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	var sess Session
+	if err := json.Unmarshal([]byte(data), &sess); err != nil {
+		return nil, fmt.Errorf("unmarshal session: %w", err)
+	}
+
+	return &sess, nil
+}
+
+// FetchUser verifies the ID token from the authorized session and returns
+// the account it describes. RawData holds the verified claims, including
+// email_verified.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess, ok := session.(*Session)
+	if !ok {
+		return goth.User{}, errors.New("invalid session type")
+	}
+
+	if sess.IDToken == "" {
+		return goth.User{}, fmt.Errorf("%s: session has no id token", p.name)
+	}
+
+	claims, err := p.verify(context.Background(), sess.IDToken, sess.Nonce)
+	if err != nil {
+		return goth.User{}, fmt.Errorf("%s: %w", p.name, err)
+	}
+
+	usr := goth.User{
+		RawData: map[string]any{
+			"sub":            claims.Subject,
+			"email":          claims.Email,
+			"email_verified": bool(claims.EmailVerified),
+			"name":           claims.Name,
+		},
+		Provider:     p.name,
+		Email:        claims.Email,
+		Name:         claims.Name,
+		UserID:       claims.Subject,
+		AccessToken:  sess.AccessToken,
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
+	}
+
+	return usr, nil
+}
+
+// RefreshTokenAvailable reports that refresh tokens are supported.
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken gets a new access token for the refresh token.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	ctx := goth.ContextForClient(p.client)
+
+	tkn, err := p.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("refresh: %w", err)
+	}
+
+	return tkn, nil
+}
+
+// EmailVerified reports whether the provider verified the email of the user.
+func EmailVerified(usr goth.User) bool {
+	verified, _ := usr.RawData["email_verified"].(bool)
+	return verified && usr.Email != ""
+}
+
+// =============================================================================
+
+// idClaims represents the claims of an ID token that are used.
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// flexBool accepts booleans sent as strings, which some providers do.
+type flexBool bool
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	*b = flexBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of the ID
+// token.
+func (p *Provider) verify(ctx context.Context, idToken string, nonce string) (idClaims, error) {
+	var claims idClaims
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}))
+
+	if _, err := parser.ParseWithClaims(idToken, &claims, keyFunc); err != nil {
+		return idClaims{}, fmt.Errorf("verify id token: %w", err)
+	}
+
+	if claims.Issuer != p.issuer {
+		return idClaims{}, fmt.Errorf("verify id token: issuer mismatch: got %q", claims.Issuer)
+	}
+
+	if !slices.Contains(claims.Audience, p.config.ClientID) {
+		return idClaims{}, errors.New("verify id token: audience mismatch")
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return idClaims{}, errors.New("verify id token: nonce mismatch")
+	}
+
+	if claims.Subject == "" {
+		return idClaims{}, errors.New("verify id token: missing subject")
+	}
+
+	return claims, nil
+}
+
+// key returns the public key for the kid, fetching the published keys again
+// when the kid is unknown since the provider may have rotated them.
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	pk, exists := p.keys[kid]
+	p.mu.RUnlock()
+
+	if exists {
+		return pk, nil
+	}
+
+	var set auth.JWKS
+	if err := getJSON(ctx, p.client, p.jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		pk, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KID] = pk
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	pk, exists = keys[kid]
+	if !exists {
+		return nil, ErrUnknownKey
+	}
+
+	return pk, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return nil
+}
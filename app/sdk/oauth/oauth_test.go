@@ -0,0 +1,279 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/rmsj/service/app/sdk/auth"
+	"github.com/rmsj/service/app/sdk/oauth"
+)
+
+func Test_Provider(t *testing.T) {
+	t.Parallel()
+
+	idp := newFakeIDP(t)
+
+	p, err := oauth.NewProvider(context.Background(), oauth.Config{
+		Name:         "fake",
+		Issuer:       idp.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		CallbackURL:  "http://localhost:6000/v1/auth/oauth/fake/callback",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to discover the provider: %s", err)
+	}
+
+	t.Run("login", func(t *testing.T) {
+		sess, challenge, nonce := begin(t, p, "state-1")
+
+		idp.authorize("code-1", challenge, idClaims(idp.URL, "client-id", nonce, true))
+
+		stored, err := p.UnmarshalSession(sess.Marshal())
+		if err != nil {
+			t.Fatalf("Should be able to unmarshal the session: %s", err)
+		}
+
+		if _, err := stored.Authorize(p, url.Values{"code": {"code-1"}}); err != nil {
+			t.Fatalf("Should be able to exchange the code: %s", err)
+		}
+
+		usr, err := p.FetchUser(stored)
+		if err != nil {
+			t.Fatalf("Should be able to fetch the user: %s", err)
+		}
+
+		if usr.UserID != "subject-1" || usr.Email != "ann@example.com" || usr.Provider != "fake" {
+			t.Fatalf("Should get the account from the id token: %+v", usr)
+		}
+
+		if !oauth.EmailVerified(usr) {
+			t.Fatalf("Should report the email as verified")
+		}
+	})
+
+	t.Run("pkce", func(t *testing.T) {
+		_, challenge, nonce := begin(t, p, "state-2")
+
+		idp.authorize("code-2", challenge, idClaims(idp.URL, "client-id", nonce, true))
+
+		// A session that didn't start the login holds a different verifier.
+		other, _, _ := begin(t, p, "state-3")
+
+		if _, err := other.Authorize(p, url.Values{"code": {"code-2"}}); err == nil {
+			t.Fatalf("Should not be able to exchange the code without the verifier")
+		}
+	})
+
+	t.Run("nonce", func(t *testing.T) {
+		sess, challenge, _ := begin(t, p, "state-4")
+
+		idp.authorize("code-4", challenge, idClaims(idp.URL, "client-id", "replayed", true))
+
+		if _, err := sess.Authorize(p, url.Values{"code": {"code-4"}}); err != nil {
+			t.Fatalf("Should be able to exchange the code: %s", err)
+		}
+
+		if _, err := p.FetchUser(sess); err == nil {
+			t.Fatalf("Should not accept an id token with another nonce")
+		}
+	})
+
+	t.Run("audience", func(t *testing.T) {
+		sess, challenge, nonce := begin(t, p, "state-5")
+
+		idp.authorize("code-5", challenge, idClaims(idp.URL, "other-client", nonce, true))
+
+		if _, err := sess.Authorize(p, url.Values{"code": {"code-5"}}); err != nil {
+			t.Fatalf("Should be able to exchange the code: %s", err)
+		}
+
+		if _, err := p.FetchUser(sess); err == nil {
+			t.Fatalf("Should not accept an id token for another client")
+		}
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		sess, challenge, nonce := begin(t, p, "state-6")
+
+		idp.authorize("code-6", challenge, idClaims(idp.URL, "client-id", nonce, false))
+
+		if _, err := sess.Authorize(p, url.Values{"code": {"code-6"}}); err != nil {
+			t.Fatalf("Should be able to exchange the code: %s", err)
+		}
+
+		usr, err := p.FetchUser(sess)
+		if err != nil {
+			t.Fatalf("Should be able to fetch the user: %s", err)
+		}
+
+		if oauth.EmailVerified(usr) {
+			t.Fatalf("Should not report the email as verified")
+		}
+	})
+}
+
+// =============================================================================
+
+// begin starts a login and returns the session along with the PKCE challenge
+// and nonce sent to the provider.
+func begin(t *testing.T, p *oauth.Provider, state string) (*oauth.Session, string, string) {
+	t.Helper()
+
+	sess, err := p.BeginAuth(state)
+	if err != nil {
+		t.Fatalf("Should be able to begin the login: %s", err)
+	}
+
+	authURL, err := sess.GetAuthURL()
+	if err != nil {
+		t.Fatalf("Should get an auth url: %s", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Should be able to parse the auth url: %s", err)
+	}
+
+	q := u.Query()
+
+	if q.Get("state") != state || q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "client-id" {
+		t.Fatalf("Should send the state and PKCE challenge: %s", authURL)
+	}
+
+	return sess.(*oauth.Session), q.Get("code_challenge"), q.Get("nonce")
+}
+
+func idClaims(issuer string, audience string, nonce string, verified bool) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "subject-1",
+		"aud":            audience,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "ann@example.com",
+		"email_verified": verified,
+		"name":           "Ann",
+	}
+}
+
+// fakeIDP is a local OpenID Connect provider. Codes are handed out with
+// authorize instead of a login page and the token endpoint checks the PKCE
+// verifier against the challenge.
+type fakeIDP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+type grant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newFakeIDP(t *testing.T) *fakeIDP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key: %s", err)
+	}
+
+	idp := fakeIDP{
+		key:   key,
+		codes: make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(auth.JWKS{
+			Keys: []auth.JWK{
+				{
+					KTY: "RSA",
+					Use: "sig",
+					Alg: "RS256",
+					KID: "fake-key",
+					N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+
+	mux.HandleFunc("/token", idp.token)
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	return &idp
+}
+
+// authorize issues the code as if the user logged in at the provider.
+func (idp *fakeIDP) authorize(code string, challenge string, claims jwt.MapClaims) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.codes[code] = grant{challenge: challenge, claims: claims}
+}
+
+func (idp *fakeIDP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	g, exists := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+	if !exists || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, g.claims)
+	tkn.Header["kid"] = "fake-key"
+
+	idToken, err := tkn.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
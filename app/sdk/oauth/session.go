@@ -0,0 +1,69 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// Session implements goth.Session. It's marshaled when a login starts and
+// kept until the provider calls back, carrying the PKCE verifier and nonce.
+type Session struct {
+	AuthURL      string    `json:"authURL"`
+	Verifier     string    `json:"verifier"`
+	Nonce        string    `json:"nonce"`
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	IDToken      string    `json:"idToken,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitzero"`
+}
+
+// GetAuthURL returns the URL the user is sent to for logging in.
+func (s *Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+
+	return s.AuthURL, nil
+}
+
+// Marshal generates the string representation of the session.
+func (s *Session) Marshal() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// Authorize exchanges the code from the callback for the tokens, proving
+// with the PKCE verifier that this session started the login.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p, ok := provider.(*Provider)
+	if !ok {
+		return "", errors.New("invalid provider type")
+	}
+
+	code := params.Get("code")
+	if code == "" {
+		return "", errors.New("missing code")
+	}
+
+	tkn, err := p.config.Exchange(goth.ContextForClient(p.client), code, oauth2.VerifierOption(s.Verifier))
+	if err != nil {
+		return "", fmt.Errorf("exchange: %w", err)
+	}
+
+	idToken, _ := tkn.Extra("id_token").(string)
+	if idToken == "" {
+		return "", errors.New("exchange: no id token returned")
+	}
+
+	s.AccessToken = tkn.AccessToken
+	s.RefreshToken = tkn.RefreshToken
+	s.ExpiresAt = tkn.Expiry
+	s.IDToken = idToken
+
+	return tkn.AccessToken, nil
+}
@@ -0,0 +1,107 @@
+// Package auditbus provides business access to the audit trail.
+package auditbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/delegate"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, audit Audit) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Business manages the set of APIs for audit access.
+type Business struct {
+	log      *logger.Logger
+	storer   Storer
+	delegate *delegate.Delegate
+}
+
+// NewBusiness constructs an audit business API for use. The business
+// registers with the delegate so events from other domains are recorded.
+func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
+	b := Business{
+		log:      log,
+		storer:   storer,
+		delegate: delegate,
+	}
+
+	b.registerDelegateFunctions()
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:      b.log,
+		storer:   storer,
+		delegate: b.delegate,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new audit record to the system.
+func (b *Business) Create(ctx context.Context, na NewAudit) (Audit, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.create")
+	defer span.End()
+
+	audit := Audit{
+		ID:        uuid.New(),
+		ObjID:     na.ObjID,
+		ObjDomain: na.ObjDomain,
+		Action:    na.Action,
+		Data:      na.Data,
+		Message:   na.Message,
+		Timestamp: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, audit); err != nil {
+		return Audit{}, fmt.Errorf("create: %w", err)
+	}
+
+	return audit, nil
+}
+
+// Query retrieves a list of existing audit records.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.query")
+	defer span.End()
+
+	audits, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return audits, nil
+}
+
+// Count returns the total number of audit records.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.auditbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
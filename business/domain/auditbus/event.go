@@ -0,0 +1,66 @@
+package auditbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/delegate"
+)
+
+// registerDelegateFunctions will register action functions with the delegate
+// system for the events this domain records.
+func (b *Business) registerDelegateFunctions() {
+	if b.delegate != nil {
+		b.delegate.Register(userbus.DomainName, userbus.ActionLocked, b.actionUserLocked)
+		b.delegate.Register(authbus.DomainName, authbus.ActionImpersonated, b.actionImpersonated)
+	}
+}
+
+// actionUserLocked records an audit entry when a user account is locked.
+func (b *Business) actionUserLocked(ctx context.Context, data delegate.Data) error {
+	var params userbus.ActionLockedParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return fmt.Errorf("expected an encoded %T: %w", params, err)
+	}
+
+	na := NewAudit{
+		ObjID:     params.UserID,
+		ObjDomain: data.Domain,
+		Action:    data.Action,
+		Data:      data.RawParams,
+		Message:   fmt.Sprintf("user locked after %d failed logins", params.FailedLogins),
+	}
+
+	if _, err := b.Create(ctx, na); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
+
+// actionImpersonated records an audit entry against the impersonated user
+// naming the admin acting as them.
+func (b *Business) actionImpersonated(ctx context.Context, data delegate.Data) error {
+	var params authbus.ActionImpersonatedParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return fmt.Errorf("expected an encoded %T: %w", params, err)
+	}
+
+	na := NewAudit{
+		ObjID:     params.UserID,
+		ObjDomain: userbus.DomainName,
+		Action:    data.Action,
+		Data:      data.RawParams,
+		Message:   fmt.Sprintf("impersonated by admin %s until %s", params.ActorID, params.ExpiresAt.UTC().Format(time.RFC3339)),
+	}
+
+	if _, err := b.Create(ctx, na); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
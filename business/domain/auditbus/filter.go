@@ -0,0 +1,11 @@
+package auditbus
+
+import "github.com/google/uuid"
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	ObjID     *uuid.UUID
+	ObjDomain *string
+	Action    *string
+}
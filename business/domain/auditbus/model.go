@@ -0,0 +1,29 @@
+package auditbus
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit represents a record of something that happened to an object in the
+// system.
+type Audit struct {
+	ID        uuid.UUID
+	ObjID     uuid.UUID
+	ObjDomain string
+	Action    string
+	Data      json.RawMessage
+	Message   string
+	Timestamp time.Time
+}
+
+// NewAudit contains information needed to create a new audit record.
+type NewAudit struct {
+	ObjID     uuid.UUID
+	ObjDomain string
+	Action    string
+	Data      json.RawMessage
+	Message   string
+}
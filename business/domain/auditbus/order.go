@@ -0,0 +1,13 @@
+package auditbus
+
+import "github.com/rmsj/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByTimestamp, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByTimestamp = "a"
+	OrderByObjID     = "b"
+	OrderByAction    = "c"
+)
@@ -0,0 +1,32 @@
+package auditdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/rmsj/service/business/domain/auditbus"
+)
+
+func (s *Store) applyFilter(filter auditbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ObjID != nil {
+		data["obj_id"] = filter.ObjID.String()
+		wc = append(wc, "obj_id = :obj_id")
+	}
+
+	if filter.ObjDomain != nil {
+		data["obj_domain"] = *filter.ObjDomain
+		wc = append(wc, "obj_domain = :obj_domain")
+	}
+
+	if filter.Action != nil {
+		data["action"] = *filter.Action
+		wc = append(wc, "action = :action")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
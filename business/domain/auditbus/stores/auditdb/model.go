@@ -0,0 +1,58 @@
+package auditdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/auditbus"
+)
+
+type audit struct {
+	ID        uuid.UUID `db:"id"`
+	ObjID     uuid.UUID `db:"obj_id"`
+	ObjDomain string    `db:"obj_domain"`
+	Action    string    `db:"action"`
+	Data      []byte    `db:"data"`
+	Message   string    `db:"message"`
+	Timestamp time.Time `db:"created_at"`
+}
+
+func toDBAudit(bus auditbus.Audit) audit {
+	db := audit{
+		ID:        bus.ID,
+		ObjID:     bus.ObjID,
+		ObjDomain: bus.ObjDomain,
+		Action:    bus.Action,
+		Data:      bus.Data,
+		Message:   bus.Message,
+		Timestamp: bus.Timestamp.UTC(),
+	}
+
+	return db
+}
+
+func toBusAudit(db audit) auditbus.Audit {
+	bus := auditbus.Audit{
+		ID:        db.ID,
+		ObjID:     db.ObjID,
+		ObjDomain: db.ObjDomain,
+		Action:    db.Action,
+		Data:      json.RawMessage(db.Data),
+		Message:   db.Message,
+		Timestamp: db.Timestamp.In(time.Local),
+	}
+
+	return bus
+}
+
+func toBusAudits(dbs []audit) []auditbus.Audit {
+	bus := make([]auditbus.Audit, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusAudit(db)
+	}
+
+	return bus
+}
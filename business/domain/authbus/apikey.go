@@ -0,0 +1,142 @@
+package authbus
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// APIKeyPrefix starts every personal API key so they are easy to recognize,
+// for instance by secret scanners.
+const APIKeyPrefix = "sk_"
+
+// apiKeyUseInterval limits how often the last use of a key is written, since
+// scripts can use a key many times a second.
+const apiKeyUseInterval = time.Minute
+
+// CreateAPIKey creates a personal API key for the user and returns it with
+// the key. The key is only available at this point. It's made of the prefix
+// used to find it and a secret, separated by a dot which the random strings
+// never contain.
+func (b *Business) CreateAPIKey(ctx context.Context, nk NewAPIKey) (APIKey, string, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.createapikey")
+	defer span.End()
+
+	prefix, err := id.NewRandomString(12)
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	secret, err := id.NewRandomString(40)
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	key := APIKeyPrefix + prefix + "." + secret
+
+	ak := APIKey{
+		ID:          uuid.New(),
+		UserID:      nk.UserID,
+		Name:        nk.Name,
+		Prefix:      prefix,
+		KeyHash:     hashToken(key),
+		Scopes:      nk.Scopes,
+		ExpiresAt:   nk.ExpiresAt,
+		DateCreated: ctxval.GetTime(ctx),
+	}
+
+	if err := b.storer.CreateAPIKey(ctx, ak); err != nil {
+		return APIKey{}, "", fmt.Errorf("createapikey: userID[%s]: %w", nk.UserID, err)
+	}
+
+	return ak, key, nil
+}
+
+// AuthenticateAPIKey finds the personal API key and records its use. An
+// unknown, malformed or expired key returns ErrInvalidAPIKey.
+func (b *Business) AuthenticateAPIKey(ctx context.Context, key string) (APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.authenticateapikey")
+	defer span.End()
+
+	rest, found := strings.CutPrefix(key, APIKeyPrefix)
+	if !found {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	prefix, _, found := strings.Cut(rest, ".")
+	if !found {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	ak, err := b.storer.QueryAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return APIKey{}, ErrInvalidAPIKey
+		}
+		return APIKey{}, fmt.Errorf("queryapikeybyprefix: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(ak.KeyHash), []byte(hashToken(key))) != 1 {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	now := ctxval.GetTime(ctx)
+
+	if !ak.ExpiresAt.IsZero() && !ak.ExpiresAt.After(now) {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	if now.Sub(ak.DateLastUsed) >= apiKeyUseInterval {
+		ak.DateLastUsed = now
+
+		if err := b.storer.UpdateAPIKey(ctx, ak); err != nil {
+			return APIKey{}, fmt.Errorf("updateapikey: keyID[%s]: %w", ak.ID, err)
+		}
+	}
+
+	return ak, nil
+}
+
+// QueryAPIKeys returns the personal API keys of the user.
+func (b *Business) QueryAPIKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.queryapikeys")
+	defer span.End()
+
+	keys, err := b.storer.QueryAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryapikeys: userID[%s]: %w", userID, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey removes a personal API key of the user. ErrNotFound is
+// returned when the user has no such key.
+func (b *Business) RevokeAPIKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revokeapikey")
+	defer span.End()
+
+	ak, err := b.storer.QueryAPIKeyByID(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("queryapikeybyid: keyID[%s]: %w", keyID, err)
+	}
+
+	if ak.UserID != userID {
+		return fmt.Errorf("queryapikeybyid: keyID[%s]: %w", keyID, ErrNotFound)
+	}
+
+	if err := b.storer.DeleteAPIKey(ctx, ak); err != nil {
+		return fmt.Errorf("deleteapikey: keyID[%s]: %w", keyID, err)
+	}
+
+	return nil
+}
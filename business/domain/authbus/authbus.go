@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/business/sdk/delegate"
 	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/sdk/lockout"
 	"github.com/rmsj/service/business/sdk/sqldb"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
@@ -18,8 +22,38 @@ import (
 var (
 	ErrNotFound      = errors.New("key not found")
 	ErrEmailRequired = errors.New("email required to createPasswordReset token")
+	ErrClientLocked  = errors.New("too many failed login attempts, try again later")
+
+	ErrMFANotEnrolled      = errors.New("mfa not enrolled")
+	ErrMFAEnabled          = errors.New("mfa already enabled")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrMFAChallengeExpired = errors.New("mfa challenge expired")
+
+	ErrInvalidSession = errors.New("invalid session")
+	ErrSessionReused  = errors.New("refresh token reused, session revoked")
+
+	ErrInvalidOAuthState = errors.New("invalid or expired oauth state")
+	ErrIdentityLinked    = errors.New("identity already linked to a user")
+
+	ErrInvalidClient = errors.New("invalid client credentials")
+	ErrInvalidScope  = errors.New("scope not allowed for client")
+
+	ErrInvalidAPIKey = errors.New("invalid or expired api key")
 )
 
+// ClientLockoutPolicy defines when a client address is locked out after
+// consecutive failed logins and for how long. It is looser than the account
+// policy since many users can share an address.
+var ClientLockoutPolicy = lockout.Policy{
+	Threshold: 20,
+	Base:      time.Minute,
+	Max:       time.Hour,
+}
+
+// clientFailureWindow is how long failures from a client address are
+// remembered once any lockout has expired.
+const clientFailureWindow = time.Hour
+
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
@@ -28,20 +62,67 @@ type Storer interface {
 	DeletePasswordReset(ctx context.Context, token PasswordResetToken) error
 	QueryPasswordResetByEmail(ctx context.Context, email string) (PasswordResetToken, error)
 	QueryPasswordResetByToken(ctx context.Context, token string) (PasswordResetToken, error)
+	QueryLoginFailure(ctx context.Context, ip string) (LoginFailure, error)
+	IncrementLoginFailure(ctx context.Context, ip string, now time.Time, windowStart time.Time) (int, error)
+	LockLoginClient(ctx context.Context, ip string, lockedUntil time.Time) error
+	UpsertMFA(ctx context.Context, mfa MFA) error
+	DeleteMFA(ctx context.Context, userID uuid.UUID) error
+	QueryMFA(ctx context.Context, userID uuid.UUID) (MFA, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []RecoveryCode) error
+	QueryRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, rc RecoveryCode) error
+	CreateMFAChallenge(ctx context.Context, ch MFAChallenge) error
+	UpdateMFAChallenge(ctx context.Context, ch MFAChallenge) error
+	DeleteMFAChallenge(ctx context.Context, ch MFAChallenge) error
+	QueryMFAChallenge(ctx context.Context, token string) (MFAChallenge, error)
+	CreateSession(ctx context.Context, sess Session) error
+	RotateSession(ctx context.Context, sess Session) error
+	RevokeSessions(ctx context.Context, filter SessionFilter, revokedAt time.Time) error
+	QuerySessionByTokenHash(ctx context.Context, tokenHash string) (Session, error)
+	QuerySessions(ctx context.Context, userID uuid.UUID, now time.Time) ([]Session, error)
+	CreateRevokedToken(ctx context.Context, rt RevokedToken) error
+	DeleteExpiredRevokedTokens(ctx context.Context, now time.Time) error
+	QueryRevokedToken(ctx context.Context, tokenID string) (RevokedToken, error)
+	UpsertUserRevocation(ctx context.Context, ur UserRevocation) error
+	QueryUserRevocation(ctx context.Context, userID uuid.UUID) (UserRevocation, error)
+	CreateOAuthState(ctx context.Context, st OAuthState) error
+	DeleteOAuthState(ctx context.Context, st OAuthState) error
+	DeleteExpiredOAuthStates(ctx context.Context, now time.Time) error
+	QueryOAuthState(ctx context.Context, state string) (OAuthState, error)
+	CreateIdentity(ctx context.Context, idn Identity) error
+	UpdateIdentity(ctx context.Context, idn Identity) error
+	QueryIdentity(ctx context.Context, provider string, subject string) (Identity, error)
+	CreateClient(ctx context.Context, clt Client) error
+	DeleteClient(ctx context.Context, clt Client) error
+	QueryClientByID(ctx context.Context, clientID uuid.UUID) (Client, error)
+	QueryClients(ctx context.Context) ([]Client, error)
+	CreateAPIKey(ctx context.Context, key APIKey) error
+	UpdateAPIKey(ctx context.Context, key APIKey) error
+	DeleteAPIKey(ctx context.Context, key APIKey) error
+	QueryAPIKeyByID(ctx context.Context, keyID uuid.UUID) (APIKey, error)
+	QueryAPIKeyByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	QueryAPIKeys(ctx context.Context, userID uuid.UUID) ([]APIKey, error)
 }
 
 // Business manages the set of APIs for key access.mi
 type Business struct {
-	log    *logger.Logger
-	storer Storer
+	log      *logger.Logger
+	storer   Storer
+	delegate *delegate.Delegate
 }
 
-// NewBusiness constructs a key business API for use.
-func NewBusiness(log *logger.Logger, storer Storer) *Business {
-	return &Business{
-		log:    log,
-		storer: storer,
+// NewBusiness constructs a key business API for use. It registers with the
+// delegate so tokens are revoked when a user loses access.
+func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
+	b := Business{
+		log:      log,
+		storer:   storer,
+		delegate: delegate,
 	}
+
+	b.registerDelegateFunctions()
+
+	return &b
 }
 
 // NewWithTx constructs a new business value that will use the
@@ -54,8 +135,9 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 	}
 
 	bus := Business{
-		log:    b.log,
-		storer: storer,
+		log:      b.log,
+		storer:   storer,
+		delegate: b.delegate,
 	}
 
 	return &bus, nil
@@ -128,3 +210,59 @@ func (b *Business) QueryPasswordResetByToken(ctx context.Context, token string)
 
 	return prt, nil
 }
+
+// CheckLoginClient returns ErrClientLocked if the client address is currently
+// locked out because of failed logins.
+func (b *Business) CheckLoginClient(ctx context.Context, ip string) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.checkloginclient")
+	defer span.End()
+
+	lf, err := b.storer.QueryLoginFailure(ctx, ip)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("queryloginfailure: ip[%s]: %w", ip, err)
+	}
+
+	if lf.LockedUntil.After(ctxval.GetTime(ctx)) {
+		return fmt.Errorf("ip[%s] until[%s]: %w", ip, lf.LockedUntil.Format(time.RFC3339), ErrClientLocked)
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed login from the client address and locks
+// the address out once the ClientLockoutPolicy threshold is reached.
+func (b *Business) RecordLoginFailure(ctx context.Context, ip string) (LoginFailure, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.recordloginfailure")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	// The count is incremented by the store, so parallel failures from the
+	// same address are all counted, and the lock is computed from the value
+	// it stored.
+	failures, err := b.storer.IncrementLoginFailure(ctx, ip, now, now.Add(-clientFailureWindow))
+	if err != nil {
+		return LoginFailure{}, fmt.Errorf("incrementloginfailure: ip[%s]: %w", ip, err)
+	}
+
+	lf := LoginFailure{
+		IP:          ip,
+		Failures:    failures,
+		DateUpdated: now,
+	}
+
+	if d := ClientLockoutPolicy.Duration(lf.Failures); d > 0 {
+		lf.LockedUntil = now.Add(d)
+
+		if err := b.storer.LockLoginClient(ctx, ip, lf.LockedUntil); err != nil {
+			return LoginFailure{}, fmt.Errorf("lockloginclient: ip[%s]: %w", ip, err)
+		}
+
+		b.log.Info(ctx, "business.authbus.recordloginfailure", "status", "client locked", "ip", ip, "failures", lf.Failures, "lockedUntil", lf.LockedUntil)
+	}
+
+	return lf, nil
+}
@@ -2,15 +2,28 @@ package authbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"github.com/rmsj/fake"
 
+	"github.com/rmsj/service/business/domain/auditbus"
 	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/authbus/stores/authdb"
+	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/delegate"
+	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/foundation/totp"
 )
 
 func Test_Auth(t *testing.T) {
@@ -28,6 +41,16 @@ func Test_Auth(t *testing.T) {
 	unitest.Run(t, queryPasswordReset(db.BusDomain, sd), "queryPasswordReset")
 	unitest.Run(t, createPasswordReset(db.BusDomain), "createPasswordReset")
 	unitest.Run(t, deletePasswordReset(db.BusDomain, sd), "deletePasswordReset")
+	unitest.Run(t, loginFailure(db.BusDomain), "loginFailure")
+	unitest.Run(t, mfa(db.BusDomain, sd), "mfa")
+	unitest.Run(t, sessions(db.BusDomain, sd), "sessions")
+	unitest.Run(t, revoke(db.BusDomain, sd), "revoke")
+	unitest.Run(t, revokeUncached(db, sd), "revokeUncached")
+	unitest.Run(t, oauthStates(db.BusDomain), "oauthStates")
+	unitest.Run(t, identities(db.BusDomain, sd), "identities")
+	unitest.Run(t, clients(db.BusDomain, sd), "clients")
+	unitest.Run(t, apiKeys(db.BusDomain, sd), "apiKeys")
+	unitest.Run(t, impersonation(db.BusDomain, sd), "impersonation")
 }
 
 // =============================================================================
@@ -52,7 +75,19 @@ func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 
 	// -------------------------------------------------------------------------
 
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := unitest.User{
+		User: usrs[0],
+	}
+
+	// -------------------------------------------------------------------------
+
 	sd := unitest.SeedData{
+		Admins:          []unitest.User{tu1},
 		PassResetTokens: []authbus.PasswordResetToken{tokenA, tokenB},
 	}
 
@@ -181,3 +216,969 @@ func deletePasswordReset(busDomain dbtest.BusDomain, sd unitest.SeedData) []unit
 
 	return table
 }
+
+func loginFailure(busDomain dbtest.BusDomain) []unitest.Table {
+	const ip = "203.0.113.7"
+
+	table := []unitest.Table{
+		{
+			Name:    "below-threshold",
+			ExpResp: authbus.ClientLockoutPolicy.Threshold - 1,
+			ExcFunc: func(ctx context.Context) any {
+				var lf authbus.LoginFailure
+				for range authbus.ClientLockoutPolicy.Threshold - 1 {
+					var err error
+					lf, err = busDomain.Auth.RecordLoginFailure(ctx, ip)
+					if err != nil {
+						return err
+					}
+				}
+
+				if err := busDomain.Auth.CheckLoginClient(ctx, ip); err != nil {
+					return err
+				}
+
+				return lf.Failures
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "locked",
+			ExpResp: authbus.ErrClientLocked,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Auth.RecordLoginFailure(ctx, ip); err != nil {
+					return err
+				}
+
+				err := busDomain.Auth.CheckLoginClient(ctx, ip)
+				if errors.Is(err, authbus.ErrClientLocked) {
+					return authbus.ErrClientLocked
+				}
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != exp {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func mfa(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	var enr authbus.MFAEnrollment
+	var code string
+	var ch authbus.MFAChallenge
+
+	errIs := func(err error, target error) any {
+		if errors.Is(err, target) {
+			return target
+		}
+		return err
+	}
+
+	cmpErr := func(got any, exp any) string {
+		if got != exp {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "enroll",
+			ExpResp: []any{true, 10},
+			ExcFunc: func(ctx context.Context) any {
+				var err error
+				enr, err = busDomain.Auth.EnrollMFA(ctx, usr.ID, "service project", usr.Email.Address)
+				if err != nil {
+					return err
+				}
+
+				return []any{strings.HasPrefix(enr.URI, "otpauth://totp/"), len(enr.RecoveryCodes)}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "verify-invalid",
+			ExpResp: authbus.ErrInvalidMFACode,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.VerifyMFA(ctx, usr.ID, "abcdef")
+				return errIs(err, authbus.ErrInvalidMFACode)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "verify",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				var err error
+				code, err = totp.Code(enr.Secret, totp.Step(time.Now()))
+				if err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Auth.VerifyMFA(ctx, usr.ID, code)
+				if err != nil {
+					return err
+				}
+
+				return resp.Enabled
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "challenge-replay",
+			ExpResp: authbus.ErrInvalidMFACode,
+			ExcFunc: func(ctx context.Context) any {
+				var err error
+				ch, err = busDomain.Auth.CreateMFAChallenge(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Auth.CompleteMFAChallenge(ctx, ch.Token, code)
+				return errIs(err, authbus.ErrInvalidMFACode)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "challenge-recovery",
+			ExpResp: usr.ID,
+			ExcFunc: func(ctx context.Context) any {
+				userID, err := busDomain.Auth.CompleteMFAChallenge(ctx, ch.Token, strings.ToUpper(enr.RecoveryCodes[0]))
+				if err != nil {
+					return err
+				}
+
+				return userID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "challenge-used",
+			ExpResp: authbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.CompleteMFAChallenge(ctx, ch.Token, enr.RecoveryCodes[1])
+				return errIs(err, authbus.ErrNotFound)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "recovery-used",
+			ExpResp: authbus.ErrInvalidMFACode,
+			ExcFunc: func(ctx context.Context) any {
+				ch, err := busDomain.Auth.CreateMFAChallenge(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Auth.CompleteMFAChallenge(ctx, ch.Token, enr.RecoveryCodes[0])
+				return errIs(err, authbus.ErrInvalidMFACode)
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
+
+func sessions(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	var laptop authbus.Session
+	var laptopToken string
+	var phoneToken string
+	var rotatedToken string
+
+	errIs := func(err error, target error) any {
+		if errors.Is(err, target) {
+			return target
+		}
+		return err
+	}
+
+	cmpErr := func(got any, exp any) string {
+		if got != exp {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "create",
+			ExpResp: 2,
+			ExcFunc: func(ctx context.Context) any {
+				var err error
+				laptop, laptopToken, err = busDomain.Auth.CreateSession(ctx, authbus.NewSession{UserID: usr.ID, UserAgent: "laptop", IP: "10.0.0.1"})
+				if err != nil {
+					return err
+				}
+
+				_, phoneToken, err = busDomain.Auth.CreateSession(ctx, authbus.NewSession{UserID: usr.ID, UserAgent: "phone", IP: "10.0.0.2"})
+				if err != nil {
+					return err
+				}
+
+				sessions, err := busDomain.Auth.QuerySessions(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				return len(sessions)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "rotate",
+			ExpResp: []any{true, true},
+			ExcFunc: func(ctx context.Context) any {
+				var sess authbus.Session
+				var err error
+				sess, rotatedToken, err = busDomain.Auth.RotateSession(ctx, laptopToken, "laptop", "10.0.0.3")
+				if err != nil {
+					return err
+				}
+
+				return []any{sess.FamilyID == laptop.FamilyID, rotatedToken != laptopToken}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "query-by-token",
+			ExpResp: []any{true, true},
+			ExcFunc: func(ctx context.Context) any {
+				sess, err := busDomain.Auth.QuerySessionByToken(ctx, rotatedToken)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Auth.QuerySessionByToken(ctx, laptopToken)
+
+				return []any{sess.FamilyID == laptop.FamilyID, errors.Is(err, authbus.ErrInvalidSession)}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "reuse",
+			ExpResp: authbus.ErrSessionReused,
+			ExcFunc: func(ctx context.Context) any {
+				_, _, err := busDomain.Auth.RotateSession(ctx, laptopToken, "attacker", "10.0.0.4")
+				return errIs(err, authbus.ErrSessionReused)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "family-revoked",
+			ExpResp: authbus.ErrInvalidSession,
+			ExcFunc: func(ctx context.Context) any {
+				_, _, err := busDomain.Auth.RotateSession(ctx, rotatedToken, "laptop", "10.0.0.3")
+				return errIs(err, authbus.ErrInvalidSession)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "revoke",
+			ExpResp: authbus.ErrInvalidSession,
+			ExcFunc: func(ctx context.Context) any {
+				sessions, err := busDomain.Auth.QuerySessions(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				if len(sessions) != 1 {
+					return fmt.Errorf("expected 1 session, got %d", len(sessions))
+				}
+
+				if err := busDomain.Auth.RevokeSession(ctx, usr.ID, sessions[0].FamilyID); err != nil {
+					return err
+				}
+
+				_, _, err = busDomain.Auth.RotateSession(ctx, phoneToken, "phone", "10.0.0.2")
+				return errIs(err, authbus.ErrInvalidSession)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "revoke-not-found",
+			ExpResp: authbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				err := busDomain.Auth.RevokeSession(ctx, usr.ID, laptop.FamilyID)
+				return errIs(err, authbus.ErrNotFound)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "concurrent-rotate",
+			ExpResp: []any{1, 1},
+			ExcFunc: func(ctx context.Context) any {
+				_, token, err := busDomain.Auth.CreateSession(ctx, authbus.NewSession{UserID: usr.ID, UserAgent: "tablet", IP: "10.0.0.5"})
+				if err != nil {
+					return err
+				}
+
+				errs := make([]error, 2)
+
+				var wg sync.WaitGroup
+				for i := range errs {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, _, errs[i] = busDomain.Auth.RotateSession(ctx, token, "tablet", "10.0.0.5")
+					}()
+				}
+				wg.Wait()
+
+				var rotated, reused int
+				for _, err := range errs {
+					switch {
+					case err == nil:
+						rotated++
+					case errors.Is(err, authbus.ErrSessionReused):
+						reused++
+					default:
+						return err
+					}
+				}
+
+				return []any{rotated, reused}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func revoke(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	tokenID := uuid.NewString()
+	issuedAt := time.Now().Add(-time.Hour)
+
+	table := []unitest.Table{
+		{
+			Name:    "not-revoked",
+			ExpResp: false,
+			ExcFunc: func(ctx context.Context) any {
+				revoked, err := busDomain.Auth.IsTokenRevoked(ctx, tokenID, usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				return revoked
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "token",
+			ExpResp: []any{true, false},
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Auth.RevokeToken(ctx, tokenID, usr.ID, time.Now().Add(time.Hour)); err != nil {
+					return err
+				}
+
+				revoked, err := busDomain.Auth.IsTokenRevoked(ctx, tokenID, usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				other, err := busDomain.Auth.IsTokenRevoked(ctx, uuid.NewString(), usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				return []any{revoked, other}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "user-disabled",
+			ExpResp: []any{true, false},
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.User.Update(ctx, usr, userbus.UpdateUser{Enabled: dbtest.BoolPointer(false)}); err != nil {
+					return err
+				}
+
+				before, err := busDomain.Auth.IsTokenRevoked(ctx, uuid.NewString(), usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				after, err := busDomain.Auth.IsTokenRevoked(ctx, uuid.NewString(), usr.ID, time.Now().Add(time.Second))
+				if err != nil {
+					return err
+				}
+
+				return []any{before, after}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "same-second",
+			ExpResp: []any{true, false},
+			ExcFunc: func(ctx context.Context) any {
+				iat := time.Now().Add(-time.Millisecond).Truncate(time.Millisecond)
+
+				if err := busDomain.Auth.RevokeUserTokens(ctx, usr.ID); err != nil {
+					return err
+				}
+
+				// A token issued after the revocation, like the one from
+				// logging in again, is not revoked even in the same second.
+				relogin := time.Now().Truncate(time.Millisecond)
+
+				before, err := busDomain.Auth.IsTokenRevoked(ctx, uuid.NewString(), usr.ID, iat)
+				if err != nil {
+					return err
+				}
+
+				after, err := busDomain.Auth.IsTokenRevoked(ctx, uuid.NewString(), usr.ID, relogin)
+				if err != nil {
+					return err
+				}
+
+				return []any{before, after}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+// revokeUncached checks a store that doesn't cache, like the one the sales
+// service verifies tokens with, sees revocations made through another store
+// right away, even after a miss.
+func revokeUncached(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	local := authbus.NewBusiness(db.Log, delegate.New(db.Log), authdb.NewStore(db.Log, db.DB, 0))
+
+	table := []unitest.Table{
+		{
+			Name:    "token",
+			ExpResp: []any{false, true},
+			ExcFunc: func(ctx context.Context) any {
+				usr := sd.Admins[0].User
+				tokenID := uuid.NewString()
+				issuedAt := time.Now().Add(-time.Hour)
+
+				before, err := local.IsTokenRevoked(ctx, tokenID, usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				if err := db.BusDomain.Auth.RevokeToken(ctx, tokenID, usr.ID, time.Now().Add(time.Hour)); err != nil {
+					return err
+				}
+
+				after, err := local.IsTokenRevoked(ctx, tokenID, usr.ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				return []any{before, after}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "user",
+			ExpResp: []any{false, true},
+			ExcFunc: func(ctx context.Context) any {
+				usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, db.BusDomain.User)
+				if err != nil {
+					return err
+				}
+
+				issuedAt := time.Now().Add(-time.Hour)
+
+				before, err := local.IsTokenRevoked(ctx, uuid.NewString(), usrs[0].ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				if err := db.BusDomain.Auth.RevokeUserTokens(ctx, usrs[0].ID); err != nil {
+					return err
+				}
+
+				after, err := local.IsTokenRevoked(ctx, uuid.NewString(), usrs[0].ID, issuedAt)
+				if err != nil {
+					return err
+				}
+
+				return []any{before, after}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func oauthStates(busDomain dbtest.BusDomain) []unitest.Table {
+	errIs := func(err error, target error) any {
+		if errors.Is(err, target) {
+			return target
+		}
+		return err
+	}
+
+	cmpErr := func(got any, exp any) string {
+		if got != exp {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "consume",
+			ExpResp: "session-a",
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Auth.CreateOAuthState(ctx, authbus.NewOAuthState{State: "state-a", Provider: "google", Session: "session-a"}); err != nil {
+					return err
+				}
+
+				st, err := busDomain.Auth.ConsumeOAuthState(ctx, "state-a", "google")
+				if err != nil {
+					return err
+				}
+
+				return st.Session
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "replay",
+			ExpResp: authbus.ErrInvalidOAuthState,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.ConsumeOAuthState(ctx, "state-a", "google")
+				return errIs(err, authbus.ErrInvalidOAuthState)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "provider-mismatch",
+			ExpResp: authbus.ErrInvalidOAuthState,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Auth.CreateOAuthState(ctx, authbus.NewOAuthState{State: "state-b", Provider: "google", Session: "session-b"}); err != nil {
+					return err
+				}
+
+				_, err := busDomain.Auth.ConsumeOAuthState(ctx, "state-b", "oidc")
+				return errIs(err, authbus.ErrInvalidOAuthState)
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
+
+func identities(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	table := []unitest.Table{
+		{
+			Name:    "link",
+			ExpResp: usr.ID,
+			ExcFunc: func(ctx context.Context) any {
+				ni := authbus.NewIdentity{
+					Provider: "google",
+					Subject:  "subject-1",
+					UserID:   usr.ID,
+					Email:    usr.Email.Address,
+				}
+
+				if _, err := busDomain.Auth.LinkIdentity(ctx, ni); err != nil {
+					return err
+				}
+
+				idn, err := busDomain.Auth.QueryIdentity(ctx, "google", "subject-1")
+				if err != nil {
+					return err
+				}
+
+				return idn.UserID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "linked",
+			ExpResp: authbus.ErrIdentityLinked,
+			ExcFunc: func(ctx context.Context) any {
+				ni := authbus.NewIdentity{
+					Provider: "google",
+					Subject:  "subject-1",
+					UserID:   usr.ID,
+					Email:    usr.Email.Address,
+				}
+
+				_, err := busDomain.Auth.LinkIdentity(ctx, ni)
+				if errors.Is(err, authbus.ErrIdentityLinked) {
+					return authbus.ErrIdentityLinked
+				}
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != exp {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+				return ""
+			},
+		},
+		{
+			Name:    "use",
+			ExpResp: "changed@example.com",
+			ExcFunc: func(ctx context.Context) any {
+				idn, err := busDomain.Auth.QueryIdentity(ctx, "google", "subject-1")
+				if err != nil {
+					return err
+				}
+
+				if _, err := busDomain.Auth.UseIdentity(ctx, idn, "changed@example.com"); err != nil {
+					return err
+				}
+
+				idn, err = busDomain.Auth.QueryIdentity(ctx, "google", "subject-1")
+				if err != nil {
+					return err
+				}
+
+				return idn.Email
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func clients(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	var clt authbus.Client
+	var secret string
+
+	errIs := func(err error, target error) any {
+		if errors.Is(err, target) {
+			return target
+		}
+		return err
+	}
+
+	cmpErr := func(got any, exp any) string {
+		if got != exp {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "create",
+			ExpResp: []string{"products:import", "sales:*"},
+			ExcFunc: func(ctx context.Context) any {
+				nc := authbus.NewClient{
+					Name:    "Warehouse",
+					Scopes:  []permission.Permission{permission.ProductsImport, permission.MustParse("sales:*")},
+					OwnerID: usr.ID,
+				}
+
+				var err error
+				clt, secret, err = busDomain.Auth.CreateClient(ctx, nc)
+				if err != nil {
+					return err
+				}
+
+				got, err := busDomain.Auth.QueryClientByID(ctx, clt.ID)
+				if err != nil {
+					return err
+				}
+
+				return permission.ParseToString(got.Scopes)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "authenticate",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				got, err := busDomain.Auth.AuthenticateClient(ctx, clt.ID, secret)
+				if err != nil {
+					return err
+				}
+
+				return got.ID == clt.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "wrong-secret",
+			ExpResp: authbus.ErrInvalidClient,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.AuthenticateClient(ctx, clt.ID, secret+"x")
+				return errIs(err, authbus.ErrInvalidClient)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "scopes",
+			ExpResp: []any{"sales:report", true},
+			ExcFunc: func(ctx context.Context) any {
+				granted, err := clt.GrantScopes([]permission.Permission{permission.SalesReport})
+				if err != nil {
+					return err
+				}
+
+				_, err = clt.GrantScopes([]permission.Permission{permission.MustParse("users:read")})
+
+				return []any{permission.ParseToString(granted)[0], errors.Is(err, authbus.ErrInvalidScope)}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "delete",
+			ExpResp: authbus.ErrInvalidClient,
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Auth.DeleteClient(ctx, clt); err != nil {
+					return err
+				}
+
+				_, err := busDomain.Auth.AuthenticateClient(ctx, clt.ID, secret)
+				return errIs(err, authbus.ErrInvalidClient)
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
+
+func apiKeys(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	usr := sd.Admins[0].User
+
+	var ak authbus.APIKey
+	var key string
+
+	errIs := func(err error, target error) any {
+		if errors.Is(err, target) {
+			return target
+		}
+		return err
+	}
+
+	cmpErr := func(got any, exp any) string {
+		if got != exp {
+			return fmt.Sprintf("got %v, exp %v", got, exp)
+		}
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "create",
+			ExpResp: []any{true, 1},
+			ExcFunc: func(ctx context.Context) any {
+				nk := authbus.NewAPIKey{
+					UserID: usr.ID,
+					Name:   "Reports",
+					Scopes: []permission.Permission{permission.SalesReport},
+				}
+
+				var err error
+				ak, key, err = busDomain.Auth.CreateAPIKey(ctx, nk)
+				if err != nil {
+					return err
+				}
+
+				keys, err := busDomain.Auth.QueryAPIKeys(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				return []any{strings.HasPrefix(key, authbus.APIKeyPrefix+ak.Prefix+"."), len(keys)}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "authenticate",
+			ExpResp: []any{true, true},
+			ExcFunc: func(ctx context.Context) any {
+				got, err := busDomain.Auth.AuthenticateAPIKey(ctx, key)
+				if err != nil {
+					return err
+				}
+
+				return []any{got.ID == ak.ID, !got.DateLastUsed.IsZero()}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "wrong-key",
+			ExpResp: authbus.ErrInvalidAPIKey,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.AuthenticateAPIKey(ctx, key+"x")
+				return errIs(err, authbus.ErrInvalidAPIKey)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "expired",
+			ExpResp: authbus.ErrInvalidAPIKey,
+			ExcFunc: func(ctx context.Context) any {
+				nk := authbus.NewAPIKey{
+					UserID:    usr.ID,
+					Name:      "Expired",
+					ExpiresAt: time.Now().Add(-time.Minute),
+				}
+
+				_, expired, err := busDomain.Auth.CreateAPIKey(ctx, nk)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Auth.AuthenticateAPIKey(ctx, expired)
+				return errIs(err, authbus.ErrInvalidAPIKey)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "revoke-other-user",
+			ExpResp: authbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				err := busDomain.Auth.RevokeAPIKey(ctx, uuid.New(), ak.ID)
+				return errIs(err, authbus.ErrNotFound)
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "revoke",
+			ExpResp: authbus.ErrInvalidAPIKey,
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Auth.RevokeAPIKey(ctx, usr.ID, ak.ID); err != nil {
+					return err
+				}
+
+				_, err := busDomain.Auth.AuthenticateAPIKey(ctx, key)
+				return errIs(err, authbus.ErrInvalidAPIKey)
+			},
+			CmpFunc: cmpErr,
+		},
+	}
+
+	return table
+}
+
+func impersonation(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	actor := sd.Admins[0].User
+	userID := uuid.New()
+
+	table := []unitest.Table{
+		{
+			Name:    "self",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Auth.Impersonate(ctx, actor.ID, actor.ID)
+				return errors.Is(err, authbus.ErrSelfImpersonation)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "impersonate",
+			ExpResp: []any{userID, actor.ID, true},
+			ExcFunc: func(ctx context.Context) any {
+				imp, err := busDomain.Auth.Impersonate(ctx, actor.ID, userID)
+				if err != nil {
+					return err
+				}
+
+				ttl := time.Until(imp.ExpiresAt)
+
+				return []any{imp.UserID, imp.ActorID, imp.TokenID != "" && ttl > 0 && ttl <= authbus.ImpersonationTTL}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "audit",
+			ExpResp: []string{"user.impersonated"},
+			ExcFunc: func(ctx context.Context) any {
+				filter := auditbus.QueryFilter{
+					ObjID: &userID,
+				}
+
+				resp, err := busDomain.Audit.Query(ctx, filter, auditbus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				actions := make([]string, len(resp))
+				for i, a := range resp {
+					actions[i] = a.ObjDomain + "." + a.Action
+				}
+
+				return actions
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,126 @@
+package authbus
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// CreateClient registers a client and returns it with the secret. The secret
+// is only available at this point.
+func (b *Business) CreateClient(ctx context.Context, nc NewClient) (Client, string, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.createclient")
+	defer span.End()
+
+	secret, err := id.NewRandomString(48)
+	if err != nil {
+		return Client{}, "", fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	clt := Client{
+		ID:          uuid.New(),
+		Name:        nc.Name,
+		SecretHash:  hashToken(secret),
+		Scopes:      nc.Scopes,
+		OwnerID:     nc.OwnerID,
+		DateCreated: ctxval.GetTime(ctx),
+	}
+
+	if err := b.storer.CreateClient(ctx, clt); err != nil {
+		return Client{}, "", fmt.Errorf("createclient: ownerID[%s]: %w", nc.OwnerID, err)
+	}
+
+	return clt, secret, nil
+}
+
+// DeleteClient removes the client. Tokens already issued to it stop being
+// accepted.
+func (b *Business) DeleteClient(ctx context.Context, clt Client) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.deleteclient")
+	defer span.End()
+
+	if err := b.storer.DeleteClient(ctx, clt); err != nil {
+		return fmt.Errorf("deleteclient: clientID[%s]: %w", clt.ID, err)
+	}
+
+	return nil
+}
+
+// QueryClientByID finds the client by the specified id.
+func (b *Business) QueryClientByID(ctx context.Context, clientID uuid.UUID) (Client, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.queryclientbyid")
+	defer span.End()
+
+	clt, err := b.storer.QueryClientByID(ctx, clientID)
+	if err != nil {
+		return Client{}, fmt.Errorf("queryclientbyid: clientID[%s]: %w", clientID, err)
+	}
+
+	return clt, nil
+}
+
+// QueryClients returns every registered client.
+func (b *Business) QueryClients(ctx context.Context) ([]Client, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.queryclients")
+	defer span.End()
+
+	clts, err := b.storer.QueryClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("queryclients: %w", err)
+	}
+
+	return clts, nil
+}
+
+// AuthenticateClient checks the secret of the client. An unknown client and a
+// wrong secret both return ErrInvalidClient.
+func (b *Business) AuthenticateClient(ctx context.Context, clientID uuid.UUID, secret string) (Client, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.authenticateclient")
+	defer span.End()
+
+	clt, err := b.storer.QueryClientByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Client{}, ErrInvalidClient
+		}
+		return Client{}, fmt.Errorf("queryclientbyid: clientID[%s]: %w", clientID, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(clt.SecretHash), []byte(hashToken(secret))) != 1 {
+		return Client{}, ErrInvalidClient
+	}
+
+	return clt, nil
+}
+
+// GrantScopes returns the requested scopes when the client is allowed every
+// one of them, or all of the scopes of the client when none are requested.
+func (c Client) GrantScopes(requested []permission.Permission) ([]permission.Permission, error) {
+	if len(requested) == 0 {
+		return c.Scopes, nil
+	}
+
+	for _, want := range requested {
+		var granted bool
+		for _, scope := range c.Scopes {
+			if scope.Grants(want) {
+				granted = true
+				break
+			}
+		}
+
+		if !granted {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, want)
+		}
+	}
+
+	return requested, nil
+}
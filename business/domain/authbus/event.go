@@ -0,0 +1,92 @@
+package authbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/delegate"
+)
+
+// DomainName represents the name of this domain.
+const DomainName = "auth"
+
+// Set of delegate actions.
+const (
+	ActionImpersonated = "impersonated"
+)
+
+// ActionImpersonatedParms represents the parameters for the impersonated
+// action.
+type ActionImpersonatedParms struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// String returns a string representation of the action parameters.
+func (act *ActionImpersonatedParms) String() string {
+	return fmt.Sprintf("&EventParamsImpersonated{UserID:%v, ActorID:%v, TokenID:%s, ExpiresAt:%v}", act.UserID, act.ActorID, act.TokenID, act.ExpiresAt)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (act *ActionImpersonatedParms) Marshal() ([]byte, error) {
+	return json.Marshal(act)
+}
+
+// ActionImpersonatedData constructs the data for the impersonated action.
+func ActionImpersonatedData(imp Impersonation) delegate.Data {
+	params := ActionImpersonatedParms{
+		UserID:    imp.UserID,
+		ActorID:   imp.ActorID,
+		TokenID:   imp.TokenID,
+		ExpiresAt: imp.ExpiresAt,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionImpersonated,
+		RawParams: rawParams,
+	}
+}
+
+// =============================================================================
+
+// registerDelegateFunctions will register action functions with the delegate
+// system for the events this domain reacts to.
+func (b *Business) registerDelegateFunctions() {
+	if b.delegate != nil {
+		b.delegate.Register(userbus.DomainName, userbus.ActionAccessRevoked, b.actionUserAccessRevoked)
+	}
+}
+
+// actionUserAccessRevoked revokes every token and session held by a user
+// who was disabled or changed their password.
+func (b *Business) actionUserAccessRevoked(ctx context.Context, data delegate.Data) error {
+	var params userbus.ActionAccessRevokedParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return fmt.Errorf("expected an encoded %T: %w", params, err)
+	}
+
+	b.log.Info(ctx, "action-user-access-revoked", "userID", params.UserID, "reason", params.Reason)
+
+	if err := b.RevokeUserTokens(ctx, params.UserID); err != nil {
+		return fmt.Errorf("revokeusertokens: %w", err)
+	}
+
+	if err := b.RevokeUserSessions(ctx, params.UserID); err != nil {
+		return fmt.Errorf("revokeusersessions: %w", err)
+	}
+
+	return nil
+}
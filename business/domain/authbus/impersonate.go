@@ -0,0 +1,48 @@
+package authbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// ImpersonationTTL is how long a token issued to impersonate a user is valid.
+// There is no refresh token, the admin starts again once it expires.
+const ImpersonationTTL = 15 * time.Minute
+
+// ErrSelfImpersonation is returned when an admin tries to impersonate
+// themselves.
+var ErrSelfImpersonation = errors.New("cannot impersonate yourself")
+
+// Impersonate starts an admin acting as the user. The token ID and expiry to
+// issue the token with are returned and the start is sent to the delegate so
+// it's kept in the audit trail.
+func (b *Business) Impersonate(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (Impersonation, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.impersonate")
+	defer span.End()
+
+	if actorID == userID {
+		return Impersonation{}, ErrSelfImpersonation
+	}
+
+	imp := Impersonation{
+		UserID:    userID,
+		ActorID:   actorID,
+		TokenID:   uuid.NewString(),
+		ExpiresAt: ctxval.GetTime(ctx).Add(ImpersonationTTL),
+	}
+
+	if b.delegate != nil {
+		if err := b.delegate.Call(ctx, ActionImpersonatedData(imp)); err != nil {
+			return Impersonation{}, fmt.Errorf("failed to execute `%s` action: %w", ActionImpersonated, err)
+		}
+	}
+
+	return imp, nil
+}
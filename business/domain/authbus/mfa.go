@@ -0,0 +1,332 @@
+package authbus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/foundation/otel"
+	"github.com/rmsj/service/foundation/totp"
+)
+
+// Set of values that control multi-factor authentication.
+const (
+	recoveryCodeCount    = 10
+	mfaChallengeTTL      = 5 * time.Minute
+	mfaChallengeAttempts = 5
+	mfaClockSkewSteps    = 1
+)
+
+// EnrollMFA generates a new TOTP secret and set of recovery codes for the
+// user. The secret stays pending until a code is verified with VerifyMFA or
+// CompleteMFAChallenge. Enrolling again replaces a pending secret.
+func (b *Business) EnrollMFA(ctx context.Context, userID uuid.UUID, issuer string, account string) (MFAEnrollment, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.enrollmfa")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	mfa, err := b.storer.QueryMFA(ctx, userID)
+	switch {
+	case err == nil:
+		if mfa.Enabled {
+			return MFAEnrollment{}, ErrMFAEnabled
+		}
+	case errors.Is(err, ErrNotFound):
+		mfa = MFA{
+			UserID:      userID,
+			DateCreated: now,
+		}
+	default:
+		return MFAEnrollment{}, fmt.Errorf("querymfa: userID[%s]: %w", userID, err)
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return MFAEnrollment{}, fmt.Errorf("newsecret: %w", err)
+	}
+
+	mfa.Secret = secret
+	mfa.LastStep = 0
+	mfa.DateUpdated = now
+
+	if err := b.storer.UpsertMFA(ctx, mfa); err != nil {
+		return MFAEnrollment{}, fmt.Errorf("upsertmfa: userID[%s]: %w", userID, err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashed := make([]RecoveryCode, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return MFAEnrollment{}, fmt.Errorf("newrecoverycode: %w", err)
+		}
+
+		codes[i] = code
+		hashed[i] = RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		}
+	}
+
+	if err := b.storer.ReplaceRecoveryCodes(ctx, userID, hashed); err != nil {
+		return MFAEnrollment{}, fmt.Errorf("replacerecoverycodes: userID[%s]: %w", userID, err)
+	}
+
+	enr := MFAEnrollment{
+		Secret:        secret,
+		URI:           totp.URI(issuer, account, secret),
+		RecoveryCodes: codes,
+	}
+
+	return enr, nil
+}
+
+// VerifyMFA confirms a pending enrollment with a code from the authenticator
+// and enables multi-factor authentication for the user.
+func (b *Business) VerifyMFA(ctx context.Context, userID uuid.UUID, code string) (MFA, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.verifymfa")
+	defer span.End()
+
+	mfa, err := b.QueryMFA(ctx, userID)
+	if err != nil {
+		return MFA{}, err
+	}
+
+	if mfa.Enabled {
+		return MFA{}, ErrMFAEnabled
+	}
+
+	if err := b.checkMFACode(ctx, &mfa, code); err != nil {
+		return MFA{}, err
+	}
+
+	mfa.Enabled = true
+
+	if err := b.storer.UpsertMFA(ctx, mfa); err != nil {
+		return MFA{}, fmt.Errorf("upsertmfa: userID[%s]: %w", userID, err)
+	}
+
+	return mfa, nil
+}
+
+// DisableMFA removes multi-factor authentication for the user once a valid
+// code is provided.
+func (b *Business) DisableMFA(ctx context.Context, userID uuid.UUID, code string) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.disablemfa")
+	defer span.End()
+
+	mfa, err := b.QueryMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !mfa.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	if err := b.checkMFACode(ctx, &mfa, code); err != nil {
+		return err
+	}
+
+	if err := b.storer.DeleteMFA(ctx, userID); err != nil {
+		return fmt.Errorf("deletemfa: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// QueryMFA finds the multi-factor authentication settings for the user.
+func (b *Business) QueryMFA(ctx context.Context, userID uuid.UUID) (MFA, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.querymfa")
+	defer span.End()
+
+	mfa, err := b.storer.QueryMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return MFA{}, ErrMFANotEnrolled
+		}
+		return MFA{}, fmt.Errorf("querymfa: userID[%s]: %w", userID, err)
+	}
+
+	return mfa, nil
+}
+
+// CreateMFAChallenge starts the second step of a login for the user.
+func (b *Business) CreateMFAChallenge(ctx context.Context, userID uuid.UUID) (MFAChallenge, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.createmfachallenge")
+	defer span.End()
+
+	token, err := id.NewRandomString(43)
+	if err != nil {
+		return MFAChallenge{}, fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	ch := MFAChallenge{
+		Token:    token,
+		UserID:   userID,
+		ExpiryAt: ctxval.GetTime(ctx).Add(mfaChallengeTTL),
+	}
+
+	if err := b.storer.CreateMFAChallenge(ctx, ch); err != nil {
+		return MFAChallenge{}, fmt.Errorf("createmfachallenge: userID[%s]: %w", userID, err)
+	}
+
+	return ch, nil
+}
+
+// QueryMFAChallenge finds a challenge that has not expired.
+func (b *Business) QueryMFAChallenge(ctx context.Context, token string) (MFAChallenge, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.querymfachallenge")
+	defer span.End()
+
+	ch, err := b.storer.QueryMFAChallenge(ctx, token)
+	if err != nil {
+		return MFAChallenge{}, fmt.Errorf("querymfachallenge: %w", err)
+	}
+
+	if !ch.ExpiryAt.After(ctxval.GetTime(ctx)) {
+		return MFAChallenge{}, ErrMFAChallengeExpired
+	}
+
+	return ch, nil
+}
+
+// CompleteMFAChallenge checks the code for the challenge and returns the
+// user it was issued for. A pending enrollment is enabled by the first valid
+// code. The challenge is removed once used or after too many bad codes.
+func (b *Business) CompleteMFAChallenge(ctx context.Context, token string, code string) (uuid.UUID, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.completemfachallenge")
+	defer span.End()
+
+	ch, err := b.QueryMFAChallenge(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	mfa, err := b.QueryMFA(ctx, ch.UserID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := b.checkMFACode(ctx, &mfa, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) {
+			return uuid.Nil, err
+		}
+
+		ch.Attempts++
+		if ch.Attempts >= mfaChallengeAttempts {
+			if err := b.storer.DeleteMFAChallenge(ctx, ch); err != nil {
+				return uuid.Nil, fmt.Errorf("deletemfachallenge: %w", err)
+			}
+			return uuid.Nil, err
+		}
+
+		if err := b.storer.UpdateMFAChallenge(ctx, ch); err != nil {
+			return uuid.Nil, fmt.Errorf("updatemfachallenge: %w", err)
+		}
+
+		return uuid.Nil, err
+	}
+
+	mfa.Enabled = true
+
+	if err := b.storer.UpsertMFA(ctx, mfa); err != nil {
+		return uuid.Nil, fmt.Errorf("upsertmfa: userID[%s]: %w", mfa.UserID, err)
+	}
+
+	if err := b.storer.DeleteMFAChallenge(ctx, ch); err != nil {
+		return uuid.Nil, fmt.Errorf("deletemfachallenge: %w", err)
+	}
+
+	return ch.UserID, nil
+}
+
+// checkMFACode validates a TOTP code, rejecting one that was already used,
+// and falls back to the recovery codes once MFA is enabled. On success the
+// last used step is recorded on mfa; the caller persists it.
+func (b *Business) checkMFACode(ctx context.Context, mfa *MFA, code string) error {
+	now := ctxval.GetTime(ctx)
+
+	step, ok, err := totp.Validate(mfa.Secret, code, now, mfaClockSkewSteps)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if ok {
+		if step <= mfa.LastStep {
+			return ErrInvalidMFACode
+		}
+
+		mfa.LastStep = step
+		mfa.DateUpdated = now
+
+		return nil
+	}
+
+	if !mfa.Enabled {
+		return ErrInvalidMFACode
+	}
+
+	codes, err := b.storer.QueryRecoveryCodes(ctx, mfa.UserID)
+	if err != nil {
+		return fmt.Errorf("queryrecoverycodes: userID[%s]: %w", mfa.UserID, err)
+	}
+
+	hash := hashRecoveryCode(code)
+	for _, rc := range codes {
+		if !rc.DateUsed.IsZero() {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(rc.CodeHash), []byte(hash)) == 1 {
+			rc.DateUsed = now
+			if err := b.storer.UseRecoveryCode(ctx, rc); err != nil {
+				return fmt.Errorf("userecoverycode: userID[%s]: %w", mfa.UserID, err)
+			}
+
+			mfa.DateUpdated = now
+
+			return nil
+		}
+	}
+
+	return ErrInvalidMFACode
+}
+
+// =============================================================================
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCode generates a random recovery code formatted as two groups
+// of five characters.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryEncoding.EncodeToString(b))
+
+	return code[:5] + "-" + code[5:10], nil
+}
+
+// hashRecoveryCode returns the hex encoded SHA-256 of a normalised code.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,10 @@ package authbus
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/permission"
 )
 
 // PasswordResetToken represents information about an individual key.
@@ -21,3 +25,183 @@ type Login struct {
 	Email    string
 	Password string
 }
+
+// LoginFailure tracks the failed logins coming from a single client address.
+type LoginFailure struct {
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+	DateUpdated time.Time
+}
+
+// MFA represents the multi-factor authentication settings for a user. The
+// secret is pending until the user verifies a code, at which point Enabled
+// is set.
+type MFA struct {
+	UserID      uuid.UUID
+	Secret      string
+	Enabled     bool
+	LastStep    int64
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// MFAEnrollment contains what a user needs to set up an authenticator app.
+// The recovery codes are only ever returned here; just their hashes are kept.
+type MFAEnrollment struct {
+	Secret        string
+	URI           string
+	RecoveryCodes []string
+}
+
+// RecoveryCode represents a hashed single use code that can replace a TOTP
+// code when the authenticator is not available.
+type RecoveryCode struct {
+	UserID   uuid.UUID
+	CodeHash string
+	DateUsed time.Time
+}
+
+// MFAChallenge represents the pending second step of a login.
+type MFAChallenge struct {
+	Token    string
+	UserID   uuid.UUID
+	Attempts int
+	ExpiryAt time.Time
+}
+
+// Session represents a refresh token issued to a device. Every refresh
+// rotates the token into a new session in the same family, so FamilyID
+// identifies the login across rotations. Only a hash of the token is kept.
+type Session struct {
+	ID           uuid.UUID
+	FamilyID     uuid.UUID
+	UserID       uuid.UUID
+	TokenHash    string
+	UserAgent    string
+	IP           string
+	DateCreated  time.Time
+	DateLastUsed time.Time
+	ExpiresAt    time.Time
+	DateRotated  time.Time
+	DateRevoked  time.Time
+}
+
+// NewSession contains information needed to start a new session.
+type NewSession struct {
+	UserID    uuid.UUID
+	UserAgent string
+	IP        string
+}
+
+// SessionFilter selects the sessions to revoke. At least one field is set.
+type SessionFilter struct {
+	UserID   *uuid.UUID
+	FamilyID *uuid.UUID
+}
+
+// RevokedToken represents an access token that was revoked before it expired.
+// ID is the jti claim of the token.
+type RevokedToken struct {
+	ID          string
+	UserID      uuid.UUID
+	ExpiresAt   time.Time
+	DateRevoked time.Time
+}
+
+// UserRevocation marks every token issued to the user before RevokedBefore
+// as revoked.
+type UserRevocation struct {
+	UserID        uuid.UUID
+	RevokedBefore time.Time
+}
+
+// OAuthState represents a login with an identity provider that is waiting
+// for the provider to call back. Session is the provider session, which
+// carries the PKCE verifier, kept until the callback. A state is single use.
+type OAuthState struct {
+	State    string
+	Provider string
+	Session  string
+	ExpiryAt time.Time
+}
+
+// NewOAuthState contains information needed to start a login with an
+// identity provider.
+type NewOAuthState struct {
+	State    string
+	Provider string
+	Session  string
+}
+
+// Identity links an account at an identity provider to a user. Subject is
+// the identifier the provider gives the account.
+type Identity struct {
+	Provider     string
+	Subject      string
+	UserID       uuid.UUID
+	Email        string
+	DateCreated  time.Time
+	DateLastUsed time.Time
+}
+
+// NewIdentity contains information needed to link a provider account to a
+// user.
+type NewIdentity struct {
+	Provider string
+	Subject  string
+	UserID   uuid.UUID
+	Email    string
+}
+
+// Client is an OAuth2 client registered for machine to machine access. The
+// scopes are the permissions the client may be granted and the secret itself
+// is never stored.
+type Client struct {
+	ID          uuid.UUID
+	Name        string
+	SecretHash  string
+	Scopes      []permission.Permission
+	OwnerID     uuid.UUID
+	DateCreated time.Time
+}
+
+// NewClient contains information needed to register a client.
+type NewClient struct {
+	Name    string
+	Scopes  []permission.Permission
+	OwnerID uuid.UUID
+}
+
+// APIKey is a personal key a user creates for scripts. The key is found by
+// its prefix and only its hash is stored. Scopes restrict the permissions the
+// key can use, no scopes means no restriction. A zero ExpiresAt means the key
+// doesn't expire and a zero DateLastUsed means it was never used.
+type APIKey struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         string
+	Prefix       string
+	KeyHash      string
+	Scopes       []permission.Permission
+	ExpiresAt    time.Time
+	DateLastUsed time.Time
+	DateCreated  time.Time
+}
+
+// NewAPIKey contains information needed to create a personal API key.
+type NewAPIKey struct {
+	UserID    uuid.UUID
+	Name      string
+	Scopes    []permission.Permission
+	ExpiresAt time.Time
+}
+
+// Impersonation represents an admin acting as another user. The token issued
+// for it carries the ID and expires with it.
+type Impersonation struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	TokenID   string
+	ExpiresAt time.Time
+}
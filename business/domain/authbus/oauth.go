@@ -0,0 +1,118 @@
+package authbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// OAuthStateTTL is how long a user has to complete a login at an identity
+// provider.
+const OAuthStateTTL = 10 * time.Minute
+
+// CreateOAuthState records a login started with an identity provider so the
+// callback can be matched to it. Abandoned logins are removed along the way.
+func (b *Business) CreateOAuthState(ctx context.Context, ns NewOAuthState) (OAuthState, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.createoauthstate")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	st := OAuthState{
+		State:    ns.State,
+		Provider: ns.Provider,
+		Session:  ns.Session,
+		ExpiryAt: now.Add(OAuthStateTTL),
+	}
+
+	if err := b.storer.DeleteExpiredOAuthStates(ctx, now); err != nil {
+		return OAuthState{}, fmt.Errorf("deleteexpiredoauthstates: %w", err)
+	}
+
+	if err := b.storer.CreateOAuthState(ctx, st); err != nil {
+		return OAuthState{}, fmt.Errorf("createoauthstate: provider[%s]: %w", ns.Provider, err)
+	}
+
+	return st, nil
+}
+
+// ConsumeOAuthState returns the login started with the provider for the
+// state and removes it, so a callback can't be replayed.
+func (b *Business) ConsumeOAuthState(ctx context.Context, state string, provider string) (OAuthState, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.consumeoauthstate")
+	defer span.End()
+
+	st, err := b.storer.QueryOAuthState(ctx, state)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return OAuthState{}, ErrInvalidOAuthState
+		}
+		return OAuthState{}, fmt.Errorf("queryoauthstate: %w", err)
+	}
+
+	if err := b.storer.DeleteOAuthState(ctx, st); err != nil {
+		return OAuthState{}, fmt.Errorf("deleteoauthstate: %w", err)
+	}
+
+	if st.Provider != provider || !st.ExpiryAt.After(ctxval.GetTime(ctx)) {
+		return OAuthState{}, ErrInvalidOAuthState
+	}
+
+	return st, nil
+}
+
+// LinkIdentity links the provider account to the user.
+func (b *Business) LinkIdentity(ctx context.Context, ni NewIdentity) (Identity, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.linkidentity")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	idn := Identity{
+		Provider:     ni.Provider,
+		Subject:      ni.Subject,
+		UserID:       ni.UserID,
+		Email:        ni.Email,
+		DateCreated:  now,
+		DateLastUsed: now,
+	}
+
+	if err := b.storer.CreateIdentity(ctx, idn); err != nil {
+		return Identity{}, fmt.Errorf("createidentity: provider[%s] userID[%s]: %w", ni.Provider, ni.UserID, err)
+	}
+
+	return idn, nil
+}
+
+// UseIdentity records a login with the provider account, keeping the email
+// the provider last reported.
+func (b *Business) UseIdentity(ctx context.Context, idn Identity, email string) (Identity, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.useidentity")
+	defer span.End()
+
+	idn.Email = email
+	idn.DateLastUsed = ctxval.GetTime(ctx)
+
+	if err := b.storer.UpdateIdentity(ctx, idn); err != nil {
+		return Identity{}, fmt.Errorf("updateidentity: provider[%s] userID[%s]: %w", idn.Provider, idn.UserID, err)
+	}
+
+	return idn, nil
+}
+
+// QueryIdentity finds the user linked to the provider account.
+func (b *Business) QueryIdentity(ctx context.Context, provider string, subject string) (Identity, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.queryidentity")
+	defer span.End()
+
+	idn, err := b.storer.QueryIdentity(ctx, provider, subject)
+	if err != nil {
+		return Identity{}, fmt.Errorf("queryidentity: provider[%s]: %w", provider, err)
+	}
+
+	return idn, nil
+}
@@ -0,0 +1,86 @@
+package authbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// RevokeToken revokes a single access token until it expires. The record is
+// no longer needed once the token expires, so expired records are removed.
+func (b *Business) RevokeToken(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revoketoken")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	rt := RevokedToken{
+		ID:          tokenID,
+		UserID:      userID,
+		ExpiresAt:   expiresAt,
+		DateRevoked: now,
+	}
+
+	if err := b.storer.CreateRevokedToken(ctx, rt); err != nil {
+		return fmt.Errorf("createrevokedtoken: tokenID[%s]: %w", tokenID, err)
+	}
+
+	if err := b.storer.DeleteExpiredRevokedTokens(ctx, now); err != nil {
+		return fmt.Errorf("deleteexpiredrevokedtokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserTokens revokes every access token issued to the user so far.
+func (b *Business) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revokeusertokens")
+	defer span.End()
+
+	// Token issue times have millisecond precision, so the cutoff is kept to
+	// the millisecond. A token issued right after the revocation, such as the
+	// one from logging in again, is never revoked by it.
+	ur := UserRevocation{
+		UserID:        userID,
+		RevokedBefore: ctxval.GetTime(ctx).Truncate(time.Millisecond),
+	}
+
+	if err := b.storer.UpsertUserRevocation(ctx, ur); err != nil {
+		return fmt.Errorf("upsertuserrevocation: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the token with the specified id, issued to
+// the user at the specified time, has been revoked.
+func (b *Business) IsTokenRevoked(ctx context.Context, tokenID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.istokenrevoked")
+	defer span.End()
+
+	if tokenID != "" {
+		_, err := b.storer.QueryRevokedToken(ctx, tokenID)
+		switch {
+		case err == nil:
+			return true, nil
+		case !errors.Is(err, ErrNotFound):
+			return false, fmt.Errorf("queryrevokedtoken: tokenID[%s]: %w", tokenID, err)
+		}
+	}
+
+	ur, err := b.storer.QueryUserRevocation(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("queryuserrevocation: userID[%s]: %w", userID, err)
+	}
+
+	return issuedAt.Before(ur.RevokedBefore), nil
+}
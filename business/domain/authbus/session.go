@@ -0,0 +1,246 @@
+package authbus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/ctxval"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// SessionTTL is how long a refresh token stays valid without being used.
+const SessionTTL = 30 * 24 * time.Hour
+
+// maxUserAgent matches the size of the user_agent column.
+const maxUserAgent = 255
+
+// CreateSession starts a new session for a device and returns it with the
+// refresh token. The token itself is never stored.
+func (b *Business) CreateSession(ctx context.Context, ns NewSession) (Session, string, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.createsession")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	token, err := id.NewRandomString(64)
+	if err != nil {
+		return Session{}, "", fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	sessID := uuid.New()
+
+	sess := Session{
+		ID:           sessID,
+		FamilyID:     sessID,
+		UserID:       ns.UserID,
+		TokenHash:    hashToken(token),
+		UserAgent:    truncate(ns.UserAgent, maxUserAgent),
+		IP:           ns.IP,
+		DateCreated:  now,
+		DateLastUsed: now,
+		ExpiresAt:    now.Add(SessionTTL),
+	}
+
+	if err := b.storer.CreateSession(ctx, sess); err != nil {
+		return Session{}, "", fmt.Errorf("createsession: userID[%s]: %w", ns.UserID, err)
+	}
+
+	return sess, token, nil
+}
+
+// RotateSession exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already rotated means it leaked, so the whole
+// family is revoked and ErrSessionReused is returned.
+func (b *Business) RotateSession(ctx context.Context, token string, userAgent string, ip string) (Session, string, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.rotatesession")
+	defer span.End()
+
+	now := ctxval.GetTime(ctx)
+
+	sess, err := b.storer.QuerySessionByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Session{}, "", ErrInvalidSession
+		}
+		return Session{}, "", fmt.Errorf("querysessionbytokenhash: %w", err)
+	}
+
+	if !sess.DateRevoked.IsZero() || !sess.ExpiresAt.After(now) {
+		return Session{}, "", ErrInvalidSession
+	}
+
+	if !sess.DateRotated.IsZero() {
+		return Session{}, "", b.revokeReused(ctx, sess, now)
+	}
+
+	sess.DateRotated = now
+	if err := b.storer.RotateSession(ctx, sess); err != nil {
+		if errors.Is(err, ErrSessionReused) {
+			return Session{}, "", b.revokeReused(ctx, sess, now)
+		}
+		return Session{}, "", fmt.Errorf("rotatesession: sessionID[%s]: %w", sess.ID, err)
+	}
+
+	newToken, err := id.NewRandomString(64)
+	if err != nil {
+		return Session{}, "", fmt.Errorf("newrandomstring: %w", err)
+	}
+
+	next := Session{
+		ID:           uuid.New(),
+		FamilyID:     sess.FamilyID,
+		UserID:       sess.UserID,
+		TokenHash:    hashToken(newToken),
+		UserAgent:    truncate(userAgent, maxUserAgent),
+		IP:           ip,
+		DateCreated:  sess.DateCreated,
+		DateLastUsed: now,
+		ExpiresAt:    now.Add(SessionTTL),
+	}
+
+	if err := b.storer.CreateSession(ctx, next); err != nil {
+		return Session{}, "", fmt.Errorf("createsession: userID[%s]: %w", sess.UserID, err)
+	}
+
+	return next, newToken, nil
+}
+
+// revokeReused revokes the family of a session whose refresh token was
+// presented after it had already been rotated, including by a concurrent
+// request that rotated it first.
+func (b *Business) revokeReused(ctx context.Context, sess Session, now time.Time) error {
+	filter := SessionFilter{FamilyID: &sess.FamilyID}
+	if err := b.storer.RevokeSessions(ctx, filter, now); err != nil {
+		return fmt.Errorf("revokesessions: familyID[%s]: %w", sess.FamilyID, err)
+	}
+
+	b.log.Info(ctx, "business.authbus.rotatesession", "status", "refresh token reused", "userID", sess.UserID, "familyID", sess.FamilyID)
+
+	return ErrSessionReused
+}
+
+// QuerySessionByToken returns the session holding the refresh token without
+// rotating it. A token that was rotated, revoked or has expired returns
+// ErrInvalidSession.
+func (b *Business) QuerySessionByToken(ctx context.Context, token string) (Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.querysessionbytoken")
+	defer span.End()
+
+	sess, err := b.storer.QuerySessionByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Session{}, ErrInvalidSession
+		}
+		return Session{}, fmt.Errorf("querysessionbytokenhash: %w", err)
+	}
+
+	if !sess.DateRotated.IsZero() || !sess.DateRevoked.IsZero() || !sess.ExpiresAt.After(ctxval.GetTime(ctx)) {
+		return Session{}, ErrInvalidSession
+	}
+
+	return sess, nil
+}
+
+// QuerySessions retrieves the active sessions for the user, one per family.
+func (b *Business) QuerySessions(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.querysessions")
+	defer span.End()
+
+	sessions, err := b.storer.QuerySessions(ctx, userID, ctxval.GetTime(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("querysessions: userID[%s]: %w", userID, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the session family for the user. ErrNotFound is
+// returned if the user has no active session with that id.
+func (b *Business) RevokeSession(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revokesession")
+	defer span.End()
+
+	sessions, err := b.QuerySessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, sess := range sessions {
+		if sess.FamilyID == familyID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return ErrNotFound
+	}
+
+	filter := SessionFilter{FamilyID: &familyID}
+	if err := b.storer.RevokeSessions(ctx, filter, ctxval.GetTime(ctx)); err != nil {
+		return fmt.Errorf("revokesessions: familyID[%s]: %w", familyID, err)
+	}
+
+	return nil
+}
+
+// RevokeSessionByToken revokes the session family holding the refresh token,
+// provided it belongs to the user.
+func (b *Business) RevokeSessionByToken(ctx context.Context, userID uuid.UUID, token string) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revokesessionbytoken")
+	defer span.End()
+
+	sess, err := b.storer.QuerySessionByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidSession
+		}
+		return fmt.Errorf("querysessionbytokenhash: %w", err)
+	}
+
+	if sess.UserID != userID {
+		return ErrInvalidSession
+	}
+
+	filter := SessionFilter{FamilyID: &sess.FamilyID}
+	if err := b.storer.RevokeSessions(ctx, filter, ctxval.GetTime(ctx)); err != nil {
+		return fmt.Errorf("revokesessions: familyID[%s]: %w", sess.FamilyID, err)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes every session for the user.
+func (b *Business) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.authbus.revokeusersessions")
+	defer span.End()
+
+	filter := SessionFilter{UserID: &userID}
+	if err := b.storer.RevokeSessions(ctx, filter, ctxval.GetTime(ctx)); err != nil {
+		return fmt.Errorf("revokesessions: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// hashToken returns the hex encoded SHA-256 of a refresh token. The tokens are
+// random so a fast hash is enough to make a leaked table useless.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
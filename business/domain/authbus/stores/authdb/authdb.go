@@ -2,11 +2,15 @@
 package authdb
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/viccon/sturdyc"
 
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/sdk/sqldb"
@@ -15,16 +19,32 @@ import (
 
 // Store manages the set of APIs for PasswordResetToken database access.
 type Store struct {
-	log *logger.Logger
-	db  sqlx.ExtContext
+	log   *logger.Logger
+	db    sqlx.ExtContext
+	cache *sturdyc.Client[time.Time]
 }
 
-// NewStore constructs the api for data access.
-func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
-	return &Store{
+// NewStore constructs the api for data access. Token revocation lookups
+// happen on every authenticated request, so their results are cached for
+// the specified ttl. Revocations written by this store update its cache, but
+// ones written by another process are only seen once the ttl passes, so a
+// service that doesn't revoke tokens itself should pass a zero ttl to always
+// read them from the database.
+func NewStore(log *logger.Logger, db *sqlx.DB, ttl time.Duration) *Store {
+	const capacity = 10000
+	const numShards = 10
+	const evictionPercentage = 10
+
+	store := Store{
 		log: log,
 		db:  db,
 	}
+
+	if ttl > 0 {
+		store.cache = sturdyc.New[time.Time](capacity, numShards, ttl, evictionPercentage)
+	}
+
+	return &store
 }
 
 // NewWithTx constructs a new Store value replacing the sqlx DB
@@ -36,8 +56,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (authbus.Storer, error) {
 	}
 
 	store := Store{
-		log: s.log,
-		db:  ec,
+		log:   s.log,
+		db:    ec,
+		cache: s.cache,
 	}
 
 	return &store, nil
@@ -114,3 +135,981 @@ func (s *Store) QueryPasswordResetByToken(ctx context.Context, token string) (au
 
 	return toBusPasswordResetToken(dbKey), nil
 }
+
+// QueryLoginFailure gets the failed login record for the client address.
+func (s *Store) QueryLoginFailure(ctx context.Context, ip string) (authbus.LoginFailure, error) {
+	data := struct {
+		IP string `db:"ip"`
+	}{
+		IP: ip,
+	}
+
+	const q = `
+	SELECT
+		ip, failures, locked_until, updated_at
+	FROM
+		login_failures
+	WHERE
+		ip = :ip`
+
+	var dbLF loginFailure
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLF); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.LoginFailure{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.LoginFailure{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusLoginFailure(dbLF), nil
+}
+
+// IncrementLoginFailure adds a failed login for the client address and
+// returns the new count. The count starts again when the address isn't
+// locked and its last failure is older than windowStart. The increment is
+// done by the database, so concurrent failures are all counted, and
+// LAST_INSERT_ID returns the value this statement wrote.
+func (s *Store) IncrementLoginFailure(ctx context.Context, ip string, now time.Time, windowStart time.Time) (int, error) {
+	data := struct {
+		IP          string    `db:"ip"`
+		Now         time.Time `db:"now"`
+		WindowStart time.Time `db:"window_start"`
+	}{
+		IP:          ip,
+		Now:         now.UTC(),
+		WindowStart: windowStart.UTC(),
+	}
+
+	// The assignments are evaluated in order, so failures and locked_until
+	// both see the updated_at of the previous failure.
+	const q = `
+	INSERT INTO login_failures
+		(ip, failures, locked_until, updated_at)
+	VALUES
+		(:ip, LAST_INSERT_ID(1), NULL, :now)
+	ON DUPLICATE KEY UPDATE
+		failures = LAST_INSERT_ID(IF((locked_until IS NULL OR locked_until <= :now) AND updated_at < :window_start, 1, failures + 1)),
+		locked_until = IF((locked_until IS NULL OR locked_until <= :now) AND updated_at < :window_start, NULL, locked_until),
+		updated_at = :now`
+
+	result, err := sqldb.NamedExecContextResult(ctx, s.log, s.db, q, data)
+	if err != nil {
+		return 0, fmt.Errorf("namedexeccontextresult: %w", err)
+	}
+
+	failures, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("lastinsertid: %w", err)
+	}
+
+	return int(failures), nil
+}
+
+// LockLoginClient locks the client address until the specified time. A lock
+// already in place that ends later is kept.
+func (s *Store) LockLoginClient(ctx context.Context, ip string, lockedUntil time.Time) error {
+	data := struct {
+		IP          string    `db:"ip"`
+		LockedUntil time.Time `db:"locked_until"`
+	}{
+		IP:          ip,
+		LockedUntil: lockedUntil.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		login_failures
+	SET
+		locked_until = GREATEST(COALESCE(locked_until, :locked_until), :locked_until)
+	WHERE
+		ip = :ip`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertMFA stores the multi-factor authentication settings for a user.
+func (s *Store) UpsertMFA(ctx context.Context, m authbus.MFA) error {
+	const q = `
+	INSERT INTO user_mfa
+		(user_id, secret, enabled, last_step, created_at, updated_at)
+	VALUES
+		(:user_id, :secret, :enabled, :last_step, :created_at, :updated_at)
+	ON DUPLICATE KEY UPDATE
+		secret = VALUES(secret),
+		enabled = VALUES(enabled),
+		last_step = VALUES(last_step),
+		updated_at = VALUES(updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFA(m)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMFA removes the multi-factor authentication settings and recovery
+// codes for a user.
+func (s *Store) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		user_mfa
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const qCodes = `
+	DELETE FROM
+		mfa_recovery_codes
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, qCodes, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryMFA gets the multi-factor authentication settings for a user.
+func (s *Store) QueryMFA(ctx context.Context, userID uuid.UUID) (authbus.MFA, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, secret, enabled, last_step, created_at, updated_at
+	FROM
+		user_mfa
+	WHERE
+		user_id = :user_id`
+
+	var dbMFA mfa
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbMFA); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.MFA{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.MFA{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusMFA(dbMFA), nil
+}
+
+// ReplaceRecoveryCodes replaces all the recovery codes for a user.
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []authbus.RecoveryCode) error {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const qDelete = `
+	DELETE FROM
+		mfa_recovery_codes
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, qDelete, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const qInsert = `
+	INSERT INTO mfa_recovery_codes
+		(user_id, code_hash, used_at)
+	VALUES
+		(:user_id, :code_hash, :used_at)`
+
+	for _, rc := range codes {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, qInsert, toDBRecoveryCode(rc)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// QueryRecoveryCodes gets the recovery codes for a user.
+func (s *Store) QueryRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]authbus.RecoveryCode, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, code_hash, used_at
+	FROM
+		mfa_recovery_codes
+	WHERE
+		user_id = :user_id`
+
+	var dbCodes []recoveryCode
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCodes); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusRecoveryCodes(dbCodes), nil
+}
+
+// UseRecoveryCode marks a recovery code as used.
+func (s *Store) UseRecoveryCode(ctx context.Context, rc authbus.RecoveryCode) error {
+	const q = `
+	UPDATE
+		mfa_recovery_codes
+	SET
+		used_at = :used_at
+	WHERE
+		user_id = :user_id AND
+		code_hash = :code_hash`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRecoveryCode(rc)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMFAChallenge inserts a new login challenge into the database.
+func (s *Store) CreateMFAChallenge(ctx context.Context, ch authbus.MFAChallenge) error {
+	const q = `
+	INSERT INTO mfa_challenges
+		(token, user_id, attempts, expiry_at)
+	VALUES
+		(:token, :user_id, :attempts, :expiry_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFAChallenge(ch)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateMFAChallenge stores the failed attempts for a login challenge.
+func (s *Store) UpdateMFAChallenge(ctx context.Context, ch authbus.MFAChallenge) error {
+	const q = `
+	UPDATE
+		mfa_challenges
+	SET
+		attempts = :attempts
+	WHERE
+		token = :token`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFAChallenge(ch)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMFAChallenge removes a login challenge from the database.
+func (s *Store) DeleteMFAChallenge(ctx context.Context, ch authbus.MFAChallenge) error {
+	const q = `
+	DELETE FROM
+		mfa_challenges
+	WHERE
+		token = :token`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMFAChallenge(ch)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryMFAChallenge gets the specified login challenge from the database.
+func (s *Store) QueryMFAChallenge(ctx context.Context, token string) (authbus.MFAChallenge, error) {
+	data := struct {
+		Token string `db:"token"`
+	}{
+		Token: token,
+	}
+
+	const q = `
+	SELECT
+		token, user_id, attempts, expiry_at
+	FROM
+		mfa_challenges
+	WHERE
+		token = :token`
+
+	var dbCh mfaChallenge
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbCh); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.MFAChallenge{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.MFAChallenge{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusMFAChallenge(dbCh), nil
+}
+
+// CreateSession inserts a new session into the database.
+func (s *Store) CreateSession(ctx context.Context, sess authbus.Session) error {
+	const q = `
+	INSERT INTO sessions
+		(id, family_id, user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at, rotated_at, revoked_at)
+	VALUES
+		(:id, :family_id, :user_id, :token_hash, :user_agent, :ip, :created_at, :last_used_at, :expires_at, :rotated_at, :revoked_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSession(sess)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RotateSession marks the session as rotated. The update only applies to a
+// session that wasn't rotated yet, so when two requests race with the same
+// refresh token only one of them wins and the other gets
+// authbus.ErrSessionReused.
+func (s *Store) RotateSession(ctx context.Context, sess authbus.Session) error {
+	const q = `
+	UPDATE
+		sessions
+	SET
+		last_used_at = :last_used_at,
+		rotated_at = :rotated_at
+	WHERE
+		id = :id AND
+		rotated_at IS NULL`
+
+	result, err := sqldb.NamedExecContextResult(ctx, s.log, s.db, q, toDBSession(sess))
+	if err != nil {
+		return fmt.Errorf("namedexeccontextresult: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("db: %w", authbus.ErrSessionReused)
+	}
+
+	return nil
+}
+
+// RevokeSessions marks every session matching the filter as revoked.
+func (s *Store) RevokeSessions(ctx context.Context, filter authbus.SessionFilter, revokedAt time.Time) error {
+	data := map[string]any{
+		"revoked_at": revokedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		sessions
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		revoked_at IS NULL`
+
+	buf := bytes.NewBufferString(q)
+
+	if filter.UserID != nil {
+		data["user_id"] = filter.UserID.String()
+		buf.WriteString(" AND user_id = :user_id")
+	}
+
+	if filter.FamilyID != nil {
+		data["family_id"] = filter.FamilyID.String()
+		buf.WriteString(" AND family_id = :family_id")
+	}
+
+	if len(data) == 1 {
+		return errors.New("session filter is empty")
+	}
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, buf.String(), data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySessionByTokenHash gets the session holding the specified token hash.
+func (s *Store) QuerySessionByTokenHash(ctx context.Context, tokenHash string) (authbus.Session, error) {
+	data := struct {
+		TokenHash string `db:"token_hash"`
+	}{
+		TokenHash: tokenHash,
+	}
+
+	const q = `
+	SELECT
+		id, family_id, user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at, rotated_at, revoked_at
+	FROM
+		sessions
+	WHERE
+		token_hash = :token_hash`
+
+	var dbSess session
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSess); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.Session{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.Session{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSession(dbSess), nil
+}
+
+// QuerySessions gets the active sessions for a user, most recently used first.
+func (s *Store) QuerySessions(ctx context.Context, userID uuid.UUID, now time.Time) ([]authbus.Session, error) {
+	data := map[string]any{
+		"user_id": userID.String(),
+		"now":     now.UTC(),
+	}
+
+	const q = `
+	SELECT
+		id, family_id, user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at, rotated_at, revoked_at
+	FROM
+		sessions
+	WHERE
+		user_id = :user_id AND
+		rotated_at IS NULL AND
+		revoked_at IS NULL AND
+		expires_at > :now
+	ORDER BY
+		last_used_at DESC`
+
+	var dbSessions []session
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSessions); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSessions(dbSessions), nil
+}
+
+// CreateRevokedToken inserts a revoked access token into the database.
+func (s *Store) CreateRevokedToken(ctx context.Context, rt authbus.RevokedToken) error {
+	const q = `
+	INSERT INTO revoked_tokens
+		(id, user_id, expires_at, revoked_at)
+	VALUES
+		(:id, :user_id, :expires_at, :revoked_at)
+	ON DUPLICATE KEY UPDATE
+		revoked_at = VALUES(revoked_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRevokedToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	s.cacheSet(revokedTokenKey(rt.ID), rt.ExpiresAt)
+
+	return nil
+}
+
+// DeleteExpiredRevokedTokens removes the revoked tokens that have expired.
+func (s *Store) DeleteExpiredRevokedTokens(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		revoked_tokens
+	WHERE
+		expires_at < :now`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRevokedToken gets the specified revoked token. Misses are cached too
+// since most tokens checked are not revoked.
+func (s *Store) QueryRevokedToken(ctx context.Context, tokenID string) (authbus.RevokedToken, error) {
+	key := revokedTokenKey(tokenID)
+
+	if expiresAt, exists := s.cacheGet(key); exists {
+		if expiresAt.IsZero() {
+			return authbus.RevokedToken{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.RevokedToken{ID: tokenID, ExpiresAt: expiresAt}, nil
+	}
+
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: tokenID,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, expires_at, revoked_at
+	FROM
+		revoked_tokens
+	WHERE
+		id = :id`
+
+	var dbRT revokedToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			s.cacheSet(key, time.Time{})
+			return authbus.RevokedToken{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.RevokedToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	rt := toBusRevokedToken(dbRT)
+	s.cacheSet(key, rt.ExpiresAt)
+
+	return rt, nil
+}
+
+// UpsertUserRevocation inserts or updates the token revocation for a user.
+func (s *Store) UpsertUserRevocation(ctx context.Context, ur authbus.UserRevocation) error {
+	const q = `
+	INSERT INTO user_token_revocations
+		(user_id, revoked_before)
+	VALUES
+		(:user_id, :revoked_before)
+	ON DUPLICATE KEY UPDATE
+		revoked_before = VALUES(revoked_before)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUserRevocation(ur)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	s.cacheSet(userRevocationKey(ur.UserID), ur.RevokedBefore)
+
+	return nil
+}
+
+// QueryUserRevocation gets the token revocation for a user.
+func (s *Store) QueryUserRevocation(ctx context.Context, userID uuid.UUID) (authbus.UserRevocation, error) {
+	key := userRevocationKey(userID)
+
+	if revokedBefore, exists := s.cacheGet(key); exists {
+		if revokedBefore.IsZero() {
+			return authbus.UserRevocation{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.UserRevocation{UserID: userID, RevokedBefore: revokedBefore}, nil
+	}
+
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, revoked_before
+	FROM
+		user_token_revocations
+	WHERE
+		user_id = :user_id`
+
+	var dbUR userRevocation
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUR); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			s.cacheSet(key, time.Time{})
+			return authbus.UserRevocation{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.UserRevocation{}, fmt.Errorf("db: %w", err)
+	}
+
+	ur := toBusUserRevocation(dbUR)
+	s.cacheSet(key, ur.RevokedBefore)
+
+	return ur, nil
+}
+
+// CreateOAuthState inserts a pending provider login into the database.
+func (s *Store) CreateOAuthState(ctx context.Context, st authbus.OAuthState) error {
+	const q = `
+	INSERT INTO oauth_states
+		(state, provider, session, expiry_at)
+	VALUES
+		(:state, :provider, :session, :expiry_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOAuthState(st)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOAuthState removes a pending provider login from the database.
+func (s *Store) DeleteOAuthState(ctx context.Context, st authbus.OAuthState) error {
+	const q = `
+	DELETE FROM
+		oauth_states
+	WHERE
+		state = :state`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOAuthState(st)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredOAuthStates removes the provider logins that were never
+// completed.
+func (s *Store) DeleteExpiredOAuthStates(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now.UTC(),
+	}
+
+	const q = `
+	DELETE FROM
+		oauth_states
+	WHERE
+		expiry_at < :now`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryOAuthState gets the specified pending provider login from the database.
+func (s *Store) QueryOAuthState(ctx context.Context, state string) (authbus.OAuthState, error) {
+	data := struct {
+		State string `db:"state"`
+	}{
+		State: state,
+	}
+
+	const q = `
+	SELECT
+		state, provider, session, expiry_at
+	FROM
+		oauth_states
+	WHERE
+		state = :state`
+
+	var dbSt oauthState
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSt); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.OAuthState{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.OAuthState{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusOAuthState(dbSt), nil
+}
+
+// CreateIdentity inserts a new provider identity link into the database.
+func (s *Store) CreateIdentity(ctx context.Context, idn authbus.Identity) error {
+	const q = `
+	INSERT INTO user_identities
+		(provider, subject, user_id, email, created_at, last_used_at)
+	VALUES
+		(:provider, :subject, :user_id, :email, :created_at, :last_used_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", authbus.ErrIdentityLinked)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateIdentity stores the email and last use of a provider identity.
+func (s *Store) UpdateIdentity(ctx context.Context, idn authbus.Identity) error {
+	const q = `
+	UPDATE
+		user_identities
+	SET
+		email = :email,
+		last_used_at = :last_used_at
+	WHERE
+		provider = :provider AND
+		subject = :subject`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryIdentity gets the specified provider identity from the database.
+func (s *Store) QueryIdentity(ctx context.Context, provider string, subject string) (authbus.Identity, error) {
+	data := struct {
+		Provider string `db:"provider"`
+		Subject  string `db:"subject"`
+	}{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	const q = `
+	SELECT
+		provider, subject, user_id, email, created_at, last_used_at
+	FROM
+		user_identities
+	WHERE
+		provider = :provider AND
+		subject = :subject`
+
+	var dbIdn identity
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdn); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.Identity{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.Identity{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusIdentity(dbIdn), nil
+}
+
+// CreateClient inserts a new client registration into the database.
+func (s *Store) CreateClient(ctx context.Context, clt authbus.Client) error {
+	const q = `
+	INSERT INTO oauth_clients
+		(id, name, secret_hash, scopes, owner_id, created_at)
+	VALUES
+		(:id, :name, :secret_hash, :scopes, :owner_id, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBClient(clt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteClient removes a client registration from the database.
+func (s *Store) DeleteClient(ctx context.Context, clt authbus.Client) error {
+	const q = `
+	DELETE FROM
+		oauth_clients
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBClient(clt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryClientByID gets the specified client registration from the database.
+func (s *Store) QueryClientByID(ctx context.Context, clientID uuid.UUID) (authbus.Client, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: clientID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, name, secret_hash, scopes, owner_id, created_at
+	FROM
+		oauth_clients
+	WHERE
+		id = :id`
+
+	var dbClt client
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbClt); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.Client{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.Client{}, fmt.Errorf("db: %w", err)
+	}
+
+	clt, err := toBusClient(dbClt)
+	if err != nil {
+		return authbus.Client{}, err
+	}
+
+	return clt, nil
+}
+
+// QueryClients gets every client registration from the database.
+func (s *Store) QueryClients(ctx context.Context) ([]authbus.Client, error) {
+	const q = `
+	SELECT
+		id, name, secret_hash, scopes, owner_id, created_at
+	FROM
+		oauth_clients
+	ORDER BY
+		name`
+
+	var dbClts []client
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, struct{}{}, &dbClts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusClients(dbClts)
+}
+
+// CreateAPIKey inserts a new personal API key into the database.
+func (s *Store) CreateAPIKey(ctx context.Context, key authbus.APIKey) error {
+	const q = `
+	INSERT INTO api_keys
+		(id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at)
+	VALUES
+		(:id, :user_id, :name, :prefix, :key_hash, :scopes, :expires_at, :last_used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAPIKey stores the last use of a personal API key.
+func (s *Store) UpdateAPIKey(ctx context.Context, key authbus.APIKey) error {
+	const q = `
+	UPDATE
+		api_keys
+	SET
+		last_used_at = :last_used_at
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAPIKey removes a personal API key from the database.
+func (s *Store) DeleteAPIKey(ctx context.Context, key authbus.APIKey) error {
+	const q = `
+	DELETE FROM
+		api_keys
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryAPIKeyByID gets the specified personal API key from the database.
+func (s *Store) QueryAPIKeyByID(ctx context.Context, keyID uuid.UUID) (authbus.APIKey, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: keyID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		id = :id`
+
+	return s.queryAPIKey(ctx, q, data)
+}
+
+// QueryAPIKeyByPrefix gets the personal API key with the specified prefix
+// from the database.
+func (s *Store) QueryAPIKeyByPrefix(ctx context.Context, prefix string) (authbus.APIKey, error) {
+	data := struct {
+		Prefix string `db:"prefix"`
+	}{
+		Prefix: prefix,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		prefix = :prefix`
+
+	return s.queryAPIKey(ctx, q, data)
+}
+
+// QueryAPIKeys gets the personal API keys of the user from the database.
+func (s *Store) QueryAPIKeys(ctx context.Context, userID uuid.UUID) ([]authbus.APIKey, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at DESC`
+
+	var dbKeys []apiKey
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbKeys); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusAPIKeys(dbKeys)
+}
+
+func (s *Store) queryAPIKey(ctx context.Context, q string, data any) (authbus.APIKey, error) {
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return authbus.APIKey{}, fmt.Errorf("db: %w", authbus.ErrNotFound)
+		}
+		return authbus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
+
+// cacheGet returns the cached value for the key, reporting a miss when the
+// store is not caching.
+func (s *Store) cacheGet(key string) (time.Time, bool) {
+	if s.cache == nil {
+		return time.Time{}, false
+	}
+
+	return s.cache.Get(key)
+}
+
+// cacheSet caches the value for the key when the store is caching.
+func (s *Store) cacheSet(key string, value time.Time) {
+	if s.cache == nil {
+		return
+	}
+
+	s.cache.Set(key, value)
+}
+
+func revokedTokenKey(tokenID string) string {
+	return "token:" + tokenID
+}
+
+func userRevocationKey(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
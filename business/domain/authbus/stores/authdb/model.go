@@ -1,9 +1,15 @@
 package authdb
 
 import (
+	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/types/permission"
 )
 
 type PasswordResetToken struct {
@@ -28,3 +34,399 @@ func toBusPasswordResetToken(db PasswordResetToken) authbus.PasswordResetToken {
 		ExpiryAt: db.ExpiryAt,
 	}
 }
+
+type loginFailure struct {
+	IP          string       `db:"ip"`
+	Failures    int          `db:"failures"`
+	LockedUntil sql.NullTime `db:"locked_until"`
+	DateUpdated time.Time    `db:"updated_at"`
+}
+
+func toBusLoginFailure(db loginFailure) authbus.LoginFailure {
+	bus := authbus.LoginFailure{
+		IP:          db.IP,
+		Failures:    db.Failures,
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	if db.LockedUntil.Valid {
+		bus.LockedUntil = db.LockedUntil.Time.In(time.Local)
+	}
+
+	return bus
+}
+
+type mfa struct {
+	UserID      uuid.UUID `db:"user_id"`
+	Secret      string    `db:"secret"`
+	Enabled     bool      `db:"enabled"`
+	LastStep    int64     `db:"last_step"`
+	DateCreated time.Time `db:"created_at"`
+	DateUpdated time.Time `db:"updated_at"`
+}
+
+func toDBMFA(bus authbus.MFA) mfa {
+	return mfa{
+		UserID:      bus.UserID,
+		Secret:      bus.Secret,
+		Enabled:     bus.Enabled,
+		LastStep:    bus.LastStep,
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusMFA(db mfa) authbus.MFA {
+	return authbus.MFA{
+		UserID:      db.UserID,
+		Secret:      db.Secret,
+		Enabled:     db.Enabled,
+		LastStep:    db.LastStep,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+}
+
+type recoveryCode struct {
+	UserID   uuid.UUID    `db:"user_id"`
+	CodeHash string       `db:"code_hash"`
+	DateUsed sql.NullTime `db:"used_at"`
+}
+
+func toDBRecoveryCode(bus authbus.RecoveryCode) recoveryCode {
+	return recoveryCode{
+		UserID:   bus.UserID,
+		CodeHash: bus.CodeHash,
+		DateUsed: sql.NullTime{
+			Time:  bus.DateUsed.UTC(),
+			Valid: !bus.DateUsed.IsZero(),
+		},
+	}
+}
+
+func toBusRecoveryCodes(dbs []recoveryCode) []authbus.RecoveryCode {
+	bus := make([]authbus.RecoveryCode, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = authbus.RecoveryCode{
+			UserID:   db.UserID,
+			CodeHash: db.CodeHash,
+		}
+
+		if db.DateUsed.Valid {
+			bus[i].DateUsed = db.DateUsed.Time.In(time.Local)
+		}
+	}
+
+	return bus
+}
+
+type mfaChallenge struct {
+	Token    string    `db:"token"`
+	UserID   uuid.UUID `db:"user_id"`
+	Attempts int       `db:"attempts"`
+	ExpiryAt time.Time `db:"expiry_at"`
+}
+
+func toDBMFAChallenge(bus authbus.MFAChallenge) mfaChallenge {
+	return mfaChallenge{
+		Token:    bus.Token,
+		UserID:   bus.UserID,
+		Attempts: bus.Attempts,
+		ExpiryAt: bus.ExpiryAt.UTC(),
+	}
+}
+
+func toBusMFAChallenge(db mfaChallenge) authbus.MFAChallenge {
+	return authbus.MFAChallenge{
+		Token:    db.Token,
+		UserID:   db.UserID,
+		Attempts: db.Attempts,
+		ExpiryAt: db.ExpiryAt.In(time.Local),
+	}
+}
+
+type session struct {
+	ID           uuid.UUID    `db:"id"`
+	FamilyID     uuid.UUID    `db:"family_id"`
+	UserID       uuid.UUID    `db:"user_id"`
+	TokenHash    string       `db:"token_hash"`
+	UserAgent    string       `db:"user_agent"`
+	IP           string       `db:"ip"`
+	DateCreated  time.Time    `db:"created_at"`
+	DateLastUsed time.Time    `db:"last_used_at"`
+	ExpiresAt    time.Time    `db:"expires_at"`
+	DateRotated  sql.NullTime `db:"rotated_at"`
+	DateRevoked  sql.NullTime `db:"revoked_at"`
+}
+
+func toDBSession(bus authbus.Session) session {
+	return session{
+		ID:           bus.ID,
+		FamilyID:     bus.FamilyID,
+		UserID:       bus.UserID,
+		TokenHash:    bus.TokenHash,
+		UserAgent:    bus.UserAgent,
+		IP:           bus.IP,
+		DateCreated:  bus.DateCreated.UTC(),
+		DateLastUsed: bus.DateLastUsed.UTC(),
+		ExpiresAt:    bus.ExpiresAt.UTC(),
+		DateRotated:  toNullTime(bus.DateRotated),
+		DateRevoked:  toNullTime(bus.DateRevoked),
+	}
+}
+
+func toBusSession(db session) authbus.Session {
+	return authbus.Session{
+		ID:           db.ID,
+		FamilyID:     db.FamilyID,
+		UserID:       db.UserID,
+		TokenHash:    db.TokenHash,
+		UserAgent:    db.UserAgent,
+		IP:           db.IP,
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateLastUsed: db.DateLastUsed.In(time.Local),
+		ExpiresAt:    db.ExpiresAt.In(time.Local),
+		DateRotated:  toBusTime(db.DateRotated),
+		DateRevoked:  toBusTime(db.DateRevoked),
+	}
+}
+
+func toBusSessions(dbs []session) []authbus.Session {
+	bus := make([]authbus.Session, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusSession(db)
+	}
+
+	return bus
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
+
+func toBusTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
+
+type revokedToken struct {
+	ID          string    `db:"id"`
+	UserID      uuid.UUID `db:"user_id"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	DateRevoked time.Time `db:"revoked_at"`
+}
+
+func toDBRevokedToken(bus authbus.RevokedToken) revokedToken {
+	return revokedToken{
+		ID:          bus.ID,
+		UserID:      bus.UserID,
+		ExpiresAt:   bus.ExpiresAt.UTC(),
+		DateRevoked: bus.DateRevoked.UTC(),
+	}
+}
+
+func toBusRevokedToken(db revokedToken) authbus.RevokedToken {
+	return authbus.RevokedToken{
+		ID:          db.ID,
+		UserID:      db.UserID,
+		ExpiresAt:   db.ExpiresAt.In(time.Local),
+		DateRevoked: db.DateRevoked.In(time.Local),
+	}
+}
+
+type userRevocation struct {
+	UserID        uuid.UUID `db:"user_id"`
+	RevokedBefore time.Time `db:"revoked_before"`
+}
+
+func toDBUserRevocation(bus authbus.UserRevocation) userRevocation {
+	return userRevocation{
+		UserID:        bus.UserID,
+		RevokedBefore: bus.RevokedBefore.UTC(),
+	}
+}
+
+func toBusUserRevocation(db userRevocation) authbus.UserRevocation {
+	return authbus.UserRevocation{
+		UserID:        db.UserID,
+		RevokedBefore: db.RevokedBefore.In(time.Local),
+	}
+}
+
+type oauthState struct {
+	State    string    `db:"state"`
+	Provider string    `db:"provider"`
+	Session  string    `db:"session"`
+	ExpiryAt time.Time `db:"expiry_at"`
+}
+
+func toDBOAuthState(bus authbus.OAuthState) oauthState {
+	return oauthState{
+		State:    bus.State,
+		Provider: bus.Provider,
+		Session:  bus.Session,
+		ExpiryAt: bus.ExpiryAt.UTC(),
+	}
+}
+
+func toBusOAuthState(db oauthState) authbus.OAuthState {
+	return authbus.OAuthState{
+		State:    db.State,
+		Provider: db.Provider,
+		Session:  db.Session,
+		ExpiryAt: db.ExpiryAt.In(time.Local),
+	}
+}
+
+type identity struct {
+	Provider     string    `db:"provider"`
+	Subject      string    `db:"subject"`
+	UserID       uuid.UUID `db:"user_id"`
+	Email        string    `db:"email"`
+	DateCreated  time.Time `db:"created_at"`
+	DateLastUsed time.Time `db:"last_used_at"`
+}
+
+func toDBIdentity(bus authbus.Identity) identity {
+	return identity{
+		Provider:     bus.Provider,
+		Subject:      bus.Subject,
+		UserID:       bus.UserID,
+		Email:        bus.Email,
+		DateCreated:  bus.DateCreated.UTC(),
+		DateLastUsed: bus.DateLastUsed.UTC(),
+	}
+}
+
+func toBusIdentity(db identity) authbus.Identity {
+	return authbus.Identity{
+		Provider:     db.Provider,
+		Subject:      db.Subject,
+		UserID:       db.UserID,
+		Email:        db.Email,
+		DateCreated:  db.DateCreated.In(time.Local),
+		DateLastUsed: db.DateLastUsed.In(time.Local),
+	}
+}
+
+type client struct {
+	ID          uuid.UUID `db:"id"`
+	Name        string    `db:"name"`
+	SecretHash  string    `db:"secret_hash"`
+	Scopes      string    `db:"scopes"`
+	OwnerID     uuid.UUID `db:"owner_id"`
+	DateCreated time.Time `db:"created_at"`
+}
+
+// The scopes are stored space separated, the way OAuth2 writes them.
+func toDBClient(bus authbus.Client) client {
+	return client{
+		ID:          bus.ID,
+		Name:        bus.Name,
+		SecretHash:  bus.SecretHash,
+		Scopes:      strings.Join(permission.ParseToString(bus.Scopes), " "),
+		OwnerID:     bus.OwnerID,
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusClient(db client) (authbus.Client, error) {
+	scopes, err := permission.ParseMany(strings.Fields(db.Scopes))
+	if err != nil {
+		return authbus.Client{}, fmt.Errorf("parse scopes: %w", err)
+	}
+
+	bus := authbus.Client{
+		ID:          db.ID,
+		Name:        db.Name,
+		SecretHash:  db.SecretHash,
+		Scopes:      scopes,
+		OwnerID:     db.OwnerID,
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusClients(dbs []client) ([]authbus.Client, error) {
+	bus := make([]authbus.Client, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusClient(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+type apiKey struct {
+	ID           uuid.UUID    `db:"id"`
+	UserID       uuid.UUID    `db:"user_id"`
+	Name         string       `db:"name"`
+	Prefix       string       `db:"prefix"`
+	KeyHash      string       `db:"key_hash"`
+	Scopes       string       `db:"scopes"`
+	ExpiresAt    sql.NullTime `db:"expires_at"`
+	DateLastUsed sql.NullTime `db:"last_used_at"`
+	DateCreated  time.Time    `db:"created_at"`
+}
+
+func toDBAPIKey(bus authbus.APIKey) apiKey {
+	return apiKey{
+		ID:           bus.ID,
+		UserID:       bus.UserID,
+		Name:         bus.Name,
+		Prefix:       bus.Prefix,
+		KeyHash:      bus.KeyHash,
+		Scopes:       strings.Join(permission.ParseToString(bus.Scopes), " "),
+		ExpiresAt:    toNullTime(bus.ExpiresAt),
+		DateLastUsed: toNullTime(bus.DateLastUsed),
+		DateCreated:  bus.DateCreated.UTC(),
+	}
+}
+
+func toBusAPIKey(db apiKey) (authbus.APIKey, error) {
+	scopes, err := permission.ParseMany(strings.Fields(db.Scopes))
+	if err != nil {
+		return authbus.APIKey{}, fmt.Errorf("parse scopes: %w", err)
+	}
+
+	bus := authbus.APIKey{
+		ID:           db.ID,
+		UserID:       db.UserID,
+		Name:         db.Name,
+		Prefix:       db.Prefix,
+		KeyHash:      db.KeyHash,
+		Scopes:       scopes,
+		ExpiresAt:    toBusTime(db.ExpiresAt),
+		DateLastUsed: toBusTime(db.DateLastUsed),
+		DateCreated:  db.DateCreated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusAPIKeys(dbs []apiKey) ([]authbus.APIKey, error) {
+	bus := make([]authbus.APIKey, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusAPIKey(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
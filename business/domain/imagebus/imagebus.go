@@ -0,0 +1,302 @@
+// Package imagebus provides business access to product image domain.
+package imagebus
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/blob"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("image not found")
+	ErrUnsupportedType = errors.New("image type not supported")
+	ErrTooLarge        = errors.New("image too large")
+	ErrDuplicate       = errors.New("image already added to product")
+	ErrInvalidOrder    = errors.New("image order not valid")
+)
+
+// Set of limits applied to the images added to products.
+const (
+	MaxSize       = 5 << 20
+	MaxPixels     = 40_000_000
+	ThumbnailSize = 200
+)
+
+// extensions maps the supported content types to the file extension used
+// when storing them.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, img Image) error
+	Delete(ctx context.Context, img Image) error
+	UpdatePositions(ctx context.Context, imgs []Image) error
+	QueryByID(ctx context.Context, imageID uuid.UUID) (Image, error)
+	QueryByProduct(ctx context.Context, productID uuid.UUID) ([]Image, error)
+}
+
+// Business manages the set of APIs for image access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+	blobs  blob.Store
+}
+
+// NewBusiness constructs an image business API for use. The content of the
+// images and their thumbnails is kept in the blob store.
+func NewBusiness(log *logger.Logger, storer Storer, blobs blob.Store) *Business {
+	b := Business{
+		log:    log,
+		storer: storer,
+		blobs:  blobs,
+	}
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+		blobs:  b.blobs,
+	}
+
+	return &bus, nil
+}
+
+// Create validates the image, generates its thumbnail and adds it after the
+// existing images of the product. The same content can only be added once to
+// a product.
+func (b *Business) Create(ctx context.Context, ni NewImage) (Image, error) {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.create")
+	defer span.End()
+
+	if len(ni.Data) > MaxSize {
+		return Image{}, fmt.Errorf("size[%d] over %d bytes: %w", len(ni.Data), MaxSize, ErrTooLarge)
+	}
+
+	contentType := http.DetectContentType(ni.Data)
+	if _, exists := extensions[contentType]; !exists {
+		return Image{}, fmt.Errorf("content type[%s]: %w", contentType, ErrUnsupportedType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(ni.Data))
+	if err != nil {
+		return Image{}, fmt.Errorf("decode config: %s: %w", err, ErrUnsupportedType)
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Image{}, fmt.Errorf("dimensions[%dx%d] over %d pixels: %w", cfg.Width, cfg.Height, MaxPixels, ErrTooLarge)
+	}
+
+	sum := sha256.Sum256(ni.Data)
+	hash := hex.EncodeToString(sum[:])
+
+	imgs, err := b.storer.QueryByProduct(ctx, ni.ProductID)
+	if err != nil {
+		return Image{}, fmt.Errorf("query: productID[%s]: %w", ni.ProductID, err)
+	}
+
+	for _, img := range imgs {
+		if img.Hash == hash {
+			return Image{}, fmt.Errorf("imageID[%s]: %w", img.ID, ErrDuplicate)
+		}
+	}
+
+	img := Image{
+		ID:          uuid.New(),
+		ProductID:   ni.ProductID,
+		Position:    len(imgs) + 1,
+		ContentType: contentType,
+		Size:        int64(len(ni.Data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Hash:        hash,
+		DateCreated: time.Now(),
+	}
+
+	thumb, err := thumbnail(ni.Data)
+	if err != nil {
+		return Image{}, err
+	}
+
+	if err := b.blobs.Put(ctx, img.Key(), bytes.NewReader(ni.Data), img.ContentType); err != nil {
+		return Image{}, fmt.Errorf("put: %w", err)
+	}
+
+	if err := b.blobs.Put(ctx, img.ThumbnailKey(), bytes.NewReader(thumb), "image/png"); err != nil {
+		return Image{}, fmt.Errorf("put thumbnail: %w", err)
+	}
+
+	if err := b.storer.Create(ctx, img); err != nil {
+		return Image{}, fmt.Errorf("create: %w", err)
+	}
+
+	return img, nil
+}
+
+// Delete removes the image and moves the images after it up one position.
+func (b *Business) Delete(ctx context.Context, img Image) error {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.delete")
+	defer span.End()
+
+	if err := b.storer.Delete(ctx, img); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	imgs, err := b.storer.QueryByProduct(ctx, img.ProductID)
+	if err != nil {
+		return fmt.Errorf("query: productID[%s]: %w", img.ProductID, err)
+	}
+
+	for i := range imgs {
+		imgs[i].Position = i + 1
+	}
+
+	if err := b.storer.UpdatePositions(ctx, imgs); err != nil {
+		return fmt.Errorf("updatepositions: %w", err)
+	}
+
+	if err := b.blobs.Delete(ctx, img.Key()); err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+
+	if err := b.blobs.Delete(ctx, img.ThumbnailKey()); err != nil {
+		return fmt.Errorf("delete thumbnail: %w", err)
+	}
+
+	return nil
+}
+
+// Reorder sets the order of the images of a product. The set of image ids
+// must list every image of the product exactly once.
+func (b *Business) Reorder(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]Image, error) {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.reorder")
+	defer span.End()
+
+	imgs, err := b.storer.QueryByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	if len(imageIDs) != len(imgs) {
+		return nil, fmt.Errorf("got %d images, product has %d: %w", len(imageIDs), len(imgs), ErrInvalidOrder)
+	}
+
+	byID := make(map[uuid.UUID]Image, len(imgs))
+	for _, img := range imgs {
+		byID[img.ID] = img
+	}
+
+	ordered := make([]Image, len(imageIDs))
+	for i, imageID := range imageIDs {
+		img, exists := byID[imageID]
+		if !exists {
+			return nil, fmt.Errorf("imageID[%s] missing or repeated: %w", imageID, ErrInvalidOrder)
+		}
+		delete(byID, imageID)
+
+		img.Position = i + 1
+		ordered[i] = img
+	}
+
+	if err := b.storer.UpdatePositions(ctx, ordered); err != nil {
+		return nil, fmt.Errorf("updatepositions: %w", err)
+	}
+
+	return ordered, nil
+}
+
+// QueryByID finds the image by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, imageID uuid.UUID) (Image, error) {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.querybyid")
+	defer span.End()
+
+	img, err := b.storer.QueryByID(ctx, imageID)
+	if err != nil {
+		return Image{}, fmt.Errorf("query: imageID[%s]: %w", imageID, err)
+	}
+
+	return img, nil
+}
+
+// QueryByProduct retrieves the images of the specified product in order.
+func (b *Business) QueryByProduct(ctx context.Context, productID uuid.UUID) ([]Image, error) {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.querybyproduct")
+	defer span.End()
+
+	imgs, err := b.storer.QueryByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	return imgs, nil
+}
+
+// Open returns the content of the image, or of its thumbnail. The caller
+// must close the returned reader.
+func (b *Business) Open(ctx context.Context, img Image, thumb bool) (io.ReadCloser, blob.Info, error) {
+	ctx, span := otel.AddSpan(ctx, "business.imagebus.open")
+	defer span.End()
+
+	key := img.Key()
+	if thumb {
+		key = img.ThumbnailKey()
+	}
+
+	r, info, err := b.blobs.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, blob.Info{}, fmt.Errorf("get: %s: %w", err, ErrNotFound)
+		}
+		return nil, blob.Info{}, fmt.Errorf("get: %w", err)
+	}
+
+	return r, info, nil
+}
+
+// thumbnail decodes the image and encodes its thumbnail as PNG.
+func thumbnail(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode: %s: %w", err, ErrUnsupportedType)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Thumbnail(src, ThumbnailSize)); err != nil {
+		return nil, fmt.Errorf("encode thumbnail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
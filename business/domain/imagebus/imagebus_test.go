@@ -0,0 +1,306 @@
+package imagebus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func Test_Image(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Image")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, reorder(db.BusDomain, sd), "reorder")
+	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	imgs, err := imagebus.TestGenerateSeedImages(ctx, 3, busDomain.Image, prds[0].ID)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding images : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := unitest.SeedData{
+		Users:    []unitest.User{{User: usrs[0]}},
+		Products: prds,
+		Images:   imgs,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "byproduct",
+			ExpResp: positions(sd.Images),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Image.QueryByProduct(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return positions(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "thumbnail",
+			ExpResp: "200x100",
+			ExcFunc: func(ctx context.Context) any {
+				r, info, err := busDomain.Image.Open(ctx, sd.Images[0], true)
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+
+				if info.ContentType != "image/png" {
+					return fmt.Errorf("content type: %s", info.ContentType)
+				}
+
+				cfg, err := png.DecodeConfig(r)
+				if err != nil {
+					return err
+				}
+
+				return fmt.Sprintf("%dx%d", cfg.Width, cfg.Height)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	data := imagebus.TestGeneratePNG(40, 20)
+
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: imagebus.Image{
+				ProductID:   sd.Products[1].ID,
+				Position:    1,
+				ContentType: "image/png",
+				Size:        int64(len(data)),
+				Width:       40,
+				Height:      20,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Image.Create(ctx, imagebus.NewImage{ProductID: sd.Products[1].ID, Data: data})
+				if err != nil {
+					return err
+				}
+
+				r, _, err := busDomain.Image.Open(ctx, resp, false)
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+
+				stored, err := io.ReadAll(r)
+				if err != nil {
+					return err
+				}
+
+				if !bytes.Equal(stored, data) {
+					return errors.New("stored content does not match")
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(imagebus.Image)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(imagebus.Image)
+				expResp.ID = gotResp.ID
+				expResp.Hash = gotResp.Hash
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "duplicate",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Image.Create(ctx, imagebus.NewImage{ProductID: sd.Products[1].ID, Data: data})
+
+				return errors.Is(err, imagebus.ErrDuplicate)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "unsupported",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Image.Create(ctx, imagebus.NewImage{ProductID: sd.Products[1].ID, Data: []byte("not an image")})
+
+				return errors.Is(err, imagebus.ErrUnsupportedType)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "too-large",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Image.Create(ctx, imagebus.NewImage{ProductID: sd.Products[1].ID, Data: make([]byte, imagebus.MaxSize+1)})
+
+				return errors.Is(err, imagebus.ErrTooLarge)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func reorder(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: []uuid.UUID{sd.Images[2].ID, sd.Images[0].ID, sd.Images[1].ID},
+			ExcFunc: func(ctx context.Context) any {
+				ids := []uuid.UUID{sd.Images[2].ID, sd.Images[0].ID, sd.Images[1].ID}
+				if _, err := busDomain.Image.Reorder(ctx, sd.Products[0].ID, ids); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Image.QueryByProduct(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return imageIDs(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "missing",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				ids := []uuid.UUID{sd.Images[0].ID, sd.Images[0].ID, sd.Images[1].ID}
+				_, err := busDomain.Image.Reorder(ctx, sd.Products[0].ID, ids)
+
+				return errors.Is(err, imagebus.ErrInvalidOrder)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: []int{1, 2},
+			ExcFunc: func(ctx context.Context) any {
+				img, err := busDomain.Image.QueryByID(ctx, sd.Images[2].ID)
+				if err != nil {
+					return err
+				}
+
+				if err := busDomain.Image.Delete(ctx, img); err != nil {
+					return err
+				}
+
+				if _, _, err := busDomain.Image.Open(ctx, img, false); !errors.Is(err, imagebus.ErrNotFound) {
+					return fmt.Errorf("content should be removed: %v", err)
+				}
+
+				resp, err := busDomain.Image.QueryByProduct(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return positions(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+// =============================================================================
+
+func positions(imgs []imagebus.Image) []int {
+	pos := make([]int, len(imgs))
+	for i, img := range imgs {
+		pos[i] = img.Position
+	}
+
+	return pos
+}
+
+func imageIDs(imgs []imagebus.Image) []uuid.UUID {
+	ids := make([]uuid.UUID, len(imgs))
+	for i, img := range imgs {
+		ids[i] = img.ID
+	}
+
+	return ids
+}
@@ -0,0 +1,40 @@
+package imagebus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Image represents an image of a product. The images of a product are ordered
+// by position, starting at 1, and the first one is the main image. Hash is the
+// hex encoded SHA-256 of the original content.
+type Image struct {
+	ID          uuid.UUID
+	ProductID   uuid.UUID
+	Position    int
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Hash        string
+	DateCreated time.Time
+}
+
+// Key returns the blob key the original image is stored under.
+func (img Image) Key() string {
+	return fmt.Sprintf("products/%s/%s%s", img.ProductID, img.Hash, extensions[img.ContentType])
+}
+
+// ThumbnailKey returns the blob key the thumbnail of the image is stored
+// under. Thumbnails are always encoded as PNG.
+func (img Image) ThumbnailKey() string {
+	return fmt.Sprintf("products/%s/%s_thumb.png", img.ProductID, img.Hash)
+}
+
+// NewImage is what we require to add an image to a product.
+type NewImage struct {
+	ProductID uuid.UUID
+	Data      []byte
+}
@@ -0,0 +1,133 @@
+// Package imagedb contains product image related CRUD functionality.
+package imagedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// Store manages the set of APIs for image database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (imagebus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create adds an image to the sqldb.
+func (s *Store) Create(ctx context.Context, img imagebus.Image) error {
+	const q = `
+	INSERT INTO product_images
+		(id, product_id, position, content_type, size, width, height, hash, created_at)
+	VALUES
+		(:id, :product_id, :position, :content_type, :size, :width, :height, :hash, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBImage(img)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", imagebus.ErrDuplicate)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the image identified by a given ID.
+func (s *Store) Delete(ctx context.Context, img imagebus.Image) error {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: img.ID.String(),
+	}
+
+	const q = `DELETE FROM product_images WHERE id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePositions stores the position of each of the specified images.
+func (s *Store) UpdatePositions(ctx context.Context, imgs []imagebus.Image) error {
+	const q = `UPDATE product_images SET position = :position WHERE id = :id`
+
+	for _, img := range imgs {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBImage(img)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// QueryByID finds the image identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, imageID uuid.UUID) (imagebus.Image, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: imageID.String(),
+	}
+
+	const q = `SELECT * FROM product_images WHERE id = :id`
+
+	var dbImg image
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbImg); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return imagebus.Image{}, fmt.Errorf("namedquerystruct: %w", imagebus.ErrNotFound)
+		}
+		return imagebus.Image{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toBusImage(dbImg), nil
+}
+
+// QueryByProduct retrieves the images of the specified product ordered by
+// position.
+func (s *Store) QueryByProduct(ctx context.Context, productID uuid.UUID) ([]imagebus.Image, error) {
+	data := struct {
+		ProductID string `db:"product_id"`
+	}{
+		ProductID: productID.String(),
+	}
+
+	const q = `SELECT * FROM product_images WHERE product_id = :product_id ORDER BY position ASC`
+
+	var dbImgs []image
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbImgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusImages(dbImgs), nil
+}
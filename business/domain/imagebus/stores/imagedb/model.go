@@ -0,0 +1,63 @@
+package imagedb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+type image struct {
+	ID          uuid.UUID `db:"id"`
+	ProductID   uuid.UUID `db:"product_id"`
+	Position    int       `db:"position"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Width       int       `db:"width"`
+	Height      int       `db:"height"`
+	Hash        string    `db:"hash"`
+	DateCreated time.Time `db:"created_at"`
+}
+
+func toDBImage(bus imagebus.Image) image {
+	db := image{
+		ID:          bus.ID,
+		ProductID:   bus.ProductID,
+		Position:    bus.Position,
+		ContentType: bus.ContentType,
+		Size:        bus.Size,
+		Width:       bus.Width,
+		Height:      bus.Height,
+		Hash:        bus.Hash,
+		DateCreated: bus.DateCreated.UTC(),
+	}
+
+	return db
+}
+
+func toBusImage(db image) imagebus.Image {
+	bus := imagebus.Image{
+		ID:          db.ID,
+		ProductID:   db.ProductID,
+		Position:    db.Position,
+		ContentType: db.ContentType,
+		Size:        db.Size,
+		Width:       db.Width,
+		Height:      db.Height,
+		Hash:        db.Hash,
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+
+	return bus
+}
+
+func toBusImages(dbs []image) []imagebus.Image {
+	bus := make([]imagebus.Image, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = toBusImage(db)
+	}
+
+	return bus
+}
@@ -0,0 +1,54 @@
+package imagebus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// TestGeneratePNG is a helper method for testing. It returns a PNG of the
+// specified size filled with a random color so each call produces distinct
+// content.
+func TestGeneratePNG(width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	c := color.RGBA{R: uint8(rand.Intn(256)), G: uint8(rand.Intn(256)), B: uint8(rand.Intn(256)), A: 255}
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
+
+// TestGenerateSeedImages is a helper method for testing.
+func TestGenerateSeedImages(ctx context.Context, n int, api *Business, productID uuid.UUID) ([]Image, error) {
+	imgs := make([]Image, n)
+	for i := range n {
+		ni := NewImage{
+			ProductID: productID,
+			Data:      TestGeneratePNG(300+i, 150),
+		}
+
+		img, err := api.Create(ctx, ni)
+		if err != nil {
+			return nil, fmt.Errorf("seeding image: idx: %d : %w", i, err)
+		}
+
+		imgs[i] = img
+	}
+
+	return imgs, nil
+}
@@ -0,0 +1,46 @@
+package imagebus_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/rmsj/service/business/domain/imagebus"
+)
+
+func Test_Thumbnail(t *testing.T) {
+	table := []struct {
+		name          string
+		width, height int
+		expW, expH    int
+	}{
+		{name: "landscape", width: 400, height: 100, expW: 200, expH: 50},
+		{name: "portrait", width: 100, height: 800, expW: 25, expH: 200},
+		{name: "square", width: 300, height: 300, expW: 200, expH: 200},
+		{name: "small", width: 120, height: 80, expW: 120, expH: 80},
+		{name: "thin", width: 1000, height: 2, expW: 200, expH: 1},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			for y := range tt.height {
+				for x := range tt.width {
+					src.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+				}
+			}
+
+			dst := imagebus.Thumbnail(src, imagebus.ThumbnailSize)
+
+			b := dst.Bounds()
+			if b.Dx() != tt.expW || b.Dy() != tt.expH {
+				t.Fatalf("Should get a %dx%d thumbnail: got %dx%d", tt.expW, tt.expH, b.Dx(), b.Dy())
+			}
+
+			r, g, bl, a := dst.At(b.Dx()/2, b.Dy()/2).RGBA()
+			if r>>8 != 200 || g>>8 != 100 || bl>>8 != 50 || a>>8 != 255 {
+				t.Errorf("Should keep the color of the image: got %d %d %d %d", r>>8, g>>8, bl>>8, a>>8)
+			}
+		})
+	}
+}
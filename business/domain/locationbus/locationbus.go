@@ -0,0 +1,210 @@
+// Package locationbus provides business access to the locations, or shops,
+// the business operates from and the users assigned to each of them.
+package locationbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/timezone"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("location not found")
+	ErrUniqueName = errors.New("location name already exists")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, loc Location) error
+	Update(ctx context.Context, loc Location) error
+	Delete(ctx context.Context, loc Location) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Location, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, locationID uuid.UUID) (Location, error)
+	SetUsers(ctx context.Context, loc Location, userIDs []uuid.UUID) error
+	QueryUsers(ctx context.Context, loc Location) ([]uuid.UUID, error)
+	QueryIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Business manages the set of APIs for location access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a location business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	b := Business{
+		log:    log,
+		storer: storer,
+	}
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new location to the system.
+func (b *Business) Create(ctx context.Context, nl NewLocation) (Location, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.create")
+	defer span.End()
+
+	tz := nl.Timezone
+	if tz.Equal(timezone.Timezone{}) {
+		tz = timezone.Default
+	}
+
+	now := time.Now()
+
+	loc := Location{
+		ID:          uuid.New(),
+		Name:        nl.Name,
+		Address:     nl.Address,
+		Timezone:    tz,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, loc); err != nil {
+		return Location{}, fmt.Errorf("create: %w", err)
+	}
+
+	return loc, nil
+}
+
+// Update modifies information about a location.
+func (b *Business) Update(ctx context.Context, loc Location, ul UpdateLocation) (Location, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.update")
+	defer span.End()
+
+	if ul.Name != nil {
+		loc.Name = *ul.Name
+	}
+
+	if ul.Address != nil {
+		loc.Address = *ul.Address
+	}
+
+	if ul.Timezone != nil {
+		loc.Timezone = *ul.Timezone
+	}
+
+	loc.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, loc); err != nil {
+		return Location{}, fmt.Errorf("update: %w", err)
+	}
+
+	return loc, nil
+}
+
+// Delete removes the specified location along with its user assignments.
+func (b *Business) Delete(ctx context.Context, loc Location) error {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.delete")
+	defer span.End()
+
+	if err := b.storer.Delete(ctx, loc); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing locations.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Location, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.query")
+	defer span.End()
+
+	locs, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return locs, nil
+}
+
+// Count returns the total number of locations.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the location by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, locationID uuid.UUID) (Location, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.querybyid")
+	defer span.End()
+
+	loc, err := b.storer.QueryByID(ctx, locationID)
+	if err != nil {
+		return Location{}, fmt.Errorf("query: locationID[%s]: %w", locationID, err)
+	}
+
+	return loc, nil
+}
+
+// SetUsers replaces the users assigned to the location.
+func (b *Business) SetUsers(ctx context.Context, loc Location, userIDs []uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.setusers")
+	defer span.End()
+
+	if err := b.storer.SetUsers(ctx, loc, userIDs); err != nil {
+		return fmt.Errorf("setusers: locationID[%s]: %w", loc.ID, err)
+	}
+
+	return nil
+}
+
+// QueryUsers retrieves the users assigned to the location.
+func (b *Business) QueryUsers(ctx context.Context, loc Location) ([]uuid.UUID, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.queryusers")
+	defer span.End()
+
+	userIDs, err := b.storer.QueryUsers(ctx, loc)
+	if err != nil {
+		return nil, fmt.Errorf("queryusers: locationID[%s]: %w", loc.ID, err)
+	}
+
+	return userIDs, nil
+}
+
+// QueryIDsByUser retrieves the IDs of the locations the user is assigned to.
+func (b *Business) QueryIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := otel.AddSpan(ctx, "business.locationbus.queryidsbyuser")
+	defer span.End()
+
+	locIDs, err := b.storer.QueryIDsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryidsbyuser: userID[%s]: %w", userID, err)
+	}
+
+	return locIDs, nil
+}
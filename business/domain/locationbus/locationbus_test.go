@@ -0,0 +1,470 @@
+package locationbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/salebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
+	"github.com/rmsj/service/business/types/role"
+	"github.com/rmsj/service/business/types/timezone"
+)
+
+func Test_Location(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Location")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, update(db.BusDomain, sd), "update")
+	unitest.Run(t, stock(db.BusDomain, sd), "stock")
+	unitest.Run(t, sales(db.BusDomain, sd), "sales")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	locs, err := locationbus.TestGenerateSeedLocations(ctx, 2, busDomain.Location)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding locations : %w", err)
+	}
+
+	if err := busDomain.Location.SetUsers(ctx, locs[0], []uuid.UUID{usrs[0].ID}); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding location users : %w", err)
+	}
+
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 1, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	stock := quantity.MustParse(10, quantity.Piece)
+
+	prds[0], err = busDomain.Product.Update(ctx, prds[0], productbus.UpdateProduct{Stock: &stock})
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding product stock : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// One sale is made at the first location and another one at no location.
+
+	var sls []salebus.Sale
+	for _, locationID := range []uuid.UUID{locs[0].ID, uuid.Nil} {
+		ns := salebus.NewSale{
+			UserID:     usrs[0].ID,
+			LocationID: locationID,
+			Items: []salebus.NewSaleItem{
+				{ProductID: prds[0].ID, Quantity: quantity.MustParse(1, quantity.Piece), Price: prds[0].Price},
+			},
+		}
+
+		sl, err := busDomain.Sale.Create(ctx, ns)
+		if err != nil {
+			return unitest.SeedData{}, fmt.Errorf("seeding sales : %w", err)
+		}
+
+		sls = append(sls, sl)
+	}
+
+	sd := unitest.SeedData{
+		Users:     []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+		Products:  prds,
+		Sales:     sls,
+		Locations: locs,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func cmpLocation(got any, exp any) string {
+	gotResp, exists := got.(locationbus.Location)
+	if !exists {
+		return "error occurred"
+	}
+
+	expResp := exp.(locationbus.Location)
+
+	if expResp.ID == uuid.Nil {
+		expResp.ID = gotResp.ID
+	}
+
+	if gotResp.DateCreated.Format(time.RFC3339) == expResp.DateCreated.Format(time.RFC3339) {
+		expResp.DateCreated = gotResp.DateCreated
+	}
+
+	expResp.DateUpdated = gotResp.DateUpdated
+
+	return cmp.Diff(gotResp, expResp)
+}
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "byid",
+			ExpResp: sd.Locations[0],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Location.QueryByID(ctx, sd.Locations[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpLocation,
+		},
+		{
+			Name:    "byuser",
+			ExpResp: []uuid.UUID{sd.Locations[0].ID},
+			ExcFunc: func(ctx context.Context) any {
+				filter := locationbus.QueryFilter{
+					UserID: &sd.Users[0].ID,
+				}
+
+				resp, err := busDomain.Location.Query(ctx, filter, locationbus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				ids := make([]uuid.UUID, len(resp))
+				for i, loc := range resp {
+					ids[i] = loc.ID
+				}
+
+				return ids
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "users",
+			ExpResp: []uuid.UUID{sd.Users[0].ID},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Location.QueryUsers(ctx, sd.Locations[0])
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "unassigned",
+			ExpResp: []uuid.UUID{},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Location.QueryIDsByUser(ctx, sd.Users[1].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: locationbus.Location{
+				Name:     name.MustParse("Downtown"),
+				Address:  "1 Market Street",
+				Timezone: timezone.MustParse("Australia/Sydney"),
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nl := locationbus.NewLocation{
+					Name:     name.MustParse("Downtown"),
+					Address:  "1 Market Street",
+					Timezone: timezone.MustParse("Australia/Sydney"),
+				}
+
+				resp, err := busDomain.Location.Create(ctx, nl)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(locationbus.Location)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(locationbus.Location)
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmpLocation(gotResp, expResp)
+			},
+		},
+		{
+			Name: "default-timezone",
+			ExpResp: locationbus.Location{
+				Name:     name.MustParse("Warehouse"),
+				Timezone: timezone.Default,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nl := locationbus.NewLocation{
+					Name: name.MustParse("Warehouse"),
+				}
+
+				resp, err := busDomain.Location.Create(ctx, nl)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(locationbus.Location)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(locationbus.Location)
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmpLocation(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "unique-name",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				nl := locationbus.NewLocation{
+					Name: sd.Locations[1].Name,
+				}
+
+				_, err := busDomain.Location.Create(ctx, nl)
+				return errors.Is(err, locationbus.ErrUniqueName)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	tz := timezone.MustParse("Europe/Lisbon")
+
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: locationbus.Location{
+				ID:          sd.Locations[1].ID,
+				Name:        name.MustParse("Harbour"),
+				Address:     sd.Locations[1].Address,
+				Timezone:    tz,
+				DateCreated: sd.Locations[1].DateCreated,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				ul := locationbus.UpdateLocation{
+					Name:     dbtest.NamePointer("Harbour"),
+					Timezone: &tz,
+				}
+
+				resp, err := busDomain.Location.Update(ctx, sd.Locations[1], ul)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpLocation,
+		},
+	}
+
+	return table
+}
+
+func stock(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "set",
+			ExpResp: []float64{4},
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Product.SetLocationStock(ctx, sd.Products[0], sd.Locations[0].ID, quantity.MustParse(4, quantity.Piece)); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Product.QueryLocationStock(ctx, sd.Products[0])
+				if err != nil {
+					return err
+				}
+
+				stock := make([]float64, len(resp))
+				for i, ls := range resp {
+					stock[i] = ls.Stock.Value()
+				}
+
+				return stock
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "insufficient",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				prd, err := busDomain.Product.QueryByID(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				_, err = busDomain.Product.ConsumeLocationStock(ctx, prd, sd.Locations[0].ID, quantity.MustParse(5, quantity.Piece))
+				return errors.Is(err, productbus.ErrInsufficientStock)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "consume",
+			ExpResp: []float64{7, 1},
+			ExcFunc: func(ctx context.Context) any {
+				prd, err := busDomain.Product.QueryByID(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				prd, err = busDomain.Product.ConsumeLocationStock(ctx, prd, sd.Locations[0].ID, quantity.MustParse(3, quantity.Piece))
+				if err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Product.QueryLocationStock(ctx, prd)
+				if err != nil {
+					return err
+				}
+
+				return []float64{prd.Stock.Value(), resp[0].Stock.Value()}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "untracked",
+			ExpResp: 6.0,
+			ExcFunc: func(ctx context.Context) any {
+				prd, err := busDomain.Product.QueryByID(ctx, sd.Products[0].ID)
+				if err != nil {
+					return err
+				}
+
+				prd, err = busDomain.Product.ConsumeLocationStock(ctx, prd, sd.Locations[1].ID, quantity.MustParse(1, quantity.Piece))
+				if err != nil {
+					return err
+				}
+
+				return prd.Stock.Value()
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func sales(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	saleIDs := func(ctx context.Context, filter salebus.QueryFilter) any {
+		resp, err := busDomain.Sale.Query(ctx, filter, salebus.DefaultOrderBy, page.MustParse("1", "10"))
+		if err != nil {
+			return err
+		}
+
+		ids := make(map[uuid.UUID]uuid.UUID, len(resp))
+		for _, sl := range resp {
+			ids[sl.ID] = sl.LocationID
+		}
+
+		return ids
+	}
+
+	table := []unitest.Table{
+		{
+			Name: "by-location",
+			ExpResp: map[uuid.UUID]uuid.UUID{
+				sd.Sales[0].ID: sd.Locations[0].ID,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				return saleIDs(ctx, salebus.QueryFilter{LocationID: &sd.Locations[0].ID})
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "assigned-locations",
+			ExpResp: map[uuid.UUID]uuid.UUID{
+				sd.Sales[0].ID: sd.Locations[0].ID,
+				sd.Sales[1].ID: uuid.Nil,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				return saleIDs(ctx, salebus.QueryFilter{LocationIDs: []uuid.UUID{sd.Locations[0].ID}})
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "no-locations",
+			ExpResp: map[uuid.UUID]uuid.UUID{
+				sd.Sales[1].ID: uuid.Nil,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				return saleIDs(ctx, salebus.QueryFilter{LocationIDs: []uuid.UUID{}})
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
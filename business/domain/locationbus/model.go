@@ -0,0 +1,39 @@
+package locationbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/timezone"
+)
+
+// Location represents a shop where sales are made and stock is kept.
+type Location struct {
+	ID          uuid.UUID
+	Name        name.Name
+	Address     string
+	Timezone    timezone.Timezone
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewLocation is what we require from clients when adding a Location. The
+// location is in UTC when no Timezone is provided.
+type NewLocation struct {
+	Name     name.Name
+	Address  string
+	Timezone timezone.Timezone
+}
+
+// UpdateLocation defines what information may be provided to modify an
+// existing Location. All fields are optional so clients can send just the
+// fields they want changed. It uses pointer fields so we can differentiate
+// between a field that was not provided and a field that was provided as
+// explicitly blank.
+type UpdateLocation struct {
+	Name     *name.Name
+	Address  *string
+	Timezone *timezone.Timezone
+}
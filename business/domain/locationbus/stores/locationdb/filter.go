@@ -0,0 +1,33 @@
+package locationdb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/rmsj/service/business/domain/locationbus"
+)
+
+func (s *Store) applyFilter(filter locationbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["id"] = filter.ID
+		wc = append(wc, "id = :id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = fmt.Sprintf("%%%s%%", filter.Name)
+		wc = append(wc, "name LIKE :name")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = filter.UserID.String()
+		wc = append(wc, "id IN (SELECT location_id FROM user_locations WHERE user_id = :user_id)")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,288 @@
+// Package locationdb contains location related CRUD functionality.
+package locationdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// Store manages the set of APIs for location database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (locationbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new location into the database.
+func (s *Store) Create(ctx context.Context, loc locationbus.Location) error {
+	const q = `
+	INSERT INTO locations
+		(id, name, address, timezone, created_at, updated_at)
+	VALUES
+		(:id, :name, :address, :timezone, :created_at, :updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLocation(loc)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", locationbus.ErrUniqueName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a location document in the database.
+func (s *Store) Update(ctx context.Context, loc locationbus.Location) error {
+	const q = `
+	UPDATE
+		locations
+	SET
+		name = :name,
+		address = :address,
+		timezone = :timezone,
+		updated_at = :updated_at
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLocation(loc)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return locationbus.ErrUniqueName
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a location from the database. Its user assignments and
+// stock are removed by the database.
+func (s *Store) Delete(ctx context.Context, loc locationbus.Location) error {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: loc.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		locations
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing locations from the database.
+func (s *Store) Query(ctx context.Context, filter locationbus.QueryFilter, orderBy order.By, page page.Page) ([]locationbus.Location, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, address, timezone, created_at, updated_at
+	FROM
+		locations`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset")
+
+	var dbLocs []location
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbLocs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusLocations(dbLocs)
+}
+
+// Count returns the total number of locations in the DB.
+func (s *Store) Count(ctx context.Context, filter locationbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = "SELECT COUNT(id) AS `count` FROM locations"
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified location from the database.
+func (s *Store) QueryByID(ctx context.Context, locationID uuid.UUID) (locationbus.Location, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: locationID.String(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, address, timezone, created_at, updated_at
+	FROM
+		locations
+	WHERE
+		id = :id`
+
+	var dbLoc location
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLoc); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return locationbus.Location{}, fmt.Errorf("db: %w", locationbus.ErrNotFound)
+		}
+		return locationbus.Location{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusLocation(dbLoc)
+}
+
+// SetUsers replaces the users assigned to the location.
+func (s *Store) SetUsers(ctx context.Context, loc locationbus.Location, userIDs []uuid.UUID) error {
+	data := struct {
+		ID string `db:"location_id"`
+	}{
+		ID: loc.ID.String(),
+	}
+
+	const del = `
+	DELETE FROM
+		user_locations
+	WHERE
+		location_id = :location_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const ins = `
+	INSERT INTO user_locations
+		(location_id, user_id)
+	VALUES
+		(:location_id, :user_id)`
+
+	for _, userID := range userIDs {
+		row := struct {
+			LocationID string `db:"location_id"`
+			UserID     string `db:"user_id"`
+		}{
+			LocationID: loc.ID.String(),
+			UserID:     userID.String(),
+		}
+
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, ins, row); err != nil {
+			return fmt.Errorf("namedexeccontext: userID[%s]: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// QueryUsers retrieves the users assigned to the location.
+func (s *Store) QueryUsers(ctx context.Context, loc locationbus.Location) ([]uuid.UUID, error) {
+	data := struct {
+		ID string `db:"location_id"`
+	}{
+		ID: loc.ID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id AS id
+	FROM
+		user_locations
+	WHERE
+		location_id = :location_id
+	ORDER BY
+		user_id`
+
+	return s.queryIDs(ctx, q, data)
+}
+
+// QueryIDsByUser retrieves the IDs of the locations the user is assigned to.
+func (s *Store) QueryIDsByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		location_id AS id
+	FROM
+		user_locations
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		location_id`
+
+	return s.queryIDs(ctx, q, data)
+}
+
+func (s *Store) queryIDs(ctx context.Context, q string, data any) ([]uuid.UUID, error) {
+	var rows []struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &rows); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, r := range rows {
+		ids[i] = r.ID
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,71 @@
+package locationdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/timezone"
+)
+
+type location struct {
+	ID          uuid.UUID `db:"id"`
+	Name        string    `db:"name"`
+	Address     string    `db:"address"`
+	Timezone    string    `db:"timezone"`
+	DateCreated time.Time `db:"created_at"`
+	DateUpdated time.Time `db:"updated_at"`
+}
+
+func toDBLocation(bus locationbus.Location) location {
+	db := location{
+		ID:          bus.ID,
+		Name:        bus.Name.String(),
+		Address:     bus.Address,
+		Timezone:    bus.Timezone.String(),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+
+	return db
+}
+
+func toBusLocation(db location) (locationbus.Location, error) {
+	nme, err := name.Parse(db.Name)
+	if err != nil {
+		return locationbus.Location{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	tz, err := timezone.Parse(db.Timezone)
+	if err != nil {
+		return locationbus.Location{}, fmt.Errorf("parse timezone: %w", err)
+	}
+
+	bus := locationbus.Location{
+		ID:          db.ID,
+		Name:        nme,
+		Address:     db.Address,
+		Timezone:    tz,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusLocations(dbs []location) ([]locationbus.Location, error) {
+	bus := make([]locationbus.Location, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusLocation(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,22 @@
+package locationdb
+
+import (
+	"fmt"
+
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	locationbus.OrderByID:   "id",
+	locationbus.OrderByName: "name",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
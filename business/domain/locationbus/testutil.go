@@ -0,0 +1,47 @@
+package locationbus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/timezone"
+)
+
+// TestGenerateNewLocations is a helper method for testing.
+func TestGenerateNewLocations(n int) []NewLocation {
+	newLocs := make([]NewLocation, n)
+
+	idx := rand.Intn(10000)
+	for i := range n {
+		idx++
+
+		nl := NewLocation{
+			Name:     name.MustParse(fmt.Sprintf("Shop%d", idx)),
+			Address:  fmt.Sprintf("%d Main Street", idx),
+			Timezone: timezone.MustParse("America/New_York"),
+		}
+
+		newLocs[i] = nl
+	}
+
+	return newLocs
+}
+
+// TestGenerateSeedLocations is a helper method for testing.
+func TestGenerateSeedLocations(ctx context.Context, n int, api *Business) ([]Location, error) {
+	newLocs := TestGenerateNewLocations(n)
+
+	locs := make([]Location, len(newLocs))
+	for i, nl := range newLocs {
+		loc, err := api.Create(ctx, nl)
+		if err != nil {
+			return nil, fmt.Errorf("seeding location: idx: %d : %w", i, err)
+		}
+
+		locs[i] = loc
+	}
+
+	return locs, nil
+}
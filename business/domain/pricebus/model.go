@@ -0,0 +1,106 @@
+package pricebus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+)
+
+// PriceList represents a set of negotiated prices. When several lists apply
+// to a buyer the one with the highest priority wins. A zero ValidFrom or
+// ValidTo leaves that end of the validity period open.
+type PriceList struct {
+	ID          uuid.UUID
+	Name        name.Name
+	Currency    currency.Currency
+	Priority    int
+	ValidFrom   time.Time
+	ValidTo     time.Time
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// IsValid reports whether the price list is in effect at the specified time.
+func (pl PriceList) IsValid(now time.Time) bool {
+	if !pl.ValidFrom.IsZero() && now.Before(pl.ValidFrom) {
+		return false
+	}
+
+	if !pl.ValidTo.IsZero() && !now.Before(pl.ValidTo) {
+		return false
+	}
+
+	return true
+}
+
+// NewPriceList is what we require from clients when adding a PriceList.
+type NewPriceList struct {
+	Name      name.Name
+	Currency  currency.Currency
+	Priority  int
+	ValidFrom time.Time
+	ValidTo   time.Time
+}
+
+// UpdatePriceList defines what information may be provided to modify an
+// existing PriceList. All fields are optional so clients can send just the
+// fields they want changed. It uses pointer fields so we can differentiate
+// between a field that was not provided and a field that was provided as
+// explicitly blank.
+type UpdatePriceList struct {
+	Name      *name.Name
+	Currency  *currency.Currency
+	Priority  *int
+	ValidFrom *time.Time
+	ValidTo   *time.Time
+}
+
+// Item represents the price of a product within a price list. The price
+// applies from MinQuantity units on, so several items for the same product
+// form quantity break tiers.
+type Item struct {
+	ProductID   uuid.UUID
+	MinQuantity int
+	Price       money.Money
+}
+
+// Assignments represents who a price list applies to, either users directly
+// or every member of a customer group.
+type Assignments struct {
+	UserIDs []uuid.UUID
+	Groups  []name.Name
+}
+
+// Candidate represents a price list item that applies to a buyer along with
+// what is needed to rank it against other candidates.
+type Candidate struct {
+	PriceListID uuid.UUID
+	Priority    int
+	Currency    currency.Currency
+	Item
+}
+
+// PriceRequest represents a product a buyer wants priced. Quantity is in the
+// unit of the product and BasePrice, in Currency, is used when no price list
+// applies.
+type PriceRequest struct {
+	ProductID uuid.UUID
+	Quantity  float64
+	BasePrice money.Money
+	Currency  currency.Currency
+}
+
+// Quote represents the unit price resolved for a product, in the currency of
+// the price list it was taken from or of the base price. PriceListID is
+// uuid.Nil when the base price was used.
+type Quote struct {
+	ProductID   uuid.UUID
+	Quantity    float64
+	UnitPrice   money.Money
+	Currency    currency.Currency
+	PriceListID uuid.UUID
+}
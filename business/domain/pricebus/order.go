@@ -0,0 +1,13 @@
+package pricebus
+
+import "github.com/rmsj/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID       = "a"
+	OrderByName     = "b"
+	OrderByPriority = "c"
+)
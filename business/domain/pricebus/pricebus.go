@@ -0,0 +1,359 @@
+// Package pricebus provides business access to price list domain and the
+// pricing service that resolves what a buyer pays for a product.
+package pricebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound        = errors.New("price list not found")
+	ErrInvalidValidity = errors.New("price list validity ends before it starts")
+	ErrInvalidItem     = errors.New("price list item not valid")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, pl PriceList) error
+	Update(ctx context.Context, pl PriceList) error
+	Delete(ctx context.Context, pl PriceList) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]PriceList, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, priceListID uuid.UUID) (PriceList, error)
+	SetItems(ctx context.Context, pl PriceList, items []Item) error
+	QueryItems(ctx context.Context, pl PriceList) ([]Item, error)
+	SetAssignments(ctx context.Context, pl PriceList, asg Assignments) error
+	QueryAssignments(ctx context.Context, pl PriceList) (Assignments, error)
+	SetGroupMembers(ctx context.Context, group name.Name, userIDs []uuid.UUID) error
+	QueryCandidates(ctx context.Context, buyerID uuid.UUID, productIDs []uuid.UUID, now time.Time) ([]Candidate, error)
+}
+
+// Business manages the set of APIs for price list access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a price list business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	b := Business{
+		log:    log,
+		storer: storer,
+	}
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new price list to the system.
+func (b *Business) Create(ctx context.Context, npl NewPriceList) (PriceList, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	pl := PriceList{
+		ID:          uuid.New(),
+		Name:        npl.Name,
+		Currency:    npl.Currency,
+		Priority:    npl.Priority,
+		ValidFrom:   npl.ValidFrom,
+		ValidTo:     npl.ValidTo,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := checkValidity(pl); err != nil {
+		return PriceList{}, err
+	}
+
+	if err := b.storer.Create(ctx, pl); err != nil {
+		return PriceList{}, fmt.Errorf("create: %w", err)
+	}
+
+	return pl, nil
+}
+
+// Update modifies information about a price list.
+func (b *Business) Update(ctx context.Context, pl PriceList, upl UpdatePriceList) (PriceList, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.update")
+	defer span.End()
+
+	if upl.Name != nil {
+		pl.Name = *upl.Name
+	}
+
+	if upl.Currency != nil {
+		pl.Currency = *upl.Currency
+	}
+
+	if upl.Priority != nil {
+		pl.Priority = *upl.Priority
+	}
+
+	if upl.ValidFrom != nil {
+		pl.ValidFrom = *upl.ValidFrom
+	}
+
+	if upl.ValidTo != nil {
+		pl.ValidTo = *upl.ValidTo
+	}
+
+	if err := checkValidity(pl); err != nil {
+		return PriceList{}, err
+	}
+
+	pl.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, pl); err != nil {
+		return PriceList{}, fmt.Errorf("update: %w", err)
+	}
+
+	return pl, nil
+}
+
+// Delete removes the specified price list along with its items and
+// assignments.
+func (b *Business) Delete(ctx context.Context, pl PriceList) error {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.delete")
+	defer span.End()
+
+	if err := b.storer.Delete(ctx, pl); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing price lists.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]PriceList, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.query")
+	defer span.End()
+
+	pls, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return pls, nil
+}
+
+// Count returns the total number of price lists.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the price list by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, priceListID uuid.UUID) (PriceList, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.querybyid")
+	defer span.End()
+
+	pl, err := b.storer.QueryByID(ctx, priceListID)
+	if err != nil {
+		return PriceList{}, fmt.Errorf("query: priceListID[%s]: %w", priceListID, err)
+	}
+
+	return pl, nil
+}
+
+// SetItems replaces the product prices of the price list.
+func (b *Business) SetItems(ctx context.Context, pl PriceList, items []Item) error {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.setitems")
+	defer span.End()
+
+	type tier struct {
+		productID   uuid.UUID
+		minQuantity int
+	}
+	seen := make(map[tier]struct{})
+
+	for _, item := range items {
+		if item.MinQuantity < 1 {
+			return fmt.Errorf("product[%s]: min quantity[%d]: %w", item.ProductID, item.MinQuantity, ErrInvalidItem)
+		}
+
+		t := tier{item.ProductID, item.MinQuantity}
+		if _, exists := seen[t]; exists {
+			return fmt.Errorf("product[%s]: duplicate min quantity[%d]: %w", item.ProductID, item.MinQuantity, ErrInvalidItem)
+		}
+		seen[t] = struct{}{}
+	}
+
+	if err := b.storer.SetItems(ctx, pl, items); err != nil {
+		return fmt.Errorf("setitems: %w", err)
+	}
+
+	return nil
+}
+
+// QueryItems retrieves the product prices of the price list.
+func (b *Business) QueryItems(ctx context.Context, pl PriceList) ([]Item, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.queryitems")
+	defer span.End()
+
+	items, err := b.storer.QueryItems(ctx, pl)
+	if err != nil {
+		return nil, fmt.Errorf("queryitems: %w", err)
+	}
+
+	return items, nil
+}
+
+// SetAssignments replaces the users and customer groups the price list
+// applies to.
+func (b *Business) SetAssignments(ctx context.Context, pl PriceList, asg Assignments) error {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.setassignments")
+	defer span.End()
+
+	if err := b.storer.SetAssignments(ctx, pl, asg); err != nil {
+		return fmt.Errorf("setassignments: %w", err)
+	}
+
+	return nil
+}
+
+// QueryAssignments retrieves the users and customer groups the price list
+// applies to.
+func (b *Business) QueryAssignments(ctx context.Context, pl PriceList) (Assignments, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.queryassignments")
+	defer span.End()
+
+	asg, err := b.storer.QueryAssignments(ctx, pl)
+	if err != nil {
+		return Assignments{}, fmt.Errorf("queryassignments: %w", err)
+	}
+
+	return asg, nil
+}
+
+// SetGroupMembers replaces the users that belong to the customer group.
+func (b *Business) SetGroupMembers(ctx context.Context, group name.Name, userIDs []uuid.UUID) error {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.setgroupmembers")
+	defer span.End()
+
+	if err := b.storer.SetGroupMembers(ctx, group, userIDs); err != nil {
+		return fmt.Errorf("setgroupmembers: group[%s]: %w", group, err)
+	}
+
+	return nil
+}
+
+// Resolve works out the unit price the buyer pays for each of the requested
+// products. Only price lists that are assigned to the buyer, or to a group
+// the buyer belongs to, and are valid right now are considered.
+func (b *Business) Resolve(ctx context.Context, buyerID uuid.UUID, reqs []PriceRequest) ([]Quote, error) {
+	ctx, span := otel.AddSpan(ctx, "business.pricebus.resolve")
+	defer span.End()
+
+	productIDs := make([]uuid.UUID, len(reqs))
+	for i, req := range reqs {
+		productIDs[i] = req.ProductID
+	}
+
+	candidates, err := b.storer.QueryCandidates(ctx, buyerID, productIDs, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("querycandidates: buyerID[%s]: %w", buyerID, err)
+	}
+
+	quotes := make([]Quote, len(reqs))
+	for i, req := range reqs {
+		quotes[i] = SelectQuote(req, candidates)
+	}
+
+	return quotes, nil
+}
+
+// SelectQuote picks the price for the request out of the candidates. Each
+// price list contributes the tier with the largest minimum quantity the
+// request reaches. Across lists the highest priority wins, with ties settled
+// in favour of the lowest price. The base price is used when no candidate
+// applies.
+func SelectQuote(req PriceRequest, candidates []Candidate) Quote {
+	quote := Quote{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UnitPrice: req.BasePrice,
+		Currency:  req.Currency,
+	}
+
+	tiers := make(map[uuid.UUID]Candidate)
+	for _, c := range candidates {
+		if c.ProductID != req.ProductID || float64(c.MinQuantity) > req.Quantity {
+			continue
+		}
+
+		if t, exists := tiers[c.PriceListID]; !exists || c.MinQuantity > t.MinQuantity {
+			tiers[c.PriceListID] = c
+		}
+	}
+
+	var best *Candidate
+	for _, t := range tiers {
+		if best == nil || better(t, *best) {
+			best = &t
+		}
+	}
+
+	if best != nil {
+		quote.UnitPrice = best.Price
+		quote.Currency = best.Currency
+		quote.PriceListID = best.PriceListID
+	}
+
+	return quote
+}
+
+// better reports whether the tier of one price list should be preferred over
+// the tier of another.
+func better(c Candidate, best Candidate) bool {
+	switch {
+	case c.Priority != best.Priority:
+		return c.Priority > best.Priority
+
+	case c.Price.Value() != best.Price.Value():
+		return c.Price.Value() < best.Price.Value()
+	}
+
+	return c.PriceListID.String() < best.PriceListID.String()
+}
+
+func checkValidity(pl PriceList) error {
+	if !pl.ValidFrom.IsZero() && !pl.ValidTo.IsZero() && !pl.ValidTo.After(pl.ValidFrom) {
+		return ErrInvalidValidity
+	}
+
+	return nil
+}
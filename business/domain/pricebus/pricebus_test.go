@@ -0,0 +1,406 @@
+package pricebus_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func Test_Price(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Price")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, create(db.BusDomain), "create")
+	unitest.Run(t, update(db.BusDomain, sd), "update")
+	unitest.Run(t, resolve(db.BusDomain, sd), "resolve")
+	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	pls, err := pricebus.TestGenerateSeedPriceLists(ctx, 2, busDomain.Price)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding price lists : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// The first list is assigned to the first user directly and has two tiers
+	// for the first product. The second list, with a higher priority, reaches
+	// the first user through a customer group.
+
+	items := []pricebus.Item{
+		{ProductID: prds[0].ID, MinQuantity: 1, Price: money.MustParse(8)},
+		{ProductID: prds[0].ID, MinQuantity: 10, Price: money.MustParse(6)},
+	}
+	if err := busDomain.Price.SetItems(ctx, pls[0], items); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding price list items : %w", err)
+	}
+
+	if err := busDomain.Price.SetAssignments(ctx, pls[0], pricebus.Assignments{UserIDs: []uuid.UUID{usrs[0].ID}}); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding price list assignments : %w", err)
+	}
+
+	items = []pricebus.Item{
+		{ProductID: prds[1].ID, MinQuantity: 1, Price: money.MustParse(5)},
+	}
+	if err := busDomain.Price.SetItems(ctx, pls[1], items); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding price list items : %w", err)
+	}
+
+	group := name.MustParse("Wholesale")
+
+	if err := busDomain.Price.SetAssignments(ctx, pls[1], pricebus.Assignments{Groups: []name.Name{group}}); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding price list assignments : %w", err)
+	}
+
+	if err := busDomain.Price.SetGroupMembers(ctx, group, []uuid.UUID{usrs[0].ID}); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding group members : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := unitest.SeedData{
+		Users:      []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+		Products:   prds,
+		PriceLists: pls,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "byid",
+			ExpResp: sd.PriceLists[0],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.QueryByID(ctx, sd.PriceLists[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(pricebus.PriceList)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(pricebus.PriceList)
+
+				if gotResp.DateCreated.Format(time.RFC3339) == expResp.DateCreated.Format(time.RFC3339) {
+					expResp.DateCreated = gotResp.DateCreated
+				}
+
+				if gotResp.DateUpdated.Format(time.RFC3339) == expResp.DateUpdated.Format(time.RFC3339) {
+					expResp.DateUpdated = gotResp.DateUpdated
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "count",
+			ExpResp: len(sd.PriceLists),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.Count(ctx, pricebus.QueryFilter{})
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "items",
+			ExpResp: []pricebus.Item{
+				{ProductID: sd.Products[0].ID, MinQuantity: 1, Price: money.MustParse(8)},
+				{ProductID: sd.Products[0].ID, MinQuantity: 10, Price: money.MustParse(6)},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.QueryItems(ctx, sd.PriceLists[0])
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "paged",
+			ExpResp: []uuid.UUID{
+				sd.PriceLists[1].ID,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				orderBy := order.NewBy(pricebus.OrderByPriority, order.DESC)
+
+				resp, err := busDomain.Price.Query(ctx, pricebus.QueryFilter{}, orderBy, page.MustParse("1", "1"))
+				if err != nil {
+					return err
+				}
+
+				ids := make([]uuid.UUID, len(resp))
+				for i, pl := range resp {
+					ids[i] = pl.ID
+				}
+
+				return ids
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain) []unitest.Table {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: pricebus.PriceList{
+				Name:      name.MustParse("Distributors"),
+				Currency:  currency.MustParse("EUR"),
+				Priority:  5,
+				ValidFrom: from,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				npl := pricebus.NewPriceList{
+					Name:      name.MustParse("Distributors"),
+					Currency:  currency.MustParse("EUR"),
+					Priority:  5,
+					ValidFrom: from,
+				}
+
+				resp, err := busDomain.Price.Create(ctx, npl)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(pricebus.PriceList)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(pricebus.PriceList)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "invalid-validity",
+			ExpResp: pricebus.ErrInvalidValidity,
+			ExcFunc: func(ctx context.Context) any {
+				npl := pricebus.NewPriceList{
+					Name:      name.MustParse("Backwards"),
+					Currency:  currency.Default,
+					ValidFrom: from,
+					ValidTo:   from.Add(-time.Hour),
+				}
+
+				_, err := busDomain.Price.Create(ctx, npl)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != exp {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: pricebus.PriceList{
+				ID:          sd.PriceLists[1].ID,
+				Name:        name.MustParse("Wholesale"),
+				Currency:    sd.PriceLists[1].Currency,
+				Priority:    10,
+				DateCreated: sd.PriceLists[1].DateCreated,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				priority := 10
+
+				upl := pricebus.UpdatePriceList{
+					Name:     dbtest.NamePointer("Wholesale"),
+					Priority: &priority,
+				}
+
+				resp, err := busDomain.Price.Update(ctx, sd.PriceLists[1], upl)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(pricebus.PriceList)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(pricebus.PriceList)
+
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func resolve(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	reqs := func(qty0 float64, qty1 float64) []pricebus.PriceRequest {
+		return []pricebus.PriceRequest{
+			{ProductID: sd.Products[0].ID, Quantity: qty0, BasePrice: sd.Products[0].Price, Currency: currency.Default},
+			{ProductID: sd.Products[1].ID, Quantity: qty1, BasePrice: sd.Products[1].Price, Currency: currency.Default},
+		}
+	}
+
+	table := []unitest.Table{
+		{
+			Name: "assigned",
+			ExpResp: []pricebus.Quote{
+				{ProductID: sd.Products[0].ID, Quantity: 1, UnitPrice: money.MustParse(8), Currency: currency.Default, PriceListID: sd.PriceLists[0].ID},
+				{ProductID: sd.Products[1].ID, Quantity: 1, UnitPrice: money.MustParse(5), Currency: currency.Default, PriceListID: sd.PriceLists[1].ID},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.Resolve(ctx, sd.Users[0].ID, reqs(1, 1))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "quantity-break",
+			ExpResp: []pricebus.Quote{
+				{ProductID: sd.Products[0].ID, Quantity: 12, UnitPrice: money.MustParse(6), Currency: currency.Default, PriceListID: sd.PriceLists[0].ID},
+				{ProductID: sd.Products[1].ID, Quantity: 12, UnitPrice: money.MustParse(5), Currency: currency.Default, PriceListID: sd.PriceLists[1].ID},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.Resolve(ctx, sd.Users[0].ID, reqs(12, 12))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name: "unassigned",
+			ExpResp: []pricebus.Quote{
+				{ProductID: sd.Products[0].ID, Quantity: 1, UnitPrice: sd.Products[0].Price, Currency: currency.Default},
+				{ProductID: sd.Products[1].ID, Quantity: 1, UnitPrice: sd.Products[1].Price, Currency: currency.Default},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Price.Resolve(ctx, sd.Users[1].ID, reqs(1, 1))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: nil,
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Price.Delete(ctx, sd.PriceLists[0]); err != nil {
+					return err
+				}
+
+				return nil
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,69 @@
+package pricebus_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/types/money"
+)
+
+func Test_SelectQuote(t *testing.T) {
+	t.Parallel()
+
+	var (
+		prdID  = uuid.MustParse("00000000-0000-0000-0000-0000000000aa")
+		other  = uuid.MustParse("00000000-0000-0000-0000-0000000000bb")
+		listA  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+		listB  = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+		listC  = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+		base   = money.MustParse(10)
+		tierA1 = pricebus.Candidate{PriceListID: listA, Item: pricebus.Item{ProductID: prdID, MinQuantity: 1, Price: money.MustParse(9)}}
+		tierA5 = pricebus.Candidate{PriceListID: listA, Item: pricebus.Item{ProductID: prdID, MinQuantity: 5, Price: money.MustParse(11)}}
+		tierB1 = pricebus.Candidate{PriceListID: listB, Item: pricebus.Item{ProductID: prdID, MinQuantity: 1, Price: money.MustParse(8)}}
+		tierC1 = pricebus.Candidate{PriceListID: listC, Priority: 1, Item: pricebus.Item{ProductID: prdID, MinQuantity: 1, Price: money.MustParse(12)}}
+		otherB = pricebus.Candidate{PriceListID: listB, Item: pricebus.Item{ProductID: other, MinQuantity: 1, Price: money.MustParse(1)}}
+	)
+
+	tests := []struct {
+		name       string
+		quantity   float64
+		candidates []pricebus.Candidate
+		expPrice   money.Money
+		expListID  uuid.UUID
+	}{
+		{"no-candidates", 1, nil, base, uuid.Nil},
+		{"other-product", 1, []pricebus.Candidate{otherB}, base, uuid.Nil},
+		{"tier-not-reached", 1, []pricebus.Candidate{tierA5}, base, uuid.Nil},
+		{"lowest-price", 1, []pricebus.Candidate{tierA1, tierB1}, money.MustParse(8), listB},
+		{"largest-tier", 5, []pricebus.Candidate{tierA1, tierA5}, money.MustParse(11), listA},
+		{"tier-before-price", 5, []pricebus.Candidate{tierA5, tierB1, tierA1}, money.MustParse(8), listB},
+		{"priority", 1, []pricebus.Candidate{tierA1, tierB1, tierC1}, money.MustParse(12), listC},
+		{"tie", 1, []pricebus.Candidate{{PriceListID: listB, Item: tierA1.Item}, tierA1}, money.MustParse(9), listA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := pricebus.PriceRequest{
+				ProductID: prdID,
+				Quantity:  tt.quantity,
+				BasePrice: base,
+			}
+
+			got := pricebus.SelectQuote(req, tt.candidates)
+
+			exp := pricebus.Quote{
+				ProductID:   prdID,
+				Quantity:    tt.quantity,
+				UnitPrice:   tt.expPrice,
+				PriceListID: tt.expListID,
+			}
+
+			if diff := cmp.Diff(got, exp); diff != "" {
+				t.Errorf("Should get the expected quote: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package pricedb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+)
+
+func (s *Store) applyFilter(filter pricebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["id"] = filter.ID
+		wc = append(wc, "id = :id")
+	}
+
+	if filter.Name != nil {
+		data["name"] = fmt.Sprintf("%%%s%%", filter.Name)
+		wc = append(wc, "name LIKE :name")
+	}
+
+	if filter.Currency != nil {
+		data["currency"] = filter.Currency.String()
+		wc = append(wc, "currency = :currency")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,176 @@
+package pricedb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+)
+
+type priceList struct {
+	ID          uuid.UUID    `db:"id"`
+	Name        string       `db:"name"`
+	Currency    string       `db:"currency"`
+	Priority    int          `db:"priority"`
+	ValidFrom   sql.NullTime `db:"valid_from"`
+	ValidTo     sql.NullTime `db:"valid_to"`
+	DateCreated time.Time    `db:"created_at"`
+	DateUpdated time.Time    `db:"updated_at"`
+}
+
+func toDBPriceList(bus pricebus.PriceList) priceList {
+	db := priceList{
+		ID:          bus.ID,
+		Name:        bus.Name.String(),
+		Currency:    bus.Currency.String(),
+		Priority:    bus.Priority,
+		ValidFrom:   toNullTime(bus.ValidFrom),
+		ValidTo:     toNullTime(bus.ValidTo),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+
+	return db
+}
+
+func toBusPriceList(db priceList) (pricebus.PriceList, error) {
+	nme, err := name.Parse(db.Name)
+	if err != nil {
+		return pricebus.PriceList{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	cur, err := currency.Parse(db.Currency)
+	if err != nil {
+		return pricebus.PriceList{}, fmt.Errorf("parse currency: %w", err)
+	}
+
+	bus := pricebus.PriceList{
+		ID:          db.ID,
+		Name:        nme,
+		Currency:    cur,
+		Priority:    db.Priority,
+		ValidFrom:   toBusTime(db.ValidFrom),
+		ValidTo:     toBusTime(db.ValidTo),
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusPriceLists(dbs []priceList) ([]pricebus.PriceList, error) {
+	bus := make([]pricebus.PriceList, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusPriceList(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t.UTC(),
+		Valid: !t.IsZero(),
+	}
+}
+
+func toBusTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
+
+// =============================================================================
+
+type item struct {
+	PriceListID uuid.UUID `db:"price_list_id"`
+	ProductID   uuid.UUID `db:"product_id"`
+	MinQuantity int       `db:"min_quantity"`
+	Price       float64   `db:"price"`
+}
+
+func toDBItem(priceListID uuid.UUID, bus pricebus.Item) item {
+	db := item{
+		PriceListID: priceListID,
+		ProductID:   bus.ProductID,
+		MinQuantity: bus.MinQuantity,
+		Price:       bus.Price.Value(),
+	}
+
+	return db
+}
+
+func toBusItem(db item) (pricebus.Item, error) {
+	price, err := money.Parse(db.Price)
+	if err != nil {
+		return pricebus.Item{}, fmt.Errorf("parse price: %w", err)
+	}
+
+	bus := pricebus.Item{
+		ProductID:   db.ProductID,
+		MinQuantity: db.MinQuantity,
+		Price:       price,
+	}
+
+	return bus, nil
+}
+
+func toBusItems(dbs []item) ([]pricebus.Item, error) {
+	bus := make([]pricebus.Item, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusItem(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type candidate struct {
+	item
+	Priority int    `db:"priority"`
+	Currency string `db:"currency"`
+}
+
+func toBusCandidates(dbs []candidate) ([]pricebus.Candidate, error) {
+	bus := make([]pricebus.Candidate, len(dbs))
+
+	for i, db := range dbs {
+		itm, err := toBusItem(db.item)
+		if err != nil {
+			return nil, err
+		}
+
+		cur, err := currency.Parse(db.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("parse currency: %w", err)
+		}
+
+		bus[i] = pricebus.Candidate{
+			PriceListID: db.PriceListID,
+			Priority:    db.Priority,
+			Currency:    cur,
+			Item:        itm,
+		}
+	}
+
+	return bus, nil
+}
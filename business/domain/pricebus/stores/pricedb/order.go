@@ -0,0 +1,23 @@
+package pricedb
+
+import (
+	"fmt"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	pricebus.OrderByID:       "id",
+	pricebus.OrderByName:     "name",
+	pricebus.OrderByPriority: "priority",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
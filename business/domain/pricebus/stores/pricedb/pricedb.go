@@ -0,0 +1,456 @@
+// Package pricedb contains price list related CRUD functionality.
+package pricedb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// Store manages the set of APIs for price list database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (pricebus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new price list into the database.
+func (s *Store) Create(ctx context.Context, pl pricebus.PriceList) error {
+	const q = `
+	INSERT INTO price_lists
+		(id, name, currency, priority, valid_from, valid_to, created_at, updated_at)
+	VALUES
+		(:id, :name, :currency, :priority, :valid_from, :valid_to, :created_at, :updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPriceList(pl)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a price list document in the database.
+func (s *Store) Update(ctx context.Context, pl pricebus.PriceList) error {
+	const q = `
+	UPDATE
+		price_lists
+	SET
+		name = :name,
+		currency = :currency,
+		priority = :priority,
+		valid_from = :valid_from,
+		valid_to = :valid_to,
+		updated_at = :updated_at
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPriceList(pl)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a price list from the database. Its items and assignments
+// are removed by the database.
+func (s *Store) Delete(ctx context.Context, pl pricebus.PriceList) error {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: pl.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		price_lists
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing price lists from the database.
+func (s *Store) Query(ctx context.Context, filter pricebus.QueryFilter, orderBy order.By, page page.Page) ([]pricebus.PriceList, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, currency, priority, valid_from, valid_to, created_at, updated_at
+	FROM
+		price_lists`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset")
+
+	var dbPls []priceList
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPls); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPriceLists(dbPls)
+}
+
+// Count returns the total number of price lists in the DB.
+func (s *Store) Count(ctx context.Context, filter pricebus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = "SELECT COUNT(id) AS `count` FROM price_lists"
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified price list from the database.
+func (s *Store) QueryByID(ctx context.Context, priceListID uuid.UUID) (pricebus.PriceList, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: priceListID.String(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, currency, priority, valid_from, valid_to, created_at, updated_at
+	FROM
+		price_lists
+	WHERE
+		id = :id`
+
+	var dbPl priceList
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPl); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return pricebus.PriceList{}, fmt.Errorf("db: %w", pricebus.ErrNotFound)
+		}
+		return pricebus.PriceList{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPriceList(dbPl)
+}
+
+// SetItems replaces the items of the price list.
+func (s *Store) SetItems(ctx context.Context, pl pricebus.PriceList, items []pricebus.Item) error {
+	data := struct {
+		ID string `db:"price_list_id"`
+	}{
+		ID: pl.ID.String(),
+	}
+
+	const del = `
+	DELETE FROM
+		price_list_items
+	WHERE
+		price_list_id = :price_list_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const ins = `
+	INSERT INTO price_list_items
+		(price_list_id, product_id, min_quantity, price)
+	VALUES
+		(:price_list_id, :product_id, :min_quantity, :price)`
+
+	for _, itm := range items {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, ins, toDBItem(pl.ID, itm)); err != nil {
+			return fmt.Errorf("namedexeccontext: productID[%s]: %w", itm.ProductID, err)
+		}
+	}
+
+	return nil
+}
+
+// QueryItems retrieves the items of the price list.
+func (s *Store) QueryItems(ctx context.Context, pl pricebus.PriceList) ([]pricebus.Item, error) {
+	data := struct {
+		ID string `db:"price_list_id"`
+	}{
+		ID: pl.ID.String(),
+	}
+
+	const q = `
+	SELECT
+		price_list_id, product_id, min_quantity, price
+	FROM
+		price_list_items
+	WHERE
+		price_list_id = :price_list_id
+	ORDER BY
+		product_id, min_quantity`
+
+	var dbItems []item
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbItems); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusItems(dbItems)
+}
+
+// SetAssignments replaces the users and customer groups the price list is
+// assigned to.
+func (s *Store) SetAssignments(ctx context.Context, pl pricebus.PriceList, asg pricebus.Assignments) error {
+	data := struct {
+		ID string `db:"price_list_id"`
+	}{
+		ID: pl.ID.String(),
+	}
+
+	for _, del := range []string{
+		"DELETE FROM price_list_users WHERE price_list_id = :price_list_id",
+		"DELETE FROM price_list_groups WHERE price_list_id = :price_list_id",
+	} {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	const insUser = `
+	INSERT INTO price_list_users
+		(price_list_id, user_id)
+	VALUES
+		(:price_list_id, :user_id)`
+
+	for _, userID := range asg.UserIDs {
+		row := struct {
+			PriceListID string `db:"price_list_id"`
+			UserID      string `db:"user_id"`
+		}{
+			PriceListID: pl.ID.String(),
+			UserID:      userID.String(),
+		}
+
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, insUser, row); err != nil {
+			return fmt.Errorf("namedexeccontext: userID[%s]: %w", userID, err)
+		}
+	}
+
+	const insGroup = `
+	INSERT INTO price_list_groups
+		(price_list_id, customer_group)
+	VALUES
+		(:price_list_id, :customer_group)`
+
+	for _, group := range asg.Groups {
+		row := struct {
+			PriceListID string `db:"price_list_id"`
+			Group       string `db:"customer_group"`
+		}{
+			PriceListID: pl.ID.String(),
+			Group:       group.String(),
+		}
+
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, insGroup, row); err != nil {
+			return fmt.Errorf("namedexeccontext: group[%s]: %w", group, err)
+		}
+	}
+
+	return nil
+}
+
+// QueryAssignments retrieves the users and customer groups the price list is
+// assigned to.
+func (s *Store) QueryAssignments(ctx context.Context, pl pricebus.PriceList) (pricebus.Assignments, error) {
+	data := struct {
+		ID string `db:"price_list_id"`
+	}{
+		ID: pl.ID.String(),
+	}
+
+	const qUsers = `
+	SELECT
+		user_id
+	FROM
+		price_list_users
+	WHERE
+		price_list_id = :price_list_id
+	ORDER BY
+		user_id`
+
+	var users []struct {
+		UserID uuid.UUID `db:"user_id"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qUsers, data, &users); err != nil {
+		return pricebus.Assignments{}, fmt.Errorf("namedqueryslice: users: %w", err)
+	}
+
+	const qGroups = `
+	SELECT
+		customer_group
+	FROM
+		price_list_groups
+	WHERE
+		price_list_id = :price_list_id
+	ORDER BY
+		customer_group`
+
+	var groups []struct {
+		Group string `db:"customer_group"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qGroups, data, &groups); err != nil {
+		return pricebus.Assignments{}, fmt.Errorf("namedqueryslice: groups: %w", err)
+	}
+
+	asg := pricebus.Assignments{
+		UserIDs: make([]uuid.UUID, len(users)),
+		Groups:  make([]name.Name, len(groups)),
+	}
+
+	for i, u := range users {
+		asg.UserIDs[i] = u.UserID
+	}
+
+	for i, g := range groups {
+		group, err := name.Parse(g.Group)
+		if err != nil {
+			return pricebus.Assignments{}, fmt.Errorf("parse group: %w", err)
+		}
+		asg.Groups[i] = group
+	}
+
+	return asg, nil
+}
+
+// SetGroupMembers replaces the users that belong to the customer group.
+func (s *Store) SetGroupMembers(ctx context.Context, group name.Name, userIDs []uuid.UUID) error {
+	data := struct {
+		Group string `db:"customer_group"`
+	}{
+		Group: group.String(),
+	}
+
+	const del = `
+	DELETE FROM
+		customer_group_members
+	WHERE
+		customer_group = :customer_group`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const ins = `
+	INSERT INTO customer_group_members
+		(customer_group, user_id)
+	VALUES
+		(:customer_group, :user_id)`
+
+	for _, userID := range userIDs {
+		row := struct {
+			Group  string `db:"customer_group"`
+			UserID string `db:"user_id"`
+		}{
+			Group:  group.String(),
+			UserID: userID.String(),
+		}
+
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, ins, row); err != nil {
+			return fmt.Errorf("namedexeccontext: userID[%s]: %w", userID, err)
+		}
+	}
+
+	return nil
+}
+
+// QueryCandidates retrieves the items for the products that belong to price
+// lists valid at the specified time and assigned to the buyer, either
+// directly or through one of the buyer's customer groups.
+func (s *Store) QueryCandidates(ctx context.Context, buyerID uuid.UUID, productIDs []uuid.UUID, now time.Time) ([]pricebus.Candidate, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	data := map[string]any{
+		"buyer_id":    buyerID.String(),
+		"product_ids": productIDs,
+		"now":         now.UTC(),
+	}
+
+	const q = `
+	SELECT
+		pli.price_list_id, pli.product_id, pli.min_quantity, pli.price, pl.priority, pl.currency
+	FROM
+		price_list_items AS pli
+	JOIN
+		price_lists AS pl ON pl.id = pli.price_list_id
+	WHERE
+		pli.product_id IN (:product_ids) AND
+		(pl.valid_from IS NULL OR pl.valid_from <= :now) AND
+		(pl.valid_to IS NULL OR pl.valid_to > :now) AND
+		(
+			pl.id IN (
+				SELECT price_list_id FROM price_list_users WHERE user_id = :buyer_id
+			) OR
+			pl.id IN (
+				SELECT plg.price_list_id
+				FROM price_list_groups AS plg
+				JOIN customer_group_members AS cgm ON cgm.customer_group = plg.customer_group
+				WHERE cgm.user_id = :buyer_id
+			)
+		)`
+
+	var dbCandidates []candidate
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbCandidates); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusCandidates(dbCandidates)
+}
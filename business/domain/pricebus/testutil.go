@@ -0,0 +1,47 @@
+package pricebus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/name"
+)
+
+// TestGenerateNewPriceLists is a helper method for testing.
+func TestGenerateNewPriceLists(n int) []NewPriceList {
+	newPls := make([]NewPriceList, n)
+
+	idx := rand.Intn(10000)
+	for i := range n {
+		idx++
+
+		npl := NewPriceList{
+			Name:     name.MustParse(fmt.Sprintf("List%d", idx)),
+			Currency: currency.Default,
+			Priority: i,
+		}
+
+		newPls[i] = npl
+	}
+
+	return newPls
+}
+
+// TestGenerateSeedPriceLists is a helper method for testing.
+func TestGenerateSeedPriceLists(ctx context.Context, n int, api *Business) ([]PriceList, error) {
+	newPls := TestGenerateNewPriceLists(n)
+
+	pls := make([]PriceList, len(newPls))
+	for i, npl := range newPls {
+		pl, err := api.Create(ctx, npl)
+		if err != nil {
+			return nil, fmt.Errorf("seeding price list: idx: %d : %w", i, err)
+		}
+
+		pls[i] = pl
+	}
+
+	return pls, nil
+}
@@ -0,0 +1,175 @@
+package productbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// QueryComponents retrieves the products that make up the bundle, in the same
+// order as the bundle components.
+func (b *Business) QueryComponents(ctx context.Context, bundle Product) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querycomponents")
+	defer span.End()
+
+	prds := make([]Product, len(bundle.Bundle.Components))
+	for i, c := range bundle.Bundle.Components {
+		prd, err := b.storer.QueryByID(ctx, c.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("query: componentID[%s]: %w", c.ProductID, err)
+		}
+
+		prds[i] = prd
+	}
+
+	return prds, nil
+}
+
+// ConsumeStock takes the specified quantity out of the product stock. It
+// fails when the product does not have enough left.
+func (b *Business) ConsumeStock(ctx context.Context, prd Product, qty quantity.Quantity) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.consumestock")
+	defer span.End()
+
+	qty, err := inUnit(qty, prd.Unit)
+	if err != nil {
+		return Product{}, fmt.Errorf("productID[%s]: %w", prd.ID, err)
+	}
+
+	if qty.Value() > prd.Stock.Value() {
+		return Product{}, fmt.Errorf("productID[%s] stock[%s] quantity[%s]: %w", prd.ID, prd.Stock, qty, ErrInsufficientStock)
+	}
+
+	stock, err := prd.Stock.Sub(qty)
+	if err != nil {
+		return Product{}, fmt.Errorf("stock: %w", err)
+	}
+
+	prd.Stock = stock
+	prd.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("update: %w", err)
+	}
+
+	return prd, nil
+}
+
+// =============================================================================
+
+// priceBundle validates the bundle components, which must be priced in the
+// currency of the bundle, and sets the bundle cost from the current cost of
+// its components. For sum pricing it also sets the bundle
+// price from the current price of its components.
+func (b *Business) priceBundle(ctx context.Context, prd *Product) error {
+	bdl := prd.Bundle
+
+	if _, err := ParseBundlePricing(string(bdl.Pricing)); err != nil {
+		return fmt.Errorf("%s: %w", err, ErrInvalidBundle)
+	}
+
+	if bdl.Discount < 0 || bdl.Discount > 100 {
+		return fmt.Errorf("discount[%.2f] must be between 0 and 100: %w", bdl.Discount, ErrInvalidBundle)
+	}
+
+	if len(bdl.Components) == 0 {
+		return fmt.Errorf("no components: %w", ErrInvalidBundle)
+	}
+
+	seen := make(map[uuid.UUID]struct{})
+	for _, c := range bdl.Components {
+		if c.ProductID == prd.ID {
+			return fmt.Errorf("bundle can not contain itself: %w", ErrInvalidBundle)
+		}
+
+		if c.Quantity < 1 {
+			return fmt.Errorf("componentID[%s] quantity[%d]: %w", c.ProductID, c.Quantity, ErrInvalidBundle)
+		}
+
+		if _, exists := seen[c.ProductID]; exists {
+			return fmt.Errorf("componentID[%s] listed more than once: %w", c.ProductID, ErrInvalidBundle)
+		}
+		seen[c.ProductID] = struct{}{}
+	}
+
+	components, err := b.QueryComponents(ctx, *prd)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%s: %w", err, ErrInvalidBundle)
+		}
+		return err
+	}
+
+	var sum, cost float64
+	for i, cp := range components {
+		if cp.IsBundle() {
+			return fmt.Errorf("componentID[%s] is a bundle: %w", cp.ID, ErrInvalidBundle)
+		}
+
+		if !cp.Currency.Equal(prd.Currency) {
+			return fmt.Errorf("componentID[%s] currency[%s] does not match bundle currency[%s]: %w", cp.ID, cp.Currency, prd.Currency, ErrInvalidBundle)
+		}
+
+		sum += cp.Price.Value() * float64(bdl.Components[i].Quantity)
+		cost += cp.Cost.Value() * float64(bdl.Components[i].Quantity)
+	}
+
+	prd.Cost, err = money.Parse(math.Round(cost*100) / 100)
+	if err != nil {
+		return fmt.Errorf("bundle cost: %w", err)
+	}
+
+	if bdl.Pricing != PricingSum {
+		return nil
+	}
+
+	price, err := money.Parse(math.Round(sum*(100-bdl.Discount)) / 100)
+	if err != nil {
+		return fmt.Errorf("bundle price: %w", err)
+	}
+
+	prd.Price = price
+
+	return nil
+}
+
+// repriceBundles updates the price and cost of the bundles the product is a
+// component of, after the price or cost of the product changed.
+func (b *Business) repriceBundles(ctx context.Context, component Product) error {
+	bundles, err := b.storer.QueryBundlesByComponent(ctx, component.ID)
+	if err != nil {
+		return fmt.Errorf("querybundlesbycomponent: %w", err)
+	}
+
+	for _, bdl := range bundles {
+		before := bdl
+
+		if err := b.priceBundle(ctx, &bdl); err != nil {
+			return fmt.Errorf("reprice: bundleID[%s]: %w", bdl.ID, err)
+		}
+
+		if bdl.Price.Equal(before.Price) && bdl.Cost.Equal(before.Cost) {
+			continue
+		}
+
+		bdl.DateUpdated = time.Now()
+
+		if err := b.storer.Update(ctx, bdl); err != nil {
+			return fmt.Errorf("update: bundleID[%s]: %w", bdl.ID, err)
+		}
+
+		if err := b.recordCost(ctx, before, bdl); err != nil {
+			return fmt.Errorf("bundleID[%s]: %w", bdl.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -9,8 +9,10 @@ import (
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
-	ID    *uuid.UUID
-	IDs   []uuid.UUID
-	Name  *name.Name
-	Price *float64
+	ID        *uuid.UUID
+	IDs       []uuid.UUID
+	Name      *name.Name
+	Price     *float64
+	Category  *string
+	CreatedBy *uuid.UUID
 }
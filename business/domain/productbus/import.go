@@ -0,0 +1,233 @@
+package productbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// ErrMissingKey is returned when an imported product has neither an ID nor a
+// SKU to match it against the catalog.
+var ErrMissingKey = errors.New("product id or sku required")
+
+// ImportAction represents what an import did, or would do, with a product.
+type ImportAction string
+
+// Set of actions an import can take.
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+)
+
+// ImportProduct represents a single product read from an import file. It is
+// matched on ID when provided, otherwise on SKU. Price is in Currency and
+// stock is in Unit, or in those of the product when they are not set.
+type ImportProduct struct {
+	ID       uuid.UUID
+	SKU      string
+	Name     name.Name
+	Category string
+	Price    money.Money
+	Currency currency.Currency
+	Unit     quantity.Unit
+	Stock    float64
+}
+
+// ImportResult describes the outcome of importing a single product. Changes
+// lists the fields that were modified on update.
+type ImportResult struct {
+	Action  ImportAction
+	Product Product
+	Changes []string
+}
+
+// Import upserts the set of products. Products that match an existing one
+// are updated with the fields that changed, the rest are created and owned by
+// the specified user. When dryRun is true nothing is written and the results
+// describe what would have happened.
+func (b *Business) Import(ctx context.Context, ips []ImportProduct, userID uuid.UUID, dryRun bool) ([]ImportResult, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.import")
+	defer span.End()
+
+	results := make([]ImportResult, len(ips))
+
+	for i, ip := range ips {
+		res, err := b.importProduct(ctx, ip, userID, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("import: product[%d]: %w", i+1, err)
+		}
+
+		results[i] = res
+	}
+
+	return results, nil
+}
+
+func (b *Business) importProduct(ctx context.Context, ip ImportProduct, userID uuid.UUID, dryRun bool) (ImportResult, error) {
+	prd, err := b.matchImport(ctx, ip)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			return ImportResult{}, err
+		}
+
+		unit := ip.Unit
+		if unit == (quantity.Unit{}) {
+			unit = quantity.Piece
+		}
+
+		stock, err := quantity.Parse(ip.Stock, unit)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("stock: %s: %w", err, ErrInvalidQuantity)
+		}
+
+		cur := ip.Currency
+		if cur == (currency.Currency{}) {
+			cur = currency.Default
+		}
+
+		np := NewProduct{
+			SKU:       ip.SKU,
+			Name:      ip.Name,
+			Category:  ip.Category,
+			Price:     ip.Price,
+			Currency:  cur,
+			Unit:      unit,
+			Stock:     stock,
+			CreatedBy: userID,
+		}
+
+		if dryRun {
+			preview := Product{
+				SKU:       np.SKU,
+				Name:      np.Name,
+				Category:  np.Category,
+				Kind:      KindSimple,
+				Price:     np.Price,
+				Currency:  np.Currency,
+				Unit:      np.Unit,
+				Stock:     np.Stock,
+				CreatedBy: np.CreatedBy,
+			}
+
+			return ImportResult{Action: ImportCreate, Product: preview}, nil
+		}
+
+		prd, err = b.Create(ctx, np)
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		return ImportResult{Action: ImportCreate, Product: prd}, nil
+	}
+
+	up, changes, err := diffImport(prd, ip)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	if len(changes) == 0 {
+		return ImportResult{Action: ImportUnchanged, Product: prd}, nil
+	}
+
+	if dryRun {
+		return ImportResult{Action: ImportUpdate, Product: prd, Changes: changes}, nil
+	}
+
+	prd, err = b.Update(ctx, prd, up)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	return ImportResult{Action: ImportUpdate, Product: prd, Changes: changes}, nil
+}
+
+// matchImport finds the existing product the imported one refers to. A
+// product referenced by ID must exist, while an unknown SKU is reported as
+// not found so it can be created.
+func (b *Business) matchImport(ctx context.Context, ip ImportProduct) (Product, error) {
+	switch {
+	case ip.ID != uuid.Nil:
+		prd, err := b.storer.QueryByID(ctx, ip.ID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return Product{}, fmt.Errorf("id[%s]: product not in catalog", ip.ID)
+			}
+			return Product{}, fmt.Errorf("query: id[%s]: %w", ip.ID, err)
+		}
+		return prd, nil
+
+	case ip.SKU != "":
+		prd, err := b.storer.QueryBySKU(ctx, ip.SKU)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return Product{}, ErrNotFound
+			}
+			return Product{}, fmt.Errorf("query: sku[%s]: %w", ip.SKU, err)
+		}
+		return prd, nil
+	}
+
+	return Product{}, ErrMissingKey
+}
+
+// diffImport compares the imported product against the existing one and
+// returns the update needed along with a description of each change.
+func diffImport(prd Product, ip ImportProduct) (UpdateProduct, []string, error) {
+	var up UpdateProduct
+	var changes []string
+
+	if ip.SKU != "" && ip.SKU != prd.SKU {
+		up.SKU = &ip.SKU
+		changes = append(changes, fmt.Sprintf("sku: %q -> %q", prd.SKU, ip.SKU))
+	}
+
+	if !ip.Name.Equal(prd.Name) {
+		up.Name = &ip.Name
+		changes = append(changes, fmt.Sprintf("name: %q -> %q", prd.Name, ip.Name))
+	}
+
+	if ip.Category != prd.Category {
+		up.Category = &ip.Category
+		changes = append(changes, fmt.Sprintf("category: %q -> %q", prd.Category, ip.Category))
+	}
+
+	if !ip.Price.Equal(prd.Price) {
+		up.Price = &ip.Price
+		changes = append(changes, fmt.Sprintf("price: %s -> %s", prd.Price, ip.Price))
+	}
+
+	if ip.Currency != (currency.Currency{}) && !ip.Currency.Equal(prd.Currency) {
+		up.Currency = &ip.Currency
+		changes = append(changes, fmt.Sprintf("currency: %s -> %s", prd.Currency, ip.Currency))
+	}
+
+	unit := ip.Unit
+	if unit == (quantity.Unit{}) {
+		unit = prd.Unit
+	}
+
+	stock, err := quantity.Parse(ip.Stock, unit)
+	if err != nil {
+		return UpdateProduct{}, nil, fmt.Errorf("stock: %s: %w", err, ErrInvalidQuantity)
+	}
+
+	if stock, err = inUnit(stock, prd.Unit); err != nil {
+		return UpdateProduct{}, nil, fmt.Errorf("stock: %w", err)
+	}
+
+	if !stock.Equal(prd.Stock) {
+		up.Stock = &stock
+		changes = append(changes, fmt.Sprintf("stock: %s -> %s", prd.Stock, stock))
+	}
+
+	return up, changes, nil
+}
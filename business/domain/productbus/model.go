@@ -1,27 +1,79 @@
 package productbus
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
 	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
-// Product represents an individual product.
+// Product represents an individual product. Bundle is only set when the
+// product is of the bundle kind. Cost is what the product costs the business
+// and is only meant for administrators. Price and cost are in the currency of
+// the product. Price, cost, stock and the maximum quantity sold at once are
+// all in the unit of the product, and a zero MaxQuantity means there is no
+// maximum.
 type Product struct {
 	ID          uuid.UUID
+	SKU         string
 	Name        name.Name
+	Description string
+	Category    string
+	Kind        Kind
+	Bundle      Bundle
 	Price       money.Money
+	Cost        money.Money
+	Currency    currency.Currency
+	Unit        quantity.Unit
+	Stock       quantity.Quantity
+	MaxQuantity quantity.Quantity
+	CreatedBy   uuid.UUID
 	DateCreated time.Time
 	DateUpdated time.Time
 }
 
-// NewProduct is what we require from clients when adding a Product.
+// IsBundle reports whether the product is made up of other products.
+func (prd Product) IsBundle() bool {
+	return prd.Kind == KindBundle
+}
+
+// SaleQuantity converts the quantity to the unit of the product and checks it
+// does not go over the maximum the product can be sold in at once.
+func (prd Product) SaleQuantity(qty quantity.Quantity) (quantity.Quantity, error) {
+	qty, err := qty.Convert(prd.Unit)
+	if err != nil {
+		return quantity.Quantity{}, fmt.Errorf("productID[%s]: %s: %w", prd.ID, err, ErrInvalidQuantity)
+	}
+
+	if !prd.MaxQuantity.IsZero() && qty.Value() > prd.MaxQuantity.Value() {
+		return quantity.Quantity{}, fmt.Errorf("productID[%s] quantity[%s] over %s: %w", prd.ID, qty, prd.MaxQuantity, ErrMaxQuantity)
+	}
+
+	return qty, nil
+}
+
+// NewProduct is what we require from clients when adding a Product. A
+// product with a Bundle is created as a bundle. The product is priced in the
+// default currency when no Currency is provided and is sold in pieces when no
+// Unit is provided.
 type NewProduct struct {
-	Name  name.Name
-	Price money.Money
+	SKU         string
+	Name        name.Name
+	Description string
+	Category    string
+	Bundle      *Bundle
+	Price       money.Money
+	Cost        money.Money
+	Currency    currency.Currency
+	Unit        quantity.Unit
+	Stock       quantity.Quantity
+	MaxQuantity quantity.Quantity
+	CreatedBy   uuid.UUID
 }
 
 // UpdateProduct defines what information may be provided to modify an
@@ -31,6 +83,89 @@ type NewProduct struct {
 // explicitly blank. Normally we do not want to use pointers to basic types but
 // we make exceptions around marshalling/unmarshalling.
 type UpdateProduct struct {
-	Name  *name.Name
-	Price *money.Money
+	SKU         *string
+	Name        *name.Name
+	Description *string
+	Category    *string
+	Bundle      *Bundle
+	Price       *money.Money
+	Cost        *money.Money
+	Currency    *currency.Currency
+	Stock       *quantity.Quantity
+	MaxQuantity *quantity.Quantity
+}
+
+// CostChange records the cost of a product from the moment it took effect.
+type CostChange struct {
+	ProductID     uuid.UUID
+	Cost          money.Money
+	EffectiveFrom time.Time
+}
+
+// LocationStock represents the part of the stock of a product that is kept at
+// a location. The stock is in the unit of the product.
+type LocationStock struct {
+	ProductID   uuid.UUID
+	LocationID  uuid.UUID
+	Stock       quantity.Quantity
+	DateUpdated time.Time
+}
+
+// =============================================================================
+
+// Kind represents the type of a product.
+type Kind string
+
+// Set of product kinds.
+const (
+	KindSimple Kind = "simple"
+	KindBundle Kind = "bundle"
+)
+
+// BundlePricing represents how the price of a bundle is set.
+type BundlePricing string
+
+// Set of bundle pricing modes. A fixed bundle keeps the price it was given,
+// while a sum bundle is priced at the sum of its components minus the bundle
+// discount percentage.
+const (
+	PricingFixed BundlePricing = "fixed"
+	PricingSum   BundlePricing = "sum"
+)
+
+// ParseBundlePricing parses the string value and returns a bundle pricing
+// mode if one exists.
+func ParseBundlePricing(value string) (BundlePricing, error) {
+	switch BundlePricing(value) {
+	case PricingFixed:
+		return PricingFixed, nil
+	case PricingSum:
+		return PricingSum, nil
+	}
+
+	return "", fmt.Errorf("invalid bundle pricing %q", value)
+}
+
+// Bundle represents the products a bundle is made of and how it is priced.
+// Discount is a percentage between 0 and 100 and only applies to sum
+// pricing.
+type Bundle struct {
+	Pricing    BundlePricing
+	Discount   float64
+	Components []Component
+}
+
+// Component represents a product, and how many units of it, that make up a
+// bundle.
+type Component struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
+
+// SearchResult represents a product matched by a full text search along with
+// its relevance and a snippet of the matching text.
+type SearchResult struct {
+	Product Product
+	Score   float64
+	Snippet string
 }
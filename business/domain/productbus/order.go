@@ -11,4 +11,5 @@ const (
 	OrderByUserID    = "b"
 	OrderByName      = "c"
 	OrderByPrice     = "d"
+	OrderByMargin    = "e"
 )
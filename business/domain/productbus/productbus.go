@@ -13,14 +13,23 @@ import (
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/quantity"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
 )
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound     = errors.New("product not found")
-	ErrInvalidPrice = errors.New("price not valid")
+	ErrNotFound          = errors.New("product not found")
+	ErrInvalidPrice      = errors.New("price not valid")
+	ErrInvalidQuery      = errors.New("search query not valid")
+	ErrInvalidBundle     = errors.New("bundle not valid")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrComponentInUse    = errors.New("product is a component of a bundle")
+	ErrInvalidQuantity   = errors.New("quantity not valid")
+	ErrMaxQuantity       = errors.New("quantity over the product maximum")
+	ErrStockNotTracked   = errors.New("stock not tracked at location")
 )
 
 // Storer interface declares the behavior this package needs to persist and
@@ -33,6 +42,22 @@ type Storer interface {
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
+	QueryBySKU(ctx context.Context, sku string) (Product, error)
+	QueryBundlesByComponent(ctx context.Context, productID uuid.UUID) ([]Product, error)
+	AddCost(ctx context.Context, cc CostChange) error
+	QueryCostHistory(ctx context.Context, productID uuid.UUID) ([]CostChange, error)
+	SetLocationStock(ctx context.Context, ls LocationStock) error
+	QueryLocationStock(ctx context.Context, productID uuid.UUID) ([]LocationStock, error)
+	QueryLocationStockByID(ctx context.Context, productID uuid.UUID, locationID uuid.UUID) (LocationStock, error)
+}
+
+// Searcher interface declares the behavior this package needs to run full
+// text searches over products. Implementations that maintain their own index
+// are kept in sync through Index and Remove.
+type Searcher interface {
+	Index(ctx context.Context, prd Product) error
+	Remove(ctx context.Context, prd Product) error
+	Search(ctx context.Context, terms []string, page page.Page) ([]SearchResult, int, error)
 }
 
 // Business manages the set of APIs for product access.
@@ -40,14 +65,16 @@ type Business struct {
 	log      *logger.Logger
 	delegate *delegate.Delegate
 	storer   Storer
+	searcher Searcher
 }
 
 // NewBusiness constructs a product business API for use.
-func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer, searcher Searcher) *Business {
 	b := Business{
 		log:      log,
 		delegate: delegate,
 		storer:   storer,
+		searcher: searcher,
 	}
 
 	return &b
@@ -65,6 +92,7 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		log:      b.log,
 		delegate: b.delegate,
 		storer:   storer,
+		searcher: b.searcher,
 	}
 
 	return &bus, nil
@@ -79,16 +107,62 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 
 	prd := Product{
 		ID:          uuid.New(),
+		SKU:         np.SKU,
 		Name:        np.Name,
+		Description: np.Description,
+		Category:    np.Category,
+		Kind:        KindSimple,
 		Price:       np.Price,
+		Cost:        np.Cost,
+		Currency:    np.Currency,
+		Unit:        np.Unit,
+		CreatedBy:   np.CreatedBy,
 		DateCreated: now,
 		DateUpdated: now,
 	}
 
+	if prd.Currency == (currency.Currency{}) {
+		prd.Currency = currency.Default
+	}
+
+	if prd.Unit == (quantity.Unit{}) {
+		prd.Unit = quantity.Piece
+	}
+
+	var err error
+	if prd.Stock, err = inUnit(np.Stock, prd.Unit); err != nil {
+		return Product{}, fmt.Errorf("stock: %w", err)
+	}
+
+	if prd.MaxQuantity, err = inUnit(np.MaxQuantity, prd.Unit); err != nil {
+		return Product{}, fmt.Errorf("max quantity: %w", err)
+	}
+
+	if np.Bundle != nil {
+		if !prd.Unit.Equal(quantity.Piece) {
+			return Product{}, fmt.Errorf("unit[%s] bundles are sold in pieces: %w", prd.Unit, ErrInvalidBundle)
+		}
+
+		prd.Kind = KindBundle
+		prd.Bundle = *np.Bundle
+
+		if err := b.priceBundle(ctx, &prd); err != nil {
+			return Product{}, err
+		}
+	}
+
 	if err := b.storer.Create(ctx, prd); err != nil {
 		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
+	if err := b.storer.AddCost(ctx, CostChange{ProductID: prd.ID, Cost: prd.Cost, EffectiveFrom: now}); err != nil {
+		return Product{}, fmt.Errorf("addcost: %w", err)
+	}
+
+	if err := b.searcher.Index(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("index: %w", err)
+	}
+
 	return prd, nil
 }
 
@@ -97,20 +171,86 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 	ctx, span := otel.AddSpan(ctx, "business.productbus.update")
 	defer span.End()
 
+	before := prd
+
+	if up.SKU != nil {
+		prd.SKU = *up.SKU
+	}
+
 	if up.Name != nil {
 		prd.Name = *up.Name
 	}
 
+	if up.Description != nil {
+		prd.Description = *up.Description
+	}
+
+	if up.Category != nil {
+		prd.Category = *up.Category
+	}
+
 	if up.Price != nil {
 		prd.Price = *up.Price
 	}
 
+	if up.Cost != nil {
+		prd.Cost = *up.Cost
+	}
+
+	if up.Currency != nil {
+		prd.Currency = *up.Currency
+	}
+
+	if up.Stock != nil {
+		stock, err := inUnit(*up.Stock, prd.Unit)
+		if err != nil {
+			return Product{}, fmt.Errorf("stock: %w", err)
+		}
+		prd.Stock = stock
+	}
+
+	if up.MaxQuantity != nil {
+		maxQty, err := inUnit(*up.MaxQuantity, prd.Unit)
+		if err != nil {
+			return Product{}, fmt.Errorf("max quantity: %w", err)
+		}
+		prd.MaxQuantity = maxQty
+	}
+
+	if up.Bundle != nil {
+		if !prd.IsBundle() {
+			return Product{}, fmt.Errorf("productID[%s] is not a bundle: %w", prd.ID, ErrInvalidBundle)
+		}
+		prd.Bundle = *up.Bundle
+	}
+
+	if prd.IsBundle() {
+		if err := b.priceBundle(ctx, &prd); err != nil {
+			return Product{}, err
+		}
+	}
+
 	prd.DateUpdated = time.Now()
 
 	if err := b.storer.Update(ctx, prd); err != nil {
 		return Product{}, fmt.Errorf("update: %w", err)
 	}
 
+	if err := b.recordCost(ctx, before, prd); err != nil {
+		return Product{}, err
+	}
+
+	if err := b.searcher.Index(ctx, prd); err != nil {
+		return Product{}, fmt.Errorf("index: %w", err)
+	}
+
+	priced := !prd.Price.Equal(before.Price) || !prd.Cost.Equal(before.Cost) || !prd.Currency.Equal(before.Currency)
+	if priced && !prd.IsBundle() {
+		if err := b.repriceBundles(ctx, prd); err != nil {
+			return Product{}, err
+		}
+	}
+
 	return prd, nil
 }
 
@@ -119,10 +259,23 @@ func (b *Business) Delete(ctx context.Context, prd Product) error {
 	ctx, span := otel.AddSpan(ctx, "business.productbus.delete")
 	defer span.End()
 
+	bundles, err := b.storer.QueryBundlesByComponent(ctx, prd.ID)
+	if err != nil {
+		return fmt.Errorf("querybundlesbycomponent: %w", err)
+	}
+
+	if len(bundles) > 0 {
+		return fmt.Errorf("productID[%s] used by bundleID[%s]: %w", prd.ID, bundles[0].ID, ErrComponentInUse)
+	}
+
 	if err := b.storer.Delete(ctx, prd); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if err := b.searcher.Remove(ctx, prd); err != nil {
+		return fmt.Errorf("remove: %w", err)
+	}
+
 	return nil
 }
 
@@ -159,3 +312,89 @@ func (b *Business) QueryByID(ctx context.Context, productID uuid.UUID) (Product,
 
 	return prd, nil
 }
+
+// QueryBySKU finds the product by the specified SKU.
+func (b *Business) QueryBySKU(ctx context.Context, sku string) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybysku")
+	defer span.End()
+
+	prd, err := b.storer.QueryBySKU(ctx, sku)
+	if err != nil {
+		return Product{}, fmt.Errorf("query: sku[%s]: %w", sku, err)
+	}
+
+	return prd, nil
+}
+
+// QueryCostHistory retrieves the changes to the cost of the specified
+// product, most recent first.
+func (b *Business) QueryCostHistory(ctx context.Context, productID uuid.UUID) ([]CostChange, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querycosthistory")
+	defer span.End()
+
+	ccs, err := b.storer.QueryCostHistory(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	return ccs, nil
+}
+
+// Search runs a full text search over the name and description of products.
+// Results are ordered by relevance and carry a highlighted snippet of the
+// text that matched.
+func (b *Business) Search(ctx context.Context, query string, page page.Page) ([]SearchResult, int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.search")
+	defer span.End()
+
+	terms := ParseTerms(query)
+	if len(terms) == 0 {
+		return nil, 0, ErrInvalidQuery
+	}
+
+	results, total, err := b.searcher.Search(ctx, terms, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search: %w", err)
+	}
+
+	for i, res := range results {
+		results[i].Snippet = Snippet(res.Product, terms)
+	}
+
+	return results, total, nil
+}
+
+// recordCost adds an entry to the cost history of the product when the
+// update changed its cost.
+func (b *Business) recordCost(ctx context.Context, before Product, after Product) error {
+	if after.Cost.Equal(before.Cost) {
+		return nil
+	}
+
+	cc := CostChange{
+		ProductID:     after.ID,
+		Cost:          after.Cost,
+		EffectiveFrom: after.DateUpdated,
+	}
+
+	if err := b.storer.AddCost(ctx, cc); err != nil {
+		return fmt.Errorf("addcost: %w", err)
+	}
+
+	return nil
+}
+
+// inUnit expresses the quantity in the unit of a product. A zero quantity is
+// taken as zero of the unit, whatever unit it was given in.
+func inUnit(qty quantity.Quantity, unit quantity.Unit) (quantity.Quantity, error) {
+	if qty.IsZero() {
+		return quantity.Parse(0, unit)
+	}
+
+	qty, err := qty.Convert(unit)
+	if err != nil {
+		return quantity.Quantity{}, fmt.Errorf("%s: %w", err, ErrInvalidQuantity)
+	}
+
+	return qty, nil
+}
@@ -2,19 +2,24 @@ package productbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/dbtest"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/unitest"
 	"github.com/rmsj/service/business/types/money"
 	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/role"
 )
 
 func Test_Product(t *testing.T) {
@@ -40,21 +45,41 @@ func Test_Product(t *testing.T) {
 func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 	ctx := context.Background()
 
-	prds1, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product)
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds1, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
 
+	tu1 := unitest.User{
+		User: usrs[0],
+	}
+
 	// -------------------------------------------------------------------------
 
-	prds2, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product)
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds2, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
 
+	tu2 := unitest.User{
+		User: usrs[0],
+	}
+
 	// -------------------------------------------------------------------------
 
 	sd := unitest.SeedData{
+		Admins:   []unitest.User{tu2},
+		Users:    []unitest.User{tu1},
 		Products: append(prds1, prds2...),
 	}
 
@@ -144,17 +169,29 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 }
 
 func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	components := []productbus.Component{
+		{ProductID: sd.Products[0].ID, Quantity: 2},
+		{ProductID: sd.Products[2].ID, Quantity: 1},
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].ProductID.String() < components[j].ProductID.String()
+	})
+
 	table := []unitest.Table{
 		{
 			Name: "basic",
 			ExpResp: productbus.Product{
-				Name:  name.MustParse("Guitar"),
-				Price: money.MustParse(10.34),
+				Name:      name.MustParse("Guitar"),
+				Kind:      productbus.KindSimple,
+				Price:     money.MustParse(10.34),
+				CreatedBy: sd.Users[0].ID,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				np := productbus.NewProduct{
-					Name:  name.MustParse("Guitar"),
-					Price: money.MustParse(10.34),
+					Name:      name.MustParse("Guitar"),
+					Price:     money.MustParse(10.34),
+					CreatedBy: sd.Users[0].ID,
 				}
 
 				resp, err := busDomain.Product.Create(ctx, np)
@@ -179,6 +216,79 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name: "bundle",
+			ExpResp: productbus.Product{
+				Name:  name.MustParse("Starter Kit"),
+				Kind:  productbus.KindBundle,
+				Price: money.MustParse(math.Round((sd.Products[0].Price.Value()*2+sd.Products[2].Price.Value())*90) / 100),
+				Bundle: productbus.Bundle{
+					Pricing:    productbus.PricingSum,
+					Discount:   10,
+					Components: components,
+				},
+				CreatedBy: sd.Users[0].ID,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				np := productbus.NewProduct{
+					Name:  name.MustParse("Starter Kit"),
+					Price: money.MustParse(1),
+					Bundle: &productbus.Bundle{
+						Pricing:    productbus.PricingSum,
+						Discount:   10,
+						Components: components,
+					},
+					CreatedBy: sd.Users[0].ID,
+				}
+
+				resp, err := busDomain.Product.Create(ctx, np)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(productbus.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(productbus.Product)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "bundle-missing-component",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				bdl := productbus.Bundle{
+					Pricing: productbus.PricingFixed,
+					Components: []productbus.Component{
+						{ProductID: uuid.New(), Quantity: 1},
+					},
+				}
+
+				np := productbus.NewProduct{
+					Name:      name.MustParse("Broken Kit"),
+					Price:     money.MustParse(10),
+					Bundle:    &bdl,
+					CreatedBy: sd.Users[0].ID,
+				}
+
+				_, err := busDomain.Product.Create(ctx, np)
+
+				return errors.Is(err, productbus.ErrInvalidBundle)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -191,7 +301,9 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExpResp: productbus.Product{
 				ID:          sd.Products[0].ID,
 				Name:        name.MustParse("Guitar"),
+				Kind:        productbus.KindSimple,
 				Price:       money.MustParse(10.34),
+				CreatedBy:   sd.Products[0].CreatedBy,
 				DateCreated: sd.Products[0].DateCreated,
 				DateUpdated: sd.Products[0].DateCreated,
 			},
@@ -221,6 +333,34 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "cost-history",
+			ExpResp: []float64{7.25, 0},
+			ExcFunc: func(ctx context.Context) any {
+				up := productbus.UpdateProduct{
+					Cost: dbtest.MoneyPointer(7.25),
+				}
+
+				if _, err := busDomain.Product.Update(ctx, sd.Products[3], up); err != nil {
+					return err
+				}
+
+				ccs, err := busDomain.Product.QueryCostHistory(ctx, sd.Products[3].ID)
+				if err != nil {
+					return err
+				}
+
+				costs := make([]float64, len(ccs))
+				for i, cc := range ccs {
+					costs[i] = cc.Cost.Value()
+				}
+
+				return costs
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -228,6 +368,18 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 	table := []unitest.Table{
+		{
+			Name:    "component",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				err := busDomain.Product.Delete(ctx, sd.Products[2])
+
+				return errors.Is(err, productbus.ErrComponentInUse)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 		{
 			Name:    "user",
 			ExpResp: nil,
@@ -0,0 +1,335 @@
+// Package productio reads and writes products in the CSV and JSONL formats
+// used for bulk import and export. Export writes the same columns import
+// reads so a file can be round tripped without loss.
+package productio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
+)
+
+// Format represents a supported file format.
+type Format string
+
+// Set of supported formats.
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// ParseFormat parses the string value and returns a format if one exists.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case CSV:
+		return CSV, nil
+	case JSONL:
+		return JSONL, nil
+	}
+
+	return "", fmt.Errorf("invalid format %q", value)
+}
+
+// FormatFromPath determines the format from the extension of the file.
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// header lists the columns of a CSV file in the order they are written.
+var header = []string{"id", "sku", "name", "category", "price", "currency", "unit", "stock"}
+
+// record represents a product as it appears in a file. The price is in the
+// currency given and the stock in the unit given, or in those of the product
+// when none are.
+type record struct {
+	ID       string  `json:"id,omitempty"`
+	SKU      string  `json:"sku,omitempty"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Stock    float64 `json:"stock"`
+}
+
+// =============================================================================
+
+// Read parses the products in the specified format. Every line is validated
+// and all the problems found are reported together, prefixed by the line
+// they were found on.
+func Read(r io.Reader, format Format) ([]productbus.ImportProduct, error) {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case JSONL:
+		return readJSONL(r)
+	}
+
+	return nil, fmt.Errorf("invalid format %q", format)
+}
+
+func readCSV(r io.Reader) ([]productbus.ImportProduct, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	cols, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header")
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	idx := make(map[string]int)
+	for i, col := range cols {
+		idx[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+
+	for _, col := range []string{"name", "price"} {
+		if _, exists := idx[col]; !exists {
+			return nil, fmt.Errorf("missing column %q", col)
+		}
+	}
+
+	_, hasID := idx["id"]
+	_, hasSKU := idx["sku"]
+	if !hasID && !hasSKU {
+		return nil, errors.New(`missing column "id" or "sku"`)
+	}
+
+	get := func(row []string, col string) string {
+		i, exists := idx[col]
+		if !exists || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var ips []productbus.ImportProduct
+	var errs []error
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rec := record{
+			ID:       get(row, "id"),
+			SKU:      get(row, "sku"),
+			Name:     get(row, "name"),
+			Category: get(row, "category"),
+			Currency: get(row, "currency"),
+			Unit:     get(row, "unit"),
+		}
+
+		if v := get(row, "price"); v != "" {
+			rec.Price, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("line %d: invalid price %q", line, v))
+				continue
+			}
+		}
+
+		if v := get(row, "stock"); v != "" {
+			rec.Stock, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("line %d: invalid stock %q", line, v))
+				continue
+			}
+		}
+
+		ip, err := toImportProduct(rec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		ips = append(ips, ip)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return ips, nil
+}
+
+func readJSONL(r io.Reader) ([]productbus.ImportProduct, error) {
+	scanner := bufio.NewScanner(r)
+
+	var ips []productbus.ImportProduct
+	var errs []error
+
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		ip, err := toImportProduct(rec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		ips = append(ips, ip)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return ips, nil
+}
+
+func toImportProduct(rec record) (productbus.ImportProduct, error) {
+	var prdID uuid.UUID
+	if rec.ID != "" {
+		var err error
+		prdID, err = uuid.Parse(rec.ID)
+		if err != nil {
+			return productbus.ImportProduct{}, fmt.Errorf("parse id: %w", err)
+		}
+	}
+
+	if prdID == uuid.Nil && rec.SKU == "" {
+		return productbus.ImportProduct{}, productbus.ErrMissingKey
+	}
+
+	nme, err := name.Parse(rec.Name)
+	if err != nil {
+		return productbus.ImportProduct{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	price, err := money.Parse(rec.Price)
+	if err != nil {
+		return productbus.ImportProduct{}, fmt.Errorf("parse price: %w", err)
+	}
+
+	var cur currency.Currency
+	if rec.Currency != "" {
+		cur, err = currency.Parse(rec.Currency)
+		if err != nil {
+			return productbus.ImportProduct{}, fmt.Errorf("parse currency: %w", err)
+		}
+	}
+
+	var unit quantity.Unit
+	if rec.Unit != "" {
+		unit, err = quantity.ParseUnit(rec.Unit)
+		if err != nil {
+			return productbus.ImportProduct{}, fmt.Errorf("parse unit: %w", err)
+		}
+	}
+
+	ip := productbus.ImportProduct{
+		ID:       prdID,
+		SKU:      rec.SKU,
+		Name:     nme,
+		Category: rec.Category,
+		Price:    price,
+		Currency: cur,
+		Unit:     unit,
+		Stock:    rec.Stock,
+	}
+
+	return ip, nil
+}
+
+// =============================================================================
+
+// Write encodes the products in the specified format.
+func Write(w io.Writer, format Format, prds []productbus.Product) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, prds)
+	case JSONL:
+		return writeJSONL(w, prds)
+	}
+
+	return fmt.Errorf("invalid format %q", format)
+}
+
+func writeCSV(w io.Writer, prds []productbus.Product) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	for _, prd := range prds {
+		rec := toRecord(prd)
+
+		row := []string{
+			rec.ID,
+			rec.SKU,
+			rec.Name,
+			rec.Category,
+			strconv.FormatFloat(rec.Price, 'f', -1, 64),
+			rec.Currency,
+			rec.Unit,
+			strconv.FormatFloat(rec.Stock, 'f', -1, 64),
+		}
+
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("write: id[%s]: %w", prd.ID, err)
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func writeJSONL(w io.Writer, prds []productbus.Product) error {
+	enc := json.NewEncoder(w)
+
+	for _, prd := range prds {
+		if err := enc.Encode(toRecord(prd)); err != nil {
+			return fmt.Errorf("write: id[%s]: %w", prd.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func toRecord(prd productbus.Product) record {
+	return record{
+		ID:       prd.ID.String(),
+		SKU:      prd.SKU,
+		Name:     prd.Name.String(),
+		Category: prd.Category,
+		Price:    prd.Price.Value(),
+		Currency: prd.Currency.String(),
+		Unit:     prd.Unit.String(),
+		Stock:    prd.Stock.Value(),
+	}
+}
@@ -0,0 +1,130 @@
+package productio_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/productbus/productio"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
+)
+
+func Test_ProductIO(t *testing.T) {
+	t.Parallel()
+
+	t.Run("roundtrip-csv", func(t *testing.T) { roundTrip(t, productio.CSV) })
+	t.Run("roundtrip-jsonl", func(t *testing.T) { roundTrip(t, productio.JSONL) })
+	t.Run("sku-only", skuOnly)
+	t.Run("invalid", invalid)
+}
+
+// =============================================================================
+
+func roundTrip(t *testing.T, format productio.Format) {
+	prds := []productbus.Product{
+		{
+			ID:       uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			SKU:      "GTR-001",
+			Name:     name.MustParse("Acoustic Guitar"),
+			Category: "Guitars, Acoustic",
+			Price:    money.MustParse(450.99),
+			Unit:     quantity.Piece,
+			Stock:    quantity.MustParse(3, quantity.Piece),
+		},
+		{
+			ID:       uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+			SKU:      "STR-010",
+			Name:     name.MustParse("Nylon String"),
+			Category: "Strings",
+			Price:    money.MustParse(12.5),
+			Currency: currency.MustParse("EUR"),
+			Unit:     quantity.Metre,
+			Stock:    quantity.MustParse(42.75, quantity.Metre),
+		},
+		{
+			ID:    uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			Name:  name.MustParse("Drum Kit"),
+			Price: money.MustParse(800),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := productio.Write(&buf, format, prds); err != nil {
+		t.Fatalf("Should be able to write products: %s", err)
+	}
+
+	got, err := productio.Read(&buf, format)
+	if err != nil {
+		t.Fatalf("Should be able to read products: %s", err)
+	}
+
+	exp := make([]productbus.ImportProduct, len(prds))
+	for i, prd := range prds {
+		exp[i] = productbus.ImportProduct{
+			ID:       prd.ID,
+			SKU:      prd.SKU,
+			Name:     prd.Name,
+			Category: prd.Category,
+			Price:    prd.Price,
+			Currency: prd.Currency,
+			Unit:     prd.Unit,
+			Stock:    prd.Stock.Value(),
+		}
+	}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should get back the products written: %s", diff)
+	}
+}
+
+func skuOnly(t *testing.T) {
+	const data = "sku,name,price\nGTR-002, Electric Guitar, 999.5\n"
+
+	got, err := productio.Read(strings.NewReader(data), productio.CSV)
+	if err != nil {
+		t.Fatalf("Should be able to read products: %s", err)
+	}
+
+	exp := []productbus.ImportProduct{
+		{
+			SKU:   "GTR-002",
+			Name:  name.MustParse("Electric Guitar"),
+			Price: money.MustParse(999.5),
+		},
+	}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should get the product keyed on sku: %s", diff)
+	}
+}
+
+func invalid(t *testing.T) {
+	const data = "sku,name,price,stock\n" +
+		"GTR-003,Ok Guitar,10,1\n" +
+		"GTR-004,$#!,10,1\n" +
+		"GTR-005,Bad Price,-1,1\n" +
+		",No Key,10,1\n" +
+		"GTR-006,Bad Stock,10,lots\n"
+
+	_, err := productio.Read(strings.NewReader(data), productio.CSV)
+	if err == nil {
+		t.Fatalf("Should not be able to read invalid products")
+	}
+
+	for _, exp := range []string{"line 3: parse name", "line 4: parse price", "line 5: product id or sku required", "line 6: invalid stock"} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("Should report %q, got:\n%s", exp, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Should not report the valid line, got:\n%s", err)
+	}
+}
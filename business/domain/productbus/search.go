@@ -0,0 +1,256 @@
+package productbus
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Set of values that control how search queries are analysed.
+const (
+	minTermLen    = 3
+	maxTerms      = 10
+	fuzzyMinLen   = 3
+	fuzzyMaxLen   = 5
+	snippetWords  = 12
+	highlightPre  = "<em>"
+	highlightPost = "</em>"
+)
+
+var wordRE = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// variantAlphabet is the set of characters used to build typo variants of a
+// term. It matches what the tokenizer keeps for product names.
+const variantAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// Tokenize splits the text into lower case words.
+func Tokenize(text string) []string {
+	return wordRE.FindAllString(strings.ToLower(text), -1)
+}
+
+// ParseTerms extracts the unique search terms from a user provided query.
+// Terms that are too short to be meaningful are dropped.
+func ParseTerms(query string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+
+	for _, tkn := range Tokenize(query) {
+		if utf8.RuneCountInString(tkn) < minTermLen {
+			continue
+		}
+
+		if _, exists := seen[tkn]; exists {
+			continue
+		}
+		seen[tkn] = struct{}{}
+
+		terms = append(terms, tkn)
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+
+	return terms
+}
+
+// MaxEdits returns the number of typos tolerated for the specified term.
+// Short terms are the ones a single typo makes unrecognisable, since longer
+// terms still match on prefix, so only they are allowed an edit.
+func MaxEdits(term string) int {
+	n := utf8.RuneCountInString(term)
+	if n >= fuzzyMinLen && n <= fuzzyMaxLen {
+		return 1
+	}
+
+	return 0
+}
+
+// Matches reports whether the token satisfies the search term. A token
+// matches when it equals the term, starts with it, or is within the number
+// of edits the term tolerates.
+func Matches(token string, term string) bool {
+	if strings.HasPrefix(token, term) {
+		return true
+	}
+
+	edits := MaxEdits(term)
+	if edits == 0 {
+		return false
+	}
+
+	return editDistance(token, term, edits) <= edits
+}
+
+// Variants returns every word that is a single edit away from the term. It
+// allows stores that can't compute edit distance, like a full text index, to
+// be typo tolerant by searching for the variants as alternatives. Terms that
+// tolerate no edits have no variants.
+func Variants(term string) []string {
+	if MaxEdits(term) == 0 {
+		return nil
+	}
+
+	rs := []rune(term)
+	seen := map[string]struct{}{term: {}}
+	var variants []string
+
+	add := func(v []rune) {
+		s := string(v)
+		if _, exists := seen[s]; exists {
+			return
+		}
+		seen[s] = struct{}{}
+		variants = append(variants, s)
+	}
+
+	for i := range rs {
+		// Deletion.
+		add(append(append([]rune{}, rs[:i]...), rs[i+1:]...))
+
+		// Transposition.
+		if i < len(rs)-1 {
+			v := append([]rune{}, rs...)
+			v[i], v[i+1] = v[i+1], v[i]
+			add(v)
+		}
+
+		// Substitution.
+		for _, c := range variantAlphabet {
+			v := append([]rune{}, rs...)
+			v[i] = c
+			add(v)
+		}
+	}
+
+	// Insertion.
+	for i := 0; i <= len(rs); i++ {
+		for _, c := range variantAlphabet {
+			v := make([]rune, 0, len(rs)+1)
+			v = append(v, rs[:i]...)
+			v = append(v, c)
+			v = append(v, rs[i:]...)
+			add(v)
+		}
+	}
+
+	return variants
+}
+
+// Snippet returns a short piece of the product text with the words matching
+// the terms highlighted. The description is preferred when it matches since
+// it carries more context than the name.
+func Snippet(prd Product, terms []string) string {
+	if s, ok := highlight(prd.Description, terms); ok {
+		return s
+	}
+
+	s, _ := highlight(prd.Name.String(), terms)
+	return s
+}
+
+// highlight wraps the words in text that match any of the terms and trims the
+// text to a window around the first match. It reports false when nothing in
+// the text matched. The text is HTML escaped since the snippet is rendered
+// as HTML, leaving the markers as the only markup.
+func highlight(text string, terms []string) (string, bool) {
+	locs := wordRE.FindAllStringIndex(text, -1)
+
+	first := -1
+	matched := make([]bool, len(locs))
+	for i, loc := range locs {
+		tkn := strings.ToLower(text[loc[0]:loc[1]])
+		for _, term := range terms {
+			if Matches(tkn, term) {
+				matched[i] = true
+				if first == -1 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+
+	if first == -1 {
+		return "", false
+	}
+
+	// Center the window on the first match, shifting it back when the match
+	// is close to the end of the text.
+	from := max(first-snippetWords/2, 0)
+	to := min(from+snippetWords, len(locs))
+	from = max(to-snippetWords, 0)
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("...")
+	}
+
+	start := locs[from][0]
+	for i := from; i < to; i++ {
+		b.WriteString(html.EscapeString(text[start:locs[i][0]]))
+		if matched[i] {
+			b.WriteString(highlightPre)
+			b.WriteString(html.EscapeString(text[locs[i][0]:locs[i][1]]))
+			b.WriteString(highlightPost)
+		} else {
+			b.WriteString(html.EscapeString(text[locs[i][0]:locs[i][1]]))
+		}
+		start = locs[i][1]
+	}
+
+	if to < len(locs) {
+		b.WriteString("...")
+	} else {
+		b.WriteString(html.EscapeString(text[start:]))
+	}
+
+	return b.String(), true
+}
+
+// editDistance calculates the optimal string alignment distance between a and
+// b, counting an adjacent transposition as a single edit. It stops early and
+// returns limit+1 once the distance is known to exceed the limit.
+func editDistance(a string, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+
+			rowMin = min(rowMin, curr[j])
+		}
+
+		if rowMin > limit {
+			return limit + 1
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,174 @@
+package productbus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/productbus/stores/productmem"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/name"
+)
+
+func Test_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("terms", searchTerms)
+	t.Run("ranking", searchRanking)
+	t.Run("typos", searchTypos)
+	t.Run("snippet", searchSnippet)
+	t.Run("remove", searchRemove)
+}
+
+// =============================================================================
+
+func newSearchProducts() []productbus.Product {
+	return []productbus.Product{
+		{
+			ID:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			Name:        name.MustParse("Acoustic Guitar"),
+			Description: "A steel string acoustic guitar with a solid spruce top.",
+			Price:       money.MustParse(450),
+		},
+		{
+			ID:          uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			Name:        name.MustParse("Guitar Strings"),
+			Description: "Replacement strings for any acoustic instrument.",
+			Price:       money.MustParse(12),
+		},
+		{
+			ID:          uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+			Name:        name.MustParse("Drum Kit"),
+			Description: "Five piece kit with cymbals and a bass drum pedal.",
+			Price:       money.MustParse(800),
+		},
+	}
+}
+
+func newSearchIndex(t *testing.T) *productmem.Index {
+	idx := productmem.New()
+
+	for _, prd := range newSearchProducts() {
+		if err := idx.Index(context.Background(), prd); err != nil {
+			t.Fatalf("Should be able to index product %s: %s", prd.ID, err)
+		}
+	}
+
+	return idx
+}
+
+func searchIDs(t *testing.T, idx *productmem.Index, query string) []string {
+	res, total, err := idx.Search(context.Background(), productbus.ParseTerms(query), page.MustParse("1", "10"))
+	if err != nil {
+		t.Fatalf("Should be able to search for %q: %s", query, err)
+	}
+
+	if total != len(res) {
+		t.Fatalf("Should get a total that matches the results: got %d, exp %d", total, len(res))
+	}
+
+	ids := make([]string, len(res))
+	for i, r := range res {
+		ids[i] = r.Product.ID.String()[len(r.Product.ID.String())-1:]
+	}
+
+	return ids
+}
+
+// =============================================================================
+
+func searchTerms(t *testing.T) {
+	got := productbus.ParseTerms("Guitar, guitar STRINGS a of")
+	exp := []string{"guitar", "strings"}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should get the expected terms: %s", diff)
+	}
+}
+
+func searchRanking(t *testing.T) {
+	idx := newSearchIndex(t)
+
+	got := searchIDs(t, idx, "guitar")
+	exp := []string{"1", "2"}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should rank the repeated match first: %s", diff)
+	}
+
+	got = searchIDs(t, idx, "acoustic")
+	exp = []string{"1", "2"}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should rank the name match first: %s", diff)
+	}
+
+	got = searchIDs(t, idx, "gui kit")
+	if len(got) != 0 {
+		t.Fatalf("Should require every term to match: got %v", got)
+	}
+}
+
+func searchTypos(t *testing.T) {
+	idx := newSearchIndex(t)
+
+	got := searchIDs(t, idx, "drun")
+	exp := []string{"3"}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should tolerate a typo in a short term: %s", diff)
+	}
+
+	got = searchIDs(t, idx, "guiatr")
+	if len(got) != 0 {
+		t.Fatalf("Should not tolerate typos in long terms: got %v", got)
+	}
+
+	for _, v := range productbus.Variants("drun") {
+		if v == "drum" {
+			return
+		}
+	}
+	t.Fatalf("Should include the corrected term in the variants")
+}
+
+func searchSnippet(t *testing.T) {
+	prds := newSearchProducts()
+
+	got := productbus.Snippet(prds[2], productbus.ParseTerms("pedal"))
+	exp := "Five piece kit with cymbals and a bass drum <em>pedal</em>."
+	if got != exp {
+		t.Fatalf("Should highlight the description match:\ngot: %s\nexp: %s", got, exp)
+	}
+
+	got = productbus.Snippet(prds[1], productbus.ParseTerms("guitar"))
+	exp = "<em>Guitar</em> Strings"
+	if got != exp {
+		t.Fatalf("Should fall back to the name:\ngot: %s\nexp: %s", got, exp)
+	}
+
+	prd := prds[0]
+	prd.Description = `Tone <script>alert("x")</script> & sustain`
+
+	got = productbus.Snippet(prd, productbus.ParseTerms("tone"))
+	exp = "<em>Tone</em> &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; sustain"
+	if got != exp {
+		t.Fatalf("Should escape markup in the description:\ngot: %s\nexp: %s", got, exp)
+	}
+}
+
+func searchRemove(t *testing.T) {
+	idx := newSearchIndex(t)
+
+	prds := newSearchProducts()
+	if err := idx.Remove(context.Background(), prds[0]); err != nil {
+		t.Fatalf("Should be able to remove product: %s", err)
+	}
+
+	got := searchIDs(t, idx, "guitar")
+	exp := []string{"2"}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should not find the removed product: %s", diff)
+	}
+}
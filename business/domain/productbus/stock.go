@@ -0,0 +1,94 @@
+package productbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/quantity"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// SetLocationStock sets the stock of the product kept at the location. The
+// stock of a product is only tracked at the locations it has been set for.
+func (b *Business) SetLocationStock(ctx context.Context, prd Product, locationID uuid.UUID, qty quantity.Quantity) (LocationStock, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.setlocationstock")
+	defer span.End()
+
+	if prd.IsBundle() {
+		return LocationStock{}, fmt.Errorf("productID[%s] is a bundle: %w", prd.ID, ErrInvalidBundle)
+	}
+
+	qty, err := inUnit(qty, prd.Unit)
+	if err != nil {
+		return LocationStock{}, fmt.Errorf("productID[%s]: %w", prd.ID, err)
+	}
+
+	ls := LocationStock{
+		ProductID:   prd.ID,
+		LocationID:  locationID,
+		Stock:       qty,
+		DateUpdated: time.Now(),
+	}
+
+	if err := b.storer.SetLocationStock(ctx, ls); err != nil {
+		return LocationStock{}, fmt.Errorf("setlocationstock: %w", err)
+	}
+
+	return ls, nil
+}
+
+// QueryLocationStock retrieves the stock of the product at each location it
+// is tracked at.
+func (b *Business) QueryLocationStock(ctx context.Context, prd Product) ([]LocationStock, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querylocationstock")
+	defer span.End()
+
+	lss, err := b.storer.QueryLocationStock(ctx, prd.ID)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", prd.ID, err)
+	}
+
+	return lss, nil
+}
+
+// ConsumeLocationStock takes the specified quantity out of the product stock
+// and, when the stock of the product is tracked at the location, out of the
+// stock kept there. It fails when either does not have enough left.
+func (b *Business) ConsumeLocationStock(ctx context.Context, prd Product, locationID uuid.UUID, qty quantity.Quantity) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.consumelocationstock")
+	defer span.End()
+
+	qty, err := inUnit(qty, prd.Unit)
+	if err != nil {
+		return Product{}, fmt.Errorf("productID[%s]: %w", prd.ID, err)
+	}
+
+	ls, err := b.storer.QueryLocationStockByID(ctx, prd.ID, locationID)
+	switch {
+	case errors.Is(err, ErrStockNotTracked):
+		return b.ConsumeStock(ctx, prd, qty)
+
+	case err != nil:
+		return Product{}, fmt.Errorf("query: productID[%s] locationID[%s]: %w", prd.ID, locationID, err)
+	}
+
+	if qty.Value() > ls.Stock.Value() {
+		return Product{}, fmt.Errorf("productID[%s] locationID[%s] stock[%s] quantity[%s]: %w", prd.ID, locationID, ls.Stock, qty, ErrInsufficientStock)
+	}
+
+	ls.Stock, err = ls.Stock.Sub(qty)
+	if err != nil {
+		return Product{}, fmt.Errorf("stock: %w", err)
+	}
+	ls.DateUpdated = time.Now()
+
+	if err := b.storer.SetLocationStock(ctx, ls); err != nil {
+		return Product{}, fmt.Errorf("setlocationstock: %w", err)
+	}
+
+	return b.ConsumeStock(ctx, prd, qty)
+}
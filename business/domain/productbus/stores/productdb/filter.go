@@ -26,6 +26,16 @@ func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any,
 		wc = append(wc, "price = :price")
 	}
 
+	if filter.Category != nil {
+		data["category"] = filter.Category
+		wc = append(wc, "category = :category")
+	}
+
+	if filter.CreatedBy != nil {
+		data["created_by"] = filter.CreatedBy
+		wc = append(wc, "created_by = :created_by")
+	}
+
 	if len(filter.IDs) > 0 {
 		data["ids"] = filter.IDs
 		wc = append(wc, "id IN (:ids)")
@@ -1,31 +1,65 @@
 package productdb
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
 	"github.com/rmsj/service/business/types/name"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
 type product struct {
-	ID          uuid.UUID `db:"id"`
-	Name        string    `db:"name"`
-	Price       float64   `db:"price"`
-	DateCreated time.Time `db:"created_at"`
-	DateUpdated time.Time `db:"updated_at"`
+	ID             uuid.UUID      `db:"id"`
+	SKU            sql.NullString `db:"sku"`
+	Name           string         `db:"name"`
+	Description    string         `db:"description"`
+	Category       string         `db:"category"`
+	Kind           string         `db:"kind"`
+	BundlePricing  sql.NullString `db:"bundle_pricing"`
+	BundleDiscount float64        `db:"bundle_discount"`
+	Price          float64        `db:"price"`
+	Cost           float64        `db:"cost"`
+	Currency       string         `db:"currency"`
+	Unit           string         `db:"unit"`
+	Stock          float64        `db:"stock"`
+	MaxQuantity    float64        `db:"max_quantity"`
+	CreatedBy      id.Nullable    `db:"created_by"`
+	DateCreated    time.Time      `db:"created_at"`
+	DateUpdated    time.Time      `db:"updated_at"`
 }
 
 func toDBProduct(bus productbus.Product) product {
 	db := product{
-		ID:          bus.ID,
+		ID: bus.ID,
+		SKU: sql.NullString{
+			String: bus.SKU,
+			Valid:  bus.SKU != "",
+		},
 		Name:        bus.Name.String(),
-		Price:       bus.Price.Value(),
-		DateCreated: bus.DateCreated.UTC(),
-		DateUpdated: bus.DateUpdated.UTC(),
+		Description: bus.Description,
+		Category:    bus.Category,
+		Kind:        string(bus.Kind),
+		BundlePricing: sql.NullString{
+			String: string(bus.Bundle.Pricing),
+			Valid:  bus.IsBundle(),
+		},
+		BundleDiscount: bus.Bundle.Discount,
+		Price:          bus.Price.Value(),
+		Cost:           bus.Cost.Value(),
+		Currency:       bus.Currency.String(),
+		Unit:           bus.Unit.String(),
+		Stock:          bus.Stock.Value(),
+		MaxQuantity:    bus.MaxQuantity.Value(),
+		CreatedBy:      id.Nullable{UUID: bus.CreatedBy},
+		DateCreated:    bus.DateCreated.UTC(),
+		DateUpdated:    bus.DateUpdated.UTC(),
 	}
 
 	return db
@@ -38,18 +72,60 @@ func toBusProduct(db product) (productbus.Product, error) {
 	}
 
 	price, err := money.Parse(db.Price)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse price: %w", err)
+	}
+
+	cost, err := money.Parse(db.Cost)
 	if err != nil {
 		return productbus.Product{}, fmt.Errorf("parse cost: %w", err)
 	}
 
+	cur, err := currency.Parse(db.Currency)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse currency: %w", err)
+	}
+
+	unit, err := quantity.ParseUnit(db.Unit)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse unit: %w", err)
+	}
+
+	stock, err := quantity.Parse(db.Stock, unit)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse stock: %w", err)
+	}
+
+	maxQty, err := quantity.Parse(db.MaxQuantity, unit)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse max quantity: %w", err)
+	}
+
 	bus := productbus.Product{
 		ID:          db.ID,
+		SKU:         db.SKU.String,
 		Name:        name,
+		Description: db.Description,
+		Category:    db.Category,
+		Kind:        productbus.Kind(db.Kind),
 		Price:       price,
+		Cost:        cost,
+		Currency:    cur,
+		Unit:        unit,
+		Stock:       stock,
+		MaxQuantity: maxQty,
+		CreatedBy:   db.CreatedBy.UUID,
 		DateCreated: db.DateCreated.In(time.Local),
 		DateUpdated: db.DateUpdated.In(time.Local),
 	}
 
+	if bus.IsBundle() {
+		bus.Bundle = productbus.Bundle{
+			Pricing:  productbus.BundlePricing(db.BundlePricing.String),
+			Discount: db.BundleDiscount,
+		}
+	}
+
 	return bus, nil
 }
 
@@ -66,3 +142,143 @@ func toBusProducts(dbs []product) ([]productbus.Product, error) {
 
 	return bus, nil
 }
+
+type costChange struct {
+	ProductID     uuid.UUID `db:"product_id"`
+	Cost          float64   `db:"cost"`
+	EffectiveFrom time.Time `db:"effective_from"`
+}
+
+func toDBCostChange(bus productbus.CostChange) costChange {
+	return costChange{
+		ProductID:     bus.ProductID,
+		Cost:          bus.Cost.Value(),
+		EffectiveFrom: bus.EffectiveFrom.UTC(),
+	}
+}
+
+func toBusCostChanges(dbs []costChange) ([]productbus.CostChange, error) {
+	bus := make([]productbus.CostChange, len(dbs))
+
+	for i, db := range dbs {
+		cost, err := money.Parse(db.Cost)
+		if err != nil {
+			return nil, fmt.Errorf("parse cost: %w", err)
+		}
+
+		bus[i] = productbus.CostChange{
+			ProductID:     db.ProductID,
+			Cost:          cost,
+			EffectiveFrom: db.EffectiveFrom.In(time.Local),
+		}
+	}
+
+	return bus, nil
+}
+
+type locationStock struct {
+	ProductID   uuid.UUID `db:"product_id"`
+	LocationID  uuid.UUID `db:"location_id"`
+	Stock       float64   `db:"stock"`
+	Unit        string    `db:"unit"`
+	DateUpdated time.Time `db:"updated_at"`
+}
+
+func toDBLocationStock(bus productbus.LocationStock) locationStock {
+	return locationStock{
+		ProductID:   bus.ProductID,
+		LocationID:  bus.LocationID,
+		Stock:       bus.Stock.Value(),
+		Unit:        bus.Stock.Unit().String(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+}
+
+func toBusLocationStock(db locationStock) (productbus.LocationStock, error) {
+	unit, err := quantity.ParseUnit(db.Unit)
+	if err != nil {
+		return productbus.LocationStock{}, fmt.Errorf("parse unit: %w", err)
+	}
+
+	stock, err := quantity.Parse(db.Stock, unit)
+	if err != nil {
+		return productbus.LocationStock{}, fmt.Errorf("parse stock: %w", err)
+	}
+
+	bus := productbus.LocationStock{
+		ProductID:   db.ProductID,
+		LocationID:  db.LocationID,
+		Stock:       stock,
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusLocationStocks(dbs []locationStock) ([]productbus.LocationStock, error) {
+	bus := make([]productbus.LocationStock, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusLocationStock(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+type searchResult struct {
+	product
+	Score float64 `db:"score"`
+}
+
+func toBusSearchResults(dbs []searchResult) ([]productbus.SearchResult, error) {
+	bus := make([]productbus.SearchResult, len(dbs))
+
+	for i, db := range dbs {
+		prd, err := toBusProduct(db.product)
+		if err != nil {
+			return nil, err
+		}
+
+		bus[i] = productbus.SearchResult{
+			Product: prd,
+			Score:   db.Score,
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type component struct {
+	BundleID  uuid.UUID `db:"bundle_id"`
+	ProductID uuid.UUID `db:"product_id"`
+	Quantity  int       `db:"quantity"`
+}
+
+func toDBComponent(bundleID uuid.UUID, bus productbus.Component) component {
+	db := component{
+		BundleID:  bundleID,
+		ProductID: bus.ProductID,
+		Quantity:  bus.Quantity,
+	}
+
+	return db
+}
+
+func toBusComponents(dbs []component) map[uuid.UUID][]productbus.Component {
+	bus := make(map[uuid.UUID][]productbus.Component)
+
+	for _, db := range dbs {
+		bus[db.BundleID] = append(bus[db.BundleID], productbus.Component{
+			ProductID: db.ProductID,
+			Quantity:  db.Quantity,
+		})
+	}
+
+	return bus
+}
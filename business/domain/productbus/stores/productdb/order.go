@@ -10,7 +10,9 @@ import (
 var orderByFields = map[string]string{
 	productbus.OrderByProductID: "id",
 	productbus.OrderByName:      "name",
-	productbus.OrderByPrice:     "cost",
+	productbus.OrderByPrice:     "price",
+	productbus.OrderByMargin:    "(price - cost)",
+	productbus.OrderByUserID:    "created_by",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
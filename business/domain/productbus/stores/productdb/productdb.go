@@ -52,14 +52,18 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error)
 func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
 	const q = `
 	INSERT INTO products
-		(id, name, price, created_at, updated_at)
+		(id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at)
 	VALUES
-		(:id, :name, :price, :created_at, :updated_at)`
+		(:id, :sku, :name, :description, :category, :kind, :bundle_pricing, :bundle_discount, :price, :cost, :currency, :unit, :stock, :max_quantity, :created_by, :created_at, :updated_at)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
+	if err := s.setComponents(ctx, prd); err != nil {
+		return fmt.Errorf("setcomponents: %w", err)
+	}
+
 	return nil
 }
 
@@ -70,8 +74,17 @@ func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 	UPDATE
 		products
 	SET
+		sku = :sku,
 		name = :name,
+		description = :description,
+		category = :category,
+		bundle_pricing = :bundle_pricing,
+		bundle_discount = :bundle_discount,
 		price = :price,
+		cost = :cost,
+		currency = :currency,
+		stock = :stock,
+		max_quantity = :max_quantity,
 		updated_at = :updated_at
 	WHERE
 		id = :id`
@@ -80,6 +93,10 @@ func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
+	if err := s.setComponents(ctx, prd); err != nil {
+		return fmt.Errorf("setcomponents: %w", err)
+	}
+
 	return nil
 }
 
@@ -113,7 +130,7 @@ func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderB
 
 	const q = `
 	SELECT
-	    id, name, price, created_at, updated_at
+	    id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at
 	FROM
 		products`
 
@@ -133,7 +150,16 @@ func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderB
 		return nil, fmt.Errorf("namedqueryslice: %w", err)
 	}
 
-	return toBusProducts(dbPrds)
+	prds, err := toBusProducts(dbPrds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadComponents(ctx, prds); err != nil {
+		return nil, fmt.Errorf("loadcomponents: %w", err)
+	}
+
+	return prds, nil
 }
 
 // Count returns the total number of products in the DB.
@@ -167,7 +193,7 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.
 
 	const q = `
 	SELECT
-	    id, name, price, created_at, updated_at
+	    id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at
 	FROM
 		products
 	WHERE
@@ -181,5 +207,288 @@ func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.
 		return productbus.Product{}, fmt.Errorf("db: %w", err)
 	}
 
-	return toBusProduct(dbPrd)
+	return s.toBusProduct(ctx, dbPrd)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, sku string) (productbus.Product, error) {
+	data := struct {
+		SKU string `db:"sku"`
+	}{
+		SKU: sku,
+	}
+
+	const q = `
+	SELECT
+	    id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at
+	FROM
+		products
+	WHERE
+		sku = :sku`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
+	}
+
+	return s.toBusProduct(ctx, dbPrd)
+}
+
+// AddCost records a change to the cost of a product.
+func (s *Store) AddCost(ctx context.Context, cc productbus.CostChange) error {
+	const q = `
+	INSERT INTO product_costs
+		(product_id, cost, effective_from)
+	VALUES
+		(:product_id, :cost, :effective_from)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBCostChange(cc)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryCostHistory retrieves the cost changes of a product, most recent
+// first.
+func (s *Store) QueryCostHistory(ctx context.Context, productID uuid.UUID) ([]productbus.CostChange, error) {
+	data := struct {
+		ID string `db:"product_id"`
+	}{
+		ID: productID.String(),
+	}
+
+	const q = `
+	SELECT
+	    product_id, cost, effective_from
+	FROM
+		product_costs
+	WHERE
+		product_id = :product_id
+	ORDER BY
+		effective_from DESC`
+
+	var dbCCs []costChange
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCCs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusCostChanges(dbCCs)
+}
+
+// SetLocationStock inserts or replaces the stock of a product kept at a
+// location.
+func (s *Store) SetLocationStock(ctx context.Context, ls productbus.LocationStock) error {
+	const q = `
+	INSERT INTO location_stock
+		(product_id, location_id, stock, updated_at)
+	VALUES
+		(:product_id, :location_id, :stock, :updated_at)
+	ON DUPLICATE KEY UPDATE
+		stock = VALUES(stock),
+		updated_at = VALUES(updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLocationStock(ls)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryLocationStock retrieves the stock of a product at each location it is
+// tracked at.
+func (s *Store) QueryLocationStock(ctx context.Context, productID uuid.UUID) ([]productbus.LocationStock, error) {
+	data := struct {
+		ID string `db:"product_id"`
+	}{
+		ID: productID.String(),
+	}
+
+	const q = `
+	SELECT
+	    ls.product_id, ls.location_id, ls.stock, p.unit, ls.updated_at
+	FROM
+		location_stock AS ls
+	JOIN
+		products AS p ON p.id = ls.product_id
+	WHERE
+		ls.product_id = :product_id
+	ORDER BY
+		ls.location_id`
+
+	var dbLSs []locationStock
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbLSs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusLocationStocks(dbLSs)
+}
+
+// QueryLocationStockByID gets the stock of a product kept at a location.
+func (s *Store) QueryLocationStockByID(ctx context.Context, productID uuid.UUID, locationID uuid.UUID) (productbus.LocationStock, error) {
+	data := struct {
+		ProductID  string `db:"product_id"`
+		LocationID string `db:"location_id"`
+	}{
+		ProductID:  productID.String(),
+		LocationID: locationID.String(),
+	}
+
+	const q = `
+	SELECT
+	    ls.product_id, ls.location_id, ls.stock, p.unit, ls.updated_at
+	FROM
+		location_stock AS ls
+	JOIN
+		products AS p ON p.id = ls.product_id
+	WHERE
+		ls.product_id = :product_id AND
+		ls.location_id = :location_id`
+
+	var dbLS locationStock
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLS); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.LocationStock{}, fmt.Errorf("db: %w", productbus.ErrStockNotTracked)
+		}
+		return productbus.LocationStock{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusLocationStock(dbLS)
+}
+
+// QueryBundlesByComponent finds the bundles the product is a component of.
+func (s *Store) QueryBundlesByComponent(ctx context.Context, productID uuid.UUID) ([]productbus.Product, error) {
+	data := struct {
+		ID string `db:"product_id"`
+	}{
+		ID: productID.String(),
+	}
+
+	const q = `
+	SELECT
+	    id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at
+	FROM
+		products
+	WHERE
+		id IN (SELECT bundle_id FROM product_components WHERE product_id = :product_id)
+	ORDER BY
+		id`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	prds, err := toBusProducts(dbPrds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadComponents(ctx, prds); err != nil {
+		return nil, fmt.Errorf("loadcomponents: %w", err)
+	}
+
+	return prds, nil
+}
+
+// =============================================================================
+
+// setComponents replaces the components of a bundle.
+func (s *Store) setComponents(ctx context.Context, prd productbus.Product) error {
+	if !prd.IsBundle() {
+		return nil
+	}
+
+	data := struct {
+		ID string `db:"bundle_id"`
+	}{
+		ID: prd.ID.String(),
+	}
+
+	const del = `
+	DELETE FROM
+		product_components
+	WHERE
+		bundle_id = :bundle_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const ins = `
+	INSERT INTO product_components
+		(bundle_id, product_id, quantity)
+	VALUES
+		(:bundle_id, :product_id, :quantity)`
+
+	for _, c := range prd.Bundle.Components {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, ins, toDBComponent(prd.ID, c)); err != nil {
+			return fmt.Errorf("namedexeccontext: componentID[%s]: %w", c.ProductID, err)
+		}
+	}
+
+	return nil
+}
+
+// loadComponents fills in the components of the bundles in the slice.
+func (s *Store) loadComponents(ctx context.Context, prds []productbus.Product) error {
+	var bundleIDs []uuid.UUID
+	for _, prd := range prds {
+		if prd.IsBundle() {
+			bundleIDs = append(bundleIDs, prd.ID)
+		}
+	}
+
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	data := struct {
+		IDs []uuid.UUID `db:"bundle_ids"`
+	}{
+		IDs: bundleIDs,
+	}
+
+	const q = `
+	SELECT
+		bundle_id, product_id, quantity
+	FROM
+		product_components
+	WHERE
+		bundle_id IN (:bundle_ids)
+	ORDER BY
+		bundle_id, product_id`
+
+	var dbComps []component
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbComps); err != nil {
+		return fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	comps := toBusComponents(dbComps)
+	for i, prd := range prds {
+		if prd.IsBundle() {
+			prds[i].Bundle.Components = comps[prd.ID]
+		}
+	}
+
+	return nil
+}
+
+// toBusProduct converts the product and loads its components when it is a
+// bundle.
+func (s *Store) toBusProduct(ctx context.Context, db product) (productbus.Product, error) {
+	prd, err := toBusProduct(db)
+	if err != nil {
+		return productbus.Product{}, err
+	}
+
+	prds := []productbus.Product{prd}
+	if err := s.loadComponents(ctx, prds); err != nil {
+		return productbus.Product{}, fmt.Errorf("loadcomponents: %w", err)
+	}
+
+	return prds[0], nil
 }
@@ -0,0 +1,109 @@
+package productdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+)
+
+// Index is a no-op since the FULLTEXT index is maintained by the database.
+func (s *Store) Index(_ context.Context, _ productbus.Product) error {
+	return nil
+}
+
+// Remove is a no-op since the FULLTEXT index is maintained by the database.
+func (s *Store) Remove(_ context.Context, _ productbus.Product) error {
+	return nil
+}
+
+// Search finds products using the FULLTEXT index over name and description,
+// ordered by the relevance MySQL calculates for each match.
+func (s *Store) Search(ctx context.Context, terms []string, page page.Page) ([]productbus.SearchResult, int, error) {
+	data := map[string]any{
+		"query":         booleanQuery(terms),
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    id, sku, name, description, category, kind, bundle_pricing, bundle_discount, price, cost, currency, unit, stock, max_quantity, created_by, created_at, updated_at,
+	    MATCH (name, description) AGAINST (:query IN BOOLEAN MODE) AS score
+	FROM
+		products
+	WHERE
+		MATCH (name, description) AGAINST (:query IN BOOLEAN MODE)
+	ORDER BY
+		score DESC, id
+	LIMIT :rows_per_page OFFSET :offset`
+
+	var dbRes []searchResult
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRes); err != nil {
+		return nil, 0, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	const qc = `
+	SELECT
+		COUNT(id) AS ` + "`count`" + `
+	FROM
+		products
+	WHERE
+		MATCH (name, description) AGAINST (:query IN BOOLEAN MODE)`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qc, data, &count); err != nil {
+		return nil, 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	res, err := toBusSearchResults(dbRes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	prds := make([]productbus.Product, len(res))
+	for i, r := range res {
+		prds[i] = r.Product
+	}
+
+	if err := s.loadComponents(ctx, prds); err != nil {
+		return nil, 0, fmt.Errorf("loadcomponents: %w", err)
+	}
+
+	for i := range res {
+		res[i].Product = prds[i]
+	}
+
+	return res, count.Count, nil
+}
+
+// booleanQuery builds a boolean mode search where every term is required.
+// Each term matches on prefix and, for terms that tolerate typos, any of its
+// variants is accepted with a lower weight than the term itself.
+func booleanQuery(terms []string) string {
+	var b strings.Builder
+
+	for i, term := range terms {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+
+		b.WriteString("+(>")
+		b.WriteString(term)
+		b.WriteString("*")
+
+		for _, v := range productbus.Variants(term) {
+			b.WriteString(" <")
+			b.WriteString(v)
+		}
+
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
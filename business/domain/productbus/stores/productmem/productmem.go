@@ -0,0 +1,230 @@
+// Package productmem provides an in-memory inverted index for product search.
+package productmem
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/sdk/page"
+)
+
+// Set of weights used to rank matches. A match in the name counts for more
+// than one in the description, and an exact match for more than a prefix or
+// typo tolerant one.
+const (
+	nameBoost    = 2.0
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	fuzzyWeight  = 0.5
+)
+
+// BM25 tuning parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+type field int
+
+const (
+	fieldName field = iota
+	fieldDescription
+)
+
+type document struct {
+	prd    productbus.Product
+	tf     [2]map[string]int
+	length [2]int
+}
+
+// Index manages an in-memory inverted index of products.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[uuid.UUID]document
+	postings map[string]map[uuid.UUID]struct{}
+	totalLen [2]int
+}
+
+// New constructs an empty index for use.
+func New() *Index {
+	return &Index{
+		docs:     make(map[uuid.UUID]document),
+		postings: make(map[string]map[uuid.UUID]struct{}),
+	}
+}
+
+// Index adds the product to the index, replacing any previous version of it.
+func (idx *Index) Index(_ context.Context, prd productbus.Product) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(prd.ID)
+
+	doc := document{
+		prd: prd,
+	}
+
+	texts := [2]string{prd.Name.String(), prd.Description}
+	for f, text := range texts {
+		doc.tf[f] = make(map[string]int)
+
+		for _, tkn := range productbus.Tokenize(text) {
+			doc.tf[f][tkn]++
+			doc.length[f]++
+
+			ids, exists := idx.postings[tkn]
+			if !exists {
+				ids = make(map[uuid.UUID]struct{})
+				idx.postings[tkn] = ids
+			}
+			ids[prd.ID] = struct{}{}
+		}
+
+		idx.totalLen[f] += doc.length[f]
+	}
+
+	idx.docs[prd.ID] = doc
+
+	return nil
+}
+
+// Remove drops the product from the index.
+func (idx *Index) Remove(_ context.Context, prd productbus.Product) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(prd.ID)
+
+	return nil
+}
+
+// Search returns the products matching every term, ranked with BM25.
+func (idx *Index) Search(_ context.Context, terms []string, page page.Page) ([]productbus.SearchResult, int, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[uuid.UUID]float64
+
+	for _, term := range terms {
+		termScores := idx.scoreTerm(term)
+
+		// Every term is required, so only documents that matched all the
+		// previous terms are kept.
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+
+		for id, score := range scores {
+			ts, exists := termScores[id]
+			if !exists {
+				delete(scores, id)
+				continue
+			}
+			scores[id] = score + ts
+		}
+	}
+
+	results := make([]productbus.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, productbus.SearchResult{
+			Product: idx.docs[id].prd,
+			Score:   score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Product.ID.String() < results[j].Product.ID.String()
+	})
+
+	total := len(results)
+
+	from := min((page.Number()-1)*page.RowsPerPage(), total)
+	to := min(from+page.RowsPerPage(), total)
+
+	return results[from:to], total, nil
+}
+
+// =============================================================================
+
+func (idx *Index) remove(id uuid.UUID) {
+	doc, exists := idx.docs[id]
+	if !exists {
+		return
+	}
+
+	for f := range doc.tf {
+		for tkn := range doc.tf[f] {
+			delete(idx.postings[tkn], id)
+			if len(idx.postings[tkn]) == 0 {
+				delete(idx.postings, tkn)
+			}
+		}
+
+		idx.totalLen[f] -= doc.length[f]
+	}
+
+	delete(idx.docs, id)
+}
+
+// scoreTerm returns the score of every document containing a token that
+// matches the term. When several tokens in a document match, the best one
+// counts.
+func (idx *Index) scoreTerm(term string) map[uuid.UUID]float64 {
+	scores := make(map[uuid.UUID]float64)
+
+	for tkn, ids := range idx.postings {
+		if !productbus.Matches(tkn, term) {
+			continue
+		}
+
+		weight := fuzzyWeight
+		switch {
+		case tkn == term:
+			weight = exactWeight
+		case strings.HasPrefix(tkn, term):
+			weight = prefixWeight
+		}
+
+		idf := idx.idf(len(ids))
+
+		for id := range ids {
+			doc := idx.docs[id]
+
+			score := nameBoost*idx.bm25(doc, fieldName, tkn, idf) + idx.bm25(doc, fieldDescription, tkn, idf)
+			score *= weight
+
+			if score > scores[id] {
+				scores[id] = score
+			}
+		}
+	}
+
+	return scores
+}
+
+func (idx *Index) idf(df int) float64 {
+	n := float64(len(idx.docs))
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+func (idx *Index) bm25(doc document, f field, tkn string, idf float64) float64 {
+	tf := float64(doc.tf[f][tkn])
+	if tf == 0 {
+		return 0
+	}
+
+	avgLen := float64(idx.totalLen[f]) / float64(len(idx.docs))
+	norm := 1 - b + b*float64(doc.length[f])/avgLen
+
+	return idf * (tf * (k1 + 1)) / (tf + k1*norm)
+}
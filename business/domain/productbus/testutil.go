@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"math/rand"
 
+	"github.com/google/uuid"
+
 	"github.com/rmsj/service/business/types/money"
 	"github.com/rmsj/service/business/types/name"
 )
 
 // TestGenerateNewProducts is a helper method for testing.
-func TestGenerateNewProducts(n int) []NewProduct {
+func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
 	newPrds := make([]NewProduct, n)
 
 	idx := rand.Intn(10000)
@@ -18,8 +20,9 @@ func TestGenerateNewProducts(n int) []NewProduct {
 		idx++
 
 		np := NewProduct{
-			Name:  name.MustParse(fmt.Sprintf("Name%d", idx)),
-			Price: money.MustParse(float64(rand.Intn(500))),
+			Name:      name.MustParse(fmt.Sprintf("Name%d", idx)),
+			Price:     money.MustParse(float64(rand.Intn(500))),
+			CreatedBy: userID,
 		}
 
 		newPrds[i] = np
@@ -29,8 +32,8 @@ func TestGenerateNewProducts(n int) []NewProduct {
 }
 
 // TestGenerateSeedProducts is a helper method for testing.
-func TestGenerateSeedProducts(ctx context.Context, n int, api *Business) ([]Product, error) {
-	newPrds := TestGenerateNewProducts(n)
+func TestGenerateSeedProducts(ctx context.Context, n int, api *Business, userID uuid.UUID) ([]Product, error) {
+	newPrds := TestGenerateNewProducts(n, userID)
 
 	prds := make([]Product, len(newPrds))
 	for i, np := range newPrds {
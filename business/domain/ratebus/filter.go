@@ -0,0 +1,12 @@
+package ratebus
+
+import (
+	"github.com/rmsj/service/business/types/currency"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	From *currency.Currency
+	To   *currency.Currency
+}
@@ -0,0 +1,41 @@
+package ratebus
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+)
+
+// Rate represents how many units of the To currency one unit of the From
+// currency buys. A rate is in effect from EffectiveFrom until a later rate
+// for the same currencies takes effect.
+type Rate struct {
+	From          currency.Currency
+	To            currency.Currency
+	Rate          float64
+	EffectiveFrom time.Time
+	DateCreated   time.Time
+}
+
+// Convert returns the amount of money in the From currency expressed in the
+// To currency.
+func (r Rate) Convert(m money.Money) (money.Money, error) {
+	converted, err := money.Parse(math.Round(m.Value()*r.Rate*100) / 100)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("convert %s %s to %s: %w", m, r.From, r.To, err)
+	}
+
+	return converted, nil
+}
+
+// NewRate is what we require from clients when adding a Rate. The rate takes
+// effect immediately when no EffectiveFrom is provided.
+type NewRate struct {
+	From          currency.Currency
+	To            currency.Currency
+	Rate          float64
+	EffectiveFrom time.Time
+}
@@ -0,0 +1,13 @@
+package ratebus
+
+import "github.com/rmsj/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByEffectiveFrom, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByEffectiveFrom = "a"
+	OrderByFrom          = "b"
+	OrderByTo            = "c"
+)
@@ -0,0 +1,223 @@
+// Package ratebus provides business access to the exchange rates used to
+// convert money between currencies.
+package ratebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound    = errors.New("exchange rate not found")
+	ErrInvalidRate = errors.New("exchange rate not valid")
+)
+
+// Decimals is the number of decimal places a rate is kept with.
+const Decimals = 8
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, rate Rate) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Rate, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryAt(ctx context.Context, from currency.Currency, to currency.Currency, at time.Time) (Rate, error)
+}
+
+// Business manages the set of APIs for exchange rate access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an exchange rate business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	b := Business{
+		log:    log,
+		storer: storer,
+	}
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new exchange rate to the system. A rate for the same
+// currencies taking effect at the same time is replaced.
+func (b *Business) Create(ctx context.Context, nr NewRate) (Rate, error) {
+	ctx, span := otel.AddSpan(ctx, "business.ratebus.create")
+	defer span.End()
+
+	rate, err := toRate(nr, time.Now())
+	if err != nil {
+		return Rate{}, err
+	}
+
+	if err := b.storer.Create(ctx, rate); err != nil {
+		return Rate{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rate, nil
+}
+
+// Import adds the set of exchange rates to the system. All the rates are
+// validated before any is stored, so callers that want all or nothing should
+// run the import inside a transaction.
+func (b *Business) Import(ctx context.Context, nrs []NewRate) ([]Rate, error) {
+	ctx, span := otel.AddSpan(ctx, "business.ratebus.import")
+	defer span.End()
+
+	now := time.Now()
+
+	rates := make([]Rate, len(nrs))
+	for i, nr := range nrs {
+		rate, err := toRate(nr, now)
+		if err != nil {
+			return nil, fmt.Errorf("rate[%d]: %w", i+1, err)
+		}
+
+		rates[i] = rate
+	}
+
+	for _, rate := range rates {
+		if err := b.storer.Create(ctx, rate); err != nil {
+			return nil, fmt.Errorf("create: %s to %s: %w", rate.From, rate.To, err)
+		}
+	}
+
+	return rates, nil
+}
+
+// Query retrieves a list of existing exchange rates.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Rate, error) {
+	ctx, span := otel.AddSpan(ctx, "business.ratebus.query")
+	defer span.End()
+
+	rates, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return rates, nil
+}
+
+// Count returns the total number of exchange rates.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.ratebus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryAt finds the rate in effect at the specified time to convert between
+// the currencies. When only the rate for the opposite direction is on record
+// its inverse is used, and converting a currency into itself always uses a
+// rate of one.
+func (b *Business) QueryAt(ctx context.Context, from currency.Currency, to currency.Currency, at time.Time) (Rate, error) {
+	ctx, span := otel.AddSpan(ctx, "business.ratebus.queryat")
+	defer span.End()
+
+	if from.Equal(to) {
+		return Rate{From: from, To: to, Rate: 1}, nil
+	}
+
+	rate, err := b.storer.QueryAt(ctx, from, to, at)
+	if err == nil {
+		return rate, nil
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return Rate{}, fmt.Errorf("query: %s to %s: %w", from, to, err)
+	}
+
+	inverse, err := b.storer.QueryAt(ctx, to, from, at)
+	if err != nil {
+		return Rate{}, fmt.Errorf("query: %s to %s at %s: %w", from, to, at.Format(time.RFC3339), err)
+	}
+
+	rate = Rate{
+		From:          from,
+		To:            to,
+		Rate:          round(1 / inverse.Rate),
+		EffectiveFrom: inverse.EffectiveFrom,
+		DateCreated:   inverse.DateCreated,
+	}
+
+	return rate, nil
+}
+
+// Convert returns the amount of money expressed in the other currency at the
+// rate in effect at the specified time, along with the rate used.
+func (b *Business) Convert(ctx context.Context, m money.Money, from currency.Currency, to currency.Currency, at time.Time) (money.Money, Rate, error) {
+	rate, err := b.QueryAt(ctx, from, to, at)
+	if err != nil {
+		return money.Money{}, Rate{}, err
+	}
+
+	converted, err := rate.Convert(m)
+	if err != nil {
+		return money.Money{}, Rate{}, err
+	}
+
+	return converted, rate, nil
+}
+
+// =============================================================================
+
+func toRate(nr NewRate, now time.Time) (Rate, error) {
+	if nr.From.Equal(nr.To) {
+		return Rate{}, fmt.Errorf("%s to itself: %w", nr.From, ErrInvalidRate)
+	}
+
+	if nr.Rate <= 0 || round(nr.Rate) != nr.Rate {
+		return Rate{}, fmt.Errorf("%s to %s rate[%v] must be positive with at most %d decimals: %w", nr.From, nr.To, nr.Rate, Decimals, ErrInvalidRate)
+	}
+
+	effectiveFrom := nr.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = now
+	}
+
+	rate := Rate{
+		From:          nr.From,
+		To:            nr.To,
+		Rate:          nr.Rate,
+		EffectiveFrom: effectiveFrom,
+		DateCreated:   now,
+	}
+
+	return rate, nil
+}
+
+// round rounds the value to the decimals a rate is kept with.
+func round(value float64) float64 {
+	p := math.Pow10(Decimals)
+	return math.Round(value*p) / p
+}
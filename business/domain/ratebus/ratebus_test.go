@@ -0,0 +1,253 @@
+package ratebus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/business/types/money"
+)
+
+var (
+	usd = currency.MustParse("USD")
+	eur = currency.MustParse("EUR")
+	gbp = currency.MustParse("GBP")
+)
+
+func Test_Rate(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Rate")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, queryAt(db.BusDomain, sd), "queryat")
+	unitest.Run(t, create(db.BusDomain), "create")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	now := time.Now()
+
+	nrs := []ratebus.NewRate{
+		{From: usd, To: eur, Rate: 0.9, EffectiveFrom: now.AddDate(0, 0, -30)},
+		{From: usd, To: eur, Rate: 0.8, EffectiveFrom: now.AddDate(0, 0, -10)},
+		{From: gbp, To: usd, Rate: 1.25, EffectiveFrom: now.AddDate(0, 0, -30)},
+	}
+
+	rates, err := ratebus.TestSeedRates(ctx, nrs, busDomain.Rate)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding rates : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Rates: rates,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func cmpRate(got any, exp any) string {
+	gotResp, exists := got.(ratebus.Rate)
+	if !exists {
+		return "error occurred"
+	}
+
+	expResp := exp.(ratebus.Rate)
+
+	expResp.EffectiveFrom = gotResp.EffectiveFrom
+	expResp.DateCreated = gotResp.DateCreated
+
+	return cmp.Diff(gotResp, expResp)
+}
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "by-currency",
+			ExpResp: []ratebus.Rate{sd.Rates[1], sd.Rates[0]},
+			ExcFunc: func(ctx context.Context) any {
+				filter := ratebus.QueryFilter{
+					From: &usd,
+					To:   &eur,
+				}
+
+				resp, err := busDomain.Rate.Query(ctx, filter, ratebus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.([]ratebus.Rate)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.([]ratebus.Rate)
+
+				for i := range gotResp {
+					if i < len(expResp) {
+						expResp[i].EffectiveFrom = gotResp[i].EffectiveFrom
+						expResp[i].DateCreated = gotResp[i].DateCreated
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryAt(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	now := time.Now()
+
+	table := []unitest.Table{
+		{
+			Name:    "latest",
+			ExpResp: sd.Rates[1],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Rate.QueryAt(ctx, usd, eur, now)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRate,
+		},
+		{
+			Name:    "historical",
+			ExpResp: sd.Rates[0],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Rate.QueryAt(ctx, usd, eur, now.AddDate(0, 0, -20))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRate,
+		},
+		{
+			Name:    "inverse",
+			ExpResp: ratebus.Rate{From: usd, To: gbp, Rate: 0.8},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Rate.QueryAt(ctx, usd, gbp, now)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRate,
+		},
+		{
+			Name:    "same-currency",
+			ExpResp: ratebus.Rate{From: eur, To: eur, Rate: 1},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Rate.QueryAt(ctx, eur, eur, now)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRate,
+		},
+		{
+			Name:    "before-first",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Rate.QueryAt(ctx, usd, eur, now.AddDate(0, 0, -60))
+				return errors.Is(err, ratebus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "convert",
+			ExpResp: money.MustParse(40),
+			ExcFunc: func(ctx context.Context) any {
+				resp, _, err := busDomain.Rate.Convert(ctx, money.MustParse(50), usd, eur, now)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: ratebus.Rate{From: eur, To: gbp, Rate: 0.85},
+			ExcFunc: func(ctx context.Context) any {
+				nr := ratebus.NewRate{
+					From: eur,
+					To:   gbp,
+					Rate: 0.85,
+				}
+
+				resp, err := busDomain.Rate.Create(ctx, nr)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRate,
+		},
+		{
+			Name:    "invalid",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				nr := ratebus.NewRate{
+					From: eur,
+					To:   gbp,
+					Rate: -1,
+				}
+
+				_, err := busDomain.Rate.Create(ctx, nr)
+				return errors.Is(err, ratebus.ErrInvalidRate)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
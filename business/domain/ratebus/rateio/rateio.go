@@ -0,0 +1,121 @@
+// Package rateio reads exchange rates from the CSV files used for bulk
+// import. A file has a header with the from, to and rate columns and an
+// optional effective_from column holding a RFC3339 timestamp or a date.
+package rateio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/types/currency"
+)
+
+// Read parses the exchange rates in the CSV file. Every line is validated and
+// all the problems found are reported together, prefixed by the line they
+// were found on.
+func Read(r io.Reader) ([]ratebus.NewRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	cols, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header")
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	idx := make(map[string]int)
+	for i, col := range cols {
+		idx[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+
+	for _, col := range []string{"from", "to", "rate"} {
+		if _, exists := idx[col]; !exists {
+			return nil, fmt.Errorf("missing column %q", col)
+		}
+	}
+
+	get := func(row []string, col string) string {
+		i, exists := idx[col]
+		if !exists || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var nrs []ratebus.NewRate
+	var errs []error
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		nr, err := toNewRate(get(row, "from"), get(row, "to"), get(row, "rate"), get(row, "effective_from"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		nrs = append(nrs, nr)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return nrs, nil
+}
+
+func toNewRate(from string, to string, rate string, effectiveFrom string) (ratebus.NewRate, error) {
+	fromCur, err := currency.Parse(from)
+	if err != nil {
+		return ratebus.NewRate{}, fmt.Errorf("parse from: %w", err)
+	}
+
+	toCur, err := currency.Parse(to)
+	if err != nil {
+		return ratebus.NewRate{}, fmt.Errorf("parse to: %w", err)
+	}
+
+	rt, err := strconv.ParseFloat(rate, 64)
+	if err != nil {
+		return ratebus.NewRate{}, fmt.Errorf("invalid rate %q", rate)
+	}
+
+	nr := ratebus.NewRate{
+		From: fromCur,
+		To:   toCur,
+		Rate: rt,
+	}
+
+	if effectiveFrom != "" {
+		nr.EffectiveFrom, err = parseTime(effectiveFrom)
+		if err != nil {
+			return ratebus.NewRate{}, fmt.Errorf("invalid effective_from %q", effectiveFrom)
+		}
+	}
+
+	return nr, nil
+}
+
+// parseTime accepts a RFC3339 timestamp or a date, which is taken as the
+// start of the day in UTC.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
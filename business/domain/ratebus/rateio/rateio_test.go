@@ -0,0 +1,71 @@
+package rateio_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/ratebus/rateio"
+	"github.com/rmsj/service/business/types/currency"
+)
+
+func Test_RateIO(t *testing.T) {
+	t.Parallel()
+
+	t.Run("read", read)
+	t.Run("invalid", invalid)
+}
+
+// =============================================================================
+
+func read(t *testing.T) {
+	const data = "from,to,rate,effective_from\n" +
+		"usd,EUR,0.92,2024-01-01\n" +
+		"GBP, USD, 1.27, 2024-02-01T12:00:00Z\n" +
+		"AUD,USD,0.65,\n"
+
+	got, err := rateio.Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Should be able to read rates: %s", err)
+	}
+
+	exp := []ratebus.NewRate{
+		{From: currency.MustParse("USD"), To: currency.MustParse("EUR"), Rate: 0.92, EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{From: currency.MustParse("GBP"), To: currency.MustParse("USD"), Rate: 1.27, EffectiveFrom: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)},
+		{From: currency.MustParse("AUD"), To: currency.MustParse("USD"), Rate: 0.65},
+	}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should get back the rates in the file: %s", diff)
+	}
+}
+
+func invalid(t *testing.T) {
+	const data = "from,to,rate,effective_from\n" +
+		"USD,EUR,0.92,2024-01-01\n" +
+		"US,EUR,0.92,\n" +
+		"USD,EUR,lots,\n" +
+		"USD,EUR,0.92,yesterday\n"
+
+	_, err := rateio.Read(strings.NewReader(data))
+	if err == nil {
+		t.Fatalf("Should not be able to read invalid rates")
+	}
+
+	for _, exp := range []string{"line 3: parse from", "line 4: invalid rate", "line 5: invalid effective_from"} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("Should report %q, got:\n%s", exp, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Should not report the valid line, got:\n%s", err)
+	}
+
+	if _, err := rateio.Read(strings.NewReader("from,rate\nUSD,1\n")); err == nil {
+		t.Errorf("Should not be able to read a file without the to column")
+	}
+}
@@ -0,0 +1,27 @@
+package ratedb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+)
+
+func (s *Store) applyFilter(filter ratebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.From != nil {
+		data["from_currency"] = filter.From.String()
+		wc = append(wc, "from_currency = :from_currency")
+	}
+
+	if filter.To != nil {
+		data["to_currency"] = filter.To.String()
+		wc = append(wc, "to_currency = :to_currency")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
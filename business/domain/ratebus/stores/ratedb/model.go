@@ -0,0 +1,65 @@
+package ratedb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/types/currency"
+)
+
+type rate struct {
+	From          string    `db:"from_currency"`
+	To            string    `db:"to_currency"`
+	Rate          float64   `db:"rate"`
+	EffectiveFrom time.Time `db:"effective_from"`
+	DateCreated   time.Time `db:"created_at"`
+}
+
+func toDBRate(bus ratebus.Rate) rate {
+	db := rate{
+		From:          bus.From.String(),
+		To:            bus.To.String(),
+		Rate:          bus.Rate,
+		EffectiveFrom: bus.EffectiveFrom.UTC(),
+		DateCreated:   bus.DateCreated.UTC(),
+	}
+
+	return db
+}
+
+func toBusRate(db rate) (ratebus.Rate, error) {
+	from, err := currency.Parse(db.From)
+	if err != nil {
+		return ratebus.Rate{}, fmt.Errorf("parse from currency: %w", err)
+	}
+
+	to, err := currency.Parse(db.To)
+	if err != nil {
+		return ratebus.Rate{}, fmt.Errorf("parse to currency: %w", err)
+	}
+
+	bus := ratebus.Rate{
+		From:          from,
+		To:            to,
+		Rate:          db.Rate,
+		EffectiveFrom: db.EffectiveFrom.In(time.Local),
+		DateCreated:   db.DateCreated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusRates(dbs []rate) ([]ratebus.Rate, error) {
+	bus := make([]ratebus.Rate, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusRate(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,23 @@
+package ratedb
+
+import (
+	"fmt"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	ratebus.OrderByEffectiveFrom: "effective_from",
+	ratebus.OrderByFrom:          "from_currency",
+	ratebus.OrderByTo:            "to_currency",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
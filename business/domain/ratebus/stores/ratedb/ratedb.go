@@ -0,0 +1,156 @@
+// Package ratedb contains exchange rate related CRUD functionality.
+package ratedb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/currency"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// Store manages the set of APIs for exchange rate database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (ratebus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new exchange rate into the database, replacing the rate
+// for the same currencies that takes effect at the same time.
+func (s *Store) Create(ctx context.Context, rt ratebus.Rate) error {
+	const q = `
+	INSERT INTO exchange_rates
+		(from_currency, to_currency, rate, effective_from, created_at)
+	VALUES
+		(:from_currency, :to_currency, :rate, :effective_from, :created_at)
+	ON DUPLICATE KEY UPDATE
+		rate = VALUES(rate),
+		created_at = VALUES(created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRate(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing exchange rates from the database.
+func (s *Store) Query(ctx context.Context, filter ratebus.QueryFilter, orderBy order.By, page page.Page) ([]ratebus.Rate, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    from_currency, to_currency, rate, effective_from, created_at
+	FROM
+		exchange_rates`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset")
+
+	var dbRates []rate
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbRates); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusRates(dbRates)
+}
+
+// Count returns the total number of exchange rates in the DB.
+func (s *Store) Count(ctx context.Context, filter ratebus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = "SELECT COUNT(*) AS `count` FROM exchange_rates"
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryAt gets the most recent rate between the currencies that took effect
+// at or before the specified time.
+func (s *Store) QueryAt(ctx context.Context, from currency.Currency, to currency.Currency, at time.Time) (ratebus.Rate, error) {
+	data := struct {
+		From string    `db:"from_currency"`
+		To   string    `db:"to_currency"`
+		At   time.Time `db:"at"`
+	}{
+		From: from.String(),
+		To:   to.String(),
+		At:   at.UTC(),
+	}
+
+	const q = `
+	SELECT
+	    from_currency, to_currency, rate, effective_from, created_at
+	FROM
+		exchange_rates
+	WHERE
+		from_currency = :from_currency AND
+		to_currency = :to_currency AND
+		effective_from <= :at
+	ORDER BY
+		effective_from DESC
+	LIMIT 1`
+
+	var dbRate rate
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRate); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return ratebus.Rate{}, fmt.Errorf("db: %w", ratebus.ErrNotFound)
+		}
+		return ratebus.Rate{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRate(dbRate)
+}
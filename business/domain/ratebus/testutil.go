@@ -0,0 +1,21 @@
+package ratebus
+
+import (
+	"context"
+	"fmt"
+)
+
+// TestSeedRates is a helper method for testing.
+func TestSeedRates(ctx context.Context, nrs []NewRate, api *Business) ([]Rate, error) {
+	rates := make([]Rate, len(nrs))
+	for i, nr := range nrs {
+		rate, err := api.Create(ctx, nr)
+		if err != nil {
+			return nil, fmt.Errorf("seeding rate: idx: %d : %w", i, err)
+		}
+
+		rates[i] = rate
+	}
+
+	return rates, nil
+}
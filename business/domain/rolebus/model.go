@@ -0,0 +1,40 @@
+package rolebus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/types/permission"
+)
+
+// Role represents a named set of permissions that can be assigned to users.
+// System roles match the roles stored with each user and can't be renamed,
+// deleted or assigned, but their permissions can be changed.
+type Role struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Permissions []permission.Permission
+	System      bool
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewRole is what we require from clients when adding a Role.
+type NewRole struct {
+	Name        string
+	Description string
+	Permissions []permission.Permission
+}
+
+// UpdateRole defines what information may be provided to modify an existing
+// Role. All fields are optional so clients can send just the fields they want
+// changed. It uses pointer fields so we can differentiate between a field that
+// was not provided and a field that was provided as explicitly blank. A non
+// nil Permissions replaces the permissions of the role.
+type UpdateRole struct {
+	Name        *string
+	Description *string
+	Permissions []permission.Permission
+}
@@ -0,0 +1,235 @@
+// Package rolebus provides business access to the roles users can be given
+// and the permissions each role grants.
+package rolebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+	"github.com/rmsj/service/foundation/otel"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("role not found")
+	ErrUniqueName = errors.New("role name already exists")
+	ErrSystemRole = errors.New("system roles can't be renamed, deleted or assigned")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, rle Role) error
+	Update(ctx context.Context, rle Role) error
+	Delete(ctx context.Context, rle Role) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Role, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, roleID uuid.UUID) (Role, error)
+	Assign(ctx context.Context, userID uuid.UUID, rle Role, assignedAt time.Time) error
+	Unassign(ctx context.Context, userID uuid.UUID, rle Role) error
+	QueryPermissions(ctx context.Context, userID uuid.UUID, roleNames []string) ([]permission.Permission, error)
+}
+
+// Business manages the set of APIs for role access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a role business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	b := Business{
+		log:    log,
+		storer: storer,
+	}
+
+	return &b
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new custom role to the system.
+func (b *Business) Create(ctx context.Context, nr NewRole) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	rle := Role{
+		ID:          uuid.New(),
+		Name:        nr.Name,
+		Description: nr.Description,
+		Permissions: compact(nr.Permissions),
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, rle); err != nil {
+		return Role{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rle, nil
+}
+
+// Update modifies information about a role.
+func (b *Business) Update(ctx context.Context, rle Role, ur UpdateRole) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.update")
+	defer span.End()
+
+	if ur.Name != nil && *ur.Name != rle.Name {
+		if rle.System {
+			return Role{}, fmt.Errorf("update: roleID[%s]: %w", rle.ID, ErrSystemRole)
+		}
+		rle.Name = *ur.Name
+	}
+
+	if ur.Description != nil {
+		rle.Description = *ur.Description
+	}
+
+	if ur.Permissions != nil {
+		rle.Permissions = compact(ur.Permissions)
+	}
+
+	rle.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, rle); err != nil {
+		return Role{}, fmt.Errorf("update: %w", err)
+	}
+
+	return rle, nil
+}
+
+// Delete removes the specified role along with its user assignments.
+func (b *Business) Delete(ctx context.Context, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.delete")
+	defer span.End()
+
+	if rle.System {
+		return fmt.Errorf("delete: roleID[%s]: %w", rle.ID, ErrSystemRole)
+	}
+
+	if err := b.storer.Delete(ctx, rle); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing roles.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.query")
+	defer span.End()
+
+	rles, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return rles, nil
+}
+
+// Count returns the total number of roles.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the role by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, roleID uuid.UUID) (Role, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.querybyid")
+	defer span.End()
+
+	rle, err := b.storer.QueryByID(ctx, roleID)
+	if err != nil {
+		return Role{}, fmt.Errorf("query: roleID[%s]: %w", roleID, err)
+	}
+
+	return rle, nil
+}
+
+// Assign gives the custom role to the user. Assigning a role the user
+// already has is not an error.
+func (b *Business) Assign(ctx context.Context, userID uuid.UUID, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.assign")
+	defer span.End()
+
+	if rle.System {
+		return fmt.Errorf("assign: roleID[%s]: %w", rle.ID, ErrSystemRole)
+	}
+
+	if err := b.storer.Assign(ctx, userID, rle, time.Now()); err != nil {
+		return fmt.Errorf("assign: userID[%s] roleID[%s]: %w", userID, rle.ID, err)
+	}
+
+	return nil
+}
+
+// Unassign takes the custom role away from the user.
+func (b *Business) Unassign(ctx context.Context, userID uuid.UUID, rle Role) error {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.unassign")
+	defer span.End()
+
+	if rle.System {
+		return fmt.Errorf("unassign: roleID[%s]: %w", rle.ID, ErrSystemRole)
+	}
+
+	if err := b.storer.Unassign(ctx, userID, rle); err != nil {
+		return fmt.Errorf("unassign: userID[%s] roleID[%s]: %w", userID, rle.ID, err)
+	}
+
+	return nil
+}
+
+// QueryPermissions returns the permissions granted to the user by the named
+// system roles along with the custom roles assigned to the user.
+func (b *Business) QueryPermissions(ctx context.Context, userID uuid.UUID, roleNames []string) ([]permission.Permission, error) {
+	ctx, span := otel.AddSpan(ctx, "business.rolebus.querypermissions")
+	defer span.End()
+
+	perms, err := b.storer.QueryPermissions(ctx, userID, roleNames)
+	if err != nil {
+		return nil, fmt.Errorf("querypermissions: userID[%s]: %w", userID, err)
+	}
+
+	return perms, nil
+}
+
+// compact sorts the permissions and drops the duplicates.
+func compact(perms []permission.Permission) []permission.Permission {
+	perms = append([]permission.Permission{}, perms...)
+
+	slices.SortFunc(perms, func(a, b permission.Permission) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return slices.CompactFunc(perms, permission.Permission.Equal)
+}
@@ -0,0 +1,364 @@
+package rolebus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/sdk/dbtest"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/unitest"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/business/types/role"
+)
+
+func Test_Role(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Role")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, update(db.BusDomain, sd), "update")
+	unitest.Run(t, system(db.BusDomain, sd), "system")
+	unitest.Run(t, permissions(db.BusDomain, sd), "permissions")
+	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	usrs, err := userbus.TestSeedUsers(ctx, 2, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	rles, err := rolebus.TestGenerateSeedRoles(ctx, 2, busDomain.Role, permission.SalesReport)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding roles : %w", err)
+	}
+
+	// The system role stored with every user is part of the seed data so
+	// the tests can check it can't be changed like a custom role.
+	nme := role.User.String()
+	sys, err := busDomain.Role.Query(ctx, rolebus.QueryFilter{Name: &nme}, rolebus.DefaultOrderBy, page.MustParse("1", "10"))
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("querying system roles : %w", err)
+	}
+
+	for _, rle := range sys {
+		if rle.System && rle.Name == nme {
+			rles = append(rles, rle)
+		}
+	}
+
+	if len(rles) != 3 {
+		return unitest.SeedData{}, fmt.Errorf("system role %q not found", nme)
+	}
+
+	if err := busDomain.Role.Assign(ctx, usrs[0].ID, rles[0]); err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding role assignment : %w", err)
+	}
+
+	sd := unitest.SeedData{
+		Users: []unitest.User{{User: usrs[0]}, {User: usrs[1]}},
+		Roles: rles,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func cmpRole(got any, exp any) string {
+	gotResp, exists := got.(rolebus.Role)
+	if !exists {
+		return "error occurred"
+	}
+
+	expResp := exp.(rolebus.Role)
+
+	if expResp.ID == uuid.Nil {
+		expResp.ID = gotResp.ID
+	}
+
+	if gotResp.DateCreated.Format(time.RFC3339) == expResp.DateCreated.Format(time.RFC3339) {
+		expResp.DateCreated = gotResp.DateCreated
+	}
+
+	expResp.DateUpdated = gotResp.DateUpdated
+
+	return cmp.Diff(gotResp, expResp, cmp.Comparer(permission.Permission.Equal))
+}
+
+func cmpBool(got any, exp any) string {
+	return cmp.Diff(got, exp)
+}
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "byid",
+			ExpResp: sd.Roles[0],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Role.QueryByID(ctx, sd.Roles[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRole,
+		},
+		{
+			Name:    "byuser",
+			ExpResp: []uuid.UUID{sd.Roles[0].ID},
+			ExcFunc: func(ctx context.Context) any {
+				filter := rolebus.QueryFilter{
+					UserID: &sd.Users[0].ID,
+				}
+
+				resp, err := busDomain.Role.Query(ctx, filter, rolebus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				ids := make([]uuid.UUID, len(resp))
+				for i, rle := range resp {
+					ids[i] = rle.ID
+				}
+
+				return ids
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "notfound",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Role.QueryByID(ctx, uuid.New())
+				return errors.Is(err, rolebus.ErrNotFound)
+			},
+			CmpFunc: cmpBool,
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: rolebus.Role{
+				Name:        "cashier",
+				Description: "Takes payments",
+				Permissions: []permission.Permission{permission.ProductsImport, permission.SalesCreate},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nr := rolebus.NewRole{
+					Name:        "cashier",
+					Description: "Takes payments",
+					Permissions: []permission.Permission{permission.SalesCreate, permission.ProductsImport, permission.SalesCreate},
+				}
+
+				resp, err := busDomain.Role.Create(ctx, nr)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(rolebus.Role)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(rolebus.Role)
+				expResp.DateCreated = gotResp.DateCreated
+
+				return cmpRole(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "unique-name",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				nr := rolebus.NewRole{
+					Name: sd.Roles[1].Name,
+				}
+
+				_, err := busDomain.Role.Create(ctx, nr)
+				return errors.Is(err, rolebus.ErrUniqueName)
+			},
+			CmpFunc: cmpBool,
+		},
+	}
+
+	return table
+}
+
+func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	nme := "store_manager"
+
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: rolebus.Role{
+				ID:          sd.Roles[1].ID,
+				Name:        nme,
+				Description: sd.Roles[1].Description,
+				Permissions: []permission.Permission{permission.MustParse("sales:*")},
+				DateCreated: sd.Roles[1].DateCreated,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				ur := rolebus.UpdateRole{
+					Name:        &nme,
+					Permissions: []permission.Permission{permission.MustParse("sales:*")},
+				}
+
+				resp, err := busDomain.Role.Update(ctx, sd.Roles[1], ur)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpRole,
+		},
+	}
+
+	return table
+}
+
+func system(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	sys := sd.Roles[2]
+
+	table := []unitest.Table{
+		{
+			Name:    "rename",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				nme := "member"
+				_, err := busDomain.Role.Update(ctx, sys, rolebus.UpdateRole{Name: &nme})
+				return errors.Is(err, rolebus.ErrSystemRole)
+			},
+			CmpFunc: cmpBool,
+		},
+		{
+			Name:    "delete",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				err := busDomain.Role.Delete(ctx, sys)
+				return errors.Is(err, rolebus.ErrSystemRole)
+			},
+			CmpFunc: cmpBool,
+		},
+		{
+			Name:    "assign",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				err := busDomain.Role.Assign(ctx, sd.Users[1].ID, sys)
+				return errors.Is(err, rolebus.ErrSystemRole)
+			},
+			CmpFunc: cmpBool,
+		},
+	}
+
+	return table
+}
+
+func permissions(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "assigned",
+			ExpResp: []string{"sales:create", "sales:report"},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Role.QueryPermissions(ctx, sd.Users[0].ID, []string{role.User.String()})
+				if err != nil {
+					return err
+				}
+
+				return permission.ParseToString(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "unassigned",
+			ExpResp: []string{"sales:create"},
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Role.Unassign(ctx, sd.Users[0].ID, sd.Roles[0]); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Role.QueryPermissions(ctx, sd.Users[0].ID, []string{role.User.String()})
+				if err != nil {
+					return err
+				}
+
+				return permission.ParseToString(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "no-roles",
+			ExpResp: []string{},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Role.QueryPermissions(ctx, sd.Users[1].ID, nil)
+				if err != nil {
+					return err
+				}
+
+				return permission.ParseToString(resp)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "custom",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Role.Delete(ctx, sd.Roles[0]); err != nil {
+					return err
+				}
+
+				_, err := busDomain.Role.QueryByID(ctx, sd.Roles[0].ID)
+				return errors.Is(err, rolebus.ErrNotFound)
+			},
+			CmpFunc: cmpBool,
+		},
+	}
+
+	return table
+}
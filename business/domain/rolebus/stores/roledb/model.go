@@ -0,0 +1,66 @@
+package roledb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/types/permission"
+)
+
+type role struct {
+	ID          uuid.UUID `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	System      bool      `db:"is_system"`
+	DateCreated time.Time `db:"created_at"`
+	DateUpdated time.Time `db:"updated_at"`
+}
+
+func toDBRole(bus rolebus.Role) role {
+	db := role{
+		ID:          bus.ID,
+		Name:        bus.Name,
+		Description: bus.Description,
+		System:      bus.System,
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+
+	return db
+}
+
+func toBusRole(db role, perms []string) (rolebus.Role, error) {
+	busPerms, err := permission.ParseMany(perms)
+	if err != nil {
+		return rolebus.Role{}, fmt.Errorf("parse permissions: %w", err)
+	}
+
+	bus := rolebus.Role{
+		ID:          db.ID,
+		Name:        db.Name,
+		Description: db.Description,
+		Permissions: busPerms,
+		System:      db.System,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type rolePermission struct {
+	RoleID     uuid.UUID `db:"role_id"`
+	Permission string    `db:"permission"`
+}
+
+// cachedPermissions holds the permissions resolved for a user along with
+// the system roles they were resolved for.
+type cachedPermissions struct {
+	roleNames string
+	perms     []permission.Permission
+}
@@ -0,0 +1,22 @@
+package roledb
+
+import (
+	"fmt"
+
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	rolebus.OrderByID:   "id",
+	rolebus.OrderByName: "name",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -0,0 +1,415 @@
+// Package roledb contains role related CRUD functionality.
+package roledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/viccon/sturdyc"
+
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/sdk/order"
+	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/permission"
+	"github.com/rmsj/service/foundation/logger"
+)
+
+// Store manages the set of APIs for role database access.
+type Store struct {
+	log   *logger.Logger
+	db    sqlx.ExtContext
+	cache *sturdyc.Client[cachedPermissions]
+}
+
+// NewStore constructs the api for data access. Permissions are resolved on
+// every authorization, so the permissions of each user are cached for the
+// specified ttl.
+func NewStore(log *logger.Logger, db *sqlx.DB, ttl time.Duration) *Store {
+	const capacity = 10000
+	const numShards = 10
+	const evictionPercentage = 10
+
+	return &Store{
+		log:   log,
+		db:    db,
+		cache: sturdyc.New[cachedPermissions](capacity, numShards, ttl, evictionPercentage),
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (rolebus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:   s.log,
+		db:    ec,
+		cache: s.cache,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new role and its permissions into the database.
+func (s *Store) Create(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	INSERT INTO roles
+		(id, name, description, is_system, created_at, updated_at)
+	VALUES
+		(:id, :name, :description, :is_system, :created_at, :updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRole(rle)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", rolebus.ErrUniqueName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	if err := s.insertPermissions(ctx, rle); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update replaces a role and its permissions in the database.
+func (s *Store) Update(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	UPDATE
+		roles
+	SET
+		name = :name,
+		description = :description,
+		updated_at = :updated_at
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRole(rle)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return rolebus.ErrUniqueName
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	data := struct {
+		ID string `db:"role_id"`
+	}{
+		ID: rle.ID.String(),
+	}
+
+	const del = `
+	DELETE FROM
+		role_permissions
+	WHERE
+		role_id = :role_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	if err := s.insertPermissions(ctx, rle); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a role from the database. Its permissions and user
+// assignments are removed by the database.
+func (s *Store) Delete(ctx context.Context, rle rolebus.Role) error {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: rle.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		roles
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing roles from the database.
+func (s *Store) Query(ctx context.Context, filter rolebus.QueryFilter, orderBy order.By, page page.Page) ([]rolebus.Role, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, description, is_system, created_at, updated_at
+	FROM
+		roles`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset")
+
+	var dbRoles []role
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbRoles); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return s.toBusRoles(ctx, dbRoles)
+}
+
+// Count returns the total number of roles in the DB.
+func (s *Store) Count(ctx context.Context, filter rolebus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = "SELECT COUNT(id) AS `count` FROM roles"
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified role from the database.
+func (s *Store) QueryByID(ctx context.Context, roleID uuid.UUID) (rolebus.Role, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: roleID.String(),
+	}
+
+	const q = `
+	SELECT
+	    id, name, description, is_system, created_at, updated_at
+	FROM
+		roles
+	WHERE
+		id = :id`
+
+	var dbRole role
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRole); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return rolebus.Role{}, fmt.Errorf("db: %w", rolebus.ErrNotFound)
+		}
+		return rolebus.Role{}, fmt.Errorf("db: %w", err)
+	}
+
+	rles, err := s.toBusRoles(ctx, []role{dbRole})
+	if err != nil {
+		return rolebus.Role{}, err
+	}
+
+	return rles[0], nil
+}
+
+// Assign gives the role to the user.
+func (s *Store) Assign(ctx context.Context, userID uuid.UUID, rle rolebus.Role, assignedAt time.Time) error {
+	data := struct {
+		UserID    string    `db:"user_id"`
+		RoleID    string    `db:"role_id"`
+		CreatedAt time.Time `db:"created_at"`
+	}{
+		UserID:    userID.String(),
+		RoleID:    rle.ID.String(),
+		CreatedAt: assignedAt.UTC(),
+	}
+
+	const q = `
+	INSERT INTO user_roles
+		(user_id, role_id, created_at)
+	VALUES
+		(:user_id, :role_id, :created_at)
+	ON DUPLICATE KEY UPDATE
+		user_id = user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	s.cache.Delete(userID.String())
+
+	return nil
+}
+
+// Unassign takes the role away from the user.
+func (s *Store) Unassign(ctx context.Context, userID uuid.UUID, rle rolebus.Role) error {
+	data := struct {
+		UserID string `db:"user_id"`
+		RoleID string `db:"role_id"`
+	}{
+		UserID: userID.String(),
+		RoleID: rle.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		user_roles
+	WHERE
+		user_id = :user_id AND role_id = :role_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	s.cache.Delete(userID.String())
+
+	return nil
+}
+
+// QueryPermissions returns the permissions granted by the named roles along
+// with the roles assigned to the user.
+func (s *Store) QueryPermissions(ctx context.Context, userID uuid.UUID, roleNames []string) ([]permission.Permission, error) {
+	names := slices.Clone(roleNames)
+	slices.Sort(names)
+	key := strings.Join(names, ",")
+
+	if cached, exists := s.cache.Get(userID.String()); exists && cached.roleNames == key {
+		return cached.perms, nil
+	}
+
+	// An empty IN list isn't valid SQL and no role has an empty name.
+	if len(names) == 0 {
+		names = []string{""}
+	}
+
+	data := struct {
+		UserID    string   `db:"user_id"`
+		RoleNames []string `db:"role_names"`
+	}{
+		UserID:    userID.String(),
+		RoleNames: names,
+	}
+
+	const q = `
+	SELECT DISTINCT
+		rp.permission
+	FROM
+		role_permissions AS rp
+	JOIN
+		roles AS r ON r.id = rp.role_id
+	WHERE
+		(r.is_system = true AND r.name IN (:role_names)) OR
+		r.id IN (SELECT role_id FROM user_roles WHERE user_id = :user_id)
+	ORDER BY
+		rp.permission`
+
+	var rows []struct {
+		Permission string `db:"permission"`
+	}
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &rows); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	perms := make([]permission.Permission, len(rows))
+	for i, row := range rows {
+		perm, err := permission.Parse(row.Permission)
+		if err != nil {
+			return nil, fmt.Errorf("parse permission: %w", err)
+		}
+		perms[i] = perm
+	}
+
+	s.cache.Set(userID.String(), cachedPermissions{roleNames: key, perms: perms})
+
+	return perms, nil
+}
+
+// =============================================================================
+
+func (s *Store) insertPermissions(ctx context.Context, rle rolebus.Role) error {
+	const q = `
+	INSERT INTO role_permissions
+		(role_id, permission)
+	VALUES
+		(:role_id, :permission)`
+
+	for _, perm := range rle.Permissions {
+		row := rolePermission{
+			RoleID:     rle.ID,
+			Permission: perm.String(),
+		}
+
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, row); err != nil {
+			return fmt.Errorf("namedexeccontext: permission[%s]: %w", perm, err)
+		}
+	}
+
+	return nil
+}
+
+// toBusRoles converts the roles loading the permissions of every role.
+func (s *Store) toBusRoles(ctx context.Context, dbRoles []role) ([]rolebus.Role, error) {
+	if len(dbRoles) == 0 {
+		return []rolebus.Role{}, nil
+	}
+
+	ids := make([]string, len(dbRoles))
+	for i, dbRole := range dbRoles {
+		ids[i] = dbRole.ID.String()
+	}
+
+	data := struct {
+		IDs []string `db:"role_ids"`
+	}{
+		IDs: ids,
+	}
+
+	const q = `
+	SELECT
+		role_id, permission
+	FROM
+		role_permissions
+	WHERE
+		role_id IN (:role_ids)
+	ORDER BY
+		permission`
+
+	var rows []rolePermission
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &rows); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	perms := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		perms[row.RoleID] = append(perms[row.RoleID], row.Permission)
+	}
+
+	bus := make([]rolebus.Role, len(dbRoles))
+	for i, dbRole := range dbRoles {
+		var err error
+		bus[i], err = toBusRole(dbRole, perms[dbRole.ID])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
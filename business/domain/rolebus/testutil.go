@@ -0,0 +1,46 @@
+package rolebus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/rmsj/service/business/types/permission"
+)
+
+// TestGenerateNewRoles is a helper method for testing.
+func TestGenerateNewRoles(n int, perms ...permission.Permission) []NewRole {
+	newRles := make([]NewRole, n)
+
+	idx := rand.Intn(10000)
+	for i := range n {
+		idx++
+
+		nr := NewRole{
+			Name:        fmt.Sprintf("role%d", idx),
+			Description: fmt.Sprintf("Role %d", idx),
+			Permissions: perms,
+		}
+
+		newRles[i] = nr
+	}
+
+	return newRles
+}
+
+// TestGenerateSeedRoles is a helper method for testing.
+func TestGenerateSeedRoles(ctx context.Context, n int, api *Business, perms ...permission.Permission) ([]Role, error) {
+	newRles := TestGenerateNewRoles(n, perms...)
+
+	rles := make([]Role, len(newRles))
+	for i, nr := range newRles {
+		rle, err := api.Create(ctx, nr)
+		if err != nil {
+			return nil, fmt.Errorf("seeding role: idx: %d : %w", i, err)
+		}
+
+		rles[i] = rle
+	}
+
+	return rles, nil
+}
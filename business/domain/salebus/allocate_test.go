@@ -0,0 +1,102 @@
+package salebus_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/salebus"
+	"github.com/rmsj/service/business/types/money"
+)
+
+func Test_AllocateBundle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		strings = productbus.Product{ID: uuid.MustParse("00000000-0000-0000-0000-0000000000aa"), Price: money.MustParse(10)}
+		picks   = productbus.Product{ID: uuid.MustParse("00000000-0000-0000-0000-0000000000bb"), Price: money.MustParse(5)}
+		cloth   = productbus.Product{ID: uuid.MustParse("00000000-0000-0000-0000-0000000000cc"), Price: money.MustParse(0)}
+	)
+
+	bundle := func(qtys ...int) productbus.Bundle {
+		prds := []productbus.Product{strings, picks, cloth}
+
+		var bdl productbus.Bundle
+		for i, qty := range qtys {
+			bdl.Components = append(bdl.Components, productbus.Component{ProductID: prds[i].ID, Quantity: qty})
+		}
+
+		return bdl
+	}
+
+	tests := []struct {
+		name       string
+		amount     float64
+		units      int
+		bundle     productbus.Bundle
+		components []productbus.Product
+		exp        []salebus.SaleItemComponent
+	}{
+		{
+			name:       "proportional",
+			amount:     18,
+			units:      1,
+			bundle:     bundle(1, 2),
+			components: []productbus.Product{strings, picks},
+			exp: []salebus.SaleItemComponent{
+				{ProductID: strings.ID, Quantity: 1, Amount: money.MustParse(9)},
+				{ProductID: picks.ID, Quantity: 2, Amount: money.MustParse(9)},
+			},
+		},
+		{
+			name:       "units",
+			amount:     30,
+			units:      3,
+			bundle:     bundle(1, 2),
+			components: []productbus.Product{strings, picks},
+			exp: []salebus.SaleItemComponent{
+				{ProductID: strings.ID, Quantity: 3, Amount: money.MustParse(15)},
+				{ProductID: picks.ID, Quantity: 6, Amount: money.MustParse(15)},
+			},
+		},
+		{
+			name:       "rounding-leftover",
+			amount:     10,
+			units:      1,
+			bundle:     bundle(1, 1, 1),
+			components: []productbus.Product{strings, strings, strings},
+			exp: []salebus.SaleItemComponent{
+				{ProductID: strings.ID, Quantity: 1, Amount: money.MustParse(3.33)},
+				{ProductID: strings.ID, Quantity: 1, Amount: money.MustParse(3.33)},
+				{ProductID: strings.ID, Quantity: 1, Amount: money.MustParse(3.34)},
+			},
+		},
+		{
+			name:       "free-component",
+			amount:     12,
+			units:      1,
+			bundle:     bundle(1, 1, 1),
+			components: []productbus.Product{strings, picks, cloth},
+			exp: []salebus.SaleItemComponent{
+				{ProductID: strings.ID, Quantity: 1, Amount: money.MustParse(8)},
+				{ProductID: picks.ID, Quantity: 1, Amount: money.MustParse(4)},
+				{ProductID: cloth.ID, Quantity: 1, Amount: money.MustParse(0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := salebus.AllocateBundle(money.MustParse(tt.amount), tt.units, tt.bundle, tt.components)
+			if err != nil {
+				t.Fatalf("Should be able to allocate the bundle: %s", err)
+			}
+
+			if diff := cmp.Diff(got, tt.exp); diff != "" {
+				t.Errorf("Should get the expected allocation:\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package salebus
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
+// LocationIDs limits the sales to those made at any of the locations, or not
+// made at a location at all, when it is not nil.
 type QueryFilter struct {
-	ID *uuid.UUID
+	ID               *uuid.UUID
+	LocationID       *uuid.UUID
+	LocationIDs      []uuid.UUID
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
 }
@@ -0,0 +1,41 @@
+package salebus_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/business/domain/salebus"
+	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
+)
+
+func Test_Margin(t *testing.T) {
+	t.Parallel()
+
+	sl := salebus.Sale{
+		Items: []salebus.SaleItem{
+			{
+				Quantity: quantity.MustParse(3, quantity.Piece),
+				UnitCost: money.MustParse(4.1),
+				Amount:   money.MustParse(30),
+				Discount: money.MustParse(1.5),
+			},
+			{
+				Quantity: quantity.MustParse(1, quantity.Piece),
+				UnitCost: money.MustParse(12),
+				Amount:   money.MustParse(10),
+			},
+		},
+	}
+
+	if got, exp := sl.Items[0].Margin(), 16.2; got != exp {
+		t.Errorf("Should get the item margin net of discount: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := sl.Items[1].Margin(), -2.0; got != exp {
+		t.Errorf("Should get a negative margin for an item sold at a loss: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := sl.Margin(), 14.2; got != exp {
+		t.Errorf("Should get the sale margin across items: got %v, exp %v", got, exp)
+	}
+}
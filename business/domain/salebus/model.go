@@ -1,47 +1,100 @@
 package salebus
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
-// Sale represents an individual sale.
+// Sale represents an individual sale. Amounts, prices and costs of the sale
+// and its items are all in the currency of the sale. LocationID is the
+// location the sale was made at, if any.
 type Sale struct {
-	ID        uuid.UUID
-	UserID    uuid.UUID
-	Discount  money.Money
-	Amount    money.Money
-	Items     []SaleItem
-	UpdatedAt time.Time
-	CreatedAt time.Time
-}
-
-type SaleItem struct {
-	SaleID     uuid.UUID
-	ProductID  uuid.UUID
-	UnityPrice money.Money
-	Quantity   int
-	Amount     money.Money
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Currency   currency.Currency
+	LocationID uuid.UUID
 	Discount   money.Money
+	Amount     money.Money
+	Items      []SaleItem
 	UpdatedAt  time.Time
 	CreatedAt  time.Time
 }
 
-// NewSale is what we require from clients when adding a sale.
+// Margin returns the gross margin of the sale across all its items.
+func (s Sale) Margin() float64 {
+	var margin float64
+	for _, item := range s.Items {
+		margin += item.Margin()
+	}
+
+	return math.Round(margin*100) / 100
+}
+
+// SaleItem represents a product sold in a sale. UnitCost is the cost of the
+// product at the time of the sale. The quantity is in the unit of the product
+// and the price and cost are per unit. ExchangeRate is the rate the price was
+// converted into the currency of the sale at.
+type SaleItem struct {
+	SaleID       uuid.UUID
+	ProductID    uuid.UUID
+	UnityPrice   money.Money
+	UnitCost     money.Money
+	PriceListID  uuid.UUID
+	ExchangeRate float64
+	Quantity     quantity.Quantity
+	Amount       money.Money
+	Discount     money.Money
+	Components   []SaleItemComponent
+	UpdatedAt    time.Time
+	CreatedAt    time.Time
+}
+
+// Margin returns the gross margin of the item, which is the amount sold net of
+// discount minus the cost of the units sold. It is negative when the item was
+// sold at a loss.
+func (si SaleItem) Margin() float64 {
+	margin := si.Amount.Value() - si.Discount.Value() - si.UnitCost.Value()*si.Quantity.Value()
+
+	return math.Round(margin*100) / 100
+}
+
+// SaleItemComponent represents a product sold as part of a bundle item, with
+// the share of the item amount allocated to it.
+type SaleItemComponent struct {
+	ProductID uuid.UUID
+	Quantity  int
+	Amount    money.Money
+}
+
+// NewSale is what we require from clients when adding a sale. The sale is in
+// the default currency when no Currency is provided. When a LocationID is
+// provided the stock sold is taken from that location.
 type NewSale struct {
-	UserID   uuid.UUID
-	Discount money.Money
-	Items    []NewSaleItem
+	UserID     uuid.UUID
+	Currency   currency.Currency
+	LocationID uuid.UUID
+	Discount   money.Money
+	Items      []NewSaleItem
 }
 
 // NewSaleItem is what we require from clients when adding a sale item.
+// PriceListID identifies the price list the price was taken from, if any. The
+// quantity can be in any unit compatible with the unit of the product, while
+// the price is per unit of the product. The price is in Currency, or in the
+// currency of the product when none is provided, and is converted into the
+// currency of the sale.
 type NewSaleItem struct {
-	ProductID uuid.UUID
-	Quantity  int
-	Price     money.Money
+	ProductID   uuid.UUID
+	Quantity    quantity.Quantity
+	Price       money.Money
+	Currency    currency.Currency
+	PriceListID uuid.UUID
 }
 
 // SaleItemValue is a helper type to calculate the amount and proportional discount, if any
@@ -49,3 +102,31 @@ type SaleItemValue struct {
 	Amount   money.Money
 	Discount money.Money
 }
+
+// Report represents the sales over a period with their amounts converted into
+// a single currency. Each day is converted at the rates in effect at the end
+// of that day.
+type Report struct {
+	Currency currency.Currency
+	Sales    int
+	Amount   float64
+	Discount float64
+	Days     []ReportDay
+}
+
+// ReportDay represents the sales of a single day in a report.
+type ReportDay struct {
+	Date     time.Time
+	Sales    int
+	Amount   float64
+	Discount float64
+}
+
+// Totals represents the sales made in a currency on a single day.
+type Totals struct {
+	Date     time.Time
+	Currency currency.Currency
+	Sales    int
+	Amount   float64
+	Discount float64
+}
@@ -10,11 +10,15 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
 	"github.com/rmsj/service/business/sdk/id"
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
 )
@@ -33,19 +37,24 @@ type Storer interface {
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Sale, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, saleID uuid.UUID) (Sale, error)
+	QueryTotals(ctx context.Context, filter QueryFilter) ([]Totals, error)
 }
 
 // Business manages the set of APIs for sale access.
 type Business struct {
-	log    *logger.Logger
-	storer Storer
+	log        *logger.Logger
+	productBus *productbus.Business
+	rateBus    *ratebus.Business
+	storer     Storer
 }
 
 // NewBusiness constructs a sale domain API for use.
-func NewBusiness(log *logger.Logger, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, productBus *productbus.Business, rateBus *ratebus.Business, storer Storer) *Business {
 	b := Business{
-		log:    log,
-		storer: storer,
+		log:        log,
+		productBus: productBus,
+		rateBus:    rateBus,
+		storer:     storer,
 	}
 
 	return &b
@@ -59,9 +68,21 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		return nil, err
 	}
 
+	productBus, err := b.productBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rateBus, err := b.rateBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
 	bus := Business{
-		log:    b.log,
-		storer: storer,
+		log:        b.log,
+		productBus: productBus,
+		rateBus:    rateBus,
+		storer:     storer,
 	}
 
 	return &bus, nil
@@ -74,18 +95,63 @@ func (b *Business) Create(ctx context.Context, ns NewSale) (Sale, error) {
 
 	now := time.Now()
 
+	cur := ns.Currency
+	if cur == (currency.Currency{}) {
+		cur = currency.Default
+	}
+
 	slDB := Sale{
-		ID:        id.New(),
-		UserID:    ns.UserID,
-		Discount:  ns.Discount,
-		UpdatedAt: now,
-		CreatedAt: now,
+		ID:         id.New(),
+		UserID:     ns.UserID,
+		Currency:   cur,
+		LocationID: ns.LocationID,
+		Discount:   ns.Discount,
+		UpdatedAt:  now,
+		CreatedAt:  now,
+	}
+
+	// Work with the quantities in the unit of each product, which is what the
+	// prices are for, and with the prices and costs in the currency of the
+	// sale at the rates in effect now.
+	prds := make([]productbus.Product, len(ns.Items))
+	items := make([]NewSaleItem, len(ns.Items))
+	rates := make([]ratebus.Rate, len(ns.Items))
+	costs := make([]money.Money, len(ns.Items))
+	for i, item := range ns.Items {
+		prd, err := b.productBus.QueryByID(ctx, item.ProductID)
+		if err != nil {
+			return Sale{}, fmt.Errorf("create sale: query product: %w", err)
+		}
+
+		item.Quantity, err = prd.SaleQuantity(item.Quantity)
+		if err != nil {
+			return Sale{}, fmt.Errorf("create sale: %w", err)
+		}
+
+		priceCur := item.Currency
+		if priceCur == (currency.Currency{}) {
+			priceCur = prd.Currency
+		}
+
+		item.Price, rates[i], err = b.rateBus.Convert(ctx, item.Price, priceCur, cur, now)
+		if err != nil {
+			return Sale{}, fmt.Errorf("create sale: convert price: productID[%s]: %w", item.ProductID, err)
+		}
+		item.Currency = cur
+
+		costs[i], _, err = b.rateBus.Convert(ctx, prd.Cost, prd.Currency, cur, now)
+		if err != nil {
+			return Sale{}, fmt.Errorf("create sale: convert cost: productID[%s]: %w", item.ProductID, err)
+		}
+
+		prds[i] = prd
+		items[i] = item
 	}
 
 	var saleAmount float64
 	var err error
-	for _, item := range ns.Items {
-		saleAmount += float64(item.Quantity) * item.Price.Value()
+	for _, item := range items {
+		saleAmount += item.Quantity.Value() * item.Price.Value()
 	}
 	slDB.Amount, err = money.Parse(saleAmount)
 	if err != nil {
@@ -96,29 +162,42 @@ func (b *Business) Create(ctx context.Context, ns NewSale) (Sale, error) {
 		return Sale{}, fmt.Errorf("discount[%.2f] is greater than total sale amount[%.2f]", ns.Discount, slDB.Amount)
 	}
 
-	itemsValues, err := SaleItemsValues(saleAmount, ns.Discount.Value(), ns.Items)
+	itemsValues, err := SaleItemsValues(saleAmount, ns.Discount.Value(), items)
 	if err != nil {
 		return Sale{}, err
 	}
 
 	// items
-	for _, item := range ns.Items {
+	for i, item := range items {
 
 		itemValue, ok := itemsValues[item.ProductID.String()]
 		if !ok {
 			return Sale{}, fmt.Errorf("error calculating item values for item: %s", item.ProductID)
 		}
 
+		prd := prds[i]
+
 		saleItem := SaleItem{
-			SaleID:     slDB.ID,
-			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			Discount:   itemValue.Discount,
-			UnityPrice: item.Price,
-			Amount:     itemValue.Amount,
-			UpdatedAt:  now,
-			CreatedAt:  now,
+			SaleID:       slDB.ID,
+			ProductID:    item.ProductID,
+			Quantity:     item.Quantity,
+			Discount:     itemValue.Discount,
+			UnityPrice:   item.Price,
+			UnitCost:     costs[i],
+			PriceListID:  item.PriceListID,
+			ExchangeRate: rates[i].Rate,
+			Amount:       itemValue.Amount,
+			UpdatedAt:    now,
+			CreatedAt:    now,
 		}
+
+		if prd.IsBundle() {
+			saleItem.Components, err = b.sellBundle(ctx, prd, ns.LocationID, int(item.Quantity.Value()), itemValue.Amount)
+			if err != nil {
+				return Sale{}, fmt.Errorf("create sale: productID[%s]: %w", item.ProductID, err)
+			}
+		}
+
 		slDB.Items = append(slDB.Items, saleItem)
 	}
 
@@ -175,6 +254,130 @@ func (b *Business) QueryByID(ctx context.Context, slID uuid.UUID) (Sale, error)
 	return sl, nil
 }
 
+// Report adds up the sales matching the filter per day, converting the amounts
+// of the sales made in other currencies into the specified currency at the
+// rates in effect at the end of each day.
+func (b *Business) Report(ctx context.Context, filter QueryFilter, cur currency.Currency) (Report, error) {
+	ctx, span := otel.AddSpan(ctx, "business.salebus.report")
+	defer span.End()
+
+	totals, err := b.storer.QueryTotals(ctx, filter)
+	if err != nil {
+		return Report{}, fmt.Errorf("query totals: %w", err)
+	}
+
+	round := func(value float64) float64 {
+		return math.Round(value*100) / 100
+	}
+
+	rpt := Report{
+		Currency: cur,
+		Days:     []ReportDay{},
+	}
+
+	for _, t := range totals {
+		endOfDay := t.Date.Add(24*time.Hour - time.Nanosecond)
+
+		rate, err := b.rateBus.QueryAt(ctx, t.Currency, cur, endOfDay)
+		if err != nil {
+			return Report{}, fmt.Errorf("report: %s: %w", t.Date.Format(time.DateOnly), err)
+		}
+
+		amount := round(t.Amount * rate.Rate)
+		discount := round(t.Discount * rate.Rate)
+
+		if n := len(rpt.Days); n == 0 || !rpt.Days[n-1].Date.Equal(t.Date) {
+			rpt.Days = append(rpt.Days, ReportDay{Date: t.Date})
+		}
+
+		day := &rpt.Days[len(rpt.Days)-1]
+		day.Sales += t.Sales
+		day.Amount = round(day.Amount + amount)
+		day.Discount = round(day.Discount + discount)
+
+		rpt.Sales += t.Sales
+		rpt.Amount = round(rpt.Amount + amount)
+		rpt.Discount = round(rpt.Discount + discount)
+	}
+
+	return rpt, nil
+}
+
+// sellBundle takes the units of each component of the bundle out of stock,
+// including the stock kept at the location the sale is made at if any, and
+// allocates the amount the bundle was sold for across them.
+func (b *Business) sellBundle(ctx context.Context, prd productbus.Product, locationID uuid.UUID, units int, amount money.Money) ([]SaleItemComponent, error) {
+	components, err := b.productBus.QueryComponents(ctx, prd)
+	if err != nil {
+		return nil, fmt.Errorf("query components: %w", err)
+	}
+
+	for i, cp := range components {
+		qty, err := quantity.Parse(float64(prd.Bundle.Components[i].Quantity*units), cp.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("componentID[%s]: %w", cp.ID, err)
+		}
+
+		if locationID == uuid.Nil {
+			if _, err := b.productBus.ConsumeStock(ctx, cp, qty); err != nil {
+				return nil, fmt.Errorf("consume stock: %w", err)
+			}
+			continue
+		}
+
+		if _, err := b.productBus.ConsumeLocationStock(ctx, cp, locationID, qty); err != nil {
+			return nil, fmt.Errorf("consume location stock: %w", err)
+		}
+	}
+
+	return AllocateBundle(amount, units, prd.Bundle, components)
+}
+
+// AllocateBundle splits the amount a bundle was sold for across its
+// components, in proportion to the price of the units of each component in
+// the bundle. Components are expected in the same order as in the bundle.
+// Any rounding leftover is added to the last component so the allocated
+// amounts always add up to the amount sold.
+func AllocateBundle(amount money.Money, units int, bundle productbus.Bundle, components []productbus.Product) ([]SaleItemComponent, error) {
+	if len(components) != len(bundle.Components) {
+		return nil, fmt.Errorf("components[%d] does not match bundle components[%d]", len(components), len(bundle.Components))
+	}
+
+	var total float64
+	for i, cp := range components {
+		total += cp.Price.Value() * float64(bundle.Components[i].Quantity)
+	}
+
+	sics := make([]SaleItemComponent, len(components))
+
+	var allocated float64
+	for i, cp := range components {
+		qty := bundle.Components[i].Quantity
+
+		var share float64
+		switch {
+		case i == len(components)-1:
+			share = math.Round((amount.Value()-allocated)*100) / 100
+		case total > 0:
+			share = math.Round(amount.Value()*cp.Price.Value()*float64(qty)/total*100) / 100
+		}
+		allocated += share
+
+		shareMoney, err := money.Parse(share)
+		if err != nil {
+			return nil, fmt.Errorf("parsing component amount: %w", err)
+		}
+
+		sics[i] = SaleItemComponent{
+			ProductID: cp.ID,
+			Quantity:  qty * units,
+			Amount:    shareMoney,
+		}
+	}
+
+	return sics, nil
+}
+
 // SaleItemsValues calculates the amount and proportional discount for each sale item based on the total sale amount.
 // It returns a map where the key is the ProductID and the value contains the item's amount and discount.
 // If the calculated discounts don't match the total sale discount, the discrepancy is adjusted on the first item.
@@ -189,7 +392,7 @@ func SaleItemsValues(saleAmount float64, saleDiscount float64, items []NewSaleIt
 
 	var distributedDiscount float64
 	for _, item := range items {
-		itemAmount := float64(item.Quantity) * item.Price.Value()
+		itemAmount := item.Quantity.Value() * item.Price.Value()
 		proportion := itemAmount / saleAmount
 		itemDiscount := float64(0)
 		if saleDiscount > 0 {
@@ -2,18 +2,24 @@ package salebus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/sdk/page"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
 	"github.com/rmsj/service/business/types/role"
 
 	"github.com/rmsj/service/business/sdk/dbtest"
@@ -33,6 +39,7 @@ func Test_Sale(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, report(db.BusDomain, sd), "report")
 	unitest.Run(t, create(db.BusDomain, sd), "create")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
 }
@@ -47,7 +54,7 @@ func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
 	}
 
-	prds, err := productbus.TestGenerateSeedProducts(ctx, 3, busDomain.Product)
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 3, busDomain.Product, usrs[0].ID)
 	if err != nil {
 		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
 	}
@@ -59,7 +66,7 @@ func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 	for _, prd := range prds {
 		items = append(items, salebus.NewSaleItem{
 			ProductID: prd.ID,
-			Quantity:  1,
+			Quantity:  quantity.MustParse(1, quantity.Piece),
 			Price:     prd.Price,
 		})
 	}
@@ -83,7 +90,7 @@ func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 	for _, prd := range prds {
 		items = append(items, salebus.NewSaleItem{
 			ProductID: prd.ID,
-			Quantity:  2,
+			Quantity:  quantity.MustParse(2, quantity.Piece),
 			Price:     prd.Price,
 		})
 	}
@@ -99,10 +106,27 @@ func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
 
 	// -------------------------------------------------------------------------
 
+	nrs := []ratebus.NewRate{
+		{
+			From:          currency.Default,
+			To:            currency.MustParse("EUR"),
+			Rate:          0.5,
+			EffectiveFrom: time.Now().AddDate(0, 0, -7),
+		},
+	}
+
+	rates, err := ratebus.TestSeedRates(ctx, nrs, busDomain.Rate)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding rates : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
 	sd := unitest.SeedData{
 		Users:    []unitest.User{td1, td2},
 		Products: prds,
 		Sales:    append(sales1, sales2...),
+		Rates:    rates,
 	}
 
 	return sd, nil
@@ -195,22 +219,97 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 	return table
 }
 
+func report(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	expReport := func(cur currency.Currency, rate float64) salebus.Report {
+		round := func(value float64) float64 {
+			return math.Round(value*100) / 100
+		}
+
+		rpt := salebus.Report{
+			Currency: cur,
+			Days:     []salebus.ReportDay{{}},
+		}
+
+		for _, sl := range sd.Sales {
+			rpt.Sales++
+			rpt.Amount += sl.Amount.Value()
+			rpt.Discount += sl.Discount.Value()
+		}
+
+		rpt.Amount = round(rpt.Amount * rate)
+		rpt.Discount = round(rpt.Discount * rate)
+		rpt.Days[0].Sales = rpt.Sales
+		rpt.Days[0].Amount = rpt.Amount
+		rpt.Days[0].Discount = rpt.Discount
+
+		return rpt
+	}
+
+	cmpFunc := func(got any, exp any) string {
+		gotResp, exists := got.(salebus.Report)
+		if !exists {
+			return "error occurred"
+		}
+
+		expResp := exp.(salebus.Report)
+
+		for i := range gotResp.Days {
+			if i < len(expResp.Days) {
+				expResp.Days[i].Date = gotResp.Days[i].Date
+			}
+		}
+
+		return cmp.Diff(gotResp, expResp)
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "same-currency",
+			ExpResp: expReport(currency.Default, 1),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Sale.Report(ctx, salebus.QueryFilter{}, currency.Default)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpFunc,
+		},
+		{
+			Name:    "converted",
+			ExpResp: expReport(currency.MustParse("EUR"), sd.Rates[0].Rate),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Sale.Report(ctx, salebus.QueryFilter{}, currency.MustParse("EUR"))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpFunc,
+		},
+	}
+
+	return table
+}
+
 func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 
 	discountedItems := []salebus.NewSaleItem{
 		{
 			ProductID: sd.Products[0].ID,
-			Quantity:  1,
+			Quantity:  quantity.MustParse(1, quantity.Piece),
 			Price:     sd.Products[0].Price,
 		},
 		{
 			ProductID: sd.Products[1].ID,
-			Quantity:  2,
+			Quantity:  quantity.MustParse(2, quantity.Piece),
 			Price:     sd.Products[1].Price,
 		},
 		{
 			ProductID: sd.Products[2].ID,
-			Quantity:  1,
+			Quantity:  quantity.MustParse(1, quantity.Piece),
 			Price:     sd.Products[2].Price,
 		},
 	}
@@ -221,29 +320,53 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 	}
 	discountedSaleExpected := salebus.Sale{
 		UserID:   sd.Users[0].User.ID,
+		Currency: currency.Default,
 		Discount: money.MustParse(10),
 		Amount:   money.MustParse(discountedSaleAmount),
 		Items: []salebus.SaleItem{
 			{
-				ProductID:  sd.Products[0].ID,
-				UnityPrice: sd.Products[0].Price,
-				Quantity:   1,
-				Amount:     sd.Products[0].Price,
-				Discount:   discountedItemsValues[sd.Products[0].ID.String()].Discount,
+				ProductID:    sd.Products[0].ID,
+				ExchangeRate: 1,
+				UnityPrice:   sd.Products[0].Price,
+				Quantity:     quantity.MustParse(1, quantity.Piece),
+				Amount:       sd.Products[0].Price,
+				Discount:     discountedItemsValues[sd.Products[0].ID.String()].Discount,
 			},
 			{
-				ProductID:  sd.Products[1].ID,
-				UnityPrice: sd.Products[1].Price,
-				Quantity:   2,
-				Amount:     discountedItemsValues[sd.Products[1].ID.String()].Amount,
-				Discount:   discountedItemsValues[sd.Products[1].ID.String()].Discount,
+				ProductID:    sd.Products[1].ID,
+				ExchangeRate: 1,
+				UnityPrice:   sd.Products[1].Price,
+				Quantity:     quantity.MustParse(2, quantity.Piece),
+				Amount:       discountedItemsValues[sd.Products[1].ID.String()].Amount,
+				Discount:     discountedItemsValues[sd.Products[1].ID.String()].Discount,
 			},
 			{
-				ProductID:  sd.Products[2].ID,
-				UnityPrice: sd.Products[2].Price,
-				Quantity:   1,
-				Amount:     sd.Products[2].Price,
-				Discount:   discountedItemsValues[sd.Products[2].ID.String()].Discount,
+				ProductID:    sd.Products[2].ID,
+				ExchangeRate: 1,
+				UnityPrice:   sd.Products[2].Price,
+				Quantity:     quantity.MustParse(1, quantity.Piece),
+				Amount:       sd.Products[2].Price,
+				Discount:     discountedItemsValues[sd.Products[2].ID.String()].Discount,
+			},
+		},
+	}
+
+	eurPrice, err := sd.Rates[0].Convert(sd.Products[0].Price)
+	if err != nil {
+		panic(err)
+	}
+	eurSaleExpected := salebus.Sale{
+		UserID:   sd.Users[0].User.ID,
+		Currency: currency.MustParse("EUR"),
+		Discount: money.MustParse(0),
+		Amount:   eurPrice,
+		Items: []salebus.SaleItem{
+			{
+				ProductID:    sd.Products[0].ID,
+				ExchangeRate: sd.Rates[0].Rate,
+				UnityPrice:   eurPrice,
+				Quantity:     quantity.MustParse(1, quantity.Piece),
+				Amount:       eurPrice,
 			},
 		},
 	}
@@ -254,20 +377,23 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExpResp: salebus.Sale{
 				ID:       uuid.UUID{},
 				UserID:   sd.Users[0].User.ID,
+				Currency: currency.Default,
 				Discount: money.MustParse(0),
 				Amount:   money.MustParse((sd.Products[0].Price.Value() * 1) + (sd.Products[1].Price.Value() * 2)),
 				Items: []salebus.SaleItem{
 					{
-						ProductID:  sd.Products[0].ID,
-						UnityPrice: sd.Products[0].Price,
-						Quantity:   1,
-						Amount:     sd.Products[0].Price,
+						ProductID:    sd.Products[0].ID,
+						ExchangeRate: 1,
+						UnityPrice:   sd.Products[0].Price,
+						Quantity:     quantity.MustParse(1, quantity.Piece),
+						Amount:       sd.Products[0].Price,
 					},
 					{
-						ProductID:  sd.Products[1].ID,
-						UnityPrice: sd.Products[1].Price,
-						Quantity:   2,
-						Amount:     money.MustParse(sd.Products[1].Price.Value() * 2),
+						ProductID:    sd.Products[1].ID,
+						ExchangeRate: 1,
+						UnityPrice:   sd.Products[1].Price,
+						Quantity:     quantity.MustParse(2, quantity.Piece),
+						Amount:       money.MustParse(sd.Products[1].Price.Value() * 2),
 					},
 				},
 			},
@@ -277,12 +403,12 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					Items: []salebus.NewSaleItem{
 						{
 							ProductID: sd.Products[0].ID,
-							Quantity:  1,
+							Quantity:  quantity.MustParse(1, quantity.Piece),
 							Price:     sd.Products[0].Price,
 						},
 						{
 							ProductID: sd.Products[1].ID,
-							Quantity:  2,
+							Quantity:  quantity.MustParse(2, quantity.Piece),
 							Price:     sd.Products[1].Price,
 						},
 					},
@@ -328,17 +454,17 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					Items: []salebus.NewSaleItem{
 						{
 							ProductID: sd.Products[0].ID,
-							Quantity:  1,
+							Quantity:  quantity.MustParse(1, quantity.Piece),
 							Price:     sd.Products[0].Price,
 						},
 						{
 							ProductID: sd.Products[1].ID,
-							Quantity:  2,
+							Quantity:  quantity.MustParse(2, quantity.Piece),
 							Price:     sd.Products[1].Price,
 						},
 						{
 							ProductID: sd.Products[2].ID,
-							Quantity:  1,
+							Quantity:  quantity.MustParse(1, quantity.Piece),
 							Price:     sd.Products[2].Price,
 						},
 					},
@@ -374,6 +500,74 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "currency",
+			ExpResp: eurSaleExpected,
+			ExcFunc: func(ctx context.Context) any {
+				ng := salebus.NewSale{
+					UserID:   sd.Users[0].User.ID,
+					Currency: currency.MustParse("EUR"),
+					Items: []salebus.NewSaleItem{
+						{
+							ProductID: sd.Products[0].ID,
+							Quantity:  quantity.MustParse(1, quantity.Piece),
+							Price:     sd.Products[0].Price,
+						},
+					},
+				}
+
+				resp, err := busDomain.Sale.Create(ctx, ng)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(salebus.Sale)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(salebus.Sale)
+
+				expResp.ID = gotResp.ID
+				expResp.UpdatedAt = gotResp.UpdatedAt
+				expResp.CreatedAt = gotResp.CreatedAt
+
+				for i := range gotResp.Items {
+					expResp.Items[i].SaleID = gotResp.Items[i].SaleID
+					expResp.Items[i].UpdatedAt = gotResp.Items[i].UpdatedAt
+					expResp.Items[i].CreatedAt = gotResp.Items[i].CreatedAt
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "missing-rate",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				ng := salebus.NewSale{
+					UserID:   sd.Users[0].User.ID,
+					Currency: currency.MustParse("JPY"),
+					Items: []salebus.NewSaleItem{
+						{
+							ProductID: sd.Products[0].ID,
+							Quantity:  quantity.MustParse(1, quantity.Piece),
+							Price:     sd.Products[0].Price,
+						},
+					},
+				}
+
+				_, err := busDomain.Sale.Create(ctx, ng)
+
+				return errors.Is(err, ratebus.ErrNotFound)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
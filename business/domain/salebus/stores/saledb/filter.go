@@ -2,6 +2,7 @@ package saledb
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/rmsj/service/business/domain/salebus"
@@ -15,6 +16,35 @@ func (s *Store) applyFilter(filter salebus.QueryFilter, data map[string]any, buf
 		wc = append(wc, "id = :id")
 	}
 
+	if filter.StartCreatedDate != nil {
+		data["start_created_at"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "created_at >= :start_created_at")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_created_at"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "created_at <= :end_created_at")
+	}
+
+	if filter.LocationID != nil {
+		data["location_id"] = filter.LocationID.String()
+		wc = append(wc, "location_id = :location_id")
+	}
+
+	if filter.LocationIDs != nil {
+		names := make([]string, len(filter.LocationIDs))
+		for i, locID := range filter.LocationIDs {
+			names[i] = fmt.Sprintf(":location_id_%d", i)
+			data[names[i][1:]] = locID.String()
+		}
+
+		if len(names) == 0 {
+			wc = append(wc, "location_id IS NULL")
+		} else {
+			wc = append(wc, fmt.Sprintf("(location_id IS NULL OR location_id IN (%s))", strings.Join(names, ", ")))
+		}
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
@@ -8,38 +8,58 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/rmsj/service/business/domain/salebus"
+	"github.com/rmsj/service/business/sdk/id"
+	"github.com/rmsj/service/business/types/currency"
 	"github.com/rmsj/service/business/types/money"
+	"github.com/rmsj/service/business/types/quantity"
 )
 
 type dbSale struct {
-	ID        uuid.UUID       `db:"id"`
-	UserID    uuid.UUID       `db:"user_id"`
-	Discount  sql.NullFloat64 `db:"discount"`
-	Amount    float64         `db:"amount"`
-	UpdatedAt time.Time       `db:"updated_at"`
-	CreatedAt time.Time       `db:"created_at"`
-}
-
-type dbSaleItem struct {
-	SaleID     uuid.UUID       `db:"sale_id"`
-	ProductID  uuid.UUID       `db:"product_id"`
-	UnityPrice float64         `db:"unity_price"`
-	Quantity   int             `db:"quantity"`
+	ID         uuid.UUID       `db:"id"`
+	UserID     uuid.UUID       `db:"user_id"`
+	Currency   string          `db:"currency"`
+	LocationID id.Nullable     `db:"location_id"`
 	Discount   sql.NullFloat64 `db:"discount"`
 	Amount     float64         `db:"amount"`
 	UpdatedAt  time.Time       `db:"updated_at"`
 	CreatedAt  time.Time       `db:"created_at"`
 }
 
+type dbSaleItem struct {
+	SaleID       uuid.UUID             `db:"sale_id"`
+	ProductID    uuid.UUID             `db:"product_id"`
+	UnityPrice   float64               `db:"unity_price"`
+	UnitCost     float64               `db:"unit_cost"`
+	PriceListID  id.Nullable           `db:"price_list_id"`
+	ExchangeRate float64               `db:"exchange_rate"`
+	Quantity     float64               `db:"quantity"`
+	Unit         string                `db:"unit"`
+	Discount     sql.NullFloat64       `db:"discount"`
+	Amount       float64               `db:"amount"`
+	UpdatedAt    time.Time             `db:"updated_at"`
+	CreatedAt    time.Time             `db:"created_at"`
+	Components   []dbSaleItemComponent `db:"-"`
+}
+
+type dbSaleItemComponent struct {
+	SaleID    uuid.UUID `db:"sale_id"`
+	BundleID  uuid.UUID `db:"bundle_id"`
+	ProductID uuid.UUID `db:"product_id"`
+	Quantity  int       `db:"quantity"`
+	Amount    float64   `db:"amount"`
+}
+
 func toDBSale(bus salebus.Sale) dbSale {
 
 	saleDB := dbSale{
-		ID:        bus.ID,
-		UserID:    bus.UserID,
-		Discount:  sql.NullFloat64{Float64: bus.Discount.Value(), Valid: bus.Discount.Value() > 0},
-		Amount:    bus.Amount.Value(),
-		UpdatedAt: bus.UpdatedAt,
-		CreatedAt: bus.CreatedAt,
+		ID:         bus.ID,
+		UserID:     bus.UserID,
+		Currency:   bus.Currency.String(),
+		LocationID: id.Nullable{UUID: bus.LocationID},
+		Discount:   sql.NullFloat64{Float64: bus.Discount.Value(), Valid: bus.Discount.Value() > 0},
+		Amount:     bus.Amount.Value(),
+		UpdatedAt:  bus.UpdatedAt,
+		CreatedAt:  bus.CreatedAt,
 	}
 
 	return saleDB
@@ -58,13 +78,20 @@ func toBusSale(db dbSale, items []dbSaleItem) (salebus.Sale, error) {
 		return salebus.Sale{}, fmt.Errorf("parse amount: %w", err)
 	}
 
+	cur, err := currency.Parse(db.Currency)
+	if err != nil {
+		return salebus.Sale{}, fmt.Errorf("parse currency: %w", err)
+	}
+
 	sl := salebus.Sale{
-		ID:        db.ID,
-		UserID:    db.UserID,
-		Discount:  discount,
-		Amount:    amount,
-		UpdatedAt: db.UpdatedAt,
-		CreatedAt: db.CreatedAt,
+		ID:         db.ID,
+		UserID:     db.UserID,
+		Currency:   cur,
+		LocationID: db.LocationID.UUID,
+		Discount:   discount,
+		Amount:     amount,
+		UpdatedAt:  db.UpdatedAt,
+		CreatedAt:  db.CreatedAt,
 	}
 
 	// far from ideal - we can use a join instead
@@ -100,14 +127,18 @@ func toBusSales(dbs []dbSale, dbItems []dbSaleItem) ([]salebus.Sale, error) {
 
 func toDBSaleItem(bus salebus.SaleItem) dbSaleItem {
 	saleItemDB := dbSaleItem{
-		SaleID:     bus.SaleID,
-		ProductID:  bus.ProductID,
-		Quantity:   bus.Quantity,
-		Discount:   sql.NullFloat64{Float64: bus.Discount.Value(), Valid: bus.Discount.Value() > 0},
-		UnityPrice: bus.UnityPrice.Value(),
-		Amount:     bus.Amount.Value(),
-		UpdatedAt:  bus.UpdatedAt,
-		CreatedAt:  bus.CreatedAt,
+		SaleID:       bus.SaleID,
+		ProductID:    bus.ProductID,
+		Quantity:     bus.Quantity.Value(),
+		Unit:         bus.Quantity.Unit().String(),
+		Discount:     sql.NullFloat64{Float64: bus.Discount.Value(), Valid: bus.Discount.Value() > 0},
+		UnityPrice:   bus.UnityPrice.Value(),
+		UnitCost:     bus.UnitCost.Value(),
+		PriceListID:  id.Nullable{UUID: bus.PriceListID},
+		ExchangeRate: bus.ExchangeRate,
+		Amount:       bus.Amount.Value(),
+		UpdatedAt:    bus.UpdatedAt,
+		CreatedAt:    bus.CreatedAt,
 	}
 
 	return saleItemDB
@@ -131,20 +162,61 @@ func toBusSaleItem(db dbSaleItem) (salebus.SaleItem, error) {
 		return salebus.SaleItem{}, fmt.Errorf("parse unity price: %w", err)
 	}
 
+	unitCost, err := money.Parse(db.UnitCost)
+	if err != nil {
+		return salebus.SaleItem{}, fmt.Errorf("parse unit cost: %w", err)
+	}
+
+	unit, err := quantity.ParseUnit(db.Unit)
+	if err != nil {
+		return salebus.SaleItem{}, fmt.Errorf("parse unit: %w", err)
+	}
+
+	qty, err := quantity.Parse(db.Quantity, unit)
+	if err != nil {
+		return salebus.SaleItem{}, fmt.Errorf("parse quantity: %w", err)
+	}
+
 	slItem := salebus.SaleItem{
-		SaleID:     db.SaleID,
-		ProductID:  db.ProductID,
-		Quantity:   db.Quantity,
-		Discount:   discount,
-		UnityPrice: unityPrice,
-		Amount:     amount,
-		UpdatedAt:  db.UpdatedAt,
-		CreatedAt:  db.CreatedAt,
+		SaleID:       db.SaleID,
+		ProductID:    db.ProductID,
+		Quantity:     qty,
+		Discount:     discount,
+		UnityPrice:   unityPrice,
+		UnitCost:     unitCost,
+		PriceListID:  db.PriceListID.UUID,
+		ExchangeRate: db.ExchangeRate,
+		Amount:       amount,
+		UpdatedAt:    db.UpdatedAt,
+		CreatedAt:    db.CreatedAt,
+	}
+
+	for _, sic := range db.Components {
+		amount, err := money.Parse(sic.Amount)
+		if err != nil {
+			return salebus.SaleItem{}, fmt.Errorf("parse component amount: %w", err)
+		}
+
+		slItem.Components = append(slItem.Components, salebus.SaleItemComponent{
+			ProductID: sic.ProductID,
+			Quantity:  sic.Quantity,
+			Amount:    amount,
+		})
 	}
 
 	return slItem, nil
 }
 
+func toDBSaleItemComponent(item salebus.SaleItem, bus salebus.SaleItemComponent) dbSaleItemComponent {
+	return dbSaleItemComponent{
+		SaleID:    item.SaleID,
+		BundleID:  item.ProductID,
+		ProductID: bus.ProductID,
+		Quantity:  bus.Quantity,
+		Amount:    bus.Amount.Value(),
+	}
+}
+
 //lint:ignore U1000 temp
 func toBusSaleItems(dbs []dbSaleItem) ([]salebus.SaleItem, error) {
 	bus := make([]salebus.SaleItem, len(dbs))
@@ -159,3 +231,34 @@ func toBusSaleItems(dbs []dbSaleItem) ([]salebus.SaleItem, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+type dbTotals struct {
+	Date     time.Time `db:"date"`
+	Currency string    `db:"currency"`
+	Sales    int       `db:"sales"`
+	Amount   float64   `db:"amount"`
+	Discount float64   `db:"discount"`
+}
+
+func toBusTotals(dbs []dbTotals) ([]salebus.Totals, error) {
+	bus := make([]salebus.Totals, len(dbs))
+
+	for i, db := range dbs {
+		cur, err := currency.Parse(db.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("parse currency: %w", err)
+		}
+
+		bus[i] = salebus.Totals{
+			Date:     db.Date,
+			Currency: cur,
+			Sales:    db.Sales,
+			Amount:   db.Amount,
+			Discount: db.Discount,
+		}
+	}
+
+	return bus, nil
+}
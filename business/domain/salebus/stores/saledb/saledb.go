@@ -52,9 +52,9 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (salebus.Storer, error) {
 func (s *Store) Create(ctx context.Context, sale salebus.Sale) error {
 	const q = `
 	INSERT INTO sales
-		(id, user_id, discount, amount, updated_at, created_at)
+		(id, user_id, currency, location_id, discount, amount, updated_at, created_at)
 	VALUES
-		(:id, :user_id, :discount, :amount, :updated_at, :created_at)`
+		(:id, :user_id, :currency, :location_id, :discount, :amount, :updated_at, :created_at)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSale(sale)); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -64,13 +64,25 @@ func (s *Store) Create(ctx context.Context, sale salebus.Sale) error {
 	for _, item := range sale.Items {
 		const qi = `
 		INSERT INTO sale_items
-			(sale_id, product_id, quantity, unity_price, discount, amount, created_at, updated_at)
+			(sale_id, product_id, quantity, unit, unity_price, unit_cost, price_list_id, exchange_rate, discount, amount, created_at, updated_at)
 		VALUES
-			(:sale_id, :product_id, :quantity, :unity_price, :discount, :amount, :created_at, :updated_at)`
+			(:sale_id, :product_id, :quantity, :unit, :unity_price, :unit_cost, :price_list_id, :exchange_rate, :discount, :amount, :created_at, :updated_at)`
 
 		if err := sqldb.NamedExecContext(ctx, s.log, s.db, qi, toDBSaleItem(item)); err != nil {
 			return fmt.Errorf("namedexeccontext: %w", err)
 		}
+
+		for _, sic := range item.Components {
+			const qc = `
+			INSERT INTO sale_item_components
+				(sale_id, bundle_id, product_id, quantity, amount)
+			VALUES
+				(:sale_id, :bundle_id, :product_id, :quantity, :amount)`
+
+			if err := sqldb.NamedExecContext(ctx, s.log, s.db, qc, toDBSaleItemComponent(item, sic)); err != nil {
+				return fmt.Errorf("namedexeccontext: %w", err)
+			}
+		}
 	}
 
 	return nil
@@ -177,7 +189,30 @@ func (s *Store) QueryByID(ctx context.Context, slID uuid.UUID) (salebus.Sale, er
 	return toBusSale(dbsl, items)
 }
 
-// QueryByID finds the sale identified by a given ID.
+// getSaleItems retrieves the items of the specified sales along with the
+// QueryTotals adds up the sales per day and currency, in date order.
+func (s *Store) QueryTotals(ctx context.Context, filter salebus.QueryFilter) ([]salebus.Totals, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		DATE(created_at) AS date, currency, COUNT(id) AS sales, COALESCE(SUM(amount), 0) AS amount, COALESCE(SUM(discount), 0) AS discount
+	FROM
+		sales`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	buf.WriteString(" GROUP BY DATE(created_at), currency ORDER BY date, currency")
+
+	var dbTotals []dbTotals
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbTotals); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusTotals(dbTotals)
+}
+
+// components of the bundles sold.
 func (s *Store) getSaleItems(ctx context.Context, slID []uuid.UUID) ([]dbSaleItem, error) {
 
 	data := struct {
@@ -193,5 +228,24 @@ func (s *Store) getSaleItems(ctx context.Context, slID []uuid.UUID) ([]dbSaleIte
 		return dbItems, fmt.Errorf("namedquerystruct: %w", err)
 	}
 
+	if len(dbItems) == 0 {
+		return dbItems, nil
+	}
+
+	const qc = `SELECT * FROM sale_item_components WHERE sale_id IN (:sale_ids) ORDER BY sale_id, bundle_id, product_id ASC`
+
+	var dbComponents []dbSaleItemComponent
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, qc, data, &dbComponents); err != nil {
+		return dbItems, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	for i, item := range dbItems {
+		for _, sic := range dbComponents {
+			if sic.SaleID == item.SaleID && sic.BundleID == item.ProductID {
+				dbItems[i].Components = append(dbItems[i].Components, sic)
+			}
+		}
+	}
+
 	return dbItems, nil
 }
@@ -3,6 +3,7 @@ package userbus
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rmsj/service/business/sdk/delegate"
@@ -13,7 +14,9 @@ const DomainName = "user"
 
 // Set of delegate actions.
 const (
-	ActionDeleted = "deleted"
+	ActionDeleted       = "deleted"
+	ActionLocked        = "locked"
+	ActionAccessRevoked = "accessrevoked"
 )
 
 // ActionDeletedParms represents the parameters for the deleted action.
@@ -48,3 +51,80 @@ func ActionDeletedData(userID uuid.UUID) delegate.Data {
 		RawParams: rawParams,
 	}
 }
+
+// =============================================================================
+
+// ActionLockedParms represents the parameters for the locked action.
+type ActionLockedParms struct {
+	UserID       uuid.UUID
+	FailedLogins int
+	LockedUntil  time.Time
+}
+
+// String returns a string representation of the action parameters.
+func (act *ActionLockedParms) String() string {
+	return fmt.Sprintf("&EventParamsLocked{UserID:%v, FailedLogins:%d, LockedUntil:%v}", act.UserID, act.FailedLogins, act.LockedUntil)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (act *ActionLockedParms) Marshal() ([]byte, error) {
+	return json.Marshal(act)
+}
+
+// ActionLockedData constructs the data for the locked action.
+func ActionLockedData(usr User) delegate.Data {
+	params := ActionLockedParms{
+		UserID:       usr.ID,
+		FailedLogins: usr.FailedLogins,
+		LockedUntil:  usr.LockedUntil,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionLocked,
+		RawParams: rawParams,
+	}
+}
+
+// =============================================================================
+
+// ActionAccessRevokedParms represents the parameters for the access revoked
+// action, raised when a user is disabled or their password changes.
+type ActionAccessRevokedParms struct {
+	UserID uuid.UUID
+	Reason string
+}
+
+// String returns a string representation of the action parameters.
+func (act *ActionAccessRevokedParms) String() string {
+	return fmt.Sprintf("&EventParamsAccessRevoked{UserID:%v, Reason:%s}", act.UserID, act.Reason)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (act *ActionAccessRevokedParms) Marshal() ([]byte, error) {
+	return json.Marshal(act)
+}
+
+// ActionAccessRevokedData constructs the data for the access revoked action.
+func ActionAccessRevokedData(userID uuid.UUID, reason string) delegate.Data {
+	params := ActionAccessRevokedParms{
+		UserID: userID,
+		Reason: reason,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    ActionAccessRevoked,
+		RawParams: rawParams,
+	}
+}
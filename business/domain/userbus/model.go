@@ -19,11 +19,18 @@ type User struct {
 	PasswordHash []byte
 	Department   name.Null
 	Enabled      bool
-	RefreshToken string
+	FailedLogins int
+	LockedUntil  time.Time
 	DateCreated  time.Time
 	DateUpdated  time.Time
 }
 
+// Lockout represents the failed login state of a user.
+type Lockout struct {
+	FailedLogins int
+	LockedUntil  time.Time
+}
+
 // NewUser contains information needed to create a new user.
 type NewUser struct {
 	Name       name.Name
@@ -39,7 +46,6 @@ type UpdateUser struct {
 	Email           *mail.Address
 	Roles           []role.Role
 	Department      *name.Null
-	RefreshToken    *string
 	Password        *string
 	PasswordConfirm *string
 	Enabled         *bool
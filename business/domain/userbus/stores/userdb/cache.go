@@ -16,12 +16,10 @@ func (s *Store) readCache(key string) (userbus.User, bool) {
 func (s *Store) writeCache(bus userbus.User) {
 	s.cache.Set(bus.ID.String(), bus)
 	s.cache.Set(bus.Email.Address, bus)
-	s.cache.Set(bus.RefreshToken, bus)
 }
 
 // deleteCache performs a safe removal from the cache for the specified userbus.
 func (s *Store) deleteCache(bus userbus.User) {
 	s.cache.Delete(bus.ID.String())
 	s.cache.Delete(bus.Email.Address)
-	s.cache.Delete(bus.RefreshToken)
 }
@@ -22,7 +22,8 @@ type user struct {
 	PasswordHash []byte         `db:"password_hash"`
 	Department   sql.NullString `db:"department"`
 	Enabled      bool           `db:"enabled"`
-	RefreshToken sql.NullString `db:"refresh_token"`
+	FailedLogins int            `db:"failed_logins"`
+	LockedUntil  sql.NullTime   `db:"locked_until"`
 	DateCreated  time.Time      `db:"created_at"`
 	DateUpdated  time.Time      `db:"updated_at"`
 }
@@ -38,7 +39,12 @@ func toDBUser(bus userbus.User) user {
 			String: bus.Department.String(),
 			Valid:  bus.Department.Valid(),
 		},
-		Enabled:     bus.Enabled,
+		Enabled:      bus.Enabled,
+		FailedLogins: bus.FailedLogins,
+		LockedUntil: sql.NullTime{
+			Time:  bus.LockedUntil.UTC(),
+			Valid: !bus.LockedUntil.IsZero(),
+		},
 		DateCreated: bus.DateCreated.UTC(),
 		DateUpdated: bus.DateUpdated.UTC(),
 	}
@@ -72,13 +78,35 @@ func toBusUser(db user) (userbus.User, error) {
 		PasswordHash: db.PasswordHash,
 		Enabled:      db.Enabled,
 		Department:   department,
+		FailedLogins: db.FailedLogins,
 		DateCreated:  db.DateCreated.In(time.Local),
 		DateUpdated:  db.DateUpdated.In(time.Local),
 	}
 
+	if db.LockedUntil.Valid {
+		bus.LockedUntil = db.LockedUntil.Time.In(time.Local)
+	}
+
 	return bus, nil
 }
 
+type lockout struct {
+	FailedLogins int          `db:"failed_logins"`
+	LockedUntil  sql.NullTime `db:"locked_until"`
+}
+
+func toBusLockout(db lockout) userbus.Lockout {
+	bus := userbus.Lockout{
+		FailedLogins: db.FailedLogins,
+	}
+
+	if db.LockedUntil.Valid {
+		bus.LockedUntil = db.LockedUntil.Time.In(time.Local)
+	}
+
+	return bus
+}
+
 func toBusUsers(dbs []user) ([]userbus.User, error) {
 	bus := make([]userbus.User, len(dbs))
 
@@ -129,7 +129,7 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 
 	const q = `
 	SELECT
-		id, name, email, password_hash, roles, department, enabled, created_at, updated_at
+		id, name, email, password_hash, roles, department, enabled, failed_logins, locked_until, created_at, updated_at
 	FROM
 		users`
 
@@ -186,7 +186,7 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 
 	const q = `
 	SELECT
-        id, name, email, password_hash, roles, department, enabled, created_at, updated_at
+        id, name, email, password_hash, roles, department, enabled, failed_logins, locked_until, created_at, updated_at
 	FROM
 		users
 	WHERE 
@@ -223,7 +223,7 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	const q = `
 	SELECT
-        id, name, email, password_hash, roles, department, enabled, created_at, updated_at
+        id, name, email, password_hash, roles, department, enabled, failed_logins, locked_until, created_at, updated_at
 	FROM
 		users
 	WHERE
@@ -245,40 +245,118 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 	return bus, nil
 }
 
-// QueryByRefreshToken gets the specified user from the database by refresh token.
-func (s *Store) QueryByRefreshToken(ctx context.Context, refreshToken string) (userbus.User, error) {
-	cached, ok := s.readCache(refreshToken)
-	if ok {
-		return cached, nil
+// UpdateLockout stores the failed login count and lockout for a user.
+func (s *Store) UpdateLockout(ctx context.Context, usr userbus.User) error {
+	const q = `
+	UPDATE
+		users
+	SET
+		failed_logins = :failed_logins,
+		locked_until = :locked_until
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
 	}
+	s.writeCache(usr)
+
+	return nil
+}
 
+// QueryLockout gets the failed login state of the user. It's always read from
+// the database, since the lockout may have been changed by another service
+// that doesn't share this cache.
+func (s *Store) QueryLockout(ctx context.Context, userID uuid.UUID) (userbus.Lockout, error) {
 	data := struct {
-		Email string `db:"refresh_token"`
+		ID string `db:"id"`
 	}{
-		Email: refreshToken,
+		ID: userID.String(),
 	}
 
 	const q = `
 	SELECT
-        *
+		failed_logins, locked_until
 	FROM
 		users
 	WHERE
-		refresh_token = :refresh_token`
+		id = :id`
 
-	var dbUsr user
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+	var dbLo lockout
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLo); err != nil {
 		if errors.Is(err, sqldb.ErrDBNotFound) {
-			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+			return userbus.Lockout{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
 		}
-		return userbus.User{}, fmt.Errorf("db: %w", err)
+		return userbus.Lockout{}, fmt.Errorf("db: %w", err)
 	}
 
-	bus, err := toBusUser(dbUsr)
+	return toBusLockout(dbLo), nil
+}
+
+// IncrementFailedLogins adds a failed login for the user and returns the new
+// count. The increment is done by the database, so concurrent failures are
+// all counted, and LAST_INSERT_ID returns the value this update wrote.
+func (s *Store) IncrementFailedLogins(ctx context.Context, usr userbus.User) (int, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: usr.ID.String(),
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		failed_logins = LAST_INSERT_ID(failed_logins + 1)
+	WHERE
+		id = :id`
+
+	result, err := sqldb.NamedExecContextResult(ctx, s.log, s.db, q, data)
 	if err != nil {
-		return userbus.User{}, err
+		return 0, fmt.Errorf("namedexeccontextresult: %w", err)
 	}
-	s.writeCache(bus)
 
-	return bus, nil
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	if rows == 0 {
+		return 0, fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	failed, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("lastinsertid: %w", err)
+	}
+	s.deleteCache(usr)
+
+	return int(failed), nil
+}
+
+// Lock locks the user until the specified time. A lock already in place that
+// ends later is kept.
+func (s *Store) Lock(ctx context.Context, usr userbus.User, lockedUntil time.Time) error {
+	data := struct {
+		ID          string    `db:"id"`
+		LockedUntil time.Time `db:"locked_until"`
+	}{
+		ID:          usr.ID.String(),
+		LockedUntil: lockedUntil.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		users
+	SET
+		locked_until = GREATEST(COALESCE(locked_until, :locked_until), :locked_until)
+	WHERE
+		id = :id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+	s.deleteCache(usr)
+
+	return nil
 }
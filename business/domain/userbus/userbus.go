@@ -11,7 +11,9 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/rmsj/service/business/sdk/ctxval"
 	"github.com/rmsj/service/business/sdk/delegate"
+	"github.com/rmsj/service/business/sdk/lockout"
 	"github.com/rmsj/service/business/sdk/order"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/sqldb"
@@ -23,8 +25,21 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrAccountLocked         = errors.New("account locked")
 )
 
+// LockoutPolicy defines when an account is locked after consecutive failed
+// logins and for how long.
+var LockoutPolicy = lockout.Policy{
+	Threshold: 5,
+	Base:      time.Minute,
+	Max:       24 * time.Hour,
+}
+
+// dummyHash is compared against when the email is unknown so the response
+// time does not reveal whether an account exists.
+var dummyHash = []byte("$2a$10$bb.vNoj8laor5pKnmShrQ.ef.bx53UvWl13e/BS5uXNMxnVZQNG9y")
+
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
@@ -36,7 +51,10 @@ type Storer interface {
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
-	QueryByRefreshToken(ctx context.Context, refreshToken string) (User, error)
+	UpdateLockout(ctx context.Context, usr User) error
+	QueryLockout(ctx context.Context, userID uuid.UUID) (Lockout, error)
+	IncrementFailedLogins(ctx context.Context, usr User) (int, error)
+	Lock(ctx context.Context, usr User, lockedUntil time.Time) error
 }
 
 // Business manages the set of APIs for user access.
@@ -126,7 +144,15 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 		usr.Department = *uu.Department
 	}
 
+	var revokeReason string
+	if uu.Password != nil {
+		revokeReason = "password changed"
+	}
+
 	if uu.Enabled != nil {
+		if usr.Enabled && !*uu.Enabled {
+			revokeReason = "user disabled"
+		}
 		usr.Enabled = *uu.Enabled
 	}
 
@@ -136,6 +162,14 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 		return User{}, fmt.Errorf("update: %w", err)
 	}
 
+	// Tokens issued before a password change or while the user was enabled
+	// must stop working, so let the domains holding them know.
+	if revokeReason != "" {
+		if err := b.delegate.Call(ctx, ActionAccessRevokedData(usr.ID, revokeReason)); err != nil {
+			return User{}, fmt.Errorf("failed to execute `%s` action: %w", ActionAccessRevoked, err)
+		}
+	}
+
 	return usr, nil
 }
 
@@ -189,29 +223,90 @@ func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User,
 	return user, nil
 }
 
-// QueryByRefreshToken finds the user by the specified refresh token
-func (b *Business) QueryByRefreshToken(ctx context.Context, refreshToken string) (User, error) {
-	user, err := b.storer.QueryByRefreshToken(ctx, refreshToken)
-	if err != nil {
-		b.log.Error(ctx, "business.userbus.querybyrefreshtoken", "error", err)
-		return User{}, fmt.Errorf("query: refreshToken[%s]: %w", refreshToken, err)
-	}
-
-	return user, nil
-}
-
 // Authenticate finds a user by their email and verifies their password. On
 // success it returns a Claims User representing this user. The claims can be
-// used to generate a token for future authentication.
+// used to generate a token for future authentication. Consecutive failures
+// lock the account according to the LockoutPolicy.
 func (b *Business) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
 	usr, err := b.QueryByEmail(ctx, email)
 	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
 		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
 	}
 
+	// The user may be cached, but the lockout must be current so an unlock or
+	// failures recorded by another service are seen straight away.
+	lo, err := b.storer.QueryLockout(ctx, usr.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("querylockout: userID[%s]: %w", usr.ID, err)
+	}
+	usr.FailedLogins = lo.FailedLogins
+	usr.LockedUntil = lo.LockedUntil
+
+	now := ctxval.GetTime(ctx)
+
+	if usr.LockedUntil.After(now) {
+		return User{}, fmt.Errorf("locked: userID[%s] until[%s]: %w", usr.ID, usr.LockedUntil.Format(time.RFC3339), ErrAccountLocked)
+	}
+
 	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+		if err := b.recordFailure(ctx, usr, now); err != nil {
+			return User{}, err
+		}
+
 		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
 	}
 
+	if usr.FailedLogins > 0 || !usr.LockedUntil.IsZero() {
+		usr.FailedLogins = 0
+		usr.LockedUntil = time.Time{}
+
+		if err := b.storer.UpdateLockout(ctx, usr); err != nil {
+			return User{}, fmt.Errorf("updatelockout: %w", err)
+		}
+	}
+
+	return usr, nil
+}
+
+// Unlock clears the failed login count and any active lockout for the user.
+func (b *Business) Unlock(ctx context.Context, usr User) (User, error) {
+	usr.FailedLogins = 0
+	usr.LockedUntil = time.Time{}
+
+	if err := b.storer.UpdateLockout(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("updatelockout: %w", err)
+	}
+
 	return usr, nil
 }
+
+// recordFailure counts a failed login for the user and locks the account once
+// the LockoutPolicy threshold is reached.
+func (b *Business) recordFailure(ctx context.Context, usr User, now time.Time) error {
+	failed, err := b.storer.IncrementFailedLogins(ctx, usr)
+	if err != nil {
+		return fmt.Errorf("incrementfailedlogins: %w", err)
+	}
+	usr.FailedLogins = failed
+
+	// The lock is computed from the count the store returned, which includes
+	// failures recorded concurrently.
+	if d := LockoutPolicy.Duration(failed); d > 0 {
+		usr.LockedUntil = now.Add(d)
+
+		if err := b.storer.Lock(ctx, usr, usr.LockedUntil); err != nil {
+			return fmt.Errorf("lock: %w", err)
+		}
+
+		b.log.Info(ctx, "business.userbus.authenticate", "status", "account locked", "userID", usr.ID, "failedLogins", usr.FailedLogins, "lockedUntil", usr.LockedUntil)
+
+		// Other domains may need to know when an account is locked, for
+		// example to keep an audit trail.
+		if err := b.delegate.Call(ctx, ActionLockedData(usr)); err != nil {
+			return fmt.Errorf("failed to execute `%s` action: %w", ActionLocked, err)
+		}
+	}
+
+	return nil
+}
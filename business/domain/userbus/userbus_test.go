@@ -2,14 +2,19 @@ package userbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/mail"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/rmsj/service/business/domain/auditbus"
 	"github.com/rmsj/service/business/domain/userbus"
+	"github.com/rmsj/service/business/domain/userbus/stores/userdb"
 	"github.com/rmsj/service/business/sdk/dbtest"
 	"github.com/rmsj/service/business/sdk/page"
 	"github.com/rmsj/service/business/sdk/unitest"
@@ -33,6 +38,7 @@ func Test_User(t *testing.T) {
 	unitest.Run(t, query(db.BusDomain, sd), "query")
 	unitest.Run(t, create(db.BusDomain), "create")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
+	unitest.Run(t, lockout(db, sd), "lockout")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
 }
 
@@ -276,6 +282,156 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 	return table
 }
 
+func lockout(db *dbtest.Database, sd unitest.SeedData) []unitest.Table {
+	busDomain := db.BusDomain
+
+	usr := sd.Admins[0].User
+	password := "Password" + strings.TrimPrefix(usr.Name.String(), "Name")
+
+	// other has its own cache, like the sales service does next to the auth
+	// service.
+	other := userbus.NewBusiness(db.Log, nil, userdb.NewStore(db.Log, db.DB, time.Hour))
+
+	type lockState struct {
+		FailedLogins int
+		Locked       bool
+	}
+
+	table := []unitest.Table{
+		{
+			Name: "failures",
+			ExpResp: lockState{
+				FailedLogins: userbus.LockoutPolicy.Threshold,
+				Locked:       true,
+			},
+			ExcFunc: func(ctx context.Context) any {
+				for range userbus.LockoutPolicy.Threshold {
+					_, err := busDomain.User.Authenticate(ctx, usr.Email, "wrong")
+					if !errors.Is(err, userbus.ErrAuthenticationFailure) {
+						return err
+					}
+				}
+
+				resp, err := busDomain.User.QueryByID(ctx, usr.ID)
+				if err != nil {
+					return err
+				}
+
+				return lockState{
+					FailedLogins: resp.FailedLogins,
+					Locked:       resp.LockedUntil.After(time.Now()),
+				}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "locked",
+			ExpResp: userbus.ErrAccountLocked,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.User.Authenticate(ctx, usr.Email, password)
+				if errors.Is(err, userbus.ErrAccountLocked) {
+					return userbus.ErrAccountLocked
+				}
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != exp {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+		{
+			Name:    "audit",
+			ExpResp: []string{"user.locked"},
+			ExcFunc: func(ctx context.Context) any {
+				filter := auditbus.QueryFilter{
+					ObjID: &usr.ID,
+				}
+
+				resp, err := busDomain.Audit.Query(ctx, filter, auditbus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				actions := make([]string, len(resp))
+				for i, a := range resp {
+					actions[i] = a.ObjDomain + "." + a.Action
+				}
+
+				return actions
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "unlock",
+			ExpResp: lockState{},
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := other.Unlock(ctx, usr); err != nil {
+					return err
+				}
+
+				resp, err := busDomain.User.Authenticate(ctx, usr.Email, password)
+				if err != nil {
+					return err
+				}
+
+				return lockState{
+					FailedLogins: resp.FailedLogins,
+					Locked:       !resp.LockedUntil.IsZero(),
+				}
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "concurrent-failures",
+			ExpResp: userbus.ErrAccountLocked,
+			ExcFunc: func(ctx context.Context) any {
+				usr := sd.Admins[1].User
+				password := "Password" + strings.TrimPrefix(usr.Name.String(), "Name")
+
+				var wg sync.WaitGroup
+				for range userbus.LockoutPolicy.Threshold - 1 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						busDomain.User.Authenticate(ctx, usr.Email, "wrong")
+					}()
+				}
+				wg.Wait()
+
+				// Every concurrent failure was counted, so this one reaches
+				// the threshold.
+				busDomain.User.Authenticate(ctx, usr.Email, "wrong")
+
+				_, err := busDomain.User.Authenticate(ctx, usr.Email, password)
+				if errors.Is(err, userbus.ErrAccountLocked) {
+					return userbus.ErrAccountLocked
+				}
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				if got != exp {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
 func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 	table := []unitest.Table{
 		{
@@ -5,39 +5,71 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
+	"github.com/rmsj/service/business/domain/auditbus"
+	"github.com/rmsj/service/business/domain/auditbus/stores/auditdb"
 	"github.com/rmsj/service/business/domain/authbus"
 	"github.com/rmsj/service/business/domain/authbus/stores/authdb"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/imagebus/stores/imagedb"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/locationbus/stores/locationdb"
+	"github.com/rmsj/service/business/domain/pricebus"
+	"github.com/rmsj/service/business/domain/pricebus/stores/pricedb"
 	"github.com/rmsj/service/business/domain/productbus"
 	"github.com/rmsj/service/business/domain/productbus/stores/productdb"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/ratebus/stores/ratedb"
+	"github.com/rmsj/service/business/domain/rolebus"
+	"github.com/rmsj/service/business/domain/rolebus/stores/roledb"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/salebus/stores/saledb"
 	"github.com/rmsj/service/business/domain/userbus"
 	"github.com/rmsj/service/business/domain/userbus/stores/userdb"
 	"github.com/rmsj/service/business/sdk/delegate"
+	"github.com/rmsj/service/foundation/blob"
 	"github.com/rmsj/service/foundation/logger"
 )
 
 // BusDomain represents all the business domain apis needed for testing.
 type BusDomain struct {
 	Delegate *delegate.Delegate
+	Audit    *auditbus.Business
 	Auth     *authbus.Business
 	User     *userbus.Business
 	Product  *productbus.Business
 	Sale     *salebus.Business
+	Price    *pricebus.Business
+	Image    *imagebus.Business
+	Rate     *ratebus.Business
+	Location *locationbus.Business
+	Role     *rolebus.Business
 }
 
-func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
+func newBusDomains(log *logger.Logger, db *sqlx.DB, blobs blob.Store) BusDomain {
 	dlg := delegate.New(log)
-	authBus := authbus.NewBusiness(log, authdb.NewStore(log, db))
+	auditBus := auditbus.NewBusiness(log, dlg, auditdb.NewStore(log, db))
+	authBus := authbus.NewBusiness(log, dlg, authdb.NewStore(log, db, 10*time.Second))
 	userBus := userbus.NewBusiness(log, dlg, userdb.NewStore(log, db, time.Hour))
-	productBus := productbus.NewBusiness(log, dlg, productdb.NewStore(log, db))
-	saleBus := salebus.NewBusiness(log, saledb.NewStore(log, db))
+	productStore := productdb.NewStore(log, db)
+	productBus := productbus.NewBusiness(log, dlg, productStore, productStore)
+	rateBus := ratebus.NewBusiness(log, ratedb.NewStore(log, db))
+	saleBus := salebus.NewBusiness(log, productBus, rateBus, saledb.NewStore(log, db))
+	priceBus := pricebus.NewBusiness(log, pricedb.NewStore(log, db))
+	imageBus := imagebus.NewBusiness(log, imagedb.NewStore(log, db), blobs)
+	locationBus := locationbus.NewBusiness(log, locationdb.NewStore(log, db))
+	roleBus := rolebus.NewBusiness(log, roledb.NewStore(log, db, time.Second))
 
 	return BusDomain{
 		Delegate: dlg,
+		Audit:    auditBus,
 		Auth:     authBus,
 		User:     userBus,
 		Product:  productBus,
 		Sale:     saleBus,
+		Price:    priceBus,
+		Image:    imageBus,
+		Rate:     rateBus,
+		Location: locationBus,
+		Role:     roleBus,
 	}
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/rmsj/service/business/sdk/migrate"
 	"github.com/rmsj/service/business/sdk/sqldb"
+	"github.com/rmsj/service/foundation/blob"
 	"github.com/rmsj/service/foundation/docker"
 	"github.com/rmsj/service/foundation/logger"
 	"github.com/rmsj/service/foundation/otel"
@@ -101,6 +102,11 @@ func New(t *testing.T, testName string) *Database {
 		t.Fatalf("Migrating error: %s", err)
 	}
 
+	blobs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Opening blob store: %v", err)
+	}
+
 	// -------------------------------------------------------------------------
 
 	var buf bytes.Buffer
@@ -134,6 +140,6 @@ func New(t *testing.T, testName string) *Database {
 	return &Database{
 		DB:        db,
 		Log:       log,
-		BusDomain: newBusDomains(log, db),
+		BusDomain: newBusDomains(log, db, blobs),
 	}
 }
@@ -58,10 +58,10 @@ func MoneyPointer(value float64) *money.Money {
 	return &money
 }
 
-// QuantityPointer is a helper to get a *Quantity from an int. It's in the tests
-// package because we normally don't want to deal with pointers to basic types
-// but it's useful in some tests.
-func QuantityPointer(value int) *quantity.Quantity {
-	quantity := quantity.MustParse(value)
+// QuantityPointer is a helper to get a *Quantity of pieces from a float. It's
+// in the tests package because we normally don't want to deal with pointers to
+// basic types but it's useful in some tests.
+func QuantityPointer(value float64) *quantity.Quantity {
+	quantity := quantity.MustParse(value, quantity.Piece)
 	return &quantity
 }
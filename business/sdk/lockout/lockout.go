@@ -0,0 +1,32 @@
+// Package lockout provides the policy used to lock out accounts and clients
+// after repeated authentication failures.
+package lockout
+
+import "time"
+
+// Policy describes when a lockout starts and how long it lasts. Once the
+// number of consecutive failures reaches Threshold the lockout lasts for
+// Base, and every further failure doubles it up to Max.
+type Policy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+// Duration returns how long to lock out after the specified number of
+// consecutive failures. A zero duration means no lockout is required.
+func (p Policy) Duration(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+
+	d := p.Base
+	for range failures - p.Threshold {
+		if d >= p.Max {
+			break
+		}
+		d *= 2
+	}
+
+	return min(d, p.Max)
+}
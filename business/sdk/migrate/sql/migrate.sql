@@ -80,4 +80,562 @@ CREATE TABLE password_reset_tokens
     KEY (email)
 ) ENGINE = InnoDB
   DEFAULT CHARSET = latin1
-  COLLATE = latin1_general_ci;
\ No newline at end of file
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.06
+-- Description: Add product owner
+ALTER TABLE products
+    ADD COLUMN created_by CHAR(36) NULL AFTER price,
+    ADD FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL;
+
+-- Version: 1.07
+-- Description: Add product description and full text search index
+ALTER TABLE products
+    ADD COLUMN description VARCHAR(1000) NOT NULL DEFAULT '' AFTER name,
+    ADD FULLTEXT INDEX products_search_idx (name, description);
+
+-- Version: 1.08
+-- Description: Add product sku, category and stock
+ALTER TABLE products
+    ADD COLUMN sku VARCHAR(64) NULL AFTER id,
+    ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '' AFTER description,
+    ADD COLUMN stock INT NOT NULL DEFAULT 0 AFTER price,
+    ADD UNIQUE INDEX products_sku_idx (sku);
+
+-- Version: 1.09
+-- Description: Create table price_lists
+CREATE TABLE price_lists
+(
+    id         CHAR(36)     NOT NULL,
+    name       VARCHAR(250) NOT NULL,
+    currency   CHAR(3)      NOT NULL,
+    priority   INT          NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP(6) NULL,
+    valid_to   TIMESTAMP(6) NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.10
+-- Description: Create table price_list_items
+CREATE TABLE price_list_items
+(
+    price_list_id CHAR(36)       NOT NULL,
+    product_id    CHAR(36)       NOT NULL,
+    min_quantity  INT            NOT NULL DEFAULT 1,
+    price         NUMERIC(10, 2) NOT NULL,
+
+    PRIMARY KEY (price_list_id, product_id, min_quantity),
+    FOREIGN KEY (price_list_id) REFERENCES price_lists (id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.11
+-- Description: Create table price_list_users
+CREATE TABLE price_list_users
+(
+    price_list_id CHAR(36) NOT NULL,
+    user_id       CHAR(36) NOT NULL,
+
+    PRIMARY KEY (price_list_id, user_id),
+    FOREIGN KEY (price_list_id) REFERENCES price_lists (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.12
+-- Description: Create table price_list_groups
+CREATE TABLE price_list_groups
+(
+    price_list_id  CHAR(36)     NOT NULL,
+    customer_group VARCHAR(100) NOT NULL,
+
+    PRIMARY KEY (price_list_id, customer_group),
+    FOREIGN KEY (price_list_id) REFERENCES price_lists (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.13
+-- Description: Create table customer_group_members
+CREATE TABLE customer_group_members
+(
+    customer_group VARCHAR(100) NOT NULL,
+    user_id        CHAR(36)     NOT NULL,
+
+    PRIMARY KEY (customer_group, user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.14
+-- Description: Record the price list applied to sale items
+ALTER TABLE sale_items
+    ADD COLUMN price_list_id CHAR(36) NULL AFTER unity_price,
+    ADD FOREIGN KEY (price_list_id) REFERENCES price_lists (id) ON DELETE SET NULL;
+
+-- Version: 1.15
+-- Description: Add product kind and bundle pricing
+ALTER TABLE products
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'simple' AFTER category,
+    ADD COLUMN bundle_pricing VARCHAR(10) NULL AFTER kind,
+    ADD COLUMN bundle_discount NUMERIC(5, 2) NOT NULL DEFAULT 0 AFTER bundle_pricing;
+
+-- Version: 1.16
+-- Description: Create table product_components
+CREATE TABLE product_components
+(
+    bundle_id  CHAR(36) NOT NULL,
+    product_id CHAR(36) NOT NULL,
+    quantity   INT      NOT NULL,
+
+    PRIMARY KEY (bundle_id, product_id),
+    FOREIGN KEY (bundle_id) REFERENCES products (id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE RESTRICT
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.17
+-- Description: Create table sale_item_components
+CREATE TABLE sale_item_components
+(
+    sale_id    CHAR(36)       NOT NULL,
+    bundle_id  CHAR(36)       NOT NULL,
+    product_id CHAR(36)       NOT NULL,
+    quantity   INT            NOT NULL,
+    amount     NUMERIC(10, 2) NOT NULL,
+
+    PRIMARY KEY (sale_id, bundle_id, product_id),
+    FOREIGN KEY (sale_id, bundle_id) REFERENCES sale_items (sale_id, product_id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.18
+-- Description: Add product cost
+ALTER TABLE products
+    ADD COLUMN cost NUMERIC(10, 2) NOT NULL DEFAULT 0 AFTER price;
+
+-- Version: 1.19
+-- Description: Create table product_costs
+CREATE TABLE product_costs
+(
+    product_id     CHAR(36)       NOT NULL,
+    cost           NUMERIC(10, 2) NOT NULL,
+    effective_from TIMESTAMP(6)   NOT NULL,
+
+    PRIMARY KEY (product_id, effective_from),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.20
+-- Description: Record the unit cost of sale items
+ALTER TABLE sale_items
+    ADD COLUMN unit_cost NUMERIC(10, 2) NOT NULL DEFAULT 0 AFTER unity_price;
+
+-- Version: 1.21
+-- Description: Create table product_images
+CREATE TABLE product_images
+(
+    id           CHAR(36)     NOT NULL,
+    product_id   CHAR(36)     NOT NULL,
+    position     INT          NOT NULL,
+    content_type VARCHAR(50)  NOT NULL,
+    size         INT          NOT NULL,
+    width        INT          NOT NULL,
+    height       INT          NOT NULL,
+    hash         CHAR(64)     NOT NULL,
+    created_at   TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (product_id, hash),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.22
+-- Description: Add product unit of measure and decimal stock
+ALTER TABLE products
+    ADD COLUMN unit VARCHAR(10) NOT NULL DEFAULT 'piece' AFTER cost,
+    MODIFY COLUMN stock NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    ADD COLUMN max_quantity NUMERIC(12, 3) NOT NULL DEFAULT 0 AFTER stock;
+
+-- Version: 1.23
+-- Description: Allow decimal sale item quantities with a unit
+ALTER TABLE sale_items
+    MODIFY COLUMN quantity NUMERIC(12, 3) NOT NULL,
+    ADD COLUMN unit VARCHAR(10) NOT NULL DEFAULT 'piece' AFTER quantity;
+
+-- Version: 1.24
+-- Description: Create table exchange_rates
+CREATE TABLE exchange_rates
+(
+    from_currency  CHAR(3)        NOT NULL,
+    to_currency    CHAR(3)        NOT NULL,
+    rate           NUMERIC(18, 8) NOT NULL,
+    effective_from TIMESTAMP(6)   NOT NULL,
+    created_at     TIMESTAMP(6)   NOT NULL,
+
+    PRIMARY KEY (from_currency, to_currency, effective_from)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.25
+-- Description: Add product currency
+ALTER TABLE products
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER cost;
+
+-- Version: 1.26
+-- Description: Add sale currency
+ALTER TABLE sales
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER user_id;
+
+-- Version: 1.27
+-- Description: Record the exchange rate applied to sale items
+ALTER TABLE sale_items
+    ADD COLUMN exchange_rate NUMERIC(18, 8) NOT NULL DEFAULT 1 AFTER price_list_id;
+
+-- Version: 1.28
+-- Description: Create table locations
+CREATE TABLE locations
+(
+    id         CHAR(36)     NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    address    VARCHAR(255) NOT NULL DEFAULT '',
+    timezone   VARCHAR(64)  NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMP(6) NOT NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (name)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.29
+-- Description: Create table user_locations
+CREATE TABLE user_locations
+(
+    location_id CHAR(36) NOT NULL,
+    user_id     CHAR(36) NOT NULL,
+
+    PRIMARY KEY (location_id, user_id),
+    FOREIGN KEY (location_id) REFERENCES locations (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.30
+-- Description: Add sale location
+ALTER TABLE sales
+    ADD COLUMN location_id CHAR(36) NULL AFTER currency,
+    ADD FOREIGN KEY (location_id) REFERENCES locations (id);
+
+-- Version: 1.31
+-- Description: Create table location_stock
+CREATE TABLE location_stock
+(
+    product_id  CHAR(36)       NOT NULL,
+    location_id CHAR(36)       NOT NULL,
+    stock       NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMP(6)   NOT NULL,
+
+    PRIMARY KEY (product_id, location_id),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    FOREIGN KEY (location_id) REFERENCES locations (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.32
+-- Description: Add user lockout
+ALTER TABLE users
+    ADD COLUMN failed_logins INT          NOT NULL DEFAULT 0 AFTER refresh_token,
+    ADD COLUMN locked_until  TIMESTAMP(6) NULL AFTER failed_logins;
+
+-- Version: 1.33
+-- Description: Create table login_failures
+CREATE TABLE login_failures
+(
+    ip           VARCHAR(45)  NOT NULL,
+    failures     INT          NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP(6) NULL,
+    updated_at   TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (ip)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.34
+-- Description: Create table audits
+CREATE TABLE audits
+(
+    id         CHAR(36)     NOT NULL,
+    obj_id     CHAR(36)     NOT NULL,
+    obj_domain VARCHAR(50)  NOT NULL,
+    action     VARCHAR(50)  NOT NULL,
+    data       JSON         NULL,
+    message    VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id),
+    INDEX (obj_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.35
+-- Description: Create table user_mfa
+CREATE TABLE user_mfa
+(
+    user_id    CHAR(36)     NOT NULL,
+    secret     VARCHAR(64)  NOT NULL,
+    enabled    BOOLEAN      NOT NULL DEFAULT FALSE,
+    last_step  BIGINT       NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(6) NOT NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.36
+-- Description: Create table mfa_recovery_codes
+CREATE TABLE mfa_recovery_codes
+(
+    user_id   CHAR(36)     NOT NULL,
+    code_hash CHAR(64)     NOT NULL,
+    used_at   TIMESTAMP(6) NULL,
+
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.37
+-- Description: Create table mfa_challenges
+CREATE TABLE mfa_challenges
+(
+    token     VARCHAR(64)  NOT NULL,
+    user_id   CHAR(36)     NOT NULL,
+    attempts  INT          NOT NULL DEFAULT 0,
+    expiry_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (token),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.38
+-- Description: Create table sessions
+CREATE TABLE sessions
+(
+    id           CHAR(36)     NOT NULL,
+    family_id    CHAR(36)     NOT NULL,
+    user_id      CHAR(36)     NOT NULL,
+    token_hash   CHAR(64)     NOT NULL,
+    user_agent   VARCHAR(255) NOT NULL DEFAULT '',
+    ip           VARCHAR(45)  NOT NULL DEFAULT '',
+    created_at   TIMESTAMP(6) NOT NULL,
+    last_used_at TIMESTAMP(6) NOT NULL,
+    expires_at   TIMESTAMP(6) NOT NULL,
+    rotated_at   TIMESTAMP(6) NULL,
+    revoked_at   TIMESTAMP(6) NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (token_hash),
+    INDEX (family_id),
+    INDEX (user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.39
+-- Description: Drop users refresh token in favour of sessions
+ALTER TABLE users
+    DROP COLUMN refresh_token;
+
+-- Version: 1.40
+-- Description: Create table revoked_tokens
+CREATE TABLE revoked_tokens
+(
+    id         CHAR(36)     NOT NULL,
+    user_id    CHAR(36)     NOT NULL,
+    expires_at TIMESTAMP(6) NOT NULL,
+    revoked_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id),
+    INDEX (expires_at)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.41
+-- Description: Create table user_token_revocations
+CREATE TABLE user_token_revocations
+(
+    user_id        CHAR(36)     NOT NULL,
+    revoked_before TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.42
+-- Description: Create table roles
+CREATE TABLE roles
+(
+    id          CHAR(36)     NOT NULL,
+    name        VARCHAR(50)  NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    is_system   BOOLEAN      NOT NULL DEFAULT false,
+    created_at  TIMESTAMP(6) NOT NULL,
+    updated_at  TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (name)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.43
+-- Description: Create table role_permissions
+CREATE TABLE role_permissions
+(
+    role_id    CHAR(36)     NOT NULL,
+    permission VARCHAR(101) NOT NULL,
+
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.44
+-- Description: Create table user_roles
+CREATE TABLE user_roles
+(
+    user_id    CHAR(36)     NOT NULL,
+    role_id    CHAR(36)     NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (user_id, role_id),
+    INDEX (role_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.45
+-- Description: Add the system roles stored with each user
+INSERT INTO roles (id, name, description, is_system, created_at, updated_at) VALUES
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f01', 'admin', 'Administrators', true, NOW(6), NOW(6)),
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f02', 'user', 'Users', true, NOW(6), NOW(6));
+
+-- Version: 1.46
+-- Description: Add the permissions of the system roles
+INSERT INTO role_permissions (role_id, permission) VALUES
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f01', '*'),
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f02', 'sales:create');
+
+-- Version: 1.47
+-- Description: Create table oauth_states
+CREATE TABLE oauth_states
+(
+    state     VARCHAR(64)  NOT NULL,
+    provider  VARCHAR(50)  NOT NULL,
+    session   TEXT         NOT NULL,
+    expiry_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (state)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.48
+-- Description: Create table user_identities
+CREATE TABLE user_identities
+(
+    provider     VARCHAR(50)  NOT NULL,
+    subject      VARCHAR(255) NOT NULL,
+    user_id      CHAR(36)     NOT NULL,
+    email        VARCHAR(255) NOT NULL,
+    created_at   TIMESTAMP(6) NOT NULL,
+    last_used_at TIMESTAMP(6) NOT NULL,
+
+    PRIMARY KEY (provider, subject),
+    INDEX (user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.49
+-- Description: Create table oauth_clients
+CREATE TABLE oauth_clients
+(
+    id          CHAR(36)      NOT NULL,
+    name        VARCHAR(100)  NOT NULL,
+    secret_hash CHAR(64)      NOT NULL,
+    scopes      VARCHAR(1000) NOT NULL,
+    owner_id    CHAR(36)      NOT NULL,
+    created_at  TIMESTAMP(6)  NOT NULL,
+
+    PRIMARY KEY (id),
+    INDEX (owner_id),
+    FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.50
+-- Description: Create table api_keys
+CREATE TABLE api_keys
+(
+    id           CHAR(36)      NOT NULL,
+    user_id      CHAR(36)      NOT NULL,
+    name         VARCHAR(100)  NOT NULL,
+    prefix       VARCHAR(16)   NOT NULL,
+    key_hash     CHAR(64)      NOT NULL,
+    scopes       VARCHAR(1000) NOT NULL,
+    expires_at   TIMESTAMP(6)  NULL,
+    last_used_at TIMESTAMP(6)  NULL,
+    created_at   TIMESTAMP(6)  NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE INDEX (prefix),
+    INDEX (user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = latin1
+  COLLATE = latin1_general_ci;
+
+-- Version: 1.51
+-- Description: Grant the user role the product permissions it had by role
+INSERT INTO role_permissions (role_id, permission) VALUES
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f02', 'products:read'),
+    ('0b6c9d43-3f2b-4e0a-9a37-2d1c8c6a6f02', 'products:write');
@@ -97,40 +97,49 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 // ExecContext is a helper function to execute a CUD operation with
 // logging and tracing.
 func ExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string) error {
-	return NamedExecContext(ctx, log, db, query, struct{}{})
+	_, err := namedExecContext(ctx, log, db, query, struct{}{})
+	return err
 }
 
 // NamedExecContext is a helper function to execute a CUD operation with
 // logging and tracing where field replacement is necessary.
-func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (err error) {
+func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
+	_, err := namedExecContext(ctx, log, db, query, data)
+	return err
+}
+
+// NamedExecContextResult is a helper function to execute a CUD operation
+// with logging and tracing where field replacement is necessary. The result
+// is returned for callers that need the rows affected or the last insert id.
+func NamedExecContextResult(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (sql.Result, error) {
+	return namedExecContext(ctx, log, db, query, data)
+}
+
+func namedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (result sql.Result, err error) {
 	q := queryString(query, data)
 
 	defer func() {
 		if err != nil {
-			switch data.(type) {
-			case struct{}:
-				log.Infoc(ctx, 6, "database.NamedExecContext", "query", q, "ERROR", err)
-			default:
-				log.Infoc(ctx, 5, "database.NamedExecContext", "query", q, "ERROR", err)
-			}
+			log.Infoc(ctx, 6, "database.NamedExecContext", "query", q, "ERROR", err)
 		}
 	}()
 
 	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.exec", attribute.String("query", q))
 	defer span.End()
 
-	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
+	result, err = sqlx.NamedExecContext(ctx, db, query, data)
+	if err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) {
 			switch mysqlErr.Number {
 			case 1062:
-				return ErrDBDuplicatedEntry
+				return nil, ErrDBDuplicatedEntry
 			}
 		}
-		return err
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
 // QuerySlice is a helper function for executing queries that return a
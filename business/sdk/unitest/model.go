@@ -4,7 +4,12 @@ import (
 	"context"
 
 	"github.com/rmsj/service/business/domain/authbus"
+	"github.com/rmsj/service/business/domain/imagebus"
+	"github.com/rmsj/service/business/domain/locationbus"
+	"github.com/rmsj/service/business/domain/pricebus"
 	"github.com/rmsj/service/business/domain/productbus"
+	"github.com/rmsj/service/business/domain/ratebus"
+	"github.com/rmsj/service/business/domain/rolebus"
 	"github.com/rmsj/service/business/domain/salebus"
 	"github.com/rmsj/service/business/domain/userbus"
 )
@@ -20,6 +25,11 @@ type SeedData struct {
 	Admins          []User
 	Products        []productbus.Product
 	Sales           []salebus.Sale
+	PriceLists      []pricebus.PriceList
+	Images          []imagebus.Image
+	Rates           []ratebus.Rate
+	Locations       []locationbus.Location
+	Roles           []rolebus.Role
 	PassResetTokens []authbus.PasswordResetToken
 }
 
@@ -0,0 +1,59 @@
+// Package currency represents a currency code in the system.
+package currency
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default is the currency used when none is specified.
+var Default = MustParse("USD")
+
+// Currency represents an ISO 4217 currency code in the system.
+type Currency struct {
+	value string
+}
+
+// String returns the value of the currency.
+func (c Currency) String() string {
+	return c.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (c Currency) Equal(c2 Currency) bool {
+	return c.value == c2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (c Currency) MarshalText() ([]byte, error) {
+	return []byte(c.value), nil
+}
+
+// =============================================================================
+
+var currencyRegEx = regexp.MustCompile("^[A-Z]{3}$")
+
+// Parse parses the string value and returns a currency if the value complies
+// with the rules for a currency code. Lower case codes are accepted.
+func Parse(value string) (Currency, error) {
+	value = strings.ToUpper(value)
+
+	if !currencyRegEx.MatchString(value) {
+		return Currency{}, fmt.Errorf("invalid currency %q", value)
+	}
+
+	return Currency{value}, nil
+}
+
+// MustParse parses the string value and returns a currency if the value
+// complies with the rules for a currency. If an error occurs the function
+// panics.
+func MustParse(value string) Currency {
+	c, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
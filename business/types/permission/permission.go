@@ -0,0 +1,134 @@
+// Package permission represents a permission granted by a role.
+package permission
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The set of permissions checked by the system. Roles may grant others.
+var (
+	All            = MustParse("*")
+	SalesRead      = MustParse("sales:read")
+	SalesCreate    = MustParse("sales:create")
+	SalesReport    = MustParse("sales:report")
+	SalesManage    = MustParse("sales:manage")
+	ProductsRead   = MustParse("products:read")
+	ProductsWrite  = MustParse("products:write")
+	ProductsManage = MustParse("products:manage")
+	ProductsImport = MustParse("products:import")
+
+	UsersRead  = MustParse("users:read")
+	UsersWrite = MustParse("users:write")
+
+	LocationsWrite = MustParse("locations:write")
+
+	PriceListsRead  = MustParse("pricelists:read")
+	PriceListsWrite = MustParse("pricelists:write")
+
+	RatesWrite = MustParse("rates:write")
+
+	RolesRead   = MustParse("roles:read")
+	RolesWrite  = MustParse("roles:write")
+	RolesAssign = MustParse("roles:assign")
+
+	TokensIntrospect = MustParse("tokens:introspect")
+)
+
+// Permission represents an action on a resource, written as resource:action.
+// The action can be * to grant every action on the resource and the
+// permission * grants every action on every resource.
+type Permission struct {
+	value string
+}
+
+// String returns the value of the permission.
+func (p Permission) String() string {
+	return p.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Permission) Equal(p2 Permission) bool {
+	return p.value == p2.value
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (p Permission) MarshalText() ([]byte, error) {
+	return []byte(p.value), nil
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (p *Permission) UnmarshalText(data []byte) error {
+	perm, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	p.value = perm.value
+	return nil
+}
+
+// Grants reports whether the permission grants the wanted permission.
+func (p Permission) Grants(want Permission) bool {
+	if p.value == All.value || p.value == want.value {
+		return true
+	}
+
+	resource, action, _ := strings.Cut(p.value, ":")
+	wantResource, _, _ := strings.Cut(want.value, ":")
+
+	return action == "*" && resource == wantResource
+}
+
+// =============================================================================
+
+var permissionRegEx = regexp.MustCompile(`^(\*|[a-z][a-z_]{0,49}:(\*|[a-z][a-z_]{0,49}))$`)
+
+// Parse parses the string value and returns a permission if the value
+// complies with the rules for a permission.
+func Parse(value string) (Permission, error) {
+	if !permissionRegEx.MatchString(value) {
+		return Permission{}, fmt.Errorf("invalid permission %q", value)
+	}
+
+	return Permission{value}, nil
+}
+
+// MustParse parses the string value and returns a permission if the value
+// complies with the rules for a permission. If an error occurs the function
+// panics.
+func MustParse(value string) Permission {
+	perm, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return perm
+}
+
+// ParseMany takes a collection of strings and converts them to a slice
+// of permissions.
+func ParseMany(values []string) ([]Permission, error) {
+	perms := make([]Permission, len(values))
+	for i, value := range values {
+		perm, err := Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		perms[i] = perm
+	}
+
+	return perms, nil
+}
+
+// ParseToString takes a collection of permissions and converts them to a
+// slice of string.
+func ParseToString(perms []Permission) []string {
+	values := make([]string, len(perms))
+	for i, perm := range perms {
+		values[i] = perm.String()
+	}
+
+	return values
+}
@@ -0,0 +1,52 @@
+package permission_test
+
+import (
+	"testing"
+
+	"github.com/rmsj/service/business/types/permission"
+)
+
+func Test_Permission(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse", parse)
+	t.Run("grants", grants)
+}
+
+// =============================================================================
+
+func parse(t *testing.T) {
+	for _, value := range []string{"*", "sales:create", "sales:*", "store_stock:adjust"} {
+		if _, err := permission.Parse(value); err != nil {
+			t.Errorf("Should be able to parse %q: %s", value, err)
+		}
+	}
+
+	for _, value := range []string{"", "sales", "sales:", ":create", "*:create", "Sales:create", "sales:create:all"} {
+		if _, err := permission.Parse(value); err == nil {
+			t.Errorf("Should not be able to parse %q", value)
+		}
+	}
+}
+
+func grants(t *testing.T) {
+	tests := []struct {
+		perm string
+		want string
+		exp  bool
+	}{
+		{"*", "sales:create", true},
+		{"sales:create", "sales:create", true},
+		{"sales:*", "sales:report", true},
+		{"sales:create", "sales:report", false},
+		{"sales:*", "products:import", false},
+		{"products:import", "*", false},
+	}
+
+	for _, tt := range tests {
+		got := permission.MustParse(tt.perm).Grants(permission.MustParse(tt.want))
+		if got != tt.exp {
+			t.Errorf("Should get %t for %q granting %q, got %t", tt.exp, tt.perm, tt.want, got)
+		}
+	}
+}
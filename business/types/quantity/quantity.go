@@ -2,27 +2,48 @@
 package quantity
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 )
 
-// Quantity represents a quantity in the system.
+// Decimals is the number of decimal places a quantity is kept with.
+const Decimals = 3
+
+// ErrIncompatibleUnits is returned when converting between units of
+// different dimensions, like kilograms and litres.
+var ErrIncompatibleUnits = errors.New("incompatible units")
+
+// Quantity represents a decimal quantity of a unit in the system.
 type Quantity struct {
-	value int
+	value float64
+	unit  Unit
 }
 
-// Value returns the int value of the quantity.
-func (q Quantity) Value() int {
+// Value returns the decimal value of the quantity.
+func (q Quantity) Value() float64 {
 	return q.value
 }
 
-// String returns the value of the quantity.
+// Unit returns the unit of the quantity.
+func (q Quantity) Unit() Unit {
+	return q.unit
+}
+
+// IsZero reports whether the quantity is zero.
+func (q Quantity) IsZero() bool {
+	return q.value == 0
+}
+
+// String returns the value of the quantity along with its unit.
 func (q Quantity) String() string {
-	return fmt.Sprintf("%d", q.value)
+	return fmt.Sprintf("%s %s", strconv.FormatFloat(q.value, 'f', -1, 64), q.unit)
 }
 
 // Equal provides support for the go-cmp package and testing.
 func (q Quantity) Equal(q2 Quantity) bool {
-	return q.value == q2.value
+	return q.value == q2.value && q.unit.Equal(q2.unit)
 }
 
 // MarshalText provides support for logging and any marshal needs.
@@ -30,25 +51,69 @@ func (q Quantity) MarshalText() ([]byte, error) {
 	return []byte(q.String()), nil
 }
 
+// Convert returns the quantity expressed in the specified unit. Converting to
+// a unit that must be whole fails when the result is fractional.
+func (q Quantity) Convert(to Unit) (Quantity, error) {
+	if q.unit.Equal(to) {
+		return q, nil
+	}
+
+	if !q.unit.Compatible(to) {
+		return Quantity{}, fmt.Errorf("%s to %s: %w", q.unit, to, ErrIncompatibleUnits)
+	}
+
+	return Parse(round(q.value*q.unit.factor/to.factor), to)
+}
+
+// Sub returns the quantity minus the specified one, which is converted to the
+// unit of the quantity first. The result can not be negative.
+func (q Quantity) Sub(q2 Quantity) (Quantity, error) {
+	q2, err := q2.Convert(q.unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	return Parse(round(q.value-q2.value), q.unit)
+}
+
 // =============================================================================
 
-// Parse parses the float value and returns a quantity if the value complies
-// with the rules for quantity.
-func Parse(value int) (Quantity, error) {
+// Parse parses the decimal value and returns a quantity of the unit if the
+// value complies with the rules for quantity.
+func Parse(value float64, unit Unit) (Quantity, error) {
+	if _, exists := units[unit.value]; !exists {
+		return Quantity{}, fmt.Errorf("invalid unit %q", unit.value)
+	}
+
 	if value < 0 || value > 1_000_000 {
-		return Quantity{}, fmt.Errorf("invalid quantity %d", value)
+		return Quantity{}, fmt.Errorf("invalid quantity %v", value)
 	}
 
-	return Quantity{value}, nil
+	if round(value) != value {
+		return Quantity{}, fmt.Errorf("invalid quantity %v: at most %d decimals", value, Decimals)
+	}
+
+	if unit.Whole() && math.Trunc(value) != value {
+		return Quantity{}, fmt.Errorf("invalid quantity %v: %s must be whole", value, unit)
+	}
+
+	return Quantity{value, unit}, nil
 }
 
-// MustParse parses the string value and returns a quantity if the value
-// complies with the rules for a quantity. If an error occurs the function panics.
-func MustParse(value int) Quantity {
-	money, err := Parse(value)
+// MustParse parses the decimal value and returns a quantity if the value
+// complies with the rules for a quantity. If an error occurs the function
+// panics.
+func MustParse(value float64, unit Unit) Quantity {
+	qty, err := Parse(value, unit)
 	if err != nil {
 		panic(err)
 	}
 
-	return money
+	return qty
+}
+
+// round rounds the value to the decimals a quantity is kept with.
+func round(value float64) float64 {
+	p := math.Pow10(Decimals)
+	return math.Round(value*p) / p
 }
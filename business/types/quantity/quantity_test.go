@@ -0,0 +1,78 @@
+package quantity_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rmsj/service/business/types/quantity"
+)
+
+func Test_Quantity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse", parse)
+	t.Run("convert", convert)
+	t.Run("sub", sub)
+}
+
+// =============================================================================
+
+func parse(t *testing.T) {
+	if _, err := quantity.Parse(2.5, quantity.Kilogram); err != nil {
+		t.Errorf("Should be able to parse a fractional weight: %s", err)
+	}
+
+	if _, err := quantity.Parse(250, quantity.Piece); err != nil {
+		t.Errorf("Should be able to parse a large number of pieces: %s", err)
+	}
+
+	if _, err := quantity.Parse(1.5, quantity.Piece); err == nil {
+		t.Errorf("Should not be able to parse fractional pieces")
+	}
+
+	if _, err := quantity.Parse(0.0005, quantity.Kilogram); err == nil {
+		t.Errorf("Should not be able to parse more than %d decimals", quantity.Decimals)
+	}
+
+	if _, err := quantity.Parse(-1, quantity.Litre); err == nil {
+		t.Errorf("Should not be able to parse a negative quantity")
+	}
+
+	if _, err := quantity.Parse(1, quantity.Unit{}); err == nil {
+		t.Errorf("Should not be able to parse a quantity without a unit")
+	}
+}
+
+func convert(t *testing.T) {
+	got, err := quantity.MustParse(750, quantity.Gram).Convert(quantity.Kilogram)
+	if err != nil {
+		t.Fatalf("Should be able to convert grams to kilograms: %s", err)
+	}
+
+	if exp := quantity.MustParse(0.75, quantity.Kilogram); !got.Equal(exp) {
+		t.Errorf("Should get the quantity in kilograms: got %s, exp %s", got, exp)
+	}
+
+	if _, err := quantity.MustParse(1.5, quantity.Kilogram).Convert(quantity.Piece); !errors.Is(err, quantity.ErrIncompatibleUnits) {
+		t.Errorf("Should not be able to convert kilograms to pieces: %v", err)
+	}
+
+	if _, err := quantity.MustParse(1, quantity.Kilogram).Convert(quantity.Litre); !errors.Is(err, quantity.ErrIncompatibleUnits) {
+		t.Errorf("Should not be able to convert kilograms to litres: %v", err)
+	}
+}
+
+func sub(t *testing.T) {
+	got, err := quantity.MustParse(2, quantity.Kilogram).Sub(quantity.MustParse(250, quantity.Gram))
+	if err != nil {
+		t.Fatalf("Should be able to subtract grams from kilograms: %s", err)
+	}
+
+	if exp := quantity.MustParse(1.75, quantity.Kilogram); !got.Equal(exp) {
+		t.Errorf("Should get the result in kilograms: got %s, exp %s", got, exp)
+	}
+
+	if _, err := quantity.MustParse(1, quantity.Kilogram).Sub(quantity.MustParse(2, quantity.Kilogram)); err == nil {
+		t.Errorf("Should not be able to go below zero")
+	}
+}
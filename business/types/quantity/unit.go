@@ -0,0 +1,93 @@
+package quantity
+
+import (
+	"fmt"
+)
+
+// The set of units that can be used. Units of the same dimension can be
+// converted into each other.
+var (
+	Piece    = newUnit("piece", "count", 1)
+	Kilogram = newUnit("kg", "mass", 1000)
+	Gram     = newUnit("g", "mass", 1)
+	Litre    = newUnit("l", "volume", 1)
+	Metre    = newUnit("m", "length", 1)
+)
+
+// =============================================================================
+
+// Set of known units.
+var units = make(map[string]Unit)
+
+// Unit represents a unit of measure in the system. Factor is how many of the
+// base unit of the dimension make up one of this unit.
+type Unit struct {
+	value     string
+	dimension string
+	factor    float64
+}
+
+func newUnit(unit string, dimension string, factor float64) Unit {
+	u := Unit{unit, dimension, factor}
+	units[unit] = u
+	return u
+}
+
+// String returns the name of the unit.
+func (u Unit) String() string {
+	return u.value
+}
+
+// Whole reports whether quantities in the unit must be whole numbers.
+func (u Unit) Whole() bool {
+	return u.dimension == "count"
+}
+
+// Compatible reports whether quantities can be converted between the units.
+func (u Unit) Compatible(u2 Unit) bool {
+	return u.dimension == u2.dimension
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (u *Unit) UnmarshalText(data []byte) error {
+	unit, err := ParseUnit(string(data))
+	if err != nil {
+		return err
+	}
+
+	*u = unit
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (u Unit) MarshalText() ([]byte, error) {
+	return []byte(u.value), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (u Unit) Equal(u2 Unit) bool {
+	return u.value == u2.value
+}
+
+// =============================================================================
+
+// ParseUnit parses the string value and returns a unit if one exists.
+func ParseUnit(value string) (Unit, error) {
+	unit, exists := units[value]
+	if !exists {
+		return Unit{}, fmt.Errorf("invalid unit %q", value)
+	}
+
+	return unit, nil
+}
+
+// MustParseUnit parses the string value and returns a unit if one exists. If
+// an error occurs the function panics.
+func MustParseUnit(value string) Unit {
+	unit, err := ParseUnit(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return unit
+}
@@ -0,0 +1,75 @@
+// Package timezone represents a time zone in the system.
+package timezone
+
+import (
+	"fmt"
+	"time"
+
+	// The zone database is embedded so time zones resolve on hosts that do
+	// not ship one.
+	_ "time/tzdata"
+)
+
+// Default is the time zone used when none is specified.
+var Default = MustParse("UTC")
+
+// Timezone represents an IANA time zone in the system.
+type Timezone struct {
+	loc *time.Location
+}
+
+// String returns the name of the time zone.
+func (tz Timezone) String() string {
+	if tz.loc == nil {
+		return ""
+	}
+
+	return tz.loc.String()
+}
+
+// Location returns the time zone as a location for use with time values.
+func (tz Timezone) Location() *time.Location {
+	if tz.loc == nil {
+		return time.UTC
+	}
+
+	return tz.loc
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (tz Timezone) Equal(tz2 Timezone) bool {
+	return tz.String() == tz2.String()
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (tz Timezone) MarshalText() ([]byte, error) {
+	return []byte(tz.String()), nil
+}
+
+// =============================================================================
+
+// Parse parses the string value and returns a time zone if the value is the
+// name of a time zone in the IANA database, like America/New_York.
+func Parse(value string) (Timezone, error) {
+	if value == "" || value == "Local" {
+		return Timezone{}, fmt.Errorf("invalid time zone %q", value)
+	}
+
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return Timezone{}, fmt.Errorf("invalid time zone %q", value)
+	}
+
+	return Timezone{loc}, nil
+}
+
+// MustParse parses the string value and returns a time zone if the value is
+// a valid time zone. If an error occurs the function panics.
+func MustParse(value string) Timezone {
+	tz, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return tz
+}
@@ -0,0 +1,34 @@
+// Package blob provides support for storing and retrieving binary objects by
+// key. The Store interface mirrors the shape of S3 compatible object stores
+// so a remote implementation can replace the local one without changes to
+// the callers.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Set of error variables for blob operations.
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob key not valid")
+)
+
+// Info describes a stored object.
+type Info struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store declares the behavior required to store and retrieve objects. Keys
+// are slash separated paths such as "products/<id>/<hash>.png".
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,128 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores objects as files under a root directory.
+type Local struct {
+	root string
+}
+
+// NewLocal constructs a store that keeps objects under the specified root
+// directory, creating it when it does not exist.
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+
+	return &Local{root: root}, nil
+}
+
+// Put writes the object under the specified key, replacing any existing one.
+// The object is written to a temporary file first so readers never see a
+// partially written object. The content type is derived from the key
+// extension when reading the object back.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("write: key[%s]: %w", key, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close: key[%s]: %w", key, err)
+	}
+
+	if err := os.Rename(f.Name(), name); err != nil {
+		return fmt.Errorf("rename: key[%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+// Get opens the object stored under the specified key. The caller must close
+// the returned reader.
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, Info{}, fmt.Errorf("key[%s]: %w", key, ErrNotFound)
+		}
+		return nil, Info{}, fmt.Errorf("open: key[%s]: %w", key, err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Info{}, fmt.Errorf("stat: key[%s]: %w", key, err)
+	}
+
+	info := Info{
+		Key:         key,
+		Size:        fi.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     fi.ModTime(),
+	}
+
+	return f, info, nil
+}
+
+// Delete removes the object stored under the specified key. Deleting an
+// object that does not exist is not an error, as with S3.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove: key[%s]: %w", key, err)
+	}
+
+	return nil
+}
+
+// path maps the key to a file under the root, rejecting keys that would
+// escape it.
+func (l *Local) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key {
+		return "", fmt.Errorf("key[%s]: %w", key, ErrInvalidKey)
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return "", fmt.Errorf("key[%s]: %w", key, ErrInvalidKey)
+		}
+	}
+
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,74 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rmsj/service/foundation/blob"
+)
+
+func Test_Local(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct the store: %s", err)
+	}
+
+	const key = "products/1234/abcd.png"
+
+	if err := store.Put(ctx, key, strings.NewReader("image bytes"), "image/png"); err != nil {
+		t.Fatalf("Should be able to put the object: %s", err)
+	}
+
+	r, info, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Should be able to get the object: %s", err)
+	}
+
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("Should be able to read the object: %s", err)
+	}
+
+	if string(data) != "image bytes" {
+		t.Errorf("Should get the stored bytes: got %q", data)
+	}
+
+	if info.Size != int64(len("image bytes")) || info.ContentType != "image/png" {
+		t.Errorf("Should get the object info: got %+v", info)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Should be able to delete the object: %s", err)
+	}
+
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("Should not find a deleted object: got %v", err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Should be able to delete a missing object: %s", err)
+	}
+}
+
+func Test_LocalInvalidKey(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct the store: %s", err)
+	}
+
+	keys := []string{"", "/etc/passwd", "../outside", "products/../../outside", "products//double", "products/./dot"}
+
+	for _, key := range keys {
+		if err := store.Put(ctx, key, strings.NewReader("x"), ""); !errors.Is(err, blob.ErrInvalidKey) {
+			t.Errorf("Should reject key %q: got %v", key, err)
+		}
+	}
+}
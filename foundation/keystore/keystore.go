@@ -4,6 +4,9 @@ package keystore
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -14,24 +17,35 @@ import (
 	"io/fs"
 	"path"
 	"strings"
+	"sync"
+	"time"
 )
 
 // key represents key information.
 type key struct {
-	privatePEM string
-	publicPEM  string
+	privatePEM  string
+	publicPEM   string
+	activatesAt time.Time
+	retiresAt   time.Time
 }
 
 // KeyStore represents an in memory store implementation of the
-// KeyLookup interface for use with the auth package.
+// KeyLookup interface for use with the auth package. Keys loaded from a
+// file system can carry a manifest describing when each key starts signing
+// and when it is retired, which allows keys to be rotated.
 type KeyStore struct {
-	store map[string]key
+	mu       sync.RWMutex
+	store    map[string]key
+	document string
+	fsys     fs.FS
+	now      func() time.Time
 }
 
 // New constructs an empty KeyStore ready for use.
 func New() *KeyStore {
 	return &KeyStore{
 		store: make(map[string]key),
+		now:   time.Now,
 	}
 }
 
@@ -42,15 +56,24 @@ func (ks *KeyStore) LoadByJSON(document string) (int, error) {
 		return 0, nil
 	}
 
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.document = document
+
+	return ks.loadJSON(ks.store, document)
+}
+
+func (ks *KeyStore) loadJSON(store map[string]key, document string) (int, error) {
 	var d struct {
 		Key string `json:"key"`
 		PEM string `json:"pem"`
 	}
 	if err := json.Unmarshal([]byte(document), &d); err != nil {
-		return len(ks.store), fmt.Errorf("unable to marshal document: %w", err)
+		return len(store), fmt.Errorf("unable to marshal document: %w", err)
 	}
 
-	publicPEM, err := toPublicPEM(d.PEM)
+	publicPEM, err := PublicPEM(d.PEM)
 	if err != nil {
 		return 0, fmt.Errorf("converting private PEM to public: %w", err)
 	}
@@ -60,17 +83,63 @@ func (ks *KeyStore) LoadByJSON(document string) (int, error) {
 		publicPEM:  publicPEM,
 	}
 
-	ks.store[d.Key] = key
+	store[d.Key] = key
 
-	return len(ks.store), nil
+	return len(store), nil
 }
 
-// LoadByFileSystem loads a set of RSA PEM files rooted inside of a directory. The
-// name of each PEM file will be used as the key id. The function also returns
-// the total number of keys in the store.
-// Example: ks.LoadRSAKeys(os.DirFS("/zarf/keys/"))
+// LoadByFileSystem loads a set of PEM encoded RSA, ECDSA or Ed25519 private
+// keys rooted inside of a directory. The name of each PEM file will be used
+// as the key id. The function also returns the total number of keys in the
+// store.
+// Example: ks.LoadByFileSystem(os.DirFS("/zarf/keys/"))
 // Example: /zarf/keys/54bb2165-71e1-41a6-af3e-7da4a0e1e2c1.pem
+// When the directory holds a manifest, the key lifetimes are read from it.
 func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.fsys = fsys
+
+	return ks.loadFileSystem(ks.store, fsys)
+}
+
+// Reload rebuilds the store from the sources it was loaded from, picking up
+// keys added, rotated or removed on disk. The store is left untouched if
+// the keys can't be loaded.
+func (ks *KeyStore) Reload() (int, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	store := make(map[string]key)
+
+	if ks.document != "" {
+		if _, err := ks.loadJSON(store, ks.document); err != nil {
+			return 0, err
+		}
+	}
+
+	if ks.fsys != nil {
+		if _, err := ks.loadFileSystem(store, ks.fsys); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(store) == 0 {
+		return 0, errors.New("no keys exist")
+	}
+
+	ks.store = store
+
+	return len(store), nil
+}
+
+func (ks *KeyStore) loadFileSystem(store map[string]key, fsys fs.FS) (int, error) {
+	m, err := readManifest(fsys)
+	if err != nil {
+		return 0, err
+	}
+
 	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("walkdir failure: %w", err)
@@ -99,17 +168,22 @@ func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
 		}
 
 		privatePEM := string(pem)
-		publicPEM, err := toPublicPEM(privatePEM)
+		publicPEM, err := PublicPEM(privatePEM)
 		if err != nil {
 			return fmt.Errorf("converting private PEM to public: %w", err)
 		}
 
+		kid := strings.TrimSuffix(dirEntry.Name(), ".pem")
+		mk := m.find(kid)
+
 		key := key{
-			privatePEM: privatePEM,
-			publicPEM:  publicPEM,
+			privatePEM:  privatePEM,
+			publicPEM:   publicPEM,
+			activatesAt: mk.ActivatesAt,
+			retiresAt:   mk.RetiresAt,
 		}
 
-		ks.store[strings.TrimSuffix(dirEntry.Name(), ".pem")] = key
+		store[kid] = key
 
 		return nil
 	}
@@ -118,14 +192,14 @@ func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
 		return 0, fmt.Errorf("walking directory: %w", err)
 	}
 
-	return len(ks.store), nil
+	return len(store), nil
 }
 
 // PrivateKey searches the key store for a given kid and returns the private key.
 func (ks *KeyStore) PrivateKey(kid string) (string, error) {
-	key, found := ks.store[kid]
-	if !found {
-		return "", errors.New("kid lookup failed")
+	key, err := ks.lookup(kid)
+	if err != nil {
+		return "", err
 	}
 
 	return key.privatePEM, nil
@@ -133,18 +207,87 @@ func (ks *KeyStore) PrivateKey(kid string) (string, error) {
 
 // PublicKey searches the key store for a given kid and returns the public key.
 func (ks *KeyStore) PublicKey(kid string) (string, error) {
+	key, err := ks.lookup(kid)
+	if err != nil {
+		return "", err
+	}
+
+	return key.publicPEM, nil
+}
+
+// PublicKeys returns the public keys that can currently verify tokens,
+// indexed by kid. Keys waiting to be promoted are included so verifiers
+// learn about them before they start signing.
+func (ks *KeyStore) PublicKeys() map[string]string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := ks.now()
+
+	keys := make(map[string]string, len(ks.store))
+	for kid, key := range ks.store {
+		if key.retired(now) {
+			continue
+		}
+		keys[kid] = key.publicPEM
+	}
+
+	return keys
+}
+
+// ActiveKID returns the kid of the key that should sign new tokens, which
+// is the most recently promoted key that is not retired. An empty string is
+// returned when no key has a lifetime in the manifest, leaving the choice
+// to configuration.
+func (ks *KeyStore) ActiveKID() string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := ks.now()
+
+	var activeKID string
+	var activatedAt time.Time
+
+	for kid, key := range ks.store {
+		if key.activatesAt.IsZero() || key.activatesAt.After(now) || key.retired(now) {
+			continue
+		}
+
+		if key.activatesAt.After(activatedAt) || (key.activatesAt.Equal(activatedAt) && kid > activeKID) {
+			activeKID = kid
+			activatedAt = key.activatesAt
+		}
+	}
+
+	return activeKID
+}
+
+func (ks *KeyStore) lookup(kid string) (key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
 	key, found := ks.store[kid]
 	if !found {
-		return "", errors.New("kid lookup failed")
+		return key, errors.New("kid lookup failed")
 	}
 
-	return key.publicPEM, nil
+	if key.retired(ks.now()) {
+		return key, errors.New("kid retired")
+	}
+
+	return key, nil
+}
+
+func (k key) retired(now time.Time) bool {
+	return !k.retiresAt.IsZero() && !k.retiresAt.After(now)
 }
 
-func toPublicPEM(privatePEM string) (string, error) {
+// PublicPEM converts a PEM encoded RSA, ECDSA or Ed25519 private key into
+// the PEM encoded public key.
+func PublicPEM(privatePEM string) (string, error) {
 	block, _ := pem.Decode([]byte(privatePEM))
 	if block == nil {
-		return "", errors.New("invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key")
+		return "", errors.New("invalid key: Key must be a PEM encoded PKCS1, PKCS8 or EC key")
 	}
 
 	var parsedKey any
@@ -152,16 +295,26 @@ func toPublicPEM(privatePEM string) (string, error) {
 	if err != nil {
 		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
-			return "", err
+			parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return "", err
+			}
 		}
 	}
 
-	pk, ok := parsedKey.(*rsa.PrivateKey)
-	if !ok {
-		return "", errors.New("key is not a valid RSA private key")
+	var publicKey crypto.PublicKey
+	switch pk := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		publicKey = &pk.PublicKey
+	case *ecdsa.PrivateKey:
+		publicKey = &pk.PublicKey
+	case ed25519.PrivateKey:
+		publicKey = pk.Public()
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", parsedKey)
 	}
 
-	asn1Bytes, err := x509.MarshalPKIXPublicKey(&pk.PublicKey)
+	asn1Bytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		return "", fmt.Errorf("marshaling public key: %w", err)
 	}
@@ -0,0 +1,78 @@
+package keystore_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rmsj/service/foundation/keystore"
+)
+
+func Test_Rotate(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := keystore.Rotate(dir, keystore.AlgRS256, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to rotate into an empty folder: %s", err)
+	}
+
+	ks := keystore.New()
+	if _, err := ks.LoadByFileSystem(os.DirFS(dir)); err != nil {
+		t.Fatalf("Should be able to load the keys: %s", err)
+	}
+
+	if kid := ks.ActiveKID(); kid != "" {
+		t.Fatalf("Should not sign with a key in its grace period, got %q", kid)
+	}
+
+	if _, exists := ks.PublicKeys()[first.KID]; !exists {
+		t.Fatal("Should publish a key in its grace period")
+	}
+
+	// Rotating with no grace and no retirement promotes the new key now and
+	// retires the previous one straight away.
+
+	second, err := keystore.Rotate(dir, keystore.AlgRS256, 0, 0)
+	if err != nil {
+		t.Fatalf("Should be able to rotate: %s", err)
+	}
+
+	if _, err := ks.Reload(); err != nil {
+		t.Fatalf("Should be able to reload the keys: %s", err)
+	}
+
+	if kid := ks.ActiveKID(); kid != second.KID {
+		t.Fatalf("Should sign with the promoted key, got %q, exp %q", kid, second.KID)
+	}
+
+	if keys := ks.PublicKeys(); len(keys) != 1 {
+		t.Fatalf("Should only publish the promoted key, got %d keys", len(keys))
+	}
+
+	if _, err := ks.PrivateKey(first.KID); err == nil {
+		t.Fatal("Should not be able to use a retired key")
+	}
+
+	third, err := keystore.Rotate(dir, keystore.AlgRS256, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to rotate: %s", err)
+	}
+
+	if !slices.Contains(third.Retired, first.KID) {
+		t.Fatalf("Should remove the retired key, got %v", third.Retired)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, first.KID+".pem")); !os.IsNotExist(err) {
+		t.Fatal("Should delete the retired key file")
+	}
+
+	if _, err := ks.Reload(); err != nil {
+		t.Fatalf("Should be able to reload the keys: %s", err)
+	}
+
+	if kid := ks.ActiveKID(); kid != second.KID {
+		t.Fatalf("Should keep signing with the current key during the grace period, got %q", kid)
+	}
+}
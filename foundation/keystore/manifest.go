@@ -0,0 +1,175 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ManifestFile is the name of the file, kept next to the PEM files, that
+// records when each key starts signing and when it is retired. Keys missing
+// from the manifest can verify tokens and never retire.
+const ManifestFile = "keys.json"
+
+type manifest struct {
+	Keys []manifestKey `json:"keys"`
+}
+
+type manifestKey struct {
+	KID         string    `json:"kid"`
+	ActivatesAt time.Time `json:"activatesAt,omitzero"`
+	RetiresAt   time.Time `json:"retiresAt,omitzero"`
+}
+
+func (m manifest) find(kid string) manifestKey {
+	for _, mk := range m.Keys {
+		if mk.KID == kid {
+			return mk
+		}
+	}
+
+	return manifestKey{KID: kid}
+}
+
+func readManifest(fsys fs.FS) (manifest, error) {
+	data, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return manifest{}, nil
+		}
+		return manifest{}, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return manifest{}, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// writeManifest replaces the manifest in the directory. The file is written
+// aside and renamed so a reload never sees a partial manifest.
+func writeManifest(dir string, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, ManifestFile)); err != nil {
+		return fmt.Errorf("replacing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Rotation describes the outcome of a key rotation.
+type Rotation struct {
+	KID         string
+	ActivatesAt time.Time
+	Retiring    map[string]time.Time
+	Retired     []string
+}
+
+// Rotate generates a new signing key for the algorithm in the directory.
+// The new key is published for verification straight away and starts
+// signing once the grace period has passed, giving verifiers time to pick it
+// up. The keys signing until then are retired retireAfter later, once the
+// tokens they signed have expired. Keys whose retirement has passed are
+// removed.
+func Rotate(dir string, alg string, grace time.Duration, retireAfter time.Duration) (Rotation, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m, err := readManifest(os.DirFS(dir))
+	if err != nil {
+		return Rotation{}, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Rotation{}, fmt.Errorf("reading directory: %w", err)
+	}
+
+	// Keys added to the directory by hand are brought into the manifest so
+	// they are retired like any other.
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), ".pem")
+		if !slices.ContainsFunc(m.Keys, func(mk manifestKey) bool { return mk.KID == kid }) {
+			m.Keys = append(m.Keys, manifestKey{KID: kid})
+		}
+	}
+
+	rot := Rotation{
+		KID:         uuid.NewString(),
+		ActivatesAt: now.Add(grace),
+		Retiring:    make(map[string]time.Time),
+	}
+
+	keys := make([]manifestKey, 0, len(m.Keys)+1)
+	for _, mk := range m.Keys {
+		if !mk.RetiresAt.IsZero() && !mk.RetiresAt.After(now) {
+			if err := os.Remove(filepath.Join(dir, mk.KID+".pem")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return Rotation{}, fmt.Errorf("removing key: %w", err)
+			}
+			rot.Retired = append(rot.Retired, mk.KID)
+			continue
+		}
+
+		if mk.RetiresAt.IsZero() {
+			mk.RetiresAt = rot.ActivatesAt.Add(retireAfter)
+		}
+
+		rot.Retiring[mk.KID] = mk.RetiresAt
+		keys = append(keys, mk)
+	}
+
+	if err := writeKey(filepath.Join(dir, rot.KID+".pem"), alg); err != nil {
+		return Rotation{}, err
+	}
+
+	m.Keys = append(keys, manifestKey{
+		KID:         rot.KID,
+		ActivatesAt: rot.ActivatesAt,
+	})
+
+	if err := writeManifest(dir, m); err != nil {
+		return Rotation{}, err
+	}
+
+	return rot, nil
+}
+
+// writeKey generates a new private key for the algorithm and writes it in
+// PEM form.
+func writeKey(fileName string, alg string) error {
+	privatePEM, err := GeneratePrivatePEM(alg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(fileName, privatePEM, 0o600); err != nil {
+		return fmt.Errorf("writing key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package totp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rmsj/service/foundation/totp"
+)
+
+// secret is the RFC 6238 SHA1 test key "12345678901234567890" in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func Test_Code(t *testing.T) {
+	tt := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tst := range tt {
+		code, err := totp.Code(secret, totp.Step(time.Unix(tst.unix, 0)))
+		if err != nil {
+			t.Fatalf("Should be able to generate a code for %d : %s", tst.unix, err)
+		}
+
+		if code != tst.code {
+			t.Errorf("Should get the RFC code for %d : got %s, exp %s", tst.unix, code, tst.code)
+		}
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok, err := totp.Validate(secret, "081804", now.Add(totp.Period), 1)
+	if err != nil || !ok {
+		t.Fatalf("Should accept a code from the previous step : ok[%v] err[%v]", ok, err)
+	}
+
+	if step != totp.Step(now) {
+		t.Errorf("Should return the matching step : got %d, exp %d", step, totp.Step(now))
+	}
+
+	if _, ok, _ := totp.Validate(secret, "081804", now.Add(3*totp.Period), 1); ok {
+		t.Errorf("Should reject a code outside the allowed skew")
+	}
+
+	if _, _, err := totp.Validate("not base32!", "081804", now, 0); err == nil {
+		t.Errorf("Should reject an invalid secret")
+	}
+}
+
+func Test_URI(t *testing.T) {
+	uri := totp.URI("service project", "jack@example.com", secret)
+
+	exp := "otpauth://totp/service%20project:jack@example.com?algorithm=SHA1&digits=6&issuer=service+project&period=30&secret=" + secret
+	if uri != exp {
+		t.Errorf("Should build the otpauth URI : got %s, exp %s", uri, exp)
+	}
+}